@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"order-management-system/internal/config"
+	"os"
+)
+
+const configUsage = `usage: main config <command>
+
+  print   muestra la configuración efectiva en formato YAML, con los secretos ocultos`
+
+// runConfig ejecuta el subcomando config y devuelve el código de salida
+func runConfig(cfg *config.Config, args []string) int {
+	if len(args) != 1 || args[0] != "print" {
+		fmt.Fprintln(os.Stderr, configUsage)
+		return 2
+	}
+	if err := config.Print(os.Stdout, cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "config print: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -1,59 +1,312 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"net/http"
+	"order-management-system/internal/clock"
 	"order-management-system/internal/config"
+	"order-management-system/internal/dashboard"
+	"order-management-system/internal/domain"
 	"order-management-system/internal/handlers"
+	"order-management-system/internal/health"
+	"order-management-system/internal/ids"
+	"order-management-system/internal/jobs"
+	"order-management-system/internal/logging"
+	"order-management-system/internal/migrations"
+	"order-management-system/internal/notifications"
+	"order-management-system/internal/outbox"
+	"order-management-system/internal/payments"
+	"order-management-system/internal/realtime"
 	"order-management-system/internal/repositories"
 	"order-management-system/internal/services"
+	"order-management-system/internal/shipping"
+	"order-management-system/internal/webhooks"
 	"os"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slog"
 )
 
 func main() {
+	// Configuración: valores por defecto, archivo (--config o CONFIG_FILE), entorno y flags
+	cfg, args, err := config.Load(os.Args[1:], os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		config.Usage(os.Stderr)
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Logger estructurado; también recibe lo que se escriba con el paquete log
+	levels, err := cfg.Log.Levels()
+	if err != nil {
+		log.Fatal(err)
+	}
+	logger, err := logging.New(os.Stderr, cfg.Log.Format, levels)
+	if err != nil {
+		log.Fatal(err)
+	}
+	slog.SetDefault(logger)
+	ctx := context.Background()
+
+	// "main migrate ..." administra el esquema y "main config print" muestra la configuración,
+	// sin levantar el servidor
+	if len(args) > 0 {
+		switch args[0] {
+		case "migrate":
+			os.Exit(runMigrate(cfg, args[1:]))
+		case "config":
+			os.Exit(runConfig(cfg, args[1:]))
+		default:
+			config.Usage(os.Stderr)
+			os.Exit(2)
+		}
+	}
+
+	// Reloj e identificadores públicos compartidos por la base y los servicios
+	systemClock := clock.System()
+	idGenerator := ids.NewULIDGenerator(systemClock)
+
 	// Initialize database
-	db, err := config.InitDB()
+	db, err := config.InitDB(ctx, cfg.Database, systemClock)
+	if err != nil {
+		fatal("failed to initialize database", err)
+	}
+
+	sqlDB, err := db.DB()
 	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+		fatal("failed to get database handle", err)
 	}
 
 	// Seed database with initial data
-	if err := config.SeedDatabase(db); err != nil {
-		log.Printf("Warning: Failed to seed database: %v", err)
+	if err := config.SeedDatabase(ctx, db); err != nil {
+		slog.Warn("failed to seed database", "error", err)
 	}
 
 	// Initialize repositories
 	userRepo := repositories.NewUserRepository(db)
 	productRepo := repositories.NewProductRepository(db)
 	orderRepo := repositories.NewOrderRepository(db)
+	promotionRepo := repositories.NewPromotionRepository(db)
+	taxRuleRepo := repositories.NewTaxRuleRepository(db)
+	addressRepo := repositories.NewAddressRepository(db)
+	paymentRepo := repositories.NewPaymentRepository(db)
+	refundRepo := repositories.NewRefundRepository(db)
+	returnRepo := repositories.NewReturnRepository(db)
+	orderChangeRepo := repositories.NewOrderChangeRepository(db)
+	outboxRepo := repositories.NewOutboxRepository(db)
+	webhookRepo := repositories.NewWebhookRepository(db)
+	webhookDeliveryRepo := repositories.NewWebhookDeliveryRepository(db)
+	notificationPreferenceRepo := repositories.NewNotificationPreferenceRepository(db)
+	notificationRepo := repositories.NewNotificationRepository(db)
+	jobRepo := repositories.NewJobRepository(db)
+	reportRepo := repositories.NewReportRepository(db)
+
+	// Los servicios que guardan varios cambios juntos comparten el transactor
+	transactor := repositories.NewTransactor(db)
 
 	// Initialize services
-	orderService := services.NewOrderService(orderRepo, productRepo, userRepo)
+	promotionService := services.NewPromotionService(promotionRepo)
+	taxService := services.NewTaxService(taxRuleRepo)
+	addressService := services.NewAddressService(addressRepo, userRepo)
+	// El simulador guarda sus autorizaciones en la base; en producción se exige una pasarela real
+	var gateway payments.Gateway = payments.NewSimulator(payments.WithAuthorizationStore(repositories.NewSimulatedAuthorizationRepository(db)))
+	if cfg.Payments.Gateway == "http" {
+		gateway = payments.NewHTTPGateway(cfg.Payments.GatewayName, cfg.Payments.APIURL, string(cfg.Payments.APIKey), nil)
+	}
+	paymentService := services.NewPaymentService(paymentRepo, orderRepo, gateway)
+	refundService := services.NewRefundService(refundRepo, orderRepo, productRepo, paymentService, services.WithRefundTransactor(transactor))
+	returnService := services.NewReturnService(returnRepo, orderRepo, productRepo, refundService, services.WithReturnTransactor(transactor))
+	// Los webhooks no pueden apuntar a la red interna salvo que se habilite para desarrollo local
+	var senderOpts []webhooks.SenderOption
+	webhookOpts := []services.WebhookServiceOption{services.WithWebhookClock(systemClock)}
+	if cfg.Webhooks.AllowPrivateNetworks {
+		senderOpts = append(senderOpts, webhooks.WithPrivateNetworks())
+		webhookOpts = append(webhookOpts, services.WithPrivateWebhookTargets())
+	}
+	webhookService := services.NewWebhookService(webhookRepo, webhookDeliveryRepo, webhooks.NewHTTPSender(nil, senderOpts...), webhookOpts...)
+	reportService := services.NewReportService(reportRepo)
+
+	taxCalculator := services.NewRuleTaxCalculator(taxRuleRepo, domain.Jurisdiction{
+		Country: cfg.Tax.Country,
+		Region:  cfg.Tax.Region,
+	})
+
+	// Las etiquetas del transportista local se guardan en la base para que el seguimiento sobreviva a los reinicios
+	labelStore := repositories.NewShippingLabelRepository(db)
+	var carrier shipping.Carrier = shipping.DefaultLocalCarrier(shipping.WithLabelStore(labelStore))
+	if cfg.Shipping.Carrier == "http" {
+		carrier = shipping.NewHTTPCarrier(cfg.Shipping.CarrierName, cfg.Shipping.APIURL, string(cfg.Shipping.APIKey), nil)
+	}
+
+	// Hub de actualizaciones en tiempo real para GET /api/orders/stream
+	orderHub := realtime.NewHub()
+
+	orderOptions := []services.OrderServiceOption{
+		services.WithPromotions(promotionService),
+		services.WithTaxCalculator(taxCalculator),
+		services.WithAddresses(addressService),
+		services.WithCarrier(carrier),
+		services.WithPayments(paymentService),
+		services.WithRefunds(refundService),
+		services.WithChangeLog(orderChangeRepo),
+		services.WithOutbox(transactor),
+		services.WithPublisher(orderHub),
+		services.WithClock(systemClock),
+		services.WithIDGenerator(idGenerator),
+	}
+	// Números de pedido para clientes (ORD-2024-XXXXXXXX-C); ORDER_NUMBER_PREFIX cambia el prefijo
+	orderNumbers, err := ids.NewOrderNumberGenerator(systemClock, cfg.Orders.NumberPrefix)
+	if err != nil {
+		fatal("invalid ORDER_NUMBER_PREFIX", err)
+	}
+	orderOptions = append(orderOptions, services.WithOrderNumbers(orderNumbers))
+	if cfg.Orders.AllowNumericIDs {
+		orderOptions = append(orderOptions, services.WithNumericOrderIDs())
+	}
+	orderService := services.NewOrderService(orderRepo, productRepo, userRepo, orderOptions...)
+	// Los pedidos anteriores a los identificadores públicos los reciben al arrancar: sin
+	// ALLOW_NUMERIC_ORDER_IDS no habría otra forma de referirse a ellos
+	if assigned, err := orderService.AssignMissingIdentifiers(ctx); err != nil {
+		fatal("failed to assign order identifiers", err)
+	} else if assigned > 0 {
+		slog.Info("assigned public identifiers to existing orders", "orders", assigned)
+	}
+
+	// Emails a clientes: SMTP real con MAIL_DRIVER=smtp, si no se guardan en un directorio local
+	renderer, err := notifications.NewRenderer()
+	if err != nil {
+		fatal("failed to load email templates", err)
+	}
+	var mailer notifications.Mailer
+	if cfg.Mail.Driver == "smtp" {
+		mailer = notifications.NewSMTPMailer(cfg.Mail.SMTPHost, cfg.Mail.SMTPPort,
+			cfg.Mail.SMTPUsername, string(cfg.Mail.SMTPPassword), cfg.Mail.From,
+			notifications.WithSMTPTimeout(cfg.Mail.SMTPTimeout))
+	} else {
+		mailbox, err := notifications.NewMailbox(cfg.Mail.MailboxDir, cfg.Mail.From)
+		if err != nil {
+			fatal("failed to create mailbox", err)
+		}
+		mailer = mailbox
+	}
+	notificationService := services.NewNotificationService(notificationPreferenceRepo, notificationRepo,
+		userRepo, orderRepo, renderer, mailer, services.WithNotificationClock(systemClock))
+
+	// Entrega de eventos de dominio guardados en el outbox
+	dispatcher := outbox.NewDispatcher(outboxRepo, outbox.WithClock(systemClock))
+	dispatcher.Subscribe("log", func(ctx context.Context, event domain.OutboxEvent) error {
+		logging.For(ctx, "outbox").Info("event", "aggregate_type", event.AggregateType, "aggregate_id", event.AggregateID)
+		return nil
+	})
+	dispatcher.Subscribe("webhooks", webhookService.HandleEvent)
+	dispatcher.Subscribe("notifications", notificationService.HandleEvent, notifications.TemplateEvents...)
+
+	// Tablero de operaciones por WebSocket; sólo se habilita si hay tokens configurados
+	var opsHub *dashboard.Hub
+	if tokens := cfg.Dashboard.TokenList(); len(tokens) > 0 {
+		opsHub = dashboard.NewHub(tokens,
+			dashboard.WithLowStockThreshold(cfg.Dashboard.LowStockThreshold),
+			dashboard.WithProducts(productRepo),
+		)
+		dispatcher.Subscribe("dashboard", opsHub.HandleEvent)
+	}
+	dispatcher.Start()
+
+	// Cancelación automática de pedidos PENDING abandonados
+	stalePolicy := services.StaleOrderPolicy{Timeout: cfg.Orders.StaleTimeout}
+	if stalePolicy.ByPaymentMethod, err = services.ParsePaymentMethodTimeouts(cfg.Orders.StaleTimeouts); err != nil {
+		fatal("invalid STALE_ORDER_TIMEOUTS", err)
+	}
+	staleOrderSweeper := services.NewStaleOrderSweeper(orderService, orderRepo, stalePolicy,
+		services.WithSweeperClock(systemClock))
+
+	// Trabajos en segundo plano: reintentos de webhooks y emails, y reportes programados
+	jobQueue := jobs.NewQueue(jobRepo, jobs.WithConcurrency(cfg.Jobs.Workers), jobs.WithClock(systemClock))
+	jobQueue.Register("webhooks.deliver_due", func(ctx context.Context, job domain.Job) error {
+		return webhookService.DeliverDue(ctx)
+	})
+	jobQueue.Register("notifications.send_due", func(ctx context.Context, job domain.Job) error {
+		return notificationService.SendDue(ctx)
+	})
+	jobQueue.Register("reports.daily_sales", reportService.RunDailySales)
+	jobQueue.Register("orders.cancel_stale", staleOrderSweeper.RunSweep)
+	for _, s := range []struct {
+		name, spec, jobType string
+		payload             interface{}
+	}{
+		{"webhook-retries", "@every 30s", "webhooks.deliver_due", struct{}{}},
+		{"notification-retries", "@every 1m", "notifications.send_due", struct{}{}},
+		{"daily-sales-report", "5 0 * * *", "reports.daily_sales", services.DailySalesPayload{}},
+		{"stale-orders", "@every 5m", "orders.cancel_stale", services.StaleSweepPayload{DryRun: cfg.Orders.StaleDryRun}},
+	} {
+		if err := jobQueue.Schedule(s.name, s.spec, s.jobType, s.payload); err != nil {
+			fatal("failed to schedule "+s.name, err)
+		}
+	}
+	jobQueue.Start()
+
+	// Chequeos de salud: liveness sólo mira los workers; readiness también la base, el
+	// esquema y el atraso del outbox, que sólo degrada la instancia
+	migrator, err := migrations.New(db, migrations.WithClock(systemClock.Now))
+	if err != nil {
+		fatal("failed to create migrator", err)
+	}
+	healthRegistry := health.NewRegistry(
+		health.WithTimeout(cfg.Health.CheckTimeout),
+		health.WithCacheTTL(cfg.Health.CacheTTL),
+	)
+	healthRegistry.Register("database", health.Database(sqlDB))
+	healthRegistry.Register("migrations", health.Migrations(migrator))
+	healthRegistry.Register("jobs", health.Heartbeat(jobQueue.LastHeartbeat, cfg.Health.HeartbeatMaxAge), health.Liveness())
+	healthRegistry.Register("outbox_dispatcher", health.Heartbeat(dispatcher.LastHeartbeat, cfg.Health.HeartbeatMaxAge), health.Liveness())
+	healthRegistry.Register("outbox_lag", health.OutboxLag(dispatcher.Lag, cfg.Health.OutboxMaxLag), health.Optional())
 
 	// Initialize handlers
 	userHandler := handlers.NewUserHandler(userRepo)
+	addressHandler := handlers.NewAddressHandler(addressService)
 	productHandler := handlers.NewProductHandler(productRepo)
 	orderHandler := handlers.NewOrderHandler(orderService)
+	orderStreamHandler := handlers.NewOrderStreamHandler(orderHub, orderService, realtime.DefaultHeartbeat)
+	promotionHandler := handlers.NewPromotionHandler(promotionService)
+	taxHandler := handlers.NewTaxHandler(taxService)
+	paymentHandler := handlers.NewPaymentHandler(paymentService)
+	refundHandler := handlers.NewRefundHandler(refundService)
+	returnHandler := handlers.NewReturnHandler(returnService)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	jobHandler := handlers.NewJobHandler(jobQueue)
+	staleOrderHandler := handlers.NewStaleOrderHandler(staleOrderSweeper)
+	adminHandler := handlers.NewAdminHandler(sqlDB)
+	healthHandler := handlers.NewHealthHandler(healthRegistry)
 
-	// Setup Gin router
-	router := gin.Default()
+	// Setup Gin router: cada request recibe un X-Request-ID y queda en el log; las sondas
+	// de salud sólo se registran en debug
+	router := gin.New()
+	router.Use(logging.Recovery())
+	router.Use(logging.Middleware(idGenerator, "/health", "/health/live", "/health/ready"))
 
 	// CONFIGURACIÓN DE CORS CORREGIDA
 	router.Use(cors.New(cors.Config{
 		// Usar AllowAllOrigins: true es lo más fácil para que no falle en Render
 		AllowAllOrigins:  true,
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", logging.RequestIDHeader},
+		ExposeHeaders:    []string{"Content-Length", logging.RequestIDHeader},
 		AllowCredentials: true,
 	}))
 
-	// Health check
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok"})
-	})
+	// Health checks; /health se mantiene como alias de readiness
+	router.GET("/health", healthHandler.Ready)
+	router.GET("/health/live", healthHandler.Live)
+	router.GET("/health/ready", healthHandler.Ready)
 
 	// API routes
 	api := router.Group("/api")
@@ -64,6 +317,13 @@ func main() {
 			users.GET("", userHandler.GetAll)
 			users.GET("/:id", userHandler.GetByID)
 			users.POST("", userHandler.Create)
+			users.GET("/:id/addresses", addressHandler.GetByUser)
+			users.POST("/:id/addresses", addressHandler.Create)
+			users.PUT("/:id/addresses/:addressId", addressHandler.Update)
+			users.DELETE("/:id/addresses/:addressId", addressHandler.Delete)
+			users.GET("/:id/notification-preferences", notificationHandler.GetPreferences)
+			users.PUT("/:id/notification-preferences", notificationHandler.UpdatePreferences)
+			users.GET("/:id/notifications", notificationHandler.GetByUser)
 		}
 
 		// Product routes
@@ -75,26 +335,136 @@ func main() {
 		}
 
 		// Order routes
-		orders := api.Group("/orders")
+		orders := api.Group("/orders", handlers.ResolveOrderParam(orderService))
 		{
 			orders.GET("", orderHandler.GetAll)
+			orders.GET("/stream", orderStreamHandler.Stream)
 			orders.GET("/:id", orderHandler.GetByID)
 			orders.GET("/user/:userId", orderHandler.GetByUserID)
 			orders.POST("", orderHandler.Create)
+			orders.POST("/stale/sweep", staleOrderHandler.Sweep)
 			orders.PATCH("/:id/confirm", orderHandler.Confirm)
 			orders.PATCH("/:id/ship", orderHandler.Ship)
 			orders.PATCH("/:id/cancel", orderHandler.Cancel)
+			orders.PATCH("/:id/items", orderHandler.AmendItems)
+			orders.GET("/:id/changes", orderHandler.Changes)
+			orders.POST("/:id/split", orderHandler.Split)
+			orders.POST("/:id/merge", orderHandler.Merge)
+			orders.GET("/:id/tracking", orderHandler.Tracking)
+			orders.GET("/:id/payments", paymentHandler.GetByOrder)
+			orders.POST("/:id/payments", paymentHandler.Authorize)
+			orders.GET("/:id/refunds", refundHandler.GetByOrder)
+			orders.POST("/:id/refunds", refundHandler.Create)
+			orders.GET("/:id/returns", returnHandler.GetByOrder)
+			orders.POST("/:id/returns", returnHandler.Create)
+		}
+
+		// Return (RMA) routes
+		returns := api.Group("/returns")
+		{
+			returns.GET("/:id", returnHandler.GetByID)
+			returns.PATCH("/:id/approve", returnHandler.Approve)
+			returns.PATCH("/:id/reject", returnHandler.Reject)
+			returns.PATCH("/:id/receive", returnHandler.Receive)
+			returns.PATCH("/:id/inspect", returnHandler.Inspect)
+		}
+
+		// Operations dashboard (WebSocket)
+		if opsHub != nil {
+			api.GET("/ops/ws", gin.WrapH(opsHub))
+		}
+
+		// Shipping routes
+		api.POST("/shipping/rates", orderHandler.QuoteShipping)
+
+		// Promotion routes
+		promotions := api.Group("/promotions")
+		{
+			promotions.GET("", promotionHandler.GetAll)
+			promotions.GET("/:id", promotionHandler.GetByID)
+			promotions.POST("", promotionHandler.Create)
+		}
+
+		// Webhook subscription routes
+		webhookRoutes := api.Group("/webhooks")
+		{
+			webhookRoutes.GET("", webhookHandler.GetAll)
+			webhookRoutes.POST("", webhookHandler.Create)
+			webhookRoutes.GET("/:id", webhookHandler.GetByID)
+			webhookRoutes.PUT("/:id", webhookHandler.Update)
+			webhookRoutes.DELETE("/:id", webhookHandler.Delete)
+			webhookRoutes.GET("/:id/deliveries", webhookHandler.Deliveries)
+			webhookRoutes.POST("/:id/deliveries/:deliveryId/redeliver", webhookHandler.Redeliver)
+		}
+
+		// Notification routes
+		notificationRoutes := api.Group("/notifications")
+		{
+			notificationRoutes.POST("/:id/retry", notificationHandler.Retry)
+		}
+
+		// Background job admin routes
+		jobRoutes := api.Group("/jobs")
+		{
+			jobRoutes.GET("", jobHandler.GetAll)
+			jobRoutes.DELETE("", jobHandler.Purge)
+			jobRoutes.GET("/schedules", jobHandler.Schedules)
+			jobRoutes.GET("/:id", jobHandler.GetByID)
+			jobRoutes.POST("/:id/retry", jobHandler.Retry)
+		}
+
+		// Admin routes
+		admin := api.Group("/admin")
+		{
+			admin.GET("/db-pool", adminHandler.DBPool)
+		}
+
+		// Tax rule routes
+		taxRules := api.Group("/tax-rules")
+		{
+			taxRules.GET("", taxHandler.GetAll)
+			taxRules.POST("", taxHandler.Create)
 		}
 	}
 
 	// Start server
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	srv := &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.Server.Port),
+		Handler:           router,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	// Los streams SSE y WebSocket no terminan solos; se cierran al empezar el apagado para
+	// que no retengan la espera de los demás requests
+	srv.RegisterOnShutdown(orderHub.Close)
+	if opsHub != nil {
+		srv.RegisterOnShutdown(opsHub.Close)
 	}
 
-	log.Printf("Server starting on port %s", port)
-	if err := router.Run(":" + port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	exitCode := 0
+	slog.Info("server starting", "port", cfg.Server.Port)
+	if err := serve(srv, cfg.Server.ShutdownTimeout); err != nil {
+		slog.Error("server stopped", "error", err)
+		exitCode = 1
 	}
+
+	// Apagado en orden: los trabajos en curso terminan antes de dejar de entregar eventos,
+	// y la base se cierra al final
+	jobQueue.Stop()
+	dispatcher.Stop()
+	orderHub.Close()
+	if opsHub != nil {
+		opsHub.Close()
+	}
+	if err := sqlDB.Close(); err != nil {
+		slog.Error("failed to close database", "error", err)
+		exitCode = 1
+	}
+	slog.Info("shutdown complete")
+	os.Exit(exitCode)
+}
+
+// fatal registra el error y termina el proceso
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
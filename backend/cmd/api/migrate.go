@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"order-management-system/internal/clock"
+	"order-management-system/internal/config"
+	"order-management-system/internal/migrations"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const migrateUsage = `usage: main migrate <command>
+
+  up [N]        aplica las migraciones pendientes (o sólo N)
+  down [N]      revierte la última migración (o las últimas N)
+  status        lista las migraciones y si están aplicadas
+  create NAME   crea los scripts vacíos de una migración en MIGRATIONS_DIR
+                (por defecto internal/migrations/sql)`
+
+// runMigrate ejecuta el subcomando migrate y devuelve el código de salida
+func runMigrate(cfg *config.Config, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	if args[0] == "create" {
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, migrateUsage)
+			return 2
+		}
+		created, err := migrations.Create(cfg.Database.MigrationsDir, args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate create: %v\n", err)
+			return 1
+		}
+		for _, file := range created {
+			fmt.Println(file)
+		}
+		return 0
+	}
+
+	steps := 0
+	if len(args) > 1 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n <= 0 {
+			fmt.Fprintf(os.Stderr, "migrate %s: invalid number of steps %q\n", args[0], args[1])
+			return 2
+		}
+		steps = n
+	}
+
+	db, err := config.OpenDB(cfg.Database, clock.System())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "migrate: %v\n", err)
+		return 1
+	}
+	migrator, err := migrations.New(db)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "migrate: %v\n", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx, steps)
+		for _, migration := range applied {
+			fmt.Printf("applied  %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate up: %v\n", err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Println("database is up to date")
+		}
+	case "down":
+		reverted, err := migrator.Down(ctx, steps)
+		for _, migration := range reverted {
+			fmt.Printf("reverted %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate down: %v\n", err)
+			return 1
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate status: %v\n", err)
+			return 1
+		}
+		printMigrationStatus(statuses)
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+	return 0
+}
+
+func printMigrationStatus(statuses []migrations.Status) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT\tNOTE")
+	for _, s := range statuses {
+		appliedAt := "pending"
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Format(time.RFC3339)
+		}
+		note := ""
+		switch {
+		case s.Modified:
+			note = "script modified after applying"
+		case s.Missing:
+			note = "script missing"
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", s.Version, s.Name, appliedAt, note)
+	}
+	w.Flush()
+}
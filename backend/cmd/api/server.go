@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"order-management-system/internal/logging"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// serve atiende requests hasta recibir SIGINT o SIGTERM y después espera, como mucho
+// timeout, a que terminen los que están en curso. Devuelve error si el servidor no pudo
+// arrancar o si quedaron requests sin terminar.
+func serve(srv *http.Server, timeout time.Duration) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+	// Una segunda señal corta el proceso sin esperar
+	stop()
+	logging.For(ctx, "server").Info("shutting down, waiting for in-flight requests", "timeout", timeout.String())
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		srv.Close()
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("requests still running after %s were cut", timeout)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+// Package backoff calcula la espera entre reintentos que comparten la cola de trabajos,
+// el outbox, los webhooks y los emails.
+package backoff
+
+import "time"
+
+// Exponential duplica la espera base en cada intento hasta el máximo indicado; el primer
+// intento espera base
+func Exponential(base, limit time.Duration, attempt int) time.Duration {
+	wait := base
+	for i := 1; i < attempt && wait < limit; i++ {
+		wait *= 2
+	}
+	if wait > limit {
+		wait = limit
+	}
+	return wait
+}
@@ -0,0 +1,24 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExponential_DoublesUpToLimit(t *testing.T) {
+	cases := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, time.Second},
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{4, 8 * time.Second},
+		{10, 30 * time.Second},
+	}
+	for _, c := range cases {
+		if got := Exponential(time.Second, 30*time.Second, c.attempt); got != c.want {
+			t.Errorf("Expected %s for attempt %d, got %s", c.want, c.attempt, got)
+		}
+	}
+}
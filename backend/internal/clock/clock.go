@@ -0,0 +1,52 @@
+// Package clock abstrae la hora actual para que los servicios y repositorios puedan
+// probarse con el tiempo congelado.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock devuelve la hora actual
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// System devuelve el reloj del sistema
+func System() Clock {
+	return systemClock{}
+}
+
+// Fake es un reloj que sólo avanza cuando se lo indica, para tests
+type Fake struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Set fija la hora del reloj
+func (f *Fake) Set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = now
+}
+
+// Advance adelanta el reloj en d
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
@@ -0,0 +1,274 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"order-management-system/internal/clock"
+	"order-management-system/internal/ids"
+	"order-management-system/internal/logging"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config es la configuración del servidor. Cada campo toma, de menor a mayor prioridad, su
+// valor por defecto, el de un archivo YAML o TOML (--config o CONFIG_FILE), el de su
+// variable de entorno y el de su flag, que se llama como la variable en minúsculas y con
+// guiones (PORT → --port, DB_HOST → --db-host).
+type Config struct {
+	Server    ServerConfig    `key:"server"`
+	Log       LogConfig       `key:"log"`
+	Database  DatabaseConfig  `key:"database"`
+	Orders    OrdersConfig    `key:"orders"`
+	Tax       TaxConfig       `key:"tax"`
+	Shipping  ShippingConfig  `key:"shipping"`
+	Payments  PaymentsConfig  `key:"payments"`
+	Webhooks  WebhooksConfig  `key:"webhooks"`
+	Mail      MailConfig      `key:"mail"`
+	Dashboard DashboardConfig `key:"dashboard"`
+	Jobs      JobsConfig      `key:"jobs"`
+	Health    HealthConfig    `key:"health"`
+}
+
+type ServerConfig struct {
+	Environment     string        `key:"environment" env:"APP_ENV" default:"development" usage:"development o production; production exige integraciones reales"`
+	Port            int           `key:"port" env:"PORT" default:"8080" usage:"puerto HTTP"`
+	ShutdownTimeout time.Duration `key:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" default:"30s" usage:"tiempo máximo para terminar los requests en curso al apagarse"`
+}
+
+type LogConfig struct {
+	Format     string `key:"format" env:"LOG_FORMAT" default:"text" usage:"text o json"`
+	Level      string `key:"level" env:"LOG_LEVEL" default:"info" usage:"nivel mínimo: debug, info, warn o error"`
+	Components string `key:"levels" env:"LOG_LEVELS" usage:"niveles por componente, como gorm=debug,jobs=warn"`
+}
+
+type DatabaseConfig struct {
+	Driver         string `key:"driver" env:"DB_DRIVER" usage:"sqlite, postgres o mysql; vacío usa postgres si hay DATABASE_URL y si no mysql"`
+	URL            Secret `key:"url" env:"DATABASE_URL" usage:"URL de conexión de Postgres"`
+	Host           string `key:"host" env:"DB_HOST" default:"localhost" usage:"host de MySQL"`
+	Port           int    `key:"port" env:"DB_PORT" default:"3306" usage:"puerto de MySQL"`
+	User           string `key:"user" env:"DB_USER" usage:"usuario de MySQL"`
+	Password       Secret `key:"password" env:"DB_PASSWORD" usage:"contraseña de MySQL"`
+	Name           string `key:"name" env:"DB_NAME" usage:"base de MySQL"`
+	SQLitePath     string `key:"sqlite_path" env:"SQLITE_PATH" default:"order_management.db" usage:"archivo de SQLite, o :memory:"`
+	MigrateOnStart bool   `key:"migrate_on_start" env:"MIGRATE_ON_START" default:"true" usage:"aplicar las migraciones pendientes al arrancar"`
+	MigrationsDir  string `key:"migrations_dir" env:"MIGRATIONS_DIR" default:"internal/migrations/sql" usage:"directorio donde migrate create escribe los scripts"`
+
+	// Pool de conexiones; con SQLite siempre se usa una sola conexión abierta
+	MaxOpenConns    int           `key:"max_open_conns" env:"DB_MAX_OPEN_CONNS" default:"25" usage:"conexiones abiertas como máximo; 0 es sin límite"`
+	MaxIdleConns    int           `key:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" default:"10" usage:"conexiones ociosas que se conservan"`
+	ConnMaxLifetime time.Duration `key:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" default:"30m" usage:"tiempo máximo de uso de una conexión; 0 es sin límite"`
+	ConnMaxIdleTime time.Duration `key:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME" default:"5m" usage:"tiempo máximo ociosa de una conexión; 0 es sin límite"`
+
+	SlowQueryThreshold time.Duration `key:"slow_query_threshold" env:"DB_SLOW_QUERY_THRESHOLD" default:"200ms" usage:"duración a partir de la que una consulta se registra como lenta; 0 lo deshabilita"`
+}
+
+type OrdersConfig struct {
+	NumberPrefix    string        `key:"number_prefix" env:"ORDER_NUMBER_PREFIX" default:"ORD" usage:"prefijo de los números de pedido"`
+	AllowNumericIDs bool          `key:"allow_numeric_ids" env:"ALLOW_NUMERIC_ORDER_IDS" usage:"aceptar también IDs numéricos de pedido en la API, por compatibilidad"`
+	StaleTimeout    time.Duration `key:"stale_timeout" env:"STALE_ORDER_TIMEOUT" default:"24h" usage:"antigüedad a partir de la que se cancela un pedido PENDING"`
+	StaleTimeouts   string        `key:"stale_timeouts" env:"STALE_ORDER_TIMEOUTS" usage:"timeouts por medio de pago, como simulator=72h,none=2h"`
+	StaleDryRun     bool          `key:"stale_dry_run" env:"STALE_ORDER_DRY_RUN" usage:"sólo informar los pedidos que se cancelarían"`
+}
+
+type TaxConfig struct {
+	Country string `key:"country" env:"TAX_DEFAULT_COUNTRY" default:"AR" usage:"país de la jurisdicción por defecto"`
+	Region  string `key:"region" env:"TAX_DEFAULT_REGION" usage:"región de la jurisdicción por defecto"`
+}
+
+type ShippingConfig struct {
+	Carrier     string `key:"carrier" env:"SHIPPING_CARRIER" default:"local" usage:"local o http"`
+	CarrierName string `key:"carrier_name" env:"CARRIER_NAME" default:"http" usage:"nombre del transportista HTTP"`
+	APIURL      string `key:"api_url" env:"CARRIER_API_URL" usage:"URL de la API del transportista"`
+	APIKey      Secret `key:"api_key" env:"CARRIER_API_KEY" usage:"clave de la API del transportista"`
+}
+
+type PaymentsConfig struct {
+	Gateway     string `key:"gateway" env:"PAYMENT_GATEWAY" default:"simulator" usage:"simulator o http"`
+	GatewayName string `key:"gateway_name" env:"PAYMENT_GATEWAY_NAME" default:"http" usage:"nombre de la pasarela HTTP"`
+	APIURL      string `key:"api_url" env:"PAYMENT_API_URL" usage:"URL de la API de la pasarela"`
+	APIKey      Secret `key:"api_key" env:"PAYMENT_API_KEY" usage:"clave de la API de la pasarela"`
+}
+
+type WebhooksConfig struct {
+	AllowPrivateNetworks bool `key:"allow_private_networks" env:"WEBHOOK_ALLOW_PRIVATE_NETWORKS" usage:"aceptar webhooks a direcciones internas (loopback, privadas, link-local); sólo para desarrollo"`
+}
+
+type MailConfig struct {
+	Driver       string        `key:"driver" env:"MAIL_DRIVER" default:"mailbox" usage:"mailbox (archivos locales) o smtp"`
+	From         string        `key:"from" env:"MAIL_FROM" usage:"remitente de los emails"`
+	MailboxDir   string        `key:"mailbox_dir" env:"MAILBOX_DIR" default:"mailbox" usage:"directorio de los emails con MAIL_DRIVER=mailbox"`
+	SMTPHost     string        `key:"smtp_host" env:"SMTP_HOST" usage:"servidor SMTP"`
+	SMTPPort     int           `key:"smtp_port" env:"SMTP_PORT" default:"587" usage:"puerto SMTP"`
+	SMTPUsername string        `key:"smtp_username" env:"SMTP_USERNAME" usage:"usuario SMTP"`
+	SMTPPassword Secret        `key:"smtp_password" env:"SMTP_PASSWORD" usage:"contraseña SMTP"`
+	SMTPTimeout  time.Duration `key:"smtp_timeout" env:"SMTP_TIMEOUT" default:"10s" usage:"tiempo máximo para conectar y enviar cada email"`
+}
+
+type DashboardConfig struct {
+	Tokens            Secret `key:"tokens" env:"OPS_DASHBOARD_TOKENS" usage:"tokens del tablero de operaciones separados por coma; vacío lo deshabilita"`
+	LowStockThreshold int    `key:"low_stock_threshold" env:"LOW_STOCK_THRESHOLD" default:"5" usage:"stock a partir del que se alerta en el tablero"`
+}
+
+type JobsConfig struct {
+	Workers int `key:"workers" env:"JOB_WORKERS" default:"4" usage:"trabajos en segundo plano en paralelo"`
+}
+
+type HealthConfig struct {
+	CheckTimeout    time.Duration `key:"check_timeout" env:"HEALTH_CHECK_TIMEOUT" default:"2s" usage:"tiempo máximo de cada chequeo de salud"`
+	CacheTTL        time.Duration `key:"cache_ttl" env:"HEALTH_CACHE_TTL" default:"2s" usage:"cuánto se reutiliza el resultado de los chequeos; 0 los corre en cada sonda"`
+	HeartbeatMaxAge time.Duration `key:"heartbeat_max_age" env:"HEALTH_HEARTBEAT_MAX_AGE" default:"1m" usage:"antigüedad máxima del último latido de los workers"`
+	OutboxMaxLag    time.Duration `key:"outbox_max_lag" env:"HEALTH_OUTBOX_MAX_LAG" default:"5m" usage:"antigüedad del evento pendiente más viejo a partir de la que la instancia figura degradada"`
+}
+
+// Secret es un valor sensible; al imprimirse se oculta y, si es una URL, sólo se oculta la contraseña
+type Secret string
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	if u, err := url.Parse(string(s)); err == nil && u.Host != "" {
+		if _, ok := u.User.Password(); ok {
+			return u.Redacted()
+		}
+	}
+	return "[REDACTED]"
+}
+
+// GoString oculta el valor también con %#v
+func (s Secret) GoString() string {
+	return strconv.Quote(s.String())
+}
+
+// Levels devuelve el nivel por defecto y los de cada componente
+func (c LogConfig) Levels() (logging.Levels, error) {
+	return logging.ParseLevels(c.Level, c.Components)
+}
+
+// Production indica si el servidor corre en producción
+func (c ServerConfig) Production() bool {
+	return c.Environment == "production"
+}
+
+// TokenList devuelve los tokens del tablero, o nil si no hay
+func (c DashboardConfig) TokenList() []string {
+	var tokens []string
+	for _, token := range strings.Split(string(c.Tokens), ",") {
+		if token = strings.TrimSpace(token); token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// normalize completa los valores que dependen de otros
+func (c *Config) normalize() {
+	c.Server.Environment = strings.ToLower(strings.TrimSpace(c.Server.Environment))
+	c.Database.Driver = strings.ToLower(strings.TrimSpace(c.Database.Driver))
+	if c.Database.Driver == "" {
+		c.Database.Driver = "mysql"
+		if c.Database.URL != "" {
+			c.Database.Driver = "postgres"
+		}
+	}
+	c.Log.Format = strings.ToLower(strings.TrimSpace(c.Log.Format))
+	c.Orders.NumberPrefix = strings.ToUpper(strings.TrimSpace(c.Orders.NumberPrefix))
+	c.Tax.Country = strings.ToUpper(strings.TrimSpace(c.Tax.Country))
+}
+
+// validate devuelve un problema por cada valor inválido, nombrado por su variable de entorno
+func (c *Config) validate() []string {
+	var problems []string
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(c.Server.Environment == "development" || c.Server.Environment == "production",
+		"APP_ENV: unknown environment %q, use development or production", c.Server.Environment)
+	check(validPort(c.Server.Port), "PORT: %d is not a valid port", c.Server.Port)
+	check(c.Server.ShutdownTimeout > 0, "SHUTDOWN_TIMEOUT: must be greater than zero")
+
+	check(c.Log.Format == "text" || c.Log.Format == "json", "LOG_FORMAT: unknown format %q, use text or json", c.Log.Format)
+	if _, err := logging.ParseLevel(c.Log.Level); err != nil {
+		problems = append(problems, "LOG_LEVEL: "+err.Error())
+	}
+	// El nivel por defecto se valida arriba; acá sólo interesan los de cada componente
+	if _, err := logging.ParseLevels("info", c.Log.Components); err != nil {
+		problems = append(problems, "LOG_LEVELS: "+err.Error())
+	}
+
+	switch c.Database.Driver {
+	case "postgres":
+		check(c.Database.URL != "", "DATABASE_URL: required when DB_DRIVER=postgres")
+	case "mysql":
+		check(c.Database.Name != "", "DB_NAME: required when DB_DRIVER=mysql")
+		check(validPort(c.Database.Port), "DB_PORT: %d is not a valid port", c.Database.Port)
+	case "sqlite":
+		check(c.Database.SQLitePath != "", "SQLITE_PATH: required when DB_DRIVER=sqlite")
+	default:
+		problems = append(problems, fmt.Sprintf("DB_DRIVER: unknown driver %q, use sqlite, postgres or mysql", c.Database.Driver))
+	}
+	check(c.Database.MaxOpenConns >= 0, "DB_MAX_OPEN_CONNS: must not be negative")
+	check(c.Database.MaxIdleConns >= 0, "DB_MAX_IDLE_CONNS: must not be negative")
+	check(c.Database.MaxOpenConns == 0 || c.Database.MaxIdleConns <= c.Database.MaxOpenConns,
+		"DB_MAX_IDLE_CONNS: %d is more than DB_MAX_OPEN_CONNS (%d)", c.Database.MaxIdleConns, c.Database.MaxOpenConns)
+	check(c.Database.ConnMaxLifetime >= 0, "DB_CONN_MAX_LIFETIME: must not be negative")
+	check(c.Database.ConnMaxIdleTime >= 0, "DB_CONN_MAX_IDLE_TIME: must not be negative")
+	check(c.Database.SlowQueryThreshold >= 0, "DB_SLOW_QUERY_THRESHOLD: must not be negative (0 disables it)")
+
+	if _, err := ids.NewOrderNumberGenerator(clock.System(), c.Orders.NumberPrefix); err != nil {
+		problems = append(problems, "ORDER_NUMBER_PREFIX: "+err.Error())
+	}
+	check(c.Orders.StaleTimeout >= 0, "STALE_ORDER_TIMEOUT: must not be negative (0 disables the sweep)")
+	check(len(c.Tax.Country) == 2, "TAX_DEFAULT_COUNTRY: %q is not a two-letter country code", c.Tax.Country)
+
+	switch c.Shipping.Carrier {
+	case "local":
+	case "http":
+		check(c.Shipping.APIURL != "", "CARRIER_API_URL: required when SHIPPING_CARRIER=http")
+	default:
+		problems = append(problems, fmt.Sprintf("SHIPPING_CARRIER: unknown carrier %q, use local or http", c.Shipping.Carrier))
+	}
+
+	switch c.Payments.Gateway {
+	case "simulator":
+		check(!c.Server.Production(), "PAYMENT_GATEWAY: the simulator approves test cards and cannot be used with APP_ENV=production")
+	case "http":
+		check(c.Payments.APIURL != "", "PAYMENT_API_URL: required when PAYMENT_GATEWAY=http")
+	default:
+		problems = append(problems, fmt.Sprintf("PAYMENT_GATEWAY: unknown gateway %q, use simulator or http", c.Payments.Gateway))
+	}
+
+	check(!c.Webhooks.AllowPrivateNetworks || !c.Server.Production(), "WEBHOOK_ALLOW_PRIVATE_NETWORKS: cannot be enabled with APP_ENV=production")
+
+	switch c.Mail.Driver {
+	case "mailbox":
+		check(!c.Server.Production(), "MAIL_DRIVER: the mailbox only writes local files and cannot be used with APP_ENV=production")
+		check(c.Mail.MailboxDir != "", "MAILBOX_DIR: required when MAIL_DRIVER=mailbox")
+	case "smtp":
+		check(c.Mail.SMTPHost != "", "SMTP_HOST: required when MAIL_DRIVER=smtp")
+		check(validPort(c.Mail.SMTPPort), "SMTP_PORT: %d is not a valid port", c.Mail.SMTPPort)
+		check(c.Mail.SMTPTimeout > 0, "SMTP_TIMEOUT: must be greater than zero")
+	default:
+		problems = append(problems, fmt.Sprintf("MAIL_DRIVER: unknown driver %q, use mailbox or smtp", c.Mail.Driver))
+	}
+
+	check(c.Dashboard.LowStockThreshold >= 0, "LOW_STOCK_THRESHOLD: must not be negative")
+	check(c.Jobs.Workers >= 1, "JOB_WORKERS: must be at least 1")
+
+	check(c.Health.CheckTimeout > 0, "HEALTH_CHECK_TIMEOUT: must be greater than zero")
+	check(c.Health.CacheTTL >= 0, "HEALTH_CACHE_TTL: must not be negative")
+	check(c.Health.HeartbeatMaxAge > 0, "HEALTH_HEARTBEAT_MAX_AGE: must be greater than zero")
+	check(c.Health.OutboxMaxLag > 0, "HEALTH_OUTBOX_MAX_LAG: must be greater than zero")
+	return problems
+}
+
+func validPort(port int) bool {
+	return port > 0 && port <= 65535
+}
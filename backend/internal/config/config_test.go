@@ -0,0 +1,169 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func envFrom(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := vars[key]
+		return value, ok
+	}
+}
+
+func TestLoad_DefaultsKeepPreviousBehavior(t *testing.T) {
+	cfg, args, err := Load([]string{"migrate", "up"}, envFrom(map[string]string{"DB_NAME": "orders", "PORT": ""}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(args) != 2 || args[0] != "migrate" {
+		t.Errorf("Expected remaining args, got %v", args)
+	}
+	if cfg.Server.Port != 8080 || cfg.Database.Driver != "mysql" || cfg.Database.Port != 3306 || !cfg.Database.MigrateOnStart ||
+		cfg.Orders.NumberPrefix != "ORD" || cfg.Orders.StaleTimeout != 24*time.Hour || cfg.Tax.Country != "AR" ||
+		cfg.Shipping.Carrier != "local" || cfg.Payments.Gateway != "simulator" || cfg.Server.Environment != "development" || cfg.Mail.Driver != "mailbox" || cfg.Mail.SMTPPort != 587 ||
+		cfg.Dashboard.LowStockThreshold != 5 || cfg.Jobs.Workers != 4 || cfg.Server.ShutdownTimeout != 30*time.Second ||
+		cfg.Database.MaxOpenConns != 25 || cfg.Database.MaxIdleConns != 10 || cfg.Database.ConnMaxLifetime != 30*time.Minute ||
+		cfg.Health.CheckTimeout != 2*time.Second || cfg.Health.OutboxMaxLag != 5*time.Minute ||
+		cfg.Log.Format != "text" || cfg.Log.Level != "info" || cfg.Database.SlowQueryThreshold != 200*time.Millisecond {
+		t.Errorf("Unexpected defaults %+v", cfg)
+	}
+
+	cfg, _, err = Load(nil, envFrom(map[string]string{"DATABASE_URL": "postgres://u:p@db/orders"}))
+	if err != nil || cfg.Database.Driver != "postgres" {
+		t.Errorf("Expected DATABASE_URL to select postgres, got %+v (%v)", cfg, err)
+	}
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	os.WriteFile(file, []byte(`
+server:
+  port: 7000
+database:
+  driver: sqlite
+  sqlite_path: file.db
+orders:
+  number_prefix: abc
+  stale_timeout: 2h
+dashboard:
+  tokens: [one, two]
+jobs:
+  workers: 2
+`), 0o644)
+
+	env := map[string]string{"CONFIG_FILE": file, "PORT": "7500", "ORDER_NUMBER_PREFIX": "env", "JOB_WORKERS": "3"}
+	cfg, _, err := Load([]string{"--port=9000", "--allow-numeric-order-ids"}, envFrom(env))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cfg.Server.Port != 9000 {
+		t.Errorf("Expected flag to win over env and file, got %d", cfg.Server.Port)
+	}
+	if cfg.Orders.NumberPrefix != "ENV" || cfg.Jobs.Workers != 3 {
+		t.Errorf("Expected env to win over file, got %q and %d", cfg.Orders.NumberPrefix, cfg.Jobs.Workers)
+	}
+	if cfg.Database.Driver != "sqlite" || cfg.Database.SQLitePath != "file.db" || cfg.Orders.StaleTimeout != 2*time.Hour {
+		t.Errorf("Expected file to win over defaults, got %+v", cfg.Database)
+	}
+	if tokens := cfg.Dashboard.TokenList(); len(tokens) != 2 || tokens[1] != "two" {
+		t.Errorf("Expected YAML list as tokens, got %v", tokens)
+	}
+	if !cfg.Orders.AllowNumericIDs {
+		t.Error("Expected boolean flag without value to be true")
+	}
+
+	toml := filepath.Join(dir, "config.toml")
+	os.WriteFile(toml, []byte("[database]\ndriver = \"sqlite\"\n\n[mail]\nsmtp_port = 2525\n"), 0o644)
+	cfg, _, err = Load([]string{"--config", toml}, envFrom(map[string]string{"CONFIG_FILE": file}))
+	if err != nil || cfg.Mail.SMTPPort != 2525 || cfg.Server.Port != 8080 {
+		t.Errorf("Expected --config to replace CONFIG_FILE, got %+v (%v)", cfg, err)
+	}
+}
+
+func TestLoad_ReportsEveryProblem(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(file, []byte("server:\n  prot: 80\n"), 0o644)
+
+	_, _, err := Load(nil, envFrom(map[string]string{"CONFIG_FILE": file, "JOB_WORKERS": "many", "MIGRATE_ON_START": "nope"}))
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+	for _, want := range []string{"server.prot", "JOB_WORKERS", "MIGRATE_ON_START"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to mention %s, got %v", want, err)
+		}
+	}
+
+	_, _, err = Load(nil, envFrom(map[string]string{
+		"DB_DRIVER": "oracle", "SHIPPING_CARRIER": "http", "MAIL_DRIVER": "smtp", "ORDER_NUMBER_PREFIX": "ORD1", "PORT": "70000",
+		"DB_MAX_OPEN_CONNS": "5", "DB_MAX_IDLE_CONNS": "8", "SHUTDOWN_TIMEOUT": "0s", "HEALTH_CHECK_TIMEOUT": "0s",
+		"LOG_FORMAT": "xml", "LOG_LEVEL": "loud", "LOG_LEVELS": "gorm", "PAYMENT_GATEWAY": "http",
+	}))
+	for _, want := range []string{"DB_DRIVER", "CARRIER_API_URL", "PAYMENT_API_URL", "SMTP_HOST", "ORDER_NUMBER_PREFIX", "PORT", "DB_MAX_IDLE_CONNS", "SHUTDOWN_TIMEOUT",
+		"HEALTH_CHECK_TIMEOUT", "LOG_FORMAT", "LOG_LEVEL:", "LOG_LEVELS"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to mention %s, got %v", want, err)
+		}
+	}
+}
+
+func TestLoad_ProductionRejectsSimulators(t *testing.T) {
+	_, _, err := Load(nil, envFrom(map[string]string{"DB_NAME": "orders", "APP_ENV": "Production"}))
+	if err == nil || !strings.Contains(err.Error(), "PAYMENT_GATEWAY") {
+		t.Errorf("Expected the payment simulator to be rejected in production, got %v", err)
+	}
+
+	if err == nil || !strings.Contains(err.Error(), "MAIL_DRIVER") {
+		t.Errorf("Expected the mailbox to be rejected in production, got %v", err)
+	}
+
+	production := map[string]string{"DB_NAME": "orders", "APP_ENV": "production",
+		"PAYMENT_GATEWAY": "http", "PAYMENT_API_URL": "https://payments.example.com",
+		"MAIL_DRIVER": "smtp", "SMTP_HOST": "smtp.example.com"}
+	cfg, _, err := Load(nil, envFrom(production))
+	if err != nil || !cfg.Server.Production() {
+		t.Errorf("Expected real integrations to be accepted in production, got %+v (%v)", cfg.Server, err)
+	}
+
+	production["WEBHOOK_ALLOW_PRIVATE_NETWORKS"] = "true"
+	_, _, err = Load(nil, envFrom(production))
+	if err == nil || !strings.Contains(err.Error(), "WEBHOOK_ALLOW_PRIVATE_NETWORKS") {
+		t.Errorf("Expected private webhook targets to be rejected in production, got %v", err)
+	}
+}
+
+func TestSecrets_AreRedactedWhenPrinted(t *testing.T) {
+	cfg, _, err := Load(nil, envFrom(map[string]string{
+		"DATABASE_URL": "postgres://orders:s3cret@db:5432/orders", "CARRIER_API_KEY": "key-123",
+		"SMTP_PASSWORD": "mailpass", "OPS_DASHBOARD_TOKENS": "tok1,tok2",
+	}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := Print(&out, cfg); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	logged := fmt.Sprintf("%v %+v %#v", cfg, cfg, cfg)
+	for _, leaked := range []string{"s3cret", "key-123", "mailpass", "tok1"} {
+		if strings.Contains(out.String(), leaked) || strings.Contains(logged, leaked) {
+			t.Errorf("Expected %q to be redacted", leaked)
+		}
+	}
+	if !strings.Contains(out.String(), "postgres://orders:xxxxx@db:5432/orders") || !strings.Contains(out.String(), "port: 8080 # PORT") {
+		t.Errorf("Unexpected output:\n%s", out.String())
+	}
+	if string(cfg.Shipping.APIKey) != "key-123" {
+		t.Errorf("Expected raw secret to stay available, got %q", cfg.Shipping.APIKey)
+	}
+}
@@ -1,66 +1,110 @@
 package config
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"order-management-system/internal/clock"
 	"order-management-system/internal/domain"
-	"os"
+	"order-management-system/internal/logging"
+	"order-management-system/internal/migrations"
 
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
-func InitDB() (*gorm.DB, error) {
+// OpenDB conecta a la base sin migrar; clk es el reloj de los timestamps que completa GORM.
+// cfg.Driver ya viene resuelto por Load (sqlite, postgres o mysql).
+func OpenDB(cfg DatabaseConfig, clk clock.Clock) (*gorm.DB, error) {
 	var dialector gorm.Dialector
-
-	// Intentamos obtener la URL completa de Render (Postgres)
-	dsn := os.Getenv("DATABASE_URL")
-
-	if dsn != "" {
+	switch cfg.Driver {
+	case "sqlite":
+		// Sin servidor: un archivo local o, con SQLITE_PATH=:memory:, una base en memoria
+		dialector = sqlite.Open(sqliteDSN(cfg.SQLitePath))
+	case "postgres":
 		// Estamos en la nube (Render / Postgres)
-		dialector = postgres.Open(dsn)
-	} else {
+		dialector = postgres.Open(string(cfg.URL))
+	case "mysql":
 		// Estamos en local (MySQL)
-		dbHost := os.Getenv("DB_HOST")
-		dbPort := os.Getenv("DB_PORT")
-		dbUser := os.Getenv("DB_USER")
-		dbPassword := os.Getenv("DB_PASSWORD")
-		dbName := os.Getenv("DB_NAME")
-
-		mysqlDsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-			dbUser, dbPassword, dbHost, dbPort, dbName)
+		mysqlDsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+			cfg.User, string(cfg.Password), cfg.Host, cfg.Port, cfg.Name)
 		dialector = mysql.Open(mysqlDsn)
+	default:
+		return nil, fmt.Errorf("unknown DB_DRIVER %q: use sqlite, postgres or mysql", cfg.Driver)
 	}
 
 	db, err := gorm.Open(dialector, &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		// El SQL sale en debug con LOG_LEVELS=gorm=debug; las consultas lentas y los errores, siempre
+		Logger:  logging.NewGormLogger(cfg.SlowQueryThreshold),
+		NowFunc: clk.Now,
+		// Las claves únicas repetidas llegan como gorm.ErrDuplicatedKey en cualquier driver
+		TranslateError: true,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	// Auto-migrate models
-	if err := db.AutoMigrate(
-		&domain.User{},
-		&domain.Product{},
-		&domain.Order{},
-		&domain.OrderItem{},
-	); err != nil {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	if cfg.Driver == "sqlite" {
+		// SQLite admite un solo escritor; con una conexión las escrituras se encolan en vez de
+		// fallar con "database is locked", y la base en memoria vive mientras la conexión exista,
+		// así que tampoco puede vencer
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+		sqlDB.SetConnMaxIdleTime(0)
+	}
+	return db, nil
+}
+
+// sqliteDSN arma la conexión a SQLite con claves foráneas activas; ":memory:" es una base en memoria
+func sqliteDSN(path string) string {
+	pragmas := "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+	if path == ":memory:" {
+		return "file::memory:?" + pragmas
+	}
+	return "file:" + path + "?" + pragmas + "&_pragma=journal_mode(WAL)"
+}
+
+// InitDB conecta y aplica las migraciones pendientes, salvo con MIGRATE_ON_START=false
+// (por ejemplo si el deploy corre "migrate up" como paso previo)
+func InitDB(ctx context.Context, cfg DatabaseConfig, clk clock.Clock) (*gorm.DB, error) {
+	db, err := OpenDB(cfg, clk)
+	if err != nil {
+		return nil, err
+	}
+	if !cfg.MigrateOnStart {
+		logging.For(ctx, "database").Info("database connected; skipping migrations", "migrate_on_start", false)
+		return db, nil
+	}
+
+	migrator, err := migrations.New(db, migrations.WithClock(clk.Now))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := migrator.Up(ctx, 0); err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
-	log.Println("Database connected and migrated successfully")
+	logging.For(ctx, "database").Info("database connected and migrated")
 	return db, nil
 }
 
-func SeedDatabase(db *gorm.DB) error {
+func SeedDatabase(ctx context.Context, db *gorm.DB) error {
+	db = db.WithContext(ctx)
 	// Check if data already exists
 	var userCount int64
 	db.Model(&domain.User{}).Count(&userCount)
 	if userCount > 0 {
-		log.Println("Database already seeded")
+		logging.For(ctx, "database").Info("database already seeded")
 		return nil
 	}
 
@@ -74,21 +118,57 @@ func SeedDatabase(db *gorm.DB) error {
 		return err
 	}
 
+	// Seed addresses
+	addresses := []domain.Address{
+		{UserID: users[0].ID, Label: "Casa", AddressSnapshot: domain.AddressSnapshot{
+			RecipientName: users[0].Name, Line1: "Av. Colón 1234", City: "Córdoba", Region: "Córdoba", PostalCode: "5000", Country: "AR",
+		}, IsDefaultShipping: true, IsDefaultBilling: true},
+		{UserID: users[1].ID, Label: "Casa", AddressSnapshot: domain.AddressSnapshot{
+			RecipientName: users[1].Name, Line1: "Av. Santa Fe 2500", City: "Buenos Aires", Region: "CABA", PostalCode: "C1425BGN", Country: "AR",
+		}, IsDefaultShipping: true, IsDefaultBilling: true},
+		{UserID: users[2].ID, Label: "Oficina", AddressSnapshot: domain.AddressSnapshot{
+			RecipientName: users[2].Name, Line1: "Bv. Oroño 800", City: "Rosario", Region: "Santa Fe", PostalCode: "2000", Country: "AR",
+		}, IsDefaultShipping: true, IsDefaultBilling: true},
+	}
+	if err := db.Create(&addresses).Error; err != nil {
+		return err
+	}
+
 	// Seed products
 	products := []domain.Product{
-		{Name: "Laptop Dell XPS 13", Price: 1200.00, Stock: 15},
-		{Name: "iPhone 15 Pro", Price: 999.00, Stock: 25},
-		{Name: "Sony WH-1000XM5", Price: 399.00, Stock: 30},
-		{Name: "Samsung Galaxy Tab S9", Price: 649.00, Stock: 20},
-		{Name: "Apple Watch Series 9", Price: 429.00, Stock: 40},
-		{Name: "Logitech MX Master 3S", Price: 99.00, Stock: 50},
-		{Name: "LG UltraFine 4K Monitor", Price: 699.00, Stock: 10},
-		{Name: "Mechanical Keyboard RGB", Price: 159.00, Stock: 35},
+		{Name: "Laptop Dell XPS 13", Price: 1200.00, Stock: 15, Category: "computers", WeightGrams: 2200, LengthCm: 40, WidthCm: 30, HeightCm: 8},
+		{Name: "iPhone 15 Pro", Price: 999.00, Stock: 25, Category: "phones", WeightGrams: 450, LengthCm: 18, WidthCm: 10, HeightCm: 6},
+		{Name: "Sony WH-1000XM5", Price: 399.00, Stock: 30, Category: "audio", WeightGrams: 700, LengthCm: 25, WidthCm: 20, HeightCm: 10},
+		{Name: "Samsung Galaxy Tab S9", Price: 649.00, Stock: 20, Category: "tablets", WeightGrams: 900, LengthCm: 32, WidthCm: 22, HeightCm: 6},
+		{Name: "Apple Watch Series 9", Price: 429.00, Stock: 40, Category: "wearables", WeightGrams: 300, LengthCm: 12, WidthCm: 10, HeightCm: 8},
+		{Name: "Logitech MX Master 3S", Price: 99.00, Stock: 50, Category: "accessories", WeightGrams: 300, LengthCm: 16, WidthCm: 11, HeightCm: 7},
+		{Name: "LG UltraFine 4K Monitor", Price: 699.00, Stock: 10, Category: "computers", WeightGrams: 8500, LengthCm: 70, WidthCm: 50, HeightCm: 18},
+		{Name: "Mechanical Keyboard RGB", Price: 159.00, Stock: 35, Category: "accessories", WeightGrams: 1300, LengthCm: 48, WidthCm: 18, HeightCm: 6},
 	}
 	if err := db.Create(&products).Error; err != nil {
 		return err
 	}
 
-	log.Println("Database seeded successfully")
+	// Seed tax rules (IVA Argentina)
+	taxRules := []domain.TaxRule{
+		{Country: "AR", TaxClass: domain.TaxClassStandard, Name: "IVA 21%", Rate: 0.21},
+		{Country: "AR", TaxClass: domain.TaxClassReduced, Name: "IVA 10.5%", Rate: 0.105},
+		{Country: "AR", TaxClass: domain.TaxClassExempt, Name: "IVA Exento", Rate: 0},
+	}
+	if err := db.Create(&taxRules).Error; err != nil {
+		return err
+	}
+
+	// Seed promotions
+	welcome := "BIENVENIDA10"
+	promotions := []domain.Promotion{
+		{Code: &welcome, Name: "10% de bienvenida", Type: domain.PromotionPercentage, Value: 10, MaxUsesPerUser: 1},
+		{Name: "Accesorios 3x2", Type: domain.PromotionBuyXGetY, BuyQuantity: 2, GetQuantity: 1, Category: "accessories", Stackable: true},
+	}
+	if err := db.Create(&promotions).Error; err != nil {
+		return err
+	}
+
+	logging.For(ctx, "database").Info("database seeded")
 	return nil
 }
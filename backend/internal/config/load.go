@@ -0,0 +1,242 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// field es un valor configurable de Config con los nombres que tiene en cada fuente
+type field struct {
+	section, key string
+	env, flag    string
+	def, usage   string
+	value        reflect.Value
+}
+
+// path es el nombre del campo en el archivo de configuración, como server.port
+func (f field) path() string {
+	return f.section + "." + f.key
+}
+
+// fields recorre Config por reflexión y devuelve sus campos en orden de declaración
+func fields(c *Config) []field {
+	var out []field
+	root := reflect.ValueOf(c).Elem()
+	for i := 0; i < root.NumField(); i++ {
+		section := root.Type().Field(i)
+		group := root.Field(i)
+		for j := 0; j < group.NumField(); j++ {
+			sf := group.Type().Field(j)
+			env := sf.Tag.Get("env")
+			out = append(out, field{
+				section: section.Tag.Get("key"),
+				key:     sf.Tag.Get("key"),
+				env:     env,
+				flag:    strings.ReplaceAll(strings.ToLower(env), "_", "-"),
+				def:     sf.Tag.Get("default"),
+				usage:   sf.Tag.Get("usage"),
+				value:   group.Field(j),
+			})
+		}
+	}
+	return out
+}
+
+// set interpreta raw según el tipo del campo
+func (f field) set(raw string) error {
+	switch f.value.Interface().(type) {
+	case string, Secret:
+		f.value.SetString(raw)
+	case int:
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", raw)
+		}
+		f.value.SetInt(int64(n))
+	case bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean (use true or false)", raw)
+		}
+		f.value.SetBool(b)
+	case time.Duration:
+		d, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("%q is not a duration (like 90s, 30m or 24h)", raw)
+		}
+		f.value.SetInt(int64(d))
+	default:
+		return fmt.Errorf("unsupported type %s", f.value.Type())
+	}
+	return nil
+}
+
+// flagValue guarda el texto de un flag para aplicarlo después del archivo y del entorno
+type flagValue struct {
+	raw    string
+	isBool bool
+}
+
+func (v *flagValue) String() string     { return v.raw }
+func (v *flagValue) Set(s string) error { v.raw = s; return nil }
+func (v *flagValue) IsBoolFlag() bool   { return v.isBool }
+
+// Load arma la configuración con los flags de args y las variables de lookupEnv, y devuelve
+// los argumentos que siguen a los flags (por ejemplo "migrate up"). Si algún valor es
+// inválido devuelve ErrInvalidConfig con todos los problemas encontrados.
+func Load(args []string, lookupEnv func(string) (string, bool)) (*Config, []string, error) {
+	cfg := &Config{}
+	all := fields(cfg)
+	var problems []string
+
+	for _, f := range all {
+		if f.def != "" {
+			if err := f.set(f.def); err != nil {
+				panic(fmt.Sprintf("config: default of %s: %v", f.env, err))
+			}
+		}
+	}
+
+	flags := flag.NewFlagSet("main", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	configFile := flags.String("config", "", "archivo de configuración YAML o TOML")
+	values := make(map[string]*flagValue)
+	for _, f := range all {
+		v := &flagValue{isBool: f.value.Kind() == reflect.Bool}
+		values[f.flag] = v
+		flags.Var(v, f.flag, f.usage)
+	}
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil, nil, err
+		}
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	path := *configFile
+	if path == "" {
+		path, _ = lookupEnv("CONFIG_FILE")
+	}
+	if path != "" {
+		fileValues, err := readFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+		}
+		known := make(map[string]bool)
+		for _, f := range all {
+			known[f.path()] = true
+			if raw, ok := fileValues[f.path()]; ok {
+				if err := f.set(raw); err != nil {
+					problems = append(problems, fmt.Sprintf("%s (%s): %v", f.path(), path, err))
+				}
+			}
+		}
+		for _, key := range sortedKeys(fileValues) {
+			if !known[key] {
+				problems = append(problems, fmt.Sprintf("%s (%s): unknown setting", key, path))
+			}
+		}
+	}
+
+	// Una variable vacía cuenta como no definida, como cuando se leían con os.Getenv
+	for _, f := range all {
+		if raw, ok := lookupEnv(f.env); ok && raw != "" {
+			if err := f.set(raw); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", f.env, err))
+			}
+		}
+	}
+
+	flags.Visit(func(fl *flag.Flag) {
+		for _, f := range all {
+			if f.flag == fl.Name {
+				if err := f.set(values[f.flag].raw); err != nil {
+					problems = append(problems, fmt.Sprintf("--%s: %v", f.flag, err))
+				}
+			}
+		}
+	})
+
+	if len(problems) == 0 {
+		cfg.normalize()
+		problems = cfg.validate()
+	}
+	if len(problems) > 0 {
+		return nil, nil, fmt.Errorf("%w:\n  - %s", ErrInvalidConfig, strings.Join(problems, "\n  - "))
+	}
+	return cfg, flags.Args(), nil
+}
+
+// Usage escribe en w los flags disponibles con su variable de entorno y valor por defecto
+func Usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: main [flags] [migrate|config] ...")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "  --%-22s %s (CONFIG_FILE)\n", "config", "archivo de configuración YAML o TOML")
+	for _, f := range fields(&Config{}) {
+		usage := f.usage + " (" + f.env
+		if f.def != "" {
+			usage += ", por defecto " + f.def
+		}
+		fmt.Fprintf(w, "  --%-22s %s)\n", f.flag, usage)
+	}
+}
+
+// readFile lee un archivo YAML o TOML y devuelve sus valores por ruta (server.port)
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var tree map[string]interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	default:
+		return nil, fmt.Errorf("%s: unsupported config format, use .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	values := make(map[string]string)
+	for section, raw := range tree {
+		settings, ok := raw.(map[string]interface{})
+		if !ok {
+			values[section] = fmt.Sprint(raw)
+			continue
+		}
+		for key, value := range settings {
+			if list, ok := value.([]interface{}); ok {
+				parts := make([]string, len(list))
+				for i, item := range list {
+					parts[i] = fmt.Sprint(item)
+				}
+				value = strings.Join(parts, ",")
+			}
+			values[section+"."+key] = fmt.Sprint(value)
+		}
+	}
+	return values, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
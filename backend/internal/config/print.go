@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Print escribe la configuración efectiva en w con el formato del archivo YAML, los secretos
+// ocultos y la variable de entorno de cada valor como comentario
+func Print(w io.Writer, c *Config) error {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	var section *yaml.Node
+	current := ""
+	for _, f := range fields(c) {
+		if f.section != current {
+			current = f.section
+			section = &yaml.Node{Kind: yaml.MappingNode}
+			root.Content = append(root.Content, scalar(f.section, "!!str"), section)
+		}
+
+		var value *yaml.Node
+		switch v := f.value.Interface().(type) {
+		case Secret:
+			value = scalar(v.String(), "!!str")
+		case string:
+			value = scalar(v, "!!str")
+		case int:
+			value = scalar(fmt.Sprint(v), "!!int")
+		case bool:
+			value = scalar(fmt.Sprint(v), "!!bool")
+		case time.Duration:
+			value = scalar(v.String(), "!!str")
+		}
+		value.LineComment = f.env
+		section.Content = append(section.Content, scalar(f.key, "!!str"), value)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+func scalar(value, tag string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+}
@@ -0,0 +1,117 @@
+package dashboard
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// client es una conexión con su cola de salida acotada. Si el cliente no consume a tiempo
+// los mensajes nuevos se descartan y, cuando la cola se libera, se le envía un mensaje
+// lagged con la cantidad perdida para que vuelva a cargar el tablero.
+type client struct {
+	conn         *websocket.Conn
+	writeTimeout time.Duration
+	queue        chan ServerMessage
+	done         chan struct{}
+	closeOnce    sync.Once
+
+	mu      sync.Mutex
+	topics  map[string]bool
+	dropped int
+}
+
+func newClient(conn *websocket.Conn, queueSize int, writeTimeout time.Duration) *client {
+	return &client{
+		conn:         conn,
+		writeTimeout: writeTimeout,
+		queue:        make(chan ServerMessage, queueSize),
+		done:         make(chan struct{}),
+		topics:       make(map[string]bool),
+	}
+}
+
+func (c *client) subscribed(topic string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.topics[topic]
+}
+
+// update agrega o quita tópicos y devuelve los vigentes ordenados
+func (c *client) update(topics []string, subscribe bool) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, topic := range topics {
+		if subscribe {
+			c.topics[topic] = true
+		} else {
+			delete(c.topics, topic)
+		}
+	}
+	current := make([]string, 0, len(c.topics))
+	for topic := range c.topics {
+		current = append(current, topic)
+	}
+	sort.Strings(current)
+	return current
+}
+
+// send encola sin bloquear; con la cola llena cuenta el mensaje como descartado
+func (c *client) send(message ServerMessage) {
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+	select {
+	case c.queue <- message:
+	default:
+		c.mu.Lock()
+		c.dropped++
+		c.mu.Unlock()
+	}
+}
+
+func (c *client) takeDropped() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	dropped := c.dropped
+	c.dropped = 0
+	return dropped
+}
+
+// writeLoop escribe la cola en la conexión; una escritura que supera el timeout la cierra
+func (c *client) writeLoop() {
+	defer c.close()
+	for {
+		select {
+		case <-c.done:
+			return
+		case message := <-c.queue:
+			if err := c.write(message); err != nil {
+				return
+			}
+			if len(c.queue) == 0 {
+				if dropped := c.takeDropped(); dropped > 0 {
+					if err := c.write(ServerMessage{Type: MessageLagged, Dropped: dropped}); err != nil {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+func (c *client) write(message ServerMessage) error {
+	c.conn.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+	return websocket.JSON.Send(c.conn, message)
+}
+
+func (c *client) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
@@ -0,0 +1,284 @@
+// Package dashboard envía a los operadores del depósito, por WebSocket, los pedidos nuevos,
+// los cambios de estado y los niveles de stock.
+//
+// El Hub recibe los eventos de dominio del outbox y los reparte entre los clientes según
+// los tópicos a los que se suscribieron. El protocolo JSON está en protocol.go y en el README.
+package dashboard
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/logging"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+const (
+	DefaultLowStockThreshold = 5
+	DefaultSendQueue         = 256
+	DefaultWriteTimeout      = 10 * time.Second
+)
+
+// ProductLookup devuelve el stock actual de un producto para el snapshot inicial
+type ProductLookup interface {
+	GetByID(ctx context.Context, id uint) (*domain.Product, error)
+}
+
+// StockLevel es el dato de los mensajes de stock
+type StockLevel struct {
+	ProductID     uint   `json:"product_id"`
+	Stock         int    `json:"stock"`
+	PreviousStock int    `json:"previous_stock,omitempty"`
+	OrderNumber   string `json:"order_number,omitempty"`
+}
+
+type Hub struct {
+	tokens            []string
+	lowStockThreshold int
+	sendQueue         int
+	writeTimeout      time.Duration
+	products          ProductLookup
+
+	mu      sync.RWMutex
+	clients map[*client]struct{}
+	closed  bool
+}
+
+// Option configura parámetros opcionales del Hub
+type Option func(*Hub)
+
+// WithLowStockThreshold define el stock a partir del cual se publica stock.low
+func WithLowStockThreshold(threshold int) Option {
+	return func(h *Hub) {
+		h.lowStockThreshold = threshold
+	}
+}
+
+// WithSendQueue define cuántos mensajes pueden quedar pendientes por cliente antes de descartar
+func WithSendQueue(size int) Option {
+	return func(h *Hub) {
+		h.sendQueue = size
+	}
+}
+
+// WithWriteTimeout define cuánto puede tardar una escritura antes de cerrar la conexión
+func WithWriteTimeout(timeout time.Duration) Option {
+	return func(h *Hub) {
+		h.writeTimeout = timeout
+	}
+}
+
+// WithProducts habilita el snapshot de stock al suscribirse a product:<id>
+func WithProducts(products ProductLookup) Option {
+	return func(h *Hub) {
+		h.products = products
+	}
+}
+
+// NewHub crea el hub; sólo se aceptan conexiones que presenten alguno de los tokens
+func NewHub(tokens []string, opts ...Option) *Hub {
+	h := &Hub{
+		tokens:            tokens,
+		lowStockThreshold: DefaultLowStockThreshold,
+		sendQueue:         DefaultSendQueue,
+		writeTimeout:      DefaultWriteTimeout,
+		clients:           make(map[*client]struct{}),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// ServeHTTP autentica la conexión con "Authorization: Bearer <token>" o ?token= y la
+// convierte en WebSocket. Los navegadores no pueden enviar headers en el handshake,
+// por eso se acepta el query.
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authenticate(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	server := websocket.Server{
+		// El token reemplaza al chequeo de Origin
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler:   h.serve,
+	}
+	server.ServeHTTP(w, r)
+}
+
+// HandleEvent es el suscriptor del outbox: traduce el evento a mensajes por tópico
+func (h *Hub) HandleEvent(ctx context.Context, event domain.OutboxEvent) error {
+	switch event.EventType {
+	case domain.EventOrderCreated, domain.EventOrderConfirmed, domain.EventOrderShipped, domain.EventOrderCancelled, domain.EventOrderMerged:
+		// El pedido llega al navegador con public_id y number, sin la clave interna
+		data, err := event.PublicPayload()
+		if err != nil {
+			return err
+		}
+		topic := TopicOrdersStatus
+		if event.EventType == domain.EventOrderCreated {
+			topic = TopicOrdersNew
+		}
+		h.broadcast(topic, event, data)
+	case domain.EventStockChanged:
+		var change domain.StockChangedEvent
+		if err := event.Decode(&change); err != nil {
+			return err
+		}
+		data, err := json.Marshal(StockLevel{
+			ProductID:     change.ProductID,
+			Stock:         change.Stock,
+			PreviousStock: change.PreviousStock,
+			OrderNumber:   change.OrderNumber,
+		})
+		if err != nil {
+			return err
+		}
+		h.broadcast(ProductTopic(change.ProductID), event, data)
+		// Sólo cuando el stock baja hasta el umbral o por debajo, para no repetir en las reposiciones
+		if change.Stock <= h.lowStockThreshold && change.Stock < change.PreviousStock {
+			h.broadcast(TopicStockLow, event, data)
+		}
+	}
+	return nil
+}
+
+// Clients devuelve la cantidad de clientes conectados
+func (h *Hub) Clients() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
+// Close desconecta a todos los clientes y rechaza conexiones nuevas
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for c := range h.clients {
+		c.close()
+	}
+}
+
+func (h *Hub) broadcast(topic string, event domain.OutboxEvent, data json.RawMessage) {
+	message := ServerMessage{
+		Type:    MessageEvent,
+		Topic:   topic,
+		Event:   string(event.EventType),
+		EventID: event.ID,
+		Data:    data,
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for c := range h.clients {
+		if c.subscribed(topic) {
+			c.send(message)
+		}
+	}
+}
+
+func (h *Hub) authenticate(r *http.Request) bool {
+	token := r.URL.Query().Get("token")
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		token = strings.TrimPrefix(header, "Bearer ")
+	}
+	if token == "" {
+		return false
+	}
+	for _, allowed := range h.tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(allowed)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// serve atiende una conexión: lee los mensajes del cliente mientras otra goroutine escribe
+func (h *Hub) serve(conn *websocket.Conn) {
+	c := newClient(conn, h.sendQueue, h.writeTimeout)
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		conn.Close()
+		return
+	}
+	h.clients[c] = struct{}{}
+	h.mu.Unlock()
+
+	// El contexto del request de handshake lleva el logger con el request_id
+	ctx := conn.Request().Context()
+	go c.writeLoop()
+	defer func() {
+		h.mu.Lock()
+		delete(h.clients, c)
+		h.mu.Unlock()
+		c.close()
+	}()
+
+	for {
+		var raw []byte
+		if err := websocket.Message.Receive(conn, &raw); err != nil {
+			return
+		}
+		var msg ClientMessage
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			c.send(ServerMessage{Type: MessageError, Error: "invalid JSON message"})
+			continue
+		}
+		h.handleMessage(ctx, c, msg)
+	}
+}
+
+func (h *Hub) handleMessage(ctx context.Context, c *client, msg ClientMessage) {
+	switch msg.Type {
+	case MessagePing:
+		c.send(ServerMessage{Type: MessagePong})
+	case MessageSubscribe, MessageUnsubscribe:
+		var productIDs []uint
+		for _, topic := range msg.Topics {
+			productID, err := validateTopic(topic)
+			if err != nil {
+				c.send(ServerMessage{Type: MessageError, Error: err.Error()})
+				return
+			}
+			if productID != 0 && !c.subscribed(topic) {
+				productIDs = append(productIDs, productID)
+			}
+		}
+		topics := c.update(msg.Topics, msg.Type == MessageSubscribe)
+		c.send(ServerMessage{Type: MessageSubscribed, Topics: topics})
+		if msg.Type == MessageSubscribe {
+			h.sendSnapshots(ctx, c, productIDs)
+		}
+	default:
+		c.send(ServerMessage{Type: MessageError, Error: "unknown message type " + msg.Type})
+	}
+}
+
+// sendSnapshots envía el stock actual de los productos recién suscriptos
+func (h *Hub) sendSnapshots(ctx context.Context, c *client, productIDs []uint) {
+	if h.products == nil {
+		return
+	}
+	for _, id := range productIDs {
+		product, err := h.products.GetByID(ctx, id)
+		if err != nil {
+			c.send(ServerMessage{Type: MessageError, Topic: ProductTopic(id), Error: "product not found"})
+			continue
+		}
+		data, err := json.Marshal(StockLevel{ProductID: product.ID, Stock: product.Stock})
+		if err != nil {
+			logging.For(ctx, "dashboard").Error("encoding snapshot", "product_id", id, "error", err)
+			continue
+		}
+		c.send(ServerMessage{Type: MessageSnapshot, Topic: ProductTopic(id), Data: data})
+	}
+}
@@ -0,0 +1,172 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"order-management-system/internal/domain"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+type productStock map[uint]int
+
+func (p productStock) GetByID(ctx context.Context, id uint) (*domain.Product, error) {
+	stock, ok := p[id]
+	if !ok {
+		return nil, errors.New("product not found")
+	}
+	return &domain.Product{ID: id, Stock: stock}, nil
+}
+
+func newTestServer(t *testing.T, opts ...Option) (*Hub, *httptest.Server) {
+	hub := NewHub([]string{"ops-token"}, opts...)
+	server := httptest.NewServer(hub)
+	t.Cleanup(func() {
+		hub.Close()
+		server.Close()
+	})
+	return hub, server
+}
+
+func dial(t *testing.T, server *httptest.Server) *websocket.Conn {
+	t.Helper()
+	config, err := websocket.NewConfig("ws"+strings.TrimPrefix(server.URL, "http"), server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	config.Header.Set("Authorization", "Bearer ops-token")
+	conn, err := websocket.DialConfig(config)
+	if err != nil {
+		t.Fatalf("Expected connection, got %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func receive(t *testing.T, conn *websocket.Conn) ServerMessage {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var msg ServerMessage
+	if err := websocket.JSON.Receive(conn, &msg); err != nil {
+		t.Fatalf("Expected message, got %v", err)
+	}
+	return msg
+}
+
+func request(t *testing.T, conn *websocket.Conn, msg ClientMessage) ServerMessage {
+	t.Helper()
+	if err := websocket.JSON.Send(conn, msg); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return receive(t, conn)
+}
+
+func outboxEvent(t *testing.T, id uint, eventType domain.EventType, payload interface{}) domain.OutboxEvent {
+	t.Helper()
+	event, err := domain.NewOutboxEvent(eventType, "order", 1, payload)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	event.ID = id
+	return *event
+}
+
+func TestHub_RejectsMissingOrInvalidToken(t *testing.T) {
+	_, server := newTestServer(t)
+
+	for _, url := range []string{server.URL, server.URL + "?token=wrong"} {
+		resp, err := http.Get(url)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected 401 for %s, got %d", url, resp.StatusCode)
+		}
+	}
+}
+
+func TestHub_FansOutSubscribedTopics(t *testing.T) {
+	hub, server := newTestServer(t, WithProducts(productStock{7: 12}), WithLowStockThreshold(5))
+	conn := dial(t, server)
+
+	if msg := request(t, conn, ClientMessage{Type: MessagePing}); msg.Type != MessagePong {
+		t.Errorf("Expected pong, got %+v", msg)
+	}
+	if msg := request(t, conn, ClientMessage{Type: MessageSubscribe, Topics: []string{"orders.everything"}}); msg.Type != MessageError {
+		t.Errorf("Expected error for unknown topic, got %+v", msg)
+	}
+
+	msg := request(t, conn, ClientMessage{Type: MessageSubscribe, Topics: []string{TopicOrdersNew, TopicStockLow, "product:7"}})
+	if msg.Type != MessageSubscribed || strings.Join(msg.Topics, ",") != "orders.new,product:7,stock.low" {
+		t.Fatalf("Expected subscribed topics, got %+v", msg)
+	}
+	if msg = receive(t, conn); msg.Type != MessageSnapshot || msg.Topic != "product:7" || !strings.Contains(string(msg.Data), `"stock":12`) {
+		t.Errorf("Expected stock snapshot for product 7, got %+v", msg)
+	}
+
+	// orders.status no está suscripto: el primer mensaje recibido es el pedido nuevo
+	hub.HandleEvent(context.Background(), outboxEvent(t, 1, domain.EventOrderConfirmed, domain.OrderEvent{OrderID: 1}))
+	hub.HandleEvent(context.Background(), outboxEvent(t, 2, domain.EventOrderCreated, domain.OrderEvent{OrderID: 2, Number: "ORD-000002", Total: 50}))
+	if msg = receive(t, conn); msg.Topic != TopicOrdersNew || msg.EventID != 2 || !strings.Contains(string(msg.Data), `"number":"ORD-000002"`) {
+		t.Errorf("Expected new order 2, got %+v", msg)
+	}
+	if strings.Contains(string(msg.Data), "order_id") {
+		t.Errorf("Expected no numeric order id in the dashboard, got %s", msg.Data)
+	}
+
+	hub.HandleEvent(context.Background(), outboxEvent(t, 3, domain.EventStockChanged, domain.StockChangedEvent{ProductID: 7, OrderID: 2, OrderNumber: "ORD-000002", PreviousStock: 12, Stock: 4}))
+	if msg = receive(t, conn); msg.Topic != "product:7" || !strings.Contains(string(msg.Data), `"stock":4`) || !strings.Contains(string(msg.Data), `"order_number":"ORD-000002"`) {
+		t.Errorf("Expected product 7 stock update, got %+v", msg)
+	}
+	if strings.Contains(string(msg.Data), "order_id") {
+		t.Errorf("Expected no numeric order id in the stock update, got %s", msg.Data)
+	}
+	if msg = receive(t, conn); msg.Topic != TopicStockLow || msg.Event != "stock.changed" {
+		t.Errorf("Expected low stock alert, got %+v", msg)
+	}
+
+	msg = request(t, conn, ClientMessage{Type: MessageUnsubscribe, Topics: []string{TopicStockLow}})
+	if msg.Type != MessageSubscribed || len(msg.Topics) != 2 {
+		t.Errorf("Expected stock.low removed, got %+v", msg)
+	}
+	if hub.Clients() != 1 {
+		t.Errorf("Expected 1 client, got %d", hub.Clients())
+	}
+}
+
+func TestHub_RemovesDisconnectedClients(t *testing.T) {
+	hub, server := newTestServer(t)
+	conn := dial(t, server)
+	request(t, conn, ClientMessage{Type: MessagePing})
+
+	conn.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for hub.Clients() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected client removed after disconnect, got %d", hub.Clients())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestClient_DropsWhenQueueIsFull(t *testing.T) {
+	c := newClient(nil, 2, time.Second)
+	for i := 0; i < 5; i++ {
+		c.send(ServerMessage{Type: MessageEvent})
+	}
+	if len(c.queue) != 2 {
+		t.Errorf("Expected queue capped at 2, got %d", len(c.queue))
+	}
+	if dropped := c.takeDropped(); dropped != 3 {
+		t.Errorf("Expected 3 dropped messages, got %d", dropped)
+	}
+	if dropped := c.takeDropped(); dropped != 0 {
+		t.Errorf("Expected dropped counter reset, got %d", dropped)
+	}
+}
@@ -0,0 +1,72 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Tópicos a los que se puede suscribir un cliente
+const (
+	TopicOrdersNew    = "orders.new"
+	TopicOrdersStatus = "orders.status"
+	TopicStockLow     = "stock.low"
+	// TopicProductPrefix seguido del ID del producto, por ejemplo "product:12"
+	TopicProductPrefix = "product:"
+)
+
+// Tipos de mensaje del cliente al servidor
+const (
+	MessageSubscribe   = "subscribe"
+	MessageUnsubscribe = "unsubscribe"
+	MessagePing        = "ping"
+)
+
+// Tipos de mensaje del servidor al cliente
+const (
+	MessageSubscribed = "subscribed"
+	MessageEvent      = "event"
+	MessageSnapshot   = "snapshot"
+	MessageLagged     = "lagged"
+	MessagePong       = "pong"
+	MessageError      = "error"
+)
+
+// ClientMessage es un mensaje enviado por el cliente
+type ClientMessage struct {
+	Type   string   `json:"type"`
+	Topics []string `json:"topics,omitempty"`
+}
+
+// ServerMessage es un mensaje enviado por el servidor
+type ServerMessage struct {
+	Type    string          `json:"type"`
+	Topic   string          `json:"topic,omitempty"`
+	Event   string          `json:"event,omitempty"`
+	EventID uint            `json:"event_id,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Topics  []string        `json:"topics,omitempty"`
+	Dropped int             `json:"dropped,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// ProductTopic devuelve el tópico de los cambios de stock de un producto
+func ProductTopic(productID uint) string {
+	return TopicProductPrefix + strconv.FormatUint(uint64(productID), 10)
+}
+
+// validateTopic acepta los tópicos fijos y product:<id>; devuelve el ID del producto si corresponde
+func validateTopic(topic string) (productID uint, err error) {
+	switch topic {
+	case TopicOrdersNew, TopicOrdersStatus, TopicStockLow:
+		return 0, nil
+	}
+	if raw := strings.TrimPrefix(topic, TopicProductPrefix); raw != topic {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err == nil && id > 0 {
+			return uint(id), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown topic %q", topic)
+}
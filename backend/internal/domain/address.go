@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// AddressSnapshot son los datos postales de una dirección. Se copian al pedido
+// para que editar la libreta de direcciones no modifique pedidos existentes.
+type AddressSnapshot struct {
+	RecipientName string `json:"recipient_name" gorm:"type:varchar(150)"`
+	Line1         string `json:"line1" gorm:"type:varchar(200)"`
+	Line2         string `json:"line2,omitempty" gorm:"type:varchar(200)"`
+	City          string `json:"city" gorm:"type:varchar(100)"`
+	Region        string `json:"region" gorm:"type:varchar(100)"`
+	PostalCode    string `json:"postal_code" gorm:"type:varchar(20)"`
+	Country       string `json:"country" gorm:"type:varchar(2)"`
+	Phone         string `json:"phone,omitempty" gorm:"type:varchar(30)"`
+}
+
+func (a AddressSnapshot) IsEmpty() bool {
+	return a.Line1 == "" && a.City == "" && a.Country == ""
+}
+
+// Address es una entrada de la libreta de direcciones de un usuario.
+type Address struct {
+	ID                uint   `json:"id" gorm:"primaryKey"`
+	UserID            uint   `json:"user_id" gorm:"not null;index"`
+	Label             string `json:"label" gorm:"type:varchar(50)"`
+	AddressSnapshot   `gorm:"embedded"`
+	IsDefaultShipping bool      `json:"is_default_shipping"`
+	IsDefaultBilling  bool      `json:"is_default_billing"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventOrderCreated   EventType = "order.created"
+	EventOrderConfirmed EventType = "order.confirmed"
+	EventOrderShipped   EventType = "order.shipped"
+	EventOrderCancelled EventType = "order.cancelled"
+	// EventOrderMerged se emite para el pedido fusionado y para el que recibe sus líneas
+	EventOrderMerged  EventType = "order.merged"
+	EventStockChanged EventType = "stock.changed"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxPending   OutboxStatus = "PENDING"
+	OutboxDelivered OutboxStatus = "DELIVERED"
+	// OutboxDead indica que se agotaron los reintentos de entrega
+	OutboxDead OutboxStatus = "DEAD"
+)
+
+// OutboxEvent es un evento de dominio guardado en la misma transacción que el cambio
+// que lo origina, pendiente de entrega a los suscriptores.
+type OutboxEvent struct {
+	ID            uint         `json:"id" gorm:"primaryKey"`
+	EventType     EventType    `json:"event_type" gorm:"type:varchar(50);not null;index"`
+	AggregateType string       `json:"aggregate_type" gorm:"type:varchar(50);not null"`
+	AggregateID   uint         `json:"aggregate_id" gorm:"not null"`
+	Payload       string       `json:"payload" gorm:"type:text;not null"`
+	Status        OutboxStatus `json:"status" gorm:"type:varchar(20);not null;index:idx_outbox_due,priority:1"`
+	Attempts      int          `json:"attempts" gorm:"not null;default:0"`
+	LastError     string       `json:"last_error,omitempty"`
+	NextAttemptAt time.Time    `json:"next_attempt_at" gorm:"not null;index:idx_outbox_due,priority:2"`
+	DeliveredAt   *time.Time   `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time    `json:"created_at"`
+}
+
+// NewOutboxEvent serializa el payload y deja el evento listo para su entrega inmediata
+func NewOutboxEvent(eventType EventType, aggregateType string, aggregateID uint, payload interface{}) (*OutboxEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &OutboxEvent{
+		EventType:     eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       string(data),
+		Status:        OutboxPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}, nil
+}
+
+// Decode deserializa el payload del evento
+func (e OutboxEvent) Decode(v interface{}) error {
+	return json.Unmarshal([]byte(e.Payload), v)
+}
+
+// PublicPayload devuelve el payload sin la clave numérica del pedido, para los datos que
+// salen del sistema (webhooks y dashboard). El pedido se identifica por public_id y number.
+func (e OutboxEvent) PublicPayload() (json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := e.Decode(&fields); err != nil {
+		return nil, err
+	}
+	delete(fields, "order_id")
+	return json.Marshal(fields)
+}
+
+// OrderEvent es el payload de los eventos del ciclo de vida de un pedido
+type OrderEvent struct {
+	OrderID        uint        `json:"order_id,omitempty"`
+	PublicID       string      `json:"public_id,omitempty"`
+	Number         string      `json:"number,omitempty"`
+	UserID         uint        `json:"user_id"`
+	Status         OrderStatus `json:"status"`
+	Total          float64     `json:"total"`
+	TrackingNumber string      `json:"tracking_number,omitempty"`
+}
+
+// StockChangedEvent es el payload de un cambio de stock causado por un pedido
+type StockChangedEvent struct {
+	ProductID     uint   `json:"product_id"`
+	OrderID       uint   `json:"order_id"`
+	OrderPublicID string `json:"order_public_id,omitempty"`
+	OrderNumber   string `json:"order_number,omitempty"`
+	PreviousStock int    `json:"previous_stock"`
+	Stock         int    `json:"stock"`
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "PENDING"
+	JobRunning   JobStatus = "RUNNING"
+	JobSucceeded JobStatus = "SUCCEEDED"
+	// JobDead es la cola de trabajos muertos: se agotaron los intentos o el error no admite reintento
+	JobDead JobStatus = "DEAD"
+)
+
+// Job es un trabajo en segundo plano persistido en la cola
+type Job struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Type        string     `json:"type" gorm:"type:varchar(100);not null;index"`
+	Payload     string     `json:"payload" gorm:"type:text;not null"`
+	Status      JobStatus  `json:"status" gorm:"type:varchar(20);not null;index:idx_jobs_due,priority:1"`
+	Attempts    int        `json:"attempts" gorm:"not null;default:0"`
+	MaxAttempts int        `json:"max_attempts" gorm:"not null"`
+	LastError   string     `json:"last_error,omitempty"`
+	RunAt       time.Time  `json:"run_at" gorm:"not null;index:idx_jobs_due,priority:2"`
+	LockedAt    *time.Time `json:"locked_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	// UniqueKey evita encolar dos veces el mismo trabajo, por ejemplo una ejecución programada
+	UniqueKey *string   `json:"unique_key,omitempty" gorm:"type:varchar(200);uniqueIndex"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewJob serializa el payload y deja el trabajo listo para ejecutarse en runAt
+func NewJob(jobType string, payload interface{}, runAt time.Time) (*Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &Job{
+		Type:    jobType,
+		Payload: string(data),
+		Status:  JobPending,
+		RunAt:   runAt,
+	}, nil
+}
+
+// Decode deserializa el payload del trabajo
+func (j Job) Decode(v interface{}) error {
+	return json.Unmarshal([]byte(j.Payload), v)
+}
+
+// JobFilter filtra el listado de trabajos; los campos vacíos no filtran
+type JobFilter struct {
+	Status JobStatus
+	Type   string
+	Limit  int
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 type OrderStatus string
 
@@ -9,6 +12,14 @@ const (
 	StatusConfirmed OrderStatus = "CONFIRMED"
 	StatusShipped   OrderStatus = "SHIPPED"
 	StatusCancelled OrderStatus = "CANCELLED"
+	// StatusMerged marca un pedido cuyas líneas se fusionaron en otro
+	StatusMerged OrderStatus = "MERGED"
+)
+
+// Actores que pueden cancelar un pedido
+const (
+	ActorUser   = "user"
+	ActorSystem = "system"
 )
 
 type User struct {
@@ -19,36 +30,91 @@ type User struct {
 }
 
 type Product struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	Name      string    `json:"name" gorm:"not null"`
-	Price     float64   `json:"price" gorm:"not null"`
-	Stock     int       `json:"stock" gorm:"not null"`
-	CreatedAt time.Time `json:"created_at"`
+	ID       uint    `json:"id" gorm:"primaryKey"`
+	Name     string  `json:"name" gorm:"not null"`
+	Price    float64 `json:"price" gorm:"not null"`
+	Stock    int     `json:"stock" gorm:"not null"`
+	Category string  `json:"category" gorm:"type:varchar(100);index"`
+	TaxClass string  `json:"tax_class" gorm:"type:varchar(20);not null;default:STANDARD"`
+	// Peso y dimensiones del producto embalado, usados para cotizar envíos
+	WeightGrams int       `json:"weight_grams" gorm:"not null;default:0" binding:"required,gt=0"`
+	LengthCm    float64   `json:"length_cm" gorm:"not null;default:0" binding:"required,gt=0"`
+	WidthCm     float64   `json:"width_cm" gorm:"not null;default:0" binding:"required,gt=0"`
+	HeightCm    float64   `json:"height_cm" gorm:"not null;default:0" binding:"required,gt=0"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 type Order struct {
-	ID         uint        `json:"id" gorm:"primaryKey"`
-	UserID     uint        `json:"user_id" gorm:"not null"`
-	User       User        `json:"user" gorm:"foreignKey:UserID"`
-	Total      float64     `json:"total" gorm:"not null"`
-	Status     OrderStatus `json:"status" gorm:"type:varchar(20);not null"`
-	Items      []OrderItem `json:"items" gorm:"foreignKey:OrderID"`
-	CreatedAt  time.Time   `json:"created_at"`
-	UpdatedAt  time.Time   `json:"updated_at"`
+	// El ID es la clave interna: la API identifica los pedidos por public_id y number
+	ID              uint            `json:"-" gorm:"primaryKey"`
+	PublicID        *string         `json:"public_id,omitempty" gorm:"type:varchar(26);uniqueIndex"`
+	Number          *string         `json:"number,omitempty" gorm:"type:varchar(32);uniqueIndex"`
+	UserID          uint            `json:"user_id" gorm:"not null"`
+	User            User            `json:"user" gorm:"foreignKey:UserID"`
+	Subtotal        float64         `json:"subtotal" gorm:"not null;default:0"`
+	DiscountTotal   float64         `json:"discount_total" gorm:"not null;default:0"`
+	TaxTotal        float64         `json:"tax_total" gorm:"not null;default:0"`
+	Total           float64         `json:"total" gorm:"not null"`
+	TaxCountry      string          `json:"tax_country,omitempty" gorm:"type:varchar(2)"`
+	TaxRegion       string          `json:"tax_region,omitempty" gorm:"type:varchar(100)"`
+	TaxLines        []OrderTaxLine  `json:"tax_lines,omitempty" gorm:"foreignKey:OrderID"`
+	ShippingAddress AddressSnapshot `json:"shipping_address" gorm:"embedded;embeddedPrefix:shipping_"`
+	BillingAddress  AddressSnapshot `json:"billing_address" gorm:"embedded;embeddedPrefix:billing_"`
+	ShippingCarrier string          `json:"shipping_carrier,omitempty" gorm:"type:varchar(50)"`
+	ShippingMethod  string          `json:"shipping_method,omitempty" gorm:"type:varchar(50)"`
+	ShippingCost    float64         `json:"shipping_cost" gorm:"not null;default:0"`
+	TrackingNumber  string          `json:"tracking_number,omitempty" gorm:"type:varchar(100)"`
+	LabelURL        string          `json:"label_url,omitempty" gorm:"type:varchar(255)"`
+	Status          OrderStatus     `json:"status" gorm:"type:varchar(20);not null"`
+	Items           []OrderItem     `json:"items" gorm:"foreignKey:OrderID"`
+	Payments        []Payment       `json:"payments,omitempty" gorm:"foreignKey:OrderID"`
+	// Pedido del que se separó este pedido, y pedido en el que se fusionó; en la API la
+	// relación se ve desde el otro lado, en child_orders y merged_orders
+	ParentOrderID *uint     `json:"-" gorm:"index"`
+	MergedIntoID  *uint     `json:"-" gorm:"index"`
+	ChildOrders   []Order   `json:"child_orders,omitempty" gorm:"foreignKey:ParentOrderID"`
+	MergedOrders  []Order   `json:"merged_orders,omitempty" gorm:"foreignKey:MergedIntoID"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	// Quién canceló el pedido, por qué y cuándo
+	CancelledBy        string     `json:"cancelled_by,omitempty" gorm:"type:varchar(50)"`
+	CancellationReason string     `json:"cancellation_reason,omitempty"`
+	CancelledAt        *time.Time `json:"cancelled_at,omitempty"`
+}
+
+// Reference es como se muestra el pedido a los clientes: su número de pedido, o #ID en
+// los pedidos creados antes de que existieran los números
+func (o Order) Reference() string {
+	if o.Number != nil {
+		return *o.Number
+	}
+	return fmt.Sprintf("#%d", o.ID)
 }
 
 type OrderItem struct {
-	ID        uint    `json:"id" gorm:"primaryKey"`
-	OrderID   uint    `json:"order_id" gorm:"not null"`
-	ProductID uint    `json:"product_id" gorm:"not null"`
-	Product   Product `json:"product" gorm:"foreignKey:ProductID"`
-	Quantity  int     `json:"quantity" gorm:"not null"`
-	Price     float64 `json:"price" gorm:"not null"`
+	ID        uint                `json:"id" gorm:"primaryKey"`
+	OrderID   uint                `json:"-" gorm:"not null"`
+	ProductID uint                `json:"product_id" gorm:"not null"`
+	Product   Product             `json:"product" gorm:"foreignKey:ProductID"`
+	Quantity  int                 `json:"quantity" gorm:"not null"`
+	Price     float64             `json:"price" gorm:"not null"`
+	Discount  float64             `json:"discount" gorm:"not null;default:0"`
+	TaxClass  string              `json:"tax_class" gorm:"type:varchar(20)"`
+	TaxRate   float64             `json:"tax_rate" gorm:"not null;default:0"`
+	TaxAmount float64             `json:"tax_amount" gorm:"not null;default:0"`
+	Discounts []OrderItemDiscount `json:"discounts,omitempty" gorm:"foreignKey:OrderItemID"`
 }
 
 type CreateOrderRequest struct {
-	UserID uint              `json:"user_id" binding:"required"`
-	Items  []OrderItemRequest `json:"items" binding:"required,dive"`
+	UserID      uint               `json:"user_id" binding:"required"`
+	Items       []OrderItemRequest `json:"items" binding:"required,dive"`
+	CouponCodes []string           `json:"coupon_codes"`
+	// Direcciones de la libreta del usuario; si se omiten se usan las predeterminadas
+	ShippingAddressID *uint `json:"shipping_address_id"`
+	BillingAddressID  *uint `json:"billing_address_id"`
+	// Método de envío cotizado; si se omite se elige la tarifa más barata
+	ShippingMethod string `json:"shipping_method"`
 }
 
 type OrderItemRequest struct {
@@ -56,6 +122,21 @@ type OrderItemRequest struct {
 	Quantity  int  `json:"quantity" binding:"required,min=1"`
 }
 
+// SplitOrderRequest indica las líneas que pasan a un nuevo pedido vinculado
+type SplitOrderRequest struct {
+	OrderItemIDs []uint `json:"order_item_ids" binding:"required,min=1"`
+}
+
+// MergeOrderRequest indica el pedido cuyas líneas se fusionan en el pedido de la URL
+type MergeOrderRequest struct {
+	SourceOrderID OrderRef `json:"source_order_id" binding:"required"`
+}
+
+// CancelOrderRequest lleva el motivo opcional de la cancelación
+type CancelOrderRequest struct {
+	Reason string `json:"reason" binding:"max=255"`
+}
+
 type UpdateOrderStatusRequest struct {
 	Status OrderStatus `json:"status" binding:"required"`
 }
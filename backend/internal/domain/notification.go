@@ -0,0 +1,68 @@
+package domain
+
+import "time"
+
+const (
+	LocaleES = "es"
+	LocaleEN = "en"
+)
+
+// NotificationPreference guarda cómo quiere recibir avisos un usuario. Sin registro se usan
+// los valores por defecto: email habilitado, en español y para todos los eventos.
+type NotificationPreference struct {
+	UserID       uint          `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	Locale       string        `json:"locale" gorm:"type:varchar(5);not null"`
+	EmailEnabled bool          `json:"email_enabled"`
+	MutedEvents  EventTypeList `json:"muted_events" gorm:"type:text"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+}
+
+// Wants indica si el usuario quiere recibir por email el tipo de evento
+func (p NotificationPreference) Wants(eventType EventType) bool {
+	if !p.EmailEnabled {
+		return false
+	}
+	for _, muted := range p.MutedEvents {
+		if muted == eventType {
+			return false
+		}
+	}
+	return true
+}
+
+type UpdateNotificationPreferenceRequest struct {
+	Locale       string      `json:"locale" binding:"required,oneof=es en"`
+	EmailEnabled bool        `json:"email_enabled"`
+	MutedEvents  []EventType `json:"muted_events"`
+}
+
+type NotificationStatus string
+
+const (
+	NotificationPending NotificationStatus = "PENDING"
+	NotificationSent    NotificationStatus = "SENT"
+	NotificationFailed  NotificationStatus = "FAILED"
+)
+
+// Notification registra un email enviado (o por enviar) por un evento de pedido. El
+// contenido se guarda ya renderizado para que los reintentos envíen lo mismo.
+type Notification struct {
+	ID            uint               `json:"id" gorm:"primaryKey"`
+	UserID        uint               `json:"user_id" gorm:"not null;index"`
+	OrderID       uint               `json:"-" gorm:"not null;index"`
+	EventID       uint               `json:"event_id" gorm:"not null;index"`
+	EventType     EventType          `json:"event_type" gorm:"type:varchar(50);not null"`
+	Channel       string             `json:"channel" gorm:"type:varchar(20);not null"`
+	Locale        string             `json:"locale" gorm:"type:varchar(5);not null"`
+	Recipient     string             `json:"recipient" gorm:"type:varchar(255);not null"`
+	Subject       string             `json:"subject" gorm:"type:varchar(255);not null"`
+	TextBody      string             `json:"-" gorm:"type:text"`
+	HTMLBody      string             `json:"-" gorm:"type:text"`
+	Status        NotificationStatus `json:"status" gorm:"type:varchar(20);not null;index"`
+	Attempts      int                `json:"attempts"`
+	LastError     string             `json:"last_error,omitempty"`
+	NextAttemptAt time.Time          `json:"next_attempt_at" gorm:"not null;index"`
+	SentAt        *time.Time         `json:"sent_at,omitempty"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+}
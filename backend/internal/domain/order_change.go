@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+type OrderChangeAction string
+
+const (
+	OrderChangeAddItem        OrderChangeAction = "ADD_ITEM"
+	OrderChangeRemoveItem     OrderChangeAction = "REMOVE_ITEM"
+	OrderChangeChangeQuantity OrderChangeAction = "CHANGE_QUANTITY"
+)
+
+// OrderChange registra una modificación de las líneas de un pedido PENDING.
+// Los totales son los del pedido antes y después de la modificación completa.
+type OrderChange struct {
+	ID            uint              `json:"id" gorm:"primaryKey"`
+	OrderID       uint              `json:"-" gorm:"not null;index"`
+	Action        OrderChangeAction `json:"action" gorm:"type:varchar(20);not null"`
+	ProductID     uint              `json:"product_id" gorm:"not null"`
+	OldQuantity   int               `json:"old_quantity"`
+	NewQuantity   int               `json:"new_quantity"`
+	PreviousTotal float64           `json:"previous_total"`
+	NewTotal      float64           `json:"new_total"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
+
+// AmendOrderRequest agrupa los cambios que se aplican juntos sobre un pedido
+type AmendOrderRequest struct {
+	Changes []OrderItemChange `json:"changes" binding:"required,min=1,dive"`
+}
+
+// OrderItemChange agrega un producto (ProductID) o quita / cambia la cantidad de una línea (OrderItemID)
+type OrderItemChange struct {
+	Action      OrderChangeAction `json:"action" binding:"required,oneof=ADD_ITEM REMOVE_ITEM CHANGE_QUANTITY"`
+	ProductID   uint              `json:"product_id"`
+	OrderItemID uint              `json:"order_item_id"`
+	Quantity    int               `json:"quantity" binding:"omitempty,min=1"`
+}
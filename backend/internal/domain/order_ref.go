@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// OrderRef es la referencia a un pedido recibida en un cuerpo JSON: el ID numérico, el
+// identificador público o el número de pedido, como número o como texto
+type OrderRef string
+
+func (r *OrderRef) UnmarshalJSON(data []byte) error {
+	var ref interface{}
+	if err := json.Unmarshal(data, &ref); err != nil {
+		return err
+	}
+	switch value := ref.(type) {
+	case string:
+		*r = OrderRef(value)
+	case float64:
+		*r = OrderRef(strconv.FormatFloat(value, 'f', -1, 64))
+	case nil:
+		*r = ""
+	default:
+		return fmt.Errorf("invalid order reference %s", data)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+type PaymentStatus string
+
+const (
+	PaymentAuthorized        PaymentStatus = "AUTHORIZED"
+	PaymentCaptured          PaymentStatus = "CAPTURED"
+	PaymentVoided            PaymentStatus = "VOIDED"
+	PaymentRefunded          PaymentStatus = "REFUNDED"
+	PaymentPartiallyRefunded PaymentStatus = "PARTIALLY_REFUNDED"
+	PaymentFailed            PaymentStatus = "FAILED"
+)
+
+// Payment registra cada intento de pago de un pedido, incluidos los rechazados.
+type Payment struct {
+	ID              uint          `json:"id" gorm:"primaryKey"`
+	OrderID         uint          `json:"-" gorm:"not null;index"`
+	Gateway         string        `json:"gateway" gorm:"type:varchar(50);not null"`
+	AuthorizationID string        `json:"authorization_id,omitempty" gorm:"type:varchar(100)"`
+	Status          PaymentStatus `json:"status" gorm:"type:varchar(20);not null"`
+	Amount          float64       `json:"amount" gorm:"not null"`
+	CapturedAmount  float64       `json:"captured_amount" gorm:"not null;default:0"`
+	RefundedAmount  float64       `json:"refunded_amount" gorm:"not null;default:0"`
+	CardLast4       string        `json:"card_last4" gorm:"type:varchar(4)"`
+	FailureCode     string        `json:"failure_code,omitempty" gorm:"type:varchar(50)"`
+	FailureMessage  string        `json:"failure_message,omitempty"`
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+}
@@ -0,0 +1,64 @@
+package domain
+
+import "time"
+
+type PromotionType string
+
+const (
+	PromotionPercentage PromotionType = "PERCENTAGE"
+	PromotionFixed      PromotionType = "FIXED_AMOUNT"
+	PromotionBuyXGetY   PromotionType = "BUY_X_GET_Y"
+)
+
+// Promotion describe un cupón (si tiene Code) o una promoción automática (sin Code).
+// El alcance se restringe opcionalmente a un producto o a una categoría.
+type Promotion struct {
+	ID             uint          `json:"id" gorm:"primaryKey"`
+	Code           *string       `json:"code,omitempty" gorm:"type:varchar(50);uniqueIndex"`
+	Name           string        `json:"name" gorm:"not null"`
+	Type           PromotionType `json:"type" gorm:"type:varchar(20);not null"`
+	Value          float64       `json:"value"`
+	BuyQuantity    int           `json:"buy_quantity"`
+	GetQuantity    int           `json:"get_quantity"`
+	ProductID      *uint         `json:"product_id,omitempty"`
+	Category       string        `json:"category,omitempty" gorm:"type:varchar(100)"`
+	MinSubtotal    float64       `json:"min_subtotal"`
+	MaxUses        int           `json:"max_uses"`
+	MaxUsesPerUser int           `json:"max_uses_per_user"`
+	UsedCount      int           `json:"used_count" gorm:"not null;default:0"`
+	StartsAt       *time.Time    `json:"starts_at,omitempty"`
+	EndsAt         *time.Time    `json:"ends_at,omitempty"`
+	Stackable      bool          `json:"stackable"`
+	Priority       int           `json:"priority"`
+	Disabled       bool          `json:"disabled"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+// PromotionRedemption registra el uso de una promoción por un usuario en un pedido.
+type PromotionRedemption struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	PromotionID uint      `json:"promotion_id" gorm:"not null;index"`
+	UserID      uint      `json:"user_id" gorm:"not null;index"`
+	OrderID     uint      `json:"-" gorm:"not null;index"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// PromotionUserUsage cuenta los usos de una promoción por usuario; se reservan con un UPDATE
+// condicional para que pedidos simultáneos no superen MaxUsesPerUser.
+type PromotionUserUsage struct {
+	PromotionID uint `json:"promotion_id" gorm:"primaryKey;autoIncrement:false"`
+	UserID      uint `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	UsedCount   int  `json:"used_count" gorm:"not null;default:0"`
+}
+
+// OrderItemDiscount guarda el descuento aplicado a una línea, con los datos de la
+// promoción copiados para que el total siga siendo explicable si la promoción cambia.
+type OrderItemDiscount struct {
+	ID          uint          `json:"id" gorm:"primaryKey"`
+	OrderItemID uint          `json:"order_item_id" gorm:"not null;index"`
+	PromotionID uint          `json:"promotion_id" gorm:"not null"`
+	Code        string        `json:"code,omitempty" gorm:"type:varchar(50)"`
+	Description string        `json:"description"`
+	Type        PromotionType `json:"type" gorm:"type:varchar(20);not null"`
+	Amount      float64       `json:"amount" gorm:"not null"`
+}
@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// Refund es una devolución de dinero sobre el pago capturado de un pedido.
+// Puede referir a líneas concretas (con cantidades) o ser un monto libre.
+type Refund struct {
+	ID              uint         `json:"id" gorm:"primaryKey"`
+	OrderID         uint         `json:"-" gorm:"not null;index"`
+	PaymentID       uint         `json:"payment_id" gorm:"not null"`
+	GatewayRefundID string       `json:"gateway_refund_id" gorm:"type:varchar(100)"`
+	Amount          float64      `json:"amount" gorm:"not null"`
+	Reason          string       `json:"reason"`
+	Lines           []RefundLine `json:"lines,omitempty" gorm:"foreignKey:RefundID"`
+	CreatedAt       time.Time    `json:"created_at"`
+}
+
+type RefundLine struct {
+	ID          uint    `json:"id" gorm:"primaryKey"`
+	RefundID    uint    `json:"refund_id" gorm:"not null;index"`
+	OrderItemID uint    `json:"order_item_id" gorm:"not null;index"`
+	Quantity    int     `json:"quantity" gorm:"not null"`
+	Amount      float64 `json:"amount" gorm:"not null"`
+	Restocked   bool    `json:"restocked"`
+}
+
+// CreateRefundRequest admite tres modos excluyentes: Full (todo el saldo cobrado),
+// Items (líneas y cantidades) o Amount (monto libre, requiere Reason).
+type CreateRefundRequest struct {
+	Reason  string              `json:"reason"`
+	Full    bool                `json:"full"`
+	Restock bool                `json:"restock"`
+	Amount  float64             `json:"amount" binding:"omitempty,gt=0"`
+	Items   []RefundItemRequest `json:"items" binding:"omitempty,dive"`
+}
+
+type RefundItemRequest struct {
+	OrderItemID uint `json:"order_item_id" binding:"required"`
+	// Quantity 0 devuelve todas las unidades aún no reembolsadas
+	Quantity int  `json:"quantity" binding:"omitempty,min=1"`
+	Restock  bool `json:"restock"`
+}
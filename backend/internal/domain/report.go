@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// StatusSales resume los pedidos de un estado dentro de un período
+type StatusSales struct {
+	Status OrderStatus `json:"status"`
+	Orders int64       `json:"orders"`
+	Total  float64     `json:"total"`
+}
+
+// SalesReport resume los pedidos creados en un período; Revenue no incluye los cancelados
+type SalesReport struct {
+	From         time.Time     `json:"from"`
+	To           time.Time     `json:"to"`
+	Orders       int64         `json:"orders"`
+	Revenue      float64       `json:"revenue"`
+	AverageOrder float64       `json:"average_order"`
+	ByStatus     []StatusSales `json:"by_status"`
+}
@@ -0,0 +1,80 @@
+package domain
+
+import "time"
+
+type ReturnStatus string
+
+const (
+	ReturnRequested ReturnStatus = "REQUESTED"
+	ReturnApproved  ReturnStatus = "APPROVED"
+	ReturnRejected  ReturnStatus = "REJECTED"
+	ReturnReceived  ReturnStatus = "RECEIVED"
+	ReturnInspected ReturnStatus = "INSPECTED"
+)
+
+type ReturnDisposition string
+
+const (
+	DispositionRestock  ReturnDisposition = "RESTOCK"
+	DispositionWriteOff ReturnDisposition = "WRITE_OFF"
+)
+
+// ReturnAuthorization (RMA) es la solicitud de devolución de ítems de un pedido enviado.
+// Al aprobarse genera un reembolso; al inspeccionar la mercadería se repone o se da de baja.
+type ReturnAuthorization struct {
+	ID          uint               `json:"id" gorm:"primaryKey"`
+	OrderID     uint               `json:"-" gorm:"not null;index"`
+	Status      ReturnStatus       `json:"status" gorm:"type:varchar(20);not null"`
+	Reason      string             `json:"reason" gorm:"not null"`
+	RefundID    *uint              `json:"refund_id,omitempty"`
+	Items       []ReturnItem       `json:"items" gorm:"foreignKey:ReturnID"`
+	Transitions []ReturnTransition `json:"transitions" gorm:"foreignKey:ReturnID"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+type ReturnItem struct {
+	ID          uint              `json:"id" gorm:"primaryKey"`
+	ReturnID    uint              `json:"return_id" gorm:"not null;index"`
+	OrderItemID uint              `json:"order_item_id" gorm:"not null"`
+	Quantity    int               `json:"quantity" gorm:"not null"`
+	Reason      string            `json:"reason"`
+	Disposition ReturnDisposition `json:"disposition,omitempty" gorm:"type:varchar(20)"`
+}
+
+// ReturnTransition registra cada cambio de estado de una devolución
+type ReturnTransition struct {
+	ID         uint         `json:"id" gorm:"primaryKey"`
+	ReturnID   uint         `json:"return_id" gorm:"not null;index"`
+	FromStatus ReturnStatus `json:"from_status" gorm:"type:varchar(20)"`
+	ToStatus   ReturnStatus `json:"to_status" gorm:"type:varchar(20);not null"`
+	Note       string       `json:"note"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+type CreateReturnRequest struct {
+	Reason string              `json:"reason" binding:"required"`
+	Items  []ReturnItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+type ReturnItemRequest struct {
+	OrderItemID uint   `json:"order_item_id" binding:"required"`
+	Quantity    int    `json:"quantity" binding:"required,min=1"`
+	Reason      string `json:"reason"`
+}
+
+// ReturnDecisionRequest acompaña la aprobación, el rechazo o la recepción de una devolución
+type ReturnDecisionRequest struct {
+	Note string `json:"note"`
+}
+
+// InspectReturnRequest indica el destino de cada ítem recibido
+type InspectReturnRequest struct {
+	Note  string                 `json:"note"`
+	Items []ReturnInspectionItem `json:"items" binding:"required,min=1,dive"`
+}
+
+type ReturnInspectionItem struct {
+	ReturnItemID uint              `json:"return_item_id" binding:"required"`
+	Disposition  ReturnDisposition `json:"disposition" binding:"required,oneof=RESTOCK WRITE_OFF"`
+}
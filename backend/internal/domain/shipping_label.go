@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// ShippingLabel es una etiqueta emitida por el transportista local. Se persiste para que
+// el seguimiento sobreviva a los reinicios del servidor.
+type ShippingLabel struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	TrackingNumber string    `json:"tracking_number" gorm:"type:varchar(100);not null;uniqueIndex"`
+	Reference      string    `json:"reference" gorm:"type:varchar(100)"`
+	Method         string    `json:"method" gorm:"type:varchar(50)"`
+	Status         string    `json:"status" gorm:"type:varchar(30);not null"`
+	Description    string    `json:"description"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
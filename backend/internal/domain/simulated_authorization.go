@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// SimulatedAuthorization es el estado de una autorización del simulador de pagos. Se
+// persiste para que las capturas y reembolsos sigan funcionando después de reiniciar.
+type SimulatedAuthorization struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	AuthorizationID string    `json:"authorization_id" gorm:"type:varchar(100);not null;uniqueIndex"`
+	Amount          float64   `json:"amount" gorm:"not null"`
+	Captured        float64   `json:"captured" gorm:"not null;default:0"`
+	Refunded        float64   `json:"refunded" gorm:"not null;default:0"`
+	Voided          bool      `json:"voided" gorm:"not null;default:false"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// PaymentMethodNone identifica a los pedidos sin intentos de pago
+const PaymentMethodNone = "none"
+
+// StaleOrder es un pedido PENDING vencido encontrado por el barrido
+type StaleOrder struct {
+	OrderID       uint      `json:"-"`
+	PublicID      string    `json:"public_id,omitempty"`
+	Number        string    `json:"number,omitempty"`
+	UserID        uint      `json:"user_id"`
+	PaymentMethod string    `json:"payment_method"`
+	Total         float64   `json:"total"`
+	CreatedAt     time.Time `json:"created_at"`
+	Timeout       string    `json:"timeout"`
+	Error         string    `json:"error,omitempty"`
+}
+
+// StaleOrderReport resume un barrido de pedidos vencidos. En modo dry-run Cancelled lista
+// los pedidos que se habrían cancelado sin modificarlos.
+type StaleOrderReport struct {
+	DryRun    bool         `json:"dry_run"`
+	RanAt     time.Time    `json:"ran_at"`
+	Cancelled []StaleOrder `json:"cancelled"`
+	Failed    []StaleOrder `json:"failed"`
+}
@@ -0,0 +1,36 @@
+package domain
+
+const (
+	TaxClassStandard = "STANDARD"
+	TaxClassReduced  = "REDUCED"
+	TaxClassExempt   = "EXEMPT"
+)
+
+// Jurisdiction identifica dónde se grava un pedido. Region vacío significa todo el país.
+type Jurisdiction struct {
+	Country string `json:"country"`
+	Region  string `json:"region,omitempty"`
+}
+
+// TaxRule define la alícuota de una clase impositiva en una jurisdicción.
+// Una regla con Region tiene prioridad sobre la regla del país para la misma clase.
+type TaxRule struct {
+	ID       uint    `json:"id" gorm:"primaryKey"`
+	Country  string  `json:"country" gorm:"type:varchar(2);not null;index"`
+	Region   string  `json:"region" gorm:"type:varchar(100)"`
+	TaxClass string  `json:"tax_class" gorm:"type:varchar(20);not null"`
+	Name     string  `json:"name" gorm:"not null"`
+	Rate     float64 `json:"rate" gorm:"not null"`
+}
+
+// OrderTaxLine es el resumen de impuestos del pedido agrupado por alícuota,
+// tal como se informa en la factura.
+type OrderTaxLine struct {
+	ID       uint    `json:"id" gorm:"primaryKey"`
+	OrderID  uint    `json:"-" gorm:"not null;index"`
+	TaxClass string  `json:"tax_class" gorm:"type:varchar(20);not null"`
+	Name     string  `json:"name" gorm:"not null"`
+	Rate     float64 `json:"rate" gorm:"not null"`
+	Base     float64 `json:"base" gorm:"not null"`
+	Amount   float64 `json:"amount" gorm:"not null"`
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// EventTypeList se guarda como texto separado por comas
+type EventTypeList []EventType
+
+func (l EventTypeList) Value() (driver.Value, error) {
+	parts := make([]string, len(l))
+	for i, eventType := range l {
+		parts[i] = string(eventType)
+	}
+	return strings.Join(parts, ","), nil
+}
+
+func (l *EventTypeList) Scan(value interface{}) error {
+	var raw string
+	switch v := value.(type) {
+	case nil:
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	default:
+		return fmt.Errorf("unsupported event type list value %T", value)
+	}
+	*l = nil
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			*l = append(*l, EventType(part))
+		}
+	}
+	return nil
+}
+
+// Includes indica si la lista contiene el tipo; una lista vacía incluye todos
+func (l EventTypeList) Includes(eventType EventType) bool {
+	if len(l) == 0 {
+		return true
+	}
+	for _, t := range l {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookSubscription es un endpoint de un partner que recibe los eventos indicados,
+// firmados con HMAC-SHA256 usando Secret.
+type WebhookSubscription struct {
+	ID                  uint          `json:"id" gorm:"primaryKey"`
+	URL                 string        `json:"url" gorm:"type:varchar(500);not null"`
+	EventTypes          EventTypeList `json:"event_types" gorm:"type:text"`
+	Secret              string        `json:"secret,omitempty" gorm:"type:varchar(100);not null"`
+	Disabled            bool          `json:"disabled"`
+	ConsecutiveFailures int           `json:"consecutive_failures"`
+	DisabledAt          *time.Time    `json:"disabled_at,omitempty"`
+	CreatedAt           time.Time     `json:"created_at"`
+	UpdatedAt           time.Time     `json:"updated_at"`
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "PENDING"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "SUCCEEDED"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "FAILED"
+)
+
+// WebhookDelivery registra el envío de un evento a una suscripción y sus intentos
+type WebhookDelivery struct {
+	ID             uint                  `json:"id" gorm:"primaryKey"`
+	SubscriptionID uint                  `json:"subscription_id" gorm:"not null;index"`
+	EventID        uint                  `json:"event_id" gorm:"not null;index"`
+	EventType      EventType             `json:"event_type" gorm:"type:varchar(50);not null"`
+	Payload        string                `json:"payload" gorm:"type:text;not null"`
+	Status         WebhookDeliveryStatus `json:"status" gorm:"type:varchar(20);not null;index"`
+	Attempts       int                   `json:"attempts"`
+	NextAttemptAt  time.Time             `json:"next_attempt_at" gorm:"not null;index"`
+	ResponseStatus int                   `json:"response_status,omitempty"`
+	ResponseBody   string                `json:"response_body,omitempty" gorm:"type:text"`
+	LastError      string                `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+}
+
+type CreateWebhookRequest struct {
+	URL        string      `json:"url" binding:"required,url"`
+	EventTypes []EventType `json:"event_types"`
+	// Secret opcional; si se omite se genera uno aleatorio
+	Secret string `json:"secret"`
+}
+
+type UpdateWebhookRequest struct {
+	URL        string      `json:"url" binding:"required,url"`
+	EventTypes []EventType `json:"event_types"`
+	Disabled   bool        `json:"disabled"`
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AddressHandler struct {
+	addressService *services.AddressService
+}
+
+func NewAddressHandler(addressService *services.AddressService) *AddressHandler {
+	return &AddressHandler{addressService: addressService}
+}
+
+func (h *AddressHandler) GetByUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid User ID"})
+		return
+	}
+
+	addresses, err := h.addressService.GetAddresses(c.Request.Context(), uint(userID))
+	if err != nil {
+		addressError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, addresses)
+}
+
+func (h *AddressHandler) Create(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid User ID"})
+		return
+	}
+
+	var address domain.Address
+	if err := c.ShouldBindJSON(&address); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.addressService.CreateAddress(c.Request.Context(), uint(userID), &address); err != nil {
+		addressError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, address)
+}
+
+func (h *AddressHandler) Update(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid User ID"})
+		return
+	}
+	addressID, err := strconv.ParseUint(c.Param("addressId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Address ID"})
+		return
+	}
+
+	var input domain.Address
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	address, err := h.addressService.UpdateAddress(c.Request.Context(), uint(userID), uint(addressID), &input)
+	if err != nil {
+		addressError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, address)
+}
+
+func (h *AddressHandler) Delete(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid User ID"})
+		return
+	}
+	addressID, err := strconv.ParseUint(c.Param("addressId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Address ID"})
+		return
+	}
+
+	if err := h.addressService.DeleteAddress(c.Request.Context(), uint(userID), uint(addressID)); err != nil {
+		addressError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func addressError(c *gin.Context, err error) {
+	statusCode := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrUserNotFound), errors.Is(err, services.ErrAddressNotFound):
+		statusCode = http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidAddress):
+		statusCode = http.StatusBadRequest
+	}
+	c.JSON(statusCode, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PoolStatter expone las estadísticas del pool de conexiones; *sql.DB lo implementa
+type PoolStatter interface {
+	Stats() sql.DBStats
+}
+
+type AdminHandler struct {
+	pool PoolStatter
+}
+
+func NewAdminHandler(pool PoolStatter) *AdminHandler {
+	return &AdminHandler{pool: pool}
+}
+
+// DBPool devuelve el estado del pool de conexiones a la base
+func (h *AdminHandler) DBPool(c *gin.Context) {
+	stats := h.pool.Stats()
+	c.JSON(http.StatusOK, gin.H{
+		"max_open_connections": stats.MaxOpenConnections,
+		"open_connections":     stats.OpenConnections,
+		"in_use":               stats.InUse,
+		"idle":                 stats.Idle,
+		"wait_count":           stats.WaitCount,
+		"wait_duration_ms":     stats.WaitDuration.Milliseconds(),
+		"max_idle_closed":      stats.MaxIdleClosed,
+		"max_idle_time_closed": stats.MaxIdleTimeClosed,
+		"max_lifetime_closed":  stats.MaxLifetimeClosed,
+	})
+}
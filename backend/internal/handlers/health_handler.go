@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+	"order-management-system/internal/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	registry *health.Registry
+}
+
+func NewHealthHandler(registry *health.Registry) *HealthHandler {
+	return &HealthHandler{registry: registry}
+}
+
+// Live responde 503 sólo si el proceso necesita reiniciarse, por ejemplo con un worker trabado
+func (h *HealthHandler) Live(c *gin.Context) {
+	respondHealth(c, h.registry.Live(c.Request.Context()))
+}
+
+// Ready responde 503 si la instancia no puede atender tráfico, con el detalle de cada chequeo
+func (h *HealthHandler) Ready(c *gin.Context) {
+	respondHealth(c, h.registry.Ready(c.Request.Context()))
+}
+
+func respondHealth(c *gin.Context, report health.Report) {
+	status := http.StatusOK
+	if !report.Healthy() {
+		status = http.StatusServiceUnavailable
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, report)
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/jobs"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JobHandler struct {
+	queue *jobs.Queue
+}
+
+func NewJobHandler(queue *jobs.Queue) *JobHandler {
+	return &JobHandler{queue: queue}
+}
+
+func (h *JobHandler) GetAll(c *gin.Context) {
+	filter := domain.JobFilter{
+		Status: domain.JobStatus(c.Query("status")),
+		Type:   c.Query("type"),
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		filter.Limit = limit
+	}
+
+	list, err := h.queue.List(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *JobHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	job, err := h.queue.Get(c.Request.Context(), uint(id))
+	if err != nil {
+		jobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+func (h *JobHandler) Schedules(c *gin.Context) {
+	c.JSON(http.StatusOK, h.queue.Schedules())
+}
+
+func (h *JobHandler) Retry(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	job, err := h.queue.Retry(c.Request.Context(), uint(id))
+	if err != nil {
+		jobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// Purge borra los trabajos terminados: ?status=DEAD|SUCCEEDED&older_than=24h (por defecto 0)
+func (h *JobHandler) Purge(c *gin.Context) {
+	var olderThan time.Duration
+	if raw := c.Query("older_than"); raw != "" {
+		var err error
+		if olderThan, err = time.ParseDuration(raw); err != nil || olderThan < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid older_than"})
+			return
+		}
+	}
+
+	deleted, err := h.queue.Purge(c.Request.Context(), domain.JobStatus(c.Query("status")), olderThan)
+	if err != nil {
+		jobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deleted": deleted})
+}
+
+func jobError(c *gin.Context, err error) {
+	statusCode := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, jobs.ErrJobNotFound):
+		statusCode = http.StatusNotFound
+	case errors.Is(err, jobs.ErrInvalidPurge):
+		statusCode = http.StatusBadRequest
+	case errors.Is(err, jobs.ErrJobNotRetryable):
+		statusCode = http.StatusConflict
+	}
+	c.JSON(statusCode, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	notificationService *services.NotificationService
+}
+
+func NewNotificationHandler(notificationService *services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	preference, err := h.notificationService.GetPreferences(c.Request.Context(), uint(id))
+	if err != nil {
+		notificationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, preference)
+}
+
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var req domain.UpdateNotificationPreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	preference, err := h.notificationService.UpdatePreferences(c.Request.Context(), uint(id), req)
+	if err != nil {
+		notificationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, preference)
+}
+
+func (h *NotificationHandler) GetByUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	notifications, err := h.notificationService.GetNotifications(c.Request.Context(), uint(id))
+	if err != nil {
+		notificationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, notifications)
+}
+
+func (h *NotificationHandler) Retry(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	notification, err := h.notificationService.Retry(c.Request.Context(), uint(id))
+	if err != nil {
+		notificationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, notification)
+}
+
+func notificationError(c *gin.Context, err error) {
+	statusCode := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrUserNotFound), errors.Is(err, services.ErrNotificationNotFound):
+		statusCode = http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidPreference):
+		statusCode = http.StatusBadRequest
+	case errors.Is(err, services.ErrNotificationSent):
+		statusCode = http.StatusConflict
+	}
+	c.JSON(statusCode, gin.H{"error": err.Error()})
+}
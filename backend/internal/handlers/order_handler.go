@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"order-management-system/internal/domain"
+	"order-management-system/internal/payments"
 	"order-management-system/internal/services"
+	"order-management-system/internal/shipping"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -24,7 +27,7 @@ func (h *OrderHandler) Create(c *gin.Context) {
 		return
 	}
 
-	order, err := h.orderService.CreateOrder(req)
+	order, err := h.orderService.CreateOrder(c.Request.Context(), req)
 	if err != nil {
 		statusCode := http.StatusInternalServerError
 		switch err {
@@ -34,6 +37,18 @@ func (h *OrderHandler) Create(c *gin.Context) {
 			statusCode = http.StatusNotFound
 		case services.ErrInsufficientStock:
 			statusCode = http.StatusBadRequest
+		case services.ErrCouponNotFound, services.ErrAddressNotFound:
+			statusCode = http.StatusNotFound
+		case services.ErrCouponExpired, services.ErrCouponUsageExceeded,
+			services.ErrCouponNotApplicable, services.ErrCouponNotStackable,
+			shipping.ErrMethodUnavailable, shipping.ErrNoRates:
+			statusCode = http.StatusUnprocessableEntity
+		}
+		if errors.Is(err, services.ErrNoTaxRule) {
+			statusCode = http.StatusUnprocessableEntity
+		}
+		if errors.Is(err, shipping.ErrCarrierUnavailable) {
+			statusCode = http.StatusBadGateway
 		}
 		c.JSON(statusCode, gin.H{"error": err.Error()})
 		return
@@ -43,7 +58,7 @@ func (h *OrderHandler) Create(c *gin.Context) {
 }
 
 func (h *OrderHandler) GetAll(c *gin.Context) {
-	orders, err := h.orderService.GetAllOrders()
+	orders, err := h.orderService.GetAllOrders(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -58,7 +73,7 @@ func (h *OrderHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	order, err := h.orderService.GetOrder(uint(id))
+	order, err := h.orderService.GetOrder(c.Request.Context(), uint(id))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
 		return
@@ -74,7 +89,7 @@ func (h *OrderHandler) GetByUserID(c *gin.Context) {
 		return
 	}
 
-	orders, err := h.orderService.GetOrdersByUser(uint(userID))
+	orders, err := h.orderService.GetOrdersByUser(c.Request.Context(), uint(userID))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -90,7 +105,7 @@ func (h *OrderHandler) Confirm(c *gin.Context) {
 		return
 	}
 
-	order, err := h.orderService.ConfirmOrder(uint(id))
+	order, err := h.orderService.ConfirmOrder(c.Request.Context(), uint(id))
 	if err != nil {
 		statusCode := http.StatusInternalServerError
 		switch err {
@@ -100,6 +115,11 @@ func (h *OrderHandler) Confirm(c *gin.Context) {
 			statusCode = http.StatusBadRequest
 		case services.ErrInsufficientStock:
 			statusCode = http.StatusBadRequest
+		case services.ErrPaymentRequired:
+			statusCode = http.StatusPaymentRequired
+		}
+		if errors.Is(err, payments.ErrGatewayUnavailable) {
+			statusCode = http.StatusBadGateway
 		}
 		c.JSON(statusCode, gin.H{"error": err.Error()})
 		return
@@ -115,14 +135,19 @@ func (h *OrderHandler) Ship(c *gin.Context) {
 		return
 	}
 
-	order, err := h.orderService.ShipOrder(uint(id))
+	order, err := h.orderService.ShipOrder(c.Request.Context(), uint(id))
 	if err != nil {
 		statusCode := http.StatusInternalServerError
 		switch err {
 		case services.ErrOrderNotFound:
 			statusCode = http.StatusNotFound
-		case services.ErrInvalidStatus:
+		case services.ErrInvalidStatus, services.ErrMissingShippingAddress:
 			statusCode = http.StatusBadRequest
+		case shipping.ErrMethodUnavailable, shipping.ErrNoRates:
+			statusCode = http.StatusUnprocessableEntity
+		}
+		if errors.Is(err, shipping.ErrCarrierUnavailable) {
+			statusCode = http.StatusBadGateway
 		}
 		c.JSON(statusCode, gin.H{"error": err.Error()})
 		return
@@ -138,14 +163,212 @@ func (h *OrderHandler) Cancel(c *gin.Context) {
 		return
 	}
 
-	order, err := h.orderService.CancelOrder(uint(id))
+	// El motivo es opcional
+	var req domain.CancelOrderRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	order, err := h.orderService.CancelOrder(c.Request.Context(), uint(id), services.CancelledBy(domain.ActorUser, req.Reason))
 	if err != nil {
 		statusCode := http.StatusInternalServerError
 		switch err {
 		case services.ErrOrderNotFound:
 			statusCode = http.StatusNotFound
-		case services.ErrCannotCancelShipped:
+		case services.ErrCannotCancelShipped, services.ErrInvalidStatus:
+			statusCode = http.StatusBadRequest
+		}
+		if errors.Is(err, payments.ErrGatewayUnavailable) {
+			statusCode = http.StatusBadGateway
+		}
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+func (h *OrderHandler) QuoteShipping(c *gin.Context) {
+	var req domain.CreateOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rates, err := h.orderService.QuoteShipping(c.Request.Context(), req)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		switch err {
+		case services.ErrUserNotFound, services.ErrProductNotFound, services.ErrAddressNotFound:
+			statusCode = http.StatusNotFound
+		case services.ErrMissingShippingAddress:
+			statusCode = http.StatusBadRequest
+		case shipping.ErrNoRates:
+			statusCode = http.StatusUnprocessableEntity
+		}
+		if errors.Is(err, shipping.ErrCarrierUnavailable) {
+			statusCode = http.StatusBadGateway
+		}
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rates)
+}
+
+func (h *OrderHandler) Tracking(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	status, err := h.orderService.GetTracking(c.Request.Context(), uint(id))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		switch err {
+		case services.ErrOrderNotFound, services.ErrNoTrackingNumber, shipping.ErrTrackingNotFound:
+			statusCode = http.StatusNotFound
+		}
+		if errors.Is(err, shipping.ErrCarrierUnavailable) {
+			statusCode = http.StatusBadGateway
+		}
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+func (h *OrderHandler) AmendItems(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var req domain.AmendOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	order, err := h.orderService.AmendOrder(c.Request.Context(), uint(id), req)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		switch err {
+		case services.ErrOrderNotFound, services.ErrProductNotFound:
+			statusCode = http.StatusNotFound
+		case services.ErrInsufficientStock:
+			statusCode = http.StatusBadRequest
+		case services.ErrOrderNotEditable:
+			statusCode = http.StatusConflict
+		case services.ErrCouponExpired, services.ErrCouponUsageExceeded,
+			services.ErrCouponNotApplicable, services.ErrCouponNotStackable,
+			shipping.ErrMethodUnavailable, shipping.ErrNoRates:
+			statusCode = http.StatusUnprocessableEntity
+		}
+		if errors.Is(err, services.ErrInvalidOrderChange) {
+			statusCode = http.StatusBadRequest
+		}
+		if errors.Is(err, services.ErrNoTaxRule) {
+			statusCode = http.StatusUnprocessableEntity
+		}
+		if errors.Is(err, shipping.ErrCarrierUnavailable) || errors.Is(err, payments.ErrGatewayUnavailable) {
+			statusCode = http.StatusBadGateway
+		}
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+func (h *OrderHandler) Changes(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	changes, err := h.orderService.GetOrderChanges(c.Request.Context(), uint(id))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err == services.ErrOrderNotFound {
+			statusCode = http.StatusNotFound
+		}
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, changes)
+}
+
+func (h *OrderHandler) Split(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var req domain.SplitOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	order, child, err := h.orderService.SplitOrder(c.Request.Context(), uint(id), req)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrOrderNotFound):
+			statusCode = http.StatusNotFound
+		case errors.Is(err, services.ErrInvalidSplit):
+			statusCode = http.StatusBadRequest
+		case errors.Is(err, services.ErrInvalidStatus):
+			statusCode = http.StatusConflict
+		case errors.Is(err, payments.ErrGatewayUnavailable):
+			statusCode = http.StatusBadGateway
+		}
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"order": order, "split_order": child})
+}
+
+func (h *OrderHandler) Merge(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var req domain.MergeOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var order *domain.Order
+	sourceID, err := h.orderService.ResolveOrderID(c.Request.Context(), string(req.SourceOrderID))
+	if err == nil {
+		order, err = h.orderService.MergeOrders(c.Request.Context(), uint(id), sourceID)
+	}
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrOrderNotFound):
+			statusCode = http.StatusNotFound
+		case errors.Is(err, services.ErrInvalidMerge):
 			statusCode = http.StatusBadRequest
+		case errors.Is(err, services.ErrInvalidStatus):
+			statusCode = http.StatusConflict
+		case errors.Is(err, payments.ErrGatewayUnavailable):
+			statusCode = http.StatusBadGateway
 		}
 		c.JSON(statusCode, gin.H{"error": err.Error()})
 		return
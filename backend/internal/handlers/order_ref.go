@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrderIDResolver convierte la referencia a un pedido recibida en la URL en su ID interno
+type OrderIDResolver interface {
+	ResolveOrderID(ctx context.Context, ref string) (uint, error)
+}
+
+// ResolveOrderParam reemplaza el parámetro :id de las rutas de pedidos por el ID interno,
+// así todos los handlers de /orders/:id aceptan también identificadores públicos
+func ResolveOrderParam(resolver OrderIDResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for i, param := range c.Params {
+			if param.Key != "id" {
+				continue
+			}
+			id, err := resolver.ResolveOrderID(c.Request.Context(), param.Value)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.Params[i].Value = strconv.FormatUint(uint64(id), 10)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"order-management-system/internal/realtime"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OrderStreamHandler struct {
+	hub       *realtime.Hub
+	orders    OrderIDResolver
+	heartbeat time.Duration
+}
+
+func NewOrderStreamHandler(hub *realtime.Hub, orders OrderIDResolver, heartbeat time.Duration) *OrderStreamHandler {
+	return &OrderStreamHandler{hub: hub, orders: orders, heartbeat: heartbeat}
+}
+
+// Stream transmite los cambios de estado de los pedidos por SSE, opcionalmente filtrados por user_id u order_id
+func (h *OrderStreamHandler) Stream(c *gin.Context) {
+	var filter realtime.Filter
+	if raw := c.Query("user_id"); raw != "" {
+		userID, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+		filter.UserID = uint(userID)
+	}
+	if raw := c.Query("order_id"); raw != "" {
+		orderID, err := h.orders.ResolveOrderID(c.Request.Context(), raw)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		filter.OrderID = orderID
+	}
+
+	h.hub.ServeSSE(c.Writer, c.Request, filter, h.heartbeat)
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"order-management-system/internal/payments"
+	"order-management-system/internal/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PaymentHandler struct {
+	paymentService *services.PaymentService
+}
+
+func NewPaymentHandler(paymentService *services.PaymentService) *PaymentHandler {
+	return &PaymentHandler{paymentService: paymentService}
+}
+
+func (h *PaymentHandler) Authorize(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var card payments.Card
+	if err := c.ShouldBindJSON(&card); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	payment, err := h.paymentService.AuthorizeOrder(c.Request.Context(), uint(id), card)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrOrderNotFound):
+			statusCode = http.StatusNotFound
+		case errors.Is(err, services.ErrPaymentNotAllowed), errors.Is(err, services.ErrPaymentAlreadyAuthorized):
+			statusCode = http.StatusConflict
+		case errors.Is(err, payments.ErrCardDeclined):
+			c.JSON(http.StatusPaymentRequired, gin.H{"error": err.Error(), "payment": payment})
+			return
+		case errors.Is(err, payments.ErrGatewayUnavailable):
+			statusCode = http.StatusBadGateway
+		}
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, payment)
+}
+
+func (h *PaymentHandler) GetByOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	records, err := h.paymentService.GetPayments(c.Request.Context(), uint(id))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err == services.ErrOrderNotFound {
+			statusCode = http.StatusNotFound
+		}
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
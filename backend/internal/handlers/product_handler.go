@@ -18,7 +18,7 @@ func NewProductHandler(productRepo repositories.ProductRepository) *ProductHandl
 }
 
 func (h *ProductHandler) GetAll(c *gin.Context) {
-	products, err := h.productRepo.GetAll()
+	products, err := h.productRepo.GetAll(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -33,7 +33,7 @@ func (h *ProductHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	product, err := h.productRepo.GetByID(uint(id))
+	product, err := h.productRepo.GetByID(c.Request.Context(), uint(id))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
 		return
@@ -49,7 +49,7 @@ func (h *ProductHandler) Create(c *gin.Context) {
 		return
 	}
 
-	if err := h.productRepo.Create(&product); err != nil {
+	if err := h.productRepo.Create(c.Request.Context(), &product); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
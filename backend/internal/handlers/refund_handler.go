@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/payments"
+	"order-management-system/internal/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RefundHandler struct {
+	refundService *services.RefundService
+}
+
+func NewRefundHandler(refundService *services.RefundService) *RefundHandler {
+	return &RefundHandler{refundService: refundService}
+}
+
+func (h *RefundHandler) Create(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var req domain.CreateRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	refund, err := h.refundService.CreateRefund(c.Request.Context(), uint(id), req)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrOrderNotFound):
+			statusCode = http.StatusNotFound
+		case errors.Is(err, services.ErrInvalidRefund):
+			statusCode = http.StatusBadRequest
+		case errors.Is(err, services.ErrNothingToRefund), errors.Is(err, services.ErrRefundExceedsPaid):
+			statusCode = http.StatusConflict
+		case errors.Is(err, payments.ErrGatewayUnavailable):
+			statusCode = http.StatusBadGateway
+		}
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, refund)
+}
+
+func (h *RefundHandler) GetByOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	refunds, err := h.refundService.GetRefunds(c.Request.Context(), uint(id))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err == services.ErrOrderNotFound {
+			statusCode = http.StatusNotFound
+		}
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, refunds)
+}
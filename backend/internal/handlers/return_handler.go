@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/payments"
+	"order-management-system/internal/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReturnHandler struct {
+	returnService *services.ReturnService
+}
+
+func NewReturnHandler(returnService *services.ReturnService) *ReturnHandler {
+	return &ReturnHandler{returnService: returnService}
+}
+
+func (h *ReturnHandler) Create(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var req domain.CreateReturnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rma, err := h.returnService.RequestReturn(c.Request.Context(), uint(id), req)
+	if err != nil {
+		returnError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, rma)
+}
+
+func (h *ReturnHandler) GetByOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	rmas, err := h.returnService.GetReturns(c.Request.Context(), uint(id))
+	if err != nil {
+		returnError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rmas)
+}
+
+func (h *ReturnHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	rma, err := h.returnService.GetReturn(c.Request.Context(), uint(id))
+	if err != nil {
+		returnError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rma)
+}
+
+func (h *ReturnHandler) Approve(c *gin.Context) {
+	h.decide(c, h.returnService.Approve)
+}
+
+func (h *ReturnHandler) Reject(c *gin.Context) {
+	h.decide(c, h.returnService.Reject)
+}
+
+func (h *ReturnHandler) Receive(c *gin.Context) {
+	h.decide(c, h.returnService.Receive)
+}
+
+func (h *ReturnHandler) Inspect(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var req domain.InspectReturnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rma, err := h.returnService.Inspect(c.Request.Context(), uint(id), req)
+	if err != nil {
+		returnError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rma)
+}
+
+// decide aplica una transición que sólo recibe una nota opcional
+func (h *ReturnHandler) decide(c *gin.Context, apply func(ctx context.Context, id uint, note string) (*domain.ReturnAuthorization, error)) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var req domain.ReturnDecisionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	rma, err := apply(c.Request.Context(), uint(id), req.Note)
+	if err != nil {
+		returnError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rma)
+}
+
+func returnError(c *gin.Context, err error) {
+	statusCode := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrOrderNotFound), errors.Is(err, services.ErrReturnNotFound):
+		statusCode = http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidReturn), errors.Is(err, services.ErrInvalidRefund):
+		statusCode = http.StatusBadRequest
+	case errors.Is(err, services.ErrReturnNotAllowed), errors.Is(err, services.ErrInvalidReturnTransition),
+		errors.Is(err, services.ErrNothingToRefund), errors.Is(err, services.ErrRefundExceedsPaid):
+		statusCode = http.StatusConflict
+	case errors.Is(err, payments.ErrGatewayUnavailable):
+		statusCode = http.StatusBadGateway
+	}
+	c.JSON(statusCode, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+	"order-management-system/internal/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StaleOrderHandler struct {
+	sweeper *services.StaleOrderSweeper
+}
+
+func NewStaleOrderHandler(sweeper *services.StaleOrderSweeper) *StaleOrderHandler {
+	return &StaleOrderHandler{sweeper: sweeper}
+}
+
+// Sweep cancela ahora los pedidos PENDING vencidos; con ?dry_run=true sólo los informa
+func (h *StaleOrderHandler) Sweep(c *gin.Context) {
+	dryRun := false
+	if raw := c.Query("dry_run"); raw != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dry_run"})
+			return
+		}
+	}
+
+	report, err := h.sweeper.Sweep(c.Request.Context(), dryRun)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
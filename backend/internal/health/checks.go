@@ -0,0 +1,91 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"order-management-system/internal/migrations"
+	"strings"
+	"time"
+)
+
+// Pinger es una conexión que responde a un ping; *sql.DB lo implementa
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// Database verifica que la base responda dentro del timeout del chequeo
+func Database(db Pinger) CheckFunc {
+	return func(ctx context.Context) (string, error) {
+		return "", db.PingContext(ctx)
+	}
+}
+
+// MigrationStatuser informa el estado de las migraciones; *migrations.Migrator lo implementa
+type MigrationStatuser interface {
+	Status(ctx context.Context) ([]migrations.Status, error)
+}
+
+// Migrations falla si quedan migraciones sin aplicar o si alguna aplicada cambió o ya no existe
+func Migrations(migrator MigrationStatuser) CheckFunc {
+	return func(ctx context.Context) (string, error) {
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return "", err
+		}
+		var version uint64
+		var pending, broken []string
+		for _, s := range statuses {
+			switch {
+			case s.Modified:
+				broken = append(broken, fmt.Sprintf("%d modified", s.Version))
+			case s.Missing:
+				broken = append(broken, fmt.Sprintf("%d missing", s.Version))
+			case !s.Applied:
+				pending = append(pending, fmt.Sprint(s.Version))
+			}
+			if s.Applied && s.Version > version {
+				version = s.Version
+			}
+		}
+		detail := fmt.Sprintf("version %d", version)
+		if len(broken) > 0 {
+			return detail, fmt.Errorf("applied migrations changed: %s", strings.Join(broken, ", "))
+		}
+		if len(pending) > 0 {
+			return detail, fmt.Errorf("%d pending migrations: %s", len(pending), strings.Join(pending, ", "))
+		}
+		return detail, nil
+	}
+}
+
+// Heartbeat falla si el último latido de un worker tiene más de maxAge o si nunca latió
+func Heartbeat(last func() time.Time, maxAge time.Duration) CheckFunc {
+	return func(ctx context.Context) (string, error) {
+		beat := last()
+		if beat.IsZero() {
+			return "", fmt.Errorf("no heartbeat yet")
+		}
+		age := time.Since(beat).Round(time.Millisecond)
+		detail := fmt.Sprintf("last heartbeat %s ago", age)
+		if age > maxAge {
+			return detail, fmt.Errorf("no heartbeat for %s (max %s)", age, maxAge)
+		}
+		return detail, nil
+	}
+}
+
+// OutboxLag falla si el evento pendiente más viejo del outbox espera hace más de maxLag
+func OutboxLag(lag func(ctx context.Context) (time.Duration, error), maxLag time.Duration) CheckFunc {
+	return func(ctx context.Context) (string, error) {
+		current, err := lag(ctx)
+		if err != nil {
+			return "", err
+		}
+		current = current.Round(time.Second)
+		detail := fmt.Sprintf("lag %s", current)
+		if current > maxLag {
+			return detail, fmt.Errorf("oldest pending event is %s old (max %s)", current, maxLag)
+		}
+		return detail, nil
+	}
+}
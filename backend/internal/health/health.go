@@ -0,0 +1,241 @@
+// Package health verifica las dependencias de la instancia para las sondas de liveness y
+// readiness.
+//
+// Cada chequeo se registra en un Registry con un nombre. Los de liveness indican que el
+// proceso tiene que reiniciarse (por ejemplo un worker trabado) y no deben depender de
+// servicios externos; readiness corre todos los chequeos y decide si la instancia puede
+// recibir tráfico. Un chequeo opcional que falla deja el estado en degraded sin sacar la
+// instancia de servicio. Los resultados se guardan un rato para que las sondas frecuentes
+// no golpeen la base.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultTimeout  = 2 * time.Second
+	DefaultCacheTTL = 2 * time.Second
+)
+
+type Status string
+
+const (
+	StatusOK       Status = "ok"
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+)
+
+// CheckFunc verifica una dependencia y devuelve un detalle opcional para el reporte
+type CheckFunc func(ctx context.Context) (detail string, err error)
+
+// Result es el resultado de un chequeo
+type Result struct {
+	Status     Status `json:"status"`
+	Optional   bool   `json:"optional,omitempty"`
+	Detail     string `json:"detail,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// Report agrega los resultados de los chequeos de una sonda
+type Report struct {
+	Status    Status            `json:"status"`
+	CheckedAt time.Time         `json:"checked_at"`
+	Checks    map[string]Result `json:"checks"`
+}
+
+// Healthy indica si la sonda pasa; degraded cuenta como sana
+func (r Report) Healthy() bool {
+	return r.Status != StatusDown
+}
+
+type check struct {
+	name     string
+	run      CheckFunc
+	liveness bool
+	optional bool
+}
+
+// CheckOption configura cómo cuenta un chequeo en las sondas
+type CheckOption func(*check)
+
+// Liveness suma el chequeo a la sonda de liveness además de la de readiness
+func Liveness() CheckOption {
+	return func(c *check) {
+		c.liveness = true
+	}
+}
+
+// Optional hace que una falla del chequeo degrade el estado sin marcar la instancia caída
+func Optional() CheckOption {
+	return func(c *check) {
+		c.optional = true
+	}
+}
+
+// Registry guarda los chequeos registrados y el último reporte de cada sonda
+type Registry struct {
+	timeout  time.Duration
+	cacheTTL time.Duration
+	now      func() time.Time
+
+	mu     sync.Mutex
+	checks []check
+
+	// running serializa las corridas para que las sondas simultáneas compartan el resultado
+	running sync.Mutex
+	cached  map[bool]Report
+}
+
+// Option configura parámetros opcionales del Registry
+type Option func(*Registry)
+
+// WithTimeout define cuánto puede tardar cada chequeo antes de darse por fallido
+func WithTimeout(timeout time.Duration) Option {
+	return func(r *Registry) {
+		r.timeout = timeout
+	}
+}
+
+// WithCacheTTL define cuánto se reutiliza un reporte; 0 corre los chequeos en cada sonda
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(r *Registry) {
+		r.cacheTTL = ttl
+	}
+}
+
+// WithClock reemplaza el reloj usado para la caché y las marcas de tiempo del reporte
+func WithClock(now func() time.Time) Option {
+	return func(r *Registry) {
+		r.now = now
+	}
+}
+
+func NewRegistry(opts ...Option) *Registry {
+	r := &Registry{
+		timeout:  DefaultTimeout,
+		cacheTTL: DefaultCacheTTL,
+		now:      time.Now,
+		cached:   make(map[bool]Report),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Register agrega un chequeo; por defecto sólo cuenta para readiness y su falla la hace fallar
+func (r *Registry) Register(name string, run CheckFunc, opts ...CheckOption) {
+	c := check{name: name, run: run}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, c)
+}
+
+// Live corre los chequeos de liveness
+func (r *Registry) Live(ctx context.Context) Report {
+	return r.report(ctx, true)
+}
+
+// Ready corre todos los chequeos
+func (r *Registry) Ready(ctx context.Context) Report {
+	return r.report(ctx, false)
+}
+
+func (r *Registry) report(ctx context.Context, liveness bool) Report {
+	r.running.Lock()
+	defer r.running.Unlock()
+
+	if cached, ok := r.cached[liveness]; ok && r.now().Sub(cached.CheckedAt) < r.cacheTTL {
+		return cached
+	}
+
+	r.mu.Lock()
+	var checks []check
+	for _, c := range r.checks {
+		if c.liveness || !liveness {
+			checks = append(checks, c)
+		}
+	}
+	r.mu.Unlock()
+
+	report := Report{Status: StatusOK, CheckedAt: r.now(), Checks: make(map[string]Result, len(checks))}
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c check) {
+			defer wg.Done()
+			results[i] = r.run(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	for i, c := range checks {
+		result := results[i]
+		report.Checks[c.name] = result
+		switch {
+		case result.Status == StatusOK:
+		case c.optional:
+			if report.Status == StatusOK {
+				report.Status = StatusDegraded
+			}
+		default:
+			report.Status = StatusDown
+		}
+	}
+
+	// Un reporte de un request cancelado no dice nada de las dependencias
+	if ctx.Err() == nil {
+		r.cached[liveness] = report
+	}
+	return report
+}
+
+// run ejecuta un chequeo con timeout; si no respeta el contexto se lo abandona al vencer
+func (r *Registry) run(ctx context.Context, c check) Result {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	type outcome struct {
+		detail string
+		err    error
+	}
+	done := make(chan outcome, 1)
+	start := time.Now()
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- outcome{err: fmt.Errorf("panic: %v", p)}
+			}
+		}()
+		detail, err := c.run(ctx)
+		done <- outcome{detail: detail, err: err}
+	}()
+
+	var out outcome
+	select {
+	case out = <-done:
+	case <-ctx.Done():
+		out.err = ctx.Err()
+	}
+	if errors.Is(out.err, context.DeadlineExceeded) {
+		out.err = fmt.Errorf("timed out after %s", r.timeout)
+	}
+
+	result := Result{Status: StatusOK, Optional: c.optional, Detail: out.detail, DurationMS: time.Since(start).Milliseconds()}
+	if out.err != nil {
+		result.Status = StatusDown
+		result.Error = out.err.Error()
+	}
+	return result
+}
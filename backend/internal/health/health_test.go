@@ -0,0 +1,140 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"order-management-system/internal/migrations"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func ok(ctx context.Context) (string, error) { return "fine", nil }
+
+func failing(ctx context.Context) (string, error) { return "", errors.New("unavailable") }
+
+func TestRegistry_AggregatesByCriticality(t *testing.T) {
+	registry := NewRegistry(WithCacheTTL(0))
+	registry.Register("database", ok)
+	registry.Register("outbox", failing, Optional())
+	registry.Register("jobs", ok, Liveness())
+
+	report := registry.Ready(context.Background())
+	if report.Status != StatusDegraded || !report.Healthy() {
+		t.Errorf("Expected degraded but healthy with an optional failure, got %+v", report)
+	}
+	if got := report.Checks["outbox"]; got.Status != StatusDown || got.Error != "unavailable" || !got.Optional {
+		t.Errorf("Unexpected outbox result %+v", got)
+	}
+	if got := report.Checks["database"]; got.Status != StatusOK || got.Detail != "fine" {
+		t.Errorf("Unexpected database result %+v", got)
+	}
+
+	registry.Register("migrations", failing)
+	if report := registry.Ready(context.Background()); report.Status != StatusDown || report.Healthy() {
+		t.Errorf("Expected down with a critical failure, got %+v", report)
+	}
+
+	live := registry.Live(context.Background())
+	if live.Status != StatusOK || len(live.Checks) != 1 || live.Checks["jobs"].Status != StatusOK {
+		t.Errorf("Expected liveness to run only liveness checks, got %+v", live)
+	}
+}
+
+func TestRegistry_TimesOutSlowChecksAndRecoversPanics(t *testing.T) {
+	registry := NewRegistry(WithTimeout(20*time.Millisecond), WithCacheTTL(0))
+	release := make(chan struct{})
+	defer close(release)
+	registry.Register("stuck", func(ctx context.Context) (string, error) { <-release; return "", nil })
+	registry.Register("panics", func(ctx context.Context) (string, error) { panic("boom") })
+
+	start := time.Now()
+	report := registry.Ready(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the probe to give up on the stuck check, took %s", elapsed)
+	}
+	if got := report.Checks["stuck"]; got.Status != StatusDown || !strings.Contains(got.Error, "timed out") {
+		t.Errorf("Expected timeout, got %+v", got)
+	}
+	if got := report.Checks["panics"]; got.Status != StatusDown || !strings.Contains(got.Error, "boom") {
+		t.Errorf("Expected panic as error, got %+v", got)
+	}
+}
+
+func TestRegistry_CachesReports(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	registry := NewRegistry(WithCacheTTL(5*time.Second), WithClock(func() time.Time { return now }))
+	var runs int32
+	registry.Register("database", func(ctx context.Context) (string, error) {
+		atomic.AddInt32(&runs, 1)
+		return "", nil
+	}, Liveness())
+
+	registry.Ready(context.Background())
+	registry.Ready(context.Background())
+	if atomic.LoadInt32(&runs) != 1 {
+		t.Errorf("Expected cached readiness, got %d runs", runs)
+	}
+	registry.Live(context.Background())
+	if atomic.LoadInt32(&runs) != 2 {
+		t.Errorf("Expected liveness to keep its own cache, got %d runs", runs)
+	}
+
+	now = now.Add(5 * time.Second)
+	registry.Ready(context.Background())
+	if atomic.LoadInt32(&runs) != 3 {
+		t.Errorf("Expected the report to expire after the TTL, got %d runs", runs)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	now = now.Add(5 * time.Second)
+	if report := registry.Ready(ctx); report.Healthy() {
+		t.Errorf("Expected a cancelled probe to fail, got %+v", report)
+	}
+	if report := registry.Ready(context.Background()); !report.Healthy() {
+		t.Errorf("Expected a cancelled probe not to be cached, got %+v", report)
+	}
+}
+
+type fakeMigrator []migrations.Status
+
+func (f fakeMigrator) Status(ctx context.Context) ([]migrations.Status, error) { return f, nil }
+
+func TestChecks(t *testing.T) {
+	ctx := context.Background()
+
+	detail, err := Migrations(fakeMigrator{{Version: 1, Applied: true}, {Version: 2, Applied: true}})(ctx)
+	if err != nil || detail != "version 2" {
+		t.Errorf("Expected up to date migrations, got %q (%v)", detail, err)
+	}
+	_, err = Migrations(fakeMigrator{{Version: 1, Applied: true}, {Version: 2}, {Version: 3}})(ctx)
+	if err == nil || !strings.Contains(err.Error(), "2 pending migrations: 2, 3") {
+		t.Errorf("Expected pending migrations, got %v", err)
+	}
+	_, err = Migrations(fakeMigrator{{Version: 1, Applied: true, Modified: true}})(ctx)
+	if err == nil || !strings.Contains(err.Error(), "1 modified") {
+		t.Errorf("Expected modified migration, got %v", err)
+	}
+
+	if _, err := Heartbeat(func() time.Time { return time.Time{} }, time.Minute)(ctx); err == nil {
+		t.Error("Expected a worker that never started to fail")
+	}
+	if _, err := Heartbeat(func() time.Time { return time.Now().Add(-2 * time.Minute) }, time.Minute)(ctx); err == nil {
+		t.Error("Expected a stale heartbeat to fail")
+	}
+	if _, err := Heartbeat(time.Now, time.Minute)(ctx); err != nil {
+		t.Errorf("Expected a fresh heartbeat to pass, got %v", err)
+	}
+
+	lag := func(d time.Duration) func(context.Context) (time.Duration, error) {
+		return func(context.Context) (time.Duration, error) { return d, nil }
+	}
+	if detail, err := OutboxLag(lag(3*time.Second), time.Minute)(ctx); err != nil || detail != "lag 3s" {
+		t.Errorf("Expected small lag to pass, got %q (%v)", detail, err)
+	}
+	if _, err := OutboxLag(lag(2*time.Minute), time.Minute)(ctx); err == nil {
+		t.Error("Expected large lag to fail")
+	}
+}
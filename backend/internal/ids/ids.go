@@ -0,0 +1,104 @@
+// Package ids genera identificadores públicos que pueden exponerse fuera del sistema
+// sin revelar el volumen ni el orden de los registros, a diferencia de las claves
+// secuenciales de la base.
+package ids
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"order-management-system/internal/clock"
+	"strings"
+	"sync"
+)
+
+// Generator crea identificadores únicos
+type Generator interface {
+	NewID() string
+}
+
+// crockford es el alfabeto base32 de ULID: sin I, L, O ni U para evitar confusiones
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULIDLength es el largo de un ULID en texto
+const ULIDLength = 26
+
+// ULIDGenerator genera ULIDs: 48 bits con los milisegundos del reloj y 80 bits aleatorios,
+// codificados en 26 caracteres que se ordenan por fecha de creación. Dentro de un mismo
+// milisegundo la parte aleatoria se incrementa para mantener el orden.
+type ULIDGenerator struct {
+	clock   clock.Clock
+	entropy io.Reader
+
+	mu       sync.Mutex
+	lastMS   uint64
+	lastHigh uint16
+	lastLow  uint64
+}
+
+// ULIDOption configura parámetros opcionales del ULIDGenerator
+type ULIDOption func(*ULIDGenerator)
+
+// WithEntropy reemplaza la fuente aleatoria, por ejemplo para tests reproducibles
+func WithEntropy(entropy io.Reader) ULIDOption {
+	return func(g *ULIDGenerator) {
+		g.entropy = entropy
+	}
+}
+
+func NewULIDGenerator(clk clock.Clock, opts ...ULIDOption) *ULIDGenerator {
+	g := &ULIDGenerator{clock: clk, entropy: rand.Reader}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+func (g *ULIDGenerator) NewID() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := uint64(g.clock.Now().UnixMilli())
+	if ms == g.lastMS {
+		g.lastLow++
+		if g.lastLow == 0 {
+			g.lastHigh++
+		}
+	} else {
+		var random [10]byte
+		if _, err := io.ReadFull(g.entropy, random[:]); err != nil {
+			panic(fmt.Sprintf("ids: reading entropy: %v", err))
+		}
+		g.lastMS = ms
+		g.lastHigh = binary.BigEndian.Uint16(random[:2])
+		g.lastLow = binary.BigEndian.Uint64(random[2:])
+	}
+	return encode(ms, g.lastHigh, g.lastLow)
+}
+
+// encode escribe los 128 bits (timestamp, aleatorio alto y bajo) en base32 de a 5 bits
+func encode(ms uint64, high uint16, low uint64) string {
+	hi := ms<<16 | uint64(high)
+	lo := low
+	var out [ULIDLength]byte
+	for i := ULIDLength - 1; i >= 0; i-- {
+		out[i] = crockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// IsULID indica si s tiene el formato de un ULID
+func IsULID(s string) bool {
+	if len(s) != ULIDLength || s[0] > '7' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !strings.ContainsRune(crockford, rune(s[i])) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package ids
+
+import (
+	"bytes"
+	"order-management-system/internal/clock"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestULIDGenerator_EncodesTimeAndKeepsOrder(t *testing.T) {
+	clk := clock.NewFake(time.UnixMilli(1469918176385))
+	generator := NewULIDGenerator(clk, WithEntropy(bytes.NewReader(bytes.Repeat([]byte{0xff, 0x00}, 20))))
+
+	first := generator.NewID()
+	if len(first) != ULIDLength || first[:10] != "01ARYZ6S41" || !IsULID(first) {
+		t.Fatalf("Unexpected ULID %s", first)
+	}
+
+	// En el mismo milisegundo se incrementa la parte aleatoria
+	second := generator.NewID()
+	clk.Advance(time.Millisecond)
+	third := generator.NewID()
+
+	generated := []string{third, first, second}
+	sort.Strings(generated)
+	if generated[0] != first || generated[1] != second || generated[2] != third || first == second {
+		t.Errorf("Expected ULIDs to sort by creation, got %v", []string{first, second, third})
+	}
+}
+
+func TestIsULID(t *testing.T) {
+	for _, s := range []string{"", "123", "01ARYZ6S41TSV4RRFFQ69G5FAVX", "01ARYZ6S41TSV4RRFFQ69G5FAU", "81ARYZ6S41TSV4RRFFQ69G5FAV", "01aryz6s41tsv4rrffq69g5fav"} {
+		if IsULID(s) {
+			t.Errorf("Expected %q to be rejected", s)
+		}
+	}
+	if !IsULID("01ARYZ6S41TSV4RRFFQ69G5FAV") {
+		t.Error("Expected a valid ULID")
+	}
+}
+
+func TestOrderNumberGenerator_FormatAndChecksum(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	generator, err := NewOrderNumberGenerator(clk, "ord", WithNumberEntropy(bytes.NewReader([]byte{0x00, 0x44, 0x32, 0x14, 0xc7})))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := NewOrderNumberGenerator(clk, "ORD-1"); err == nil {
+		t.Error("Expected invalid prefix to be rejected")
+	}
+
+	number := generator.NewID()
+	if number != "ORD-2024-01234567-8" {
+		t.Fatalf("Unexpected order number %s", number)
+	}
+	if parsed, ok := ParseOrderNumber(number); !ok || parsed != number {
+		t.Fatalf("Expected %s to parse, got %q", number, parsed)
+	}
+
+	// Se tolera lo que suele cambiar al dictarlo o tipearlo
+	typed := strings.ToLower(number[:9]) + strings.NewReplacer("0", "o", "1", "l").Replace(strings.ToLower(number[9:]))
+	if parsed, ok := ParseOrderNumber(" " + typed + " "); !ok || parsed != number {
+		t.Errorf("Expected %q to normalize to %s, got %q", typed, number, parsed)
+	}
+
+	// Cualquier carácter cambiado o par contiguo intercambiado invalida el número
+	for i := 5; i < len(number); i++ {
+		if number[i] == '-' {
+			continue
+		}
+		for _, c := range crockford {
+			if byte(c) == number[i] || (i < 9 && (c < '0' || c > '9')) {
+				continue
+			}
+			typo := number[:i] + string(c) + number[i+1:]
+			if _, ok := ParseOrderNumber(typo); ok {
+				t.Errorf("Expected typo %s to be rejected", typo)
+			}
+		}
+		if j := i + 1; j < len(number) && number[j] != '-' && number[i] != number[j] {
+			swapped := number[:i] + string(number[j]) + string(number[i]) + number[j+1:]
+			if _, ok := ParseOrderNumber(swapped); ok {
+				t.Errorf("Expected transposition %s to be rejected", swapped)
+			}
+		}
+	}
+
+	for _, s := range []string{"", "42", "01ARYZ6S41TSV4RRFFQ69G5FAV", "ORD-24-ABCDEFGH-0", "ORD-2024-ABCDEFG-0", "ORD-2024-ABCDEFGU-0"} {
+		if _, ok := ParseOrderNumber(s); ok {
+			t.Errorf("Expected %q to be rejected", s)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package ids
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"order-management-system/internal/clock"
+	"strings"
+)
+
+// orderNumberBody es la cantidad de caracteres aleatorios del número de pedido (40 bits)
+const orderNumberBody = 8
+
+// OrderNumberGenerator genera números de pedido legibles como ORD-2024-7K3QX9MZ-4: prefijo,
+// año de alta, 8 caracteres aleatorios en base32 Crockford y un dígito verificador que
+// detecta un carácter mal tipeado o dos caracteres contiguos intercambiados.
+type OrderNumberGenerator struct {
+	clock   clock.Clock
+	prefix  string
+	entropy io.Reader
+}
+
+// OrderNumberOption configura parámetros opcionales del OrderNumberGenerator
+type OrderNumberOption func(*OrderNumberGenerator)
+
+// WithNumberEntropy reemplaza la fuente aleatoria, por ejemplo para tests reproducibles
+func WithNumberEntropy(entropy io.Reader) OrderNumberOption {
+	return func(g *OrderNumberGenerator) {
+		g.entropy = entropy
+	}
+}
+
+// NewOrderNumberGenerator crea el generador; el prefijo debe tener entre 1 y 8 letras
+func NewOrderNumberGenerator(clk clock.Clock, prefix string, opts ...OrderNumberOption) (*OrderNumberGenerator, error) {
+	prefix = strings.ToUpper(strings.TrimSpace(prefix))
+	if !validPrefix(prefix) {
+		return nil, fmt.Errorf("invalid order number prefix %q: use 1 to 8 letters", prefix)
+	}
+	g := &OrderNumberGenerator{clock: clk, prefix: prefix, entropy: rand.Reader}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g, nil
+}
+
+func (g *OrderNumberGenerator) NewID() string {
+	var random [5]byte
+	if _, err := io.ReadFull(g.entropy, random[:]); err != nil {
+		panic(fmt.Sprintf("ids: reading entropy: %v", err))
+	}
+	bits := uint64(random[0])<<32 | uint64(random[1])<<24 | uint64(random[2])<<16 | uint64(random[3])<<8 | uint64(random[4])
+	var body [orderNumberBody]byte
+	for i := orderNumberBody - 1; i >= 0; i-- {
+		body[i] = crockford[bits&31]
+		bits >>= 5
+	}
+
+	year := fmt.Sprintf("%04d", g.clock.Now().Year())
+	return fmt.Sprintf("%s-%s-%s-%c", g.prefix, year, body[:], checkChar(year+string(body[:])))
+}
+
+// ParseOrderNumber normaliza un número de pedido tipeado por una persona (minúsculas,
+// espacios, O por 0, I y L por 1) y verifica su formato y dígito verificador. Devuelve
+// la forma canónica con la que se guardó.
+func ParseOrderNumber(s string) (string, bool) {
+	parts := strings.Split(strings.ToUpper(strings.TrimSpace(s)), "-")
+	if len(parts) != 4 || !validPrefix(parts[0]) || len(parts[1]) != 4 || len(parts[2]) != orderNumberBody || len(parts[3]) != 1 {
+		return "", false
+	}
+	for _, c := range parts[1] {
+		if c < '0' || c > '9' {
+			return "", false
+		}
+	}
+	body := []byte(parts[2])
+	for i, c := range body {
+		body[i] = normalize(c)
+		if !strings.ContainsRune(crockford, rune(body[i])) {
+			return "", false
+		}
+	}
+	check := normalize(parts[3][0])
+	if checkChar(parts[1]+string(body)) != check {
+		return "", false
+	}
+	return fmt.Sprintf("%s-%s-%s-%c", parts[0], parts[1], body, check), true
+}
+
+// normalize aplica las equivalencias de Crockford para caracteres ambiguos
+func normalize(c byte) byte {
+	switch c {
+	case 'O':
+		return '0'
+	case 'I', 'L':
+		return '1'
+	}
+	return c
+}
+
+// checkChar calcula el verificador con el algoritmo de Luhn mod 32 sobre el alfabeto Crockford
+func checkChar(s string) byte {
+	const n = len(crockford)
+	sum := 0
+	factor := 2
+	for i := len(s) - 1; i >= 0; i-- {
+		addend := factor * strings.IndexByte(crockford, s[i])
+		sum += addend/n + addend%n
+		if factor == 2 {
+			factor = 1
+		} else {
+			factor = 2
+		}
+	}
+	return crockford[(n-sum%n)%n]
+}
+
+func validPrefix(prefix string) bool {
+	if len(prefix) == 0 || len(prefix) > 8 {
+		return false
+	}
+	for _, c := range prefix {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,49 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule_Next(t *testing.T) {
+	// 2024-01-01 es lunes
+	from := time.Date(2024, 1, 1, 10, 7, 30, 0, time.UTC)
+	cases := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2024, 1, 1, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 1, 1, 10, 15, 0, 0, time.UTC)},
+		{"5 0 * * *", time.Date(2024, 1, 2, 0, 5, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2024, 1, 1, 13, 0, 0, 0, time.UTC)},
+		{"30 8 * * 6,7", time.Date(2024, 1, 6, 8, 30, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Con día del mes y día de la semana restringidos alcanza con que coincida uno
+		{"0 12 15 * 3", time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 30s", time.Date(2024, 1, 1, 10, 8, 0, 0, time.UTC)},
+		{"@every 1h", time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		schedule, err := ParseSchedule(c.spec)
+		if err != nil {
+			t.Fatalf("%q: expected no error, got %v", c.spec, err)
+		}
+		if next := schedule.Next(from); !next.Equal(c.expected) {
+			t.Errorf("%q: expected %s, got %s", c.spec, c.expected, next)
+		}
+	}
+
+	impossible, _ := ParseSchedule("0 0 30 2 *")
+	if next := impossible.Next(from); !next.IsZero() {
+		t.Errorf("Expected no run for February 30th, got %s", next)
+	}
+}
+
+func TestParseSchedule_RejectsInvalidSpecs(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "0 0 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *", "@every 10ms", "@sometimes"} {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("Expected %q to be rejected", spec)
+		}
+	}
+}
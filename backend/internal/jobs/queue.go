@@ -0,0 +1,531 @@
+// Package jobs ejecuta trabajos en segundo plano persistidos en la tabla jobs.
+//
+// Un pool de workers toma los trabajos vencidos, los reintenta con backoff exponencial
+// y, al agotar los intentos, los deja en estado DEAD (cola de trabajos muertos) para
+// revisarlos y reintentarlos a mano. Los trabajos programados con expresiones cron se
+// encolan con una clave única por ejecución, así que aunque corran varias instancias
+// cada ejecución ocurre una sola vez. Como un trabajo puede repetirse (por ejemplo si
+// la instancia se cae a mitad de camino) los handlers deben ser idempotentes.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"order-management-system/internal/backoff"
+	"order-management-system/internal/clock"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/logging"
+	"order-management-system/internal/repositories"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrUnknownJobType  = errors.New("unknown job type")
+	ErrJobNotFound     = errors.New("job not found")
+	ErrJobNotRetryable = errors.New("only dead jobs can be retried")
+	ErrInvalidPurge    = errors.New("only succeeded or dead jobs can be purged")
+)
+
+const (
+	DefaultConcurrency  = 4
+	DefaultPollInterval = time.Second
+	DefaultMaxAttempts  = 5
+	DefaultBaseBackoff  = 10 * time.Second
+	DefaultMaxBackoff   = 30 * time.Minute
+	DefaultLockTimeout  = 15 * time.Minute
+	DefaultRetention    = 24 * time.Hour
+
+	defaultListLimit    = 100
+	maxListLimit        = 500
+	maintenanceInterval = time.Minute
+)
+
+// Handler ejecuta un trabajo; un error provoca el reintento salvo que sea Permanent
+type Handler func(ctx context.Context, job domain.Job) error
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marca un error que no se resuelve reintentando: el trabajo pasa directo a DEAD
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+type scheduledJob struct {
+	name     string
+	spec     string
+	jobType  string
+	payload  interface{}
+	schedule Schedule
+	next     time.Time
+}
+
+// ScheduleInfo describe un trabajo programado y su próxima ejecución
+type ScheduleInfo struct {
+	Name    string    `json:"name"`
+	Spec    string    `json:"spec"`
+	Type    string    `json:"type"`
+	NextRun time.Time `json:"next_run"`
+}
+
+// Queue encola trabajos y los ejecuta con un pool de workers
+type Queue struct {
+	repo         repositories.JobRepository
+	concurrency  int
+	pollInterval time.Duration
+	maxAttempts  int
+	baseBackoff  time.Duration
+	maxBackoff   time.Duration
+	lockTimeout  time.Duration
+	retention    time.Duration
+	now          func() time.Time
+
+	mu        sync.RWMutex
+	handlers  map[string]Handler
+	schedules []*scheduledJob
+
+	scheduling      sync.Mutex
+	lastMaintenance time.Time
+
+	heartbeat atomic.Int64
+
+	cancel context.CancelFunc
+	wake   chan struct{}
+	done   sync.WaitGroup
+}
+
+// Option configura parámetros opcionales de la Queue
+type Option func(*Queue)
+
+// WithConcurrency define cuántos trabajos se ejecutan en paralelo en esta instancia
+func WithConcurrency(workers int) Option {
+	return func(q *Queue) {
+		q.concurrency = workers
+	}
+}
+
+// WithPollInterval define cada cuánto se buscan trabajos vencidos y programados
+func WithPollInterval(interval time.Duration) Option {
+	return func(q *Queue) {
+		q.pollInterval = interval
+	}
+}
+
+// WithRetry define los intentos por defecto de cada trabajo y el backoff exponencial entre ellos
+func WithRetry(maxAttempts int, baseBackoff, maxBackoff time.Duration) Option {
+	return func(q *Queue) {
+		q.maxAttempts = maxAttempts
+		q.baseBackoff = baseBackoff
+		q.maxBackoff = maxBackoff
+	}
+}
+
+// WithLockTimeout define después de cuánto un trabajo RUNNING se considera abandonado y se reencola
+func WithLockTimeout(timeout time.Duration) Option {
+	return func(q *Queue) {
+		q.lockTimeout = timeout
+	}
+}
+
+// WithRetention define cuánto se guardan los trabajos terminados con éxito
+func WithRetention(retention time.Duration) Option {
+	return func(q *Queue) {
+		q.retention = retention
+	}
+}
+
+// WithClock reemplaza el reloj usado para vencimientos, reintentos y programación
+func WithClock(clk clock.Clock) Option {
+	return func(q *Queue) {
+		q.now = clk.Now
+	}
+}
+
+func NewQueue(repo repositories.JobRepository, opts ...Option) *Queue {
+	q := &Queue{
+		repo:         repo,
+		concurrency:  DefaultConcurrency,
+		pollInterval: DefaultPollInterval,
+		maxAttempts:  DefaultMaxAttempts,
+		baseBackoff:  DefaultBaseBackoff,
+		maxBackoff:   DefaultMaxBackoff,
+		lockTimeout:  DefaultLockTimeout,
+		retention:    DefaultRetention,
+		now:          clock.System().Now,
+		handlers:     make(map[string]Handler),
+		wake:         make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q
+}
+
+// Register asocia un tipo de trabajo con su handler
+func (q *Queue) Register(jobType string, handler Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[jobType] = handler
+}
+
+type enqueueOptions struct {
+	runAt       time.Time
+	maxAttempts int
+	uniqueKey   string
+}
+
+// EnqueueOption configura un trabajo al encolarlo
+type EnqueueOption func(*enqueueOptions)
+
+// RunAt demora el trabajo hasta el instante indicado
+func RunAt(at time.Time) EnqueueOption {
+	return func(o *enqueueOptions) {
+		o.runAt = at
+	}
+}
+
+// MaxAttempts reemplaza la cantidad de intentos por defecto del trabajo
+func MaxAttempts(attempts int) EnqueueOption {
+	return func(o *enqueueOptions) {
+		o.maxAttempts = attempts
+	}
+}
+
+// UniqueKey evita encolar el trabajo si ya existe otro con la misma clave
+func UniqueKey(key string) EnqueueOption {
+	return func(o *enqueueOptions) {
+		o.uniqueKey = key
+	}
+}
+
+// Enqueue persiste un trabajo del tipo indicado. Si ya existía uno con la misma UniqueKey
+// no se crea otro y el trabajo devuelto queda sin ID.
+func (q *Queue) Enqueue(ctx context.Context, jobType string, payload interface{}, opts ...EnqueueOption) (*domain.Job, error) {
+	if q.handler(jobType) == nil {
+		return nil, fmt.Errorf("%w %q", ErrUnknownJobType, jobType)
+	}
+	options := enqueueOptions{runAt: q.now(), maxAttempts: q.maxAttempts}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	job, err := domain.NewJob(jobType, payload, options.runAt)
+	if err != nil {
+		return nil, err
+	}
+	job.MaxAttempts = options.maxAttempts
+	if options.uniqueKey != "" {
+		job.UniqueKey = &options.uniqueKey
+	}
+	if _, err := q.repo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+	if !job.RunAt.After(q.now()) {
+		q.notify()
+	}
+	return job, nil
+}
+
+// Schedule programa un trabajo con una expresión cron (ver ParseSchedule). El nombre
+// identifica la programación y forma parte de la clave única de cada ejecución.
+func (q *Queue) Schedule(name, spec, jobType string, payload interface{}) error {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return err
+	}
+	if q.handler(jobType) == nil {
+		return fmt.Errorf("%w %q", ErrUnknownJobType, jobType)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.schedules = append(q.schedules, &scheduledJob{
+		name:     name,
+		spec:     spec,
+		jobType:  jobType,
+		payload:  payload,
+		schedule: schedule,
+		next:     schedule.Next(q.now()),
+	})
+	return nil
+}
+
+// Schedules devuelve los trabajos programados ordenados por próxima ejecución
+func (q *Queue) Schedules() []ScheduleInfo {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	infos := make([]ScheduleInfo, 0, len(q.schedules))
+	for _, s := range q.schedules {
+		infos = append(infos, ScheduleInfo{Name: s.name, Spec: s.spec, Type: s.jobType, NextRun: s.next})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].NextRun.Before(infos[j].NextRun) })
+	return infos
+}
+
+// Start lanza los workers y el programador. Stop cancela el contexto de los trabajos en curso
+// y espera a que terminen; los interrumpidos vuelven a la cola sin consumir un intento.
+func (q *Queue) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	q.cancel = cancel
+
+	for i := 0; i < q.concurrency; i++ {
+		q.done.Add(1)
+		go func() {
+			defer q.done.Done()
+			q.loop(ctx, func() {
+				if _, err := q.RunDue(ctx); err != nil {
+					logging.For(ctx, "jobs").Error("running due jobs", "error", err)
+				}
+			})
+		}()
+	}
+
+	q.done.Add(1)
+	go func() {
+		defer q.done.Done()
+		q.loop(ctx, func() {
+			if err := q.RunScheduled(ctx); err != nil {
+				logging.For(ctx, "jobs").Error("scheduling jobs", "error", err)
+			}
+		})
+	}()
+}
+
+func (q *Queue) Stop() {
+	if q.cancel == nil {
+		return
+	}
+	q.cancel()
+	q.done.Wait()
+	q.cancel = nil
+}
+
+func (q *Queue) loop(ctx context.Context, tick func()) {
+	ticker := time.NewTicker(q.pollInterval)
+	defer ticker.Stop()
+	for {
+		tick()
+		q.heartbeat.Store(q.now().UnixNano())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-q.wake:
+		}
+	}
+}
+
+// LastHeartbeat devuelve cuándo terminó la última vuelta de un worker o del programador,
+// o cero si la cola no arrancó
+func (q *Queue) LastHeartbeat() time.Time {
+	if nanos := q.heartbeat.Load(); nanos != 0 {
+		return time.Unix(0, nanos)
+	}
+	return time.Time{}
+}
+
+// notify despierta a un worker sin esperar el intervalo
+func (q *Queue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// RunDue ejecuta uno por uno los trabajos vencidos hasta vaciar la cola y devuelve cuántos procesó
+func (q *Queue) RunDue(ctx context.Context) (int, error) {
+	processed := 0
+	for ctx.Err() == nil {
+		claimed, err := q.repo.Claim(ctx, q.now(), 1)
+		if err != nil {
+			return processed, fmt.Errorf("claiming jobs: %w", err)
+		}
+		if len(claimed) == 0 {
+			break
+		}
+		job := &claimed[0]
+		jobCtx := logging.WithLogger(ctx, logging.FromContext(ctx).With("job_id", job.ID, "job_type", job.Type))
+		if err := q.process(jobCtx, job); err != nil {
+			return processed, fmt.Errorf("updating job %d: %w", job.ID, err)
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+// RunScheduled encola las ejecuciones programadas que vencieron. Si se perdieron varias
+// (por ejemplo con el servicio detenido) se encola sólo la más reciente.
+func (q *Queue) RunScheduled(ctx context.Context) error {
+	q.scheduling.Lock()
+	defer q.scheduling.Unlock()
+
+	now := q.now()
+	q.mu.Lock()
+	var due []scheduledJob
+	for _, s := range q.schedules {
+		if s.next.IsZero() || s.next.After(now) {
+			continue
+		}
+		due = append(due, *s)
+		s.next = s.schedule.Next(now)
+	}
+	q.mu.Unlock()
+
+	for _, s := range due {
+		key := fmt.Sprintf("schedule:%s:%s", s.name, s.next.UTC().Format(time.RFC3339))
+		if _, err := q.Enqueue(ctx, s.jobType, s.payload, RunAt(s.next), UniqueKey(key)); err != nil {
+			return fmt.Errorf("enqueueing scheduled job %s: %w", s.name, err)
+		}
+	}
+
+	if now.Sub(q.lastMaintenance) >= maintenanceInterval {
+		q.lastMaintenance = now
+		return q.maintain(ctx, now)
+	}
+	return nil
+}
+
+// maintain reencola los trabajos abandonados por una instancia caída y borra los viejos
+func (q *Queue) maintain(ctx context.Context, now time.Time) error {
+	requeued, err := q.repo.RequeueStale(ctx, now.Add(-q.lockTimeout))
+	if err != nil {
+		return fmt.Errorf("requeueing stale jobs: %w", err)
+	}
+	if requeued > 0 {
+		logging.For(ctx, "jobs").Warn("requeued stale jobs", "count", requeued)
+		q.notify()
+	}
+	if _, err := q.repo.DeleteFinished(ctx, domain.JobSucceeded, now.Add(-q.retention)); err != nil {
+		return fmt.Errorf("deleting old jobs: %w", err)
+	}
+	return nil
+}
+
+// process ejecuta el trabajo y registra el resultado; sólo devuelve errores al guardarlo
+func (q *Queue) process(ctx context.Context, job *domain.Job) error {
+	var err error
+	if handler := q.handler(job.Type); handler != nil {
+		err = safeRun(ctx, handler, *job)
+	} else {
+		err = Permanent(fmt.Errorf("%w %q", ErrUnknownJobType, job.Type))
+	}
+
+	now := q.now()
+	job.LockedAt = nil
+	interrupted := err != nil && ctx.Err() != nil
+	// El resultado se guarda aunque Stop haya cancelado el contexto del trabajo
+	ctx = withoutCancel(ctx)
+	if interrupted {
+		// Interrumpido por Stop: vuelve a la cola sin contar el intento
+		job.Status = domain.JobPending
+		job.RunAt = now
+		return q.repo.Update(ctx, job)
+	}
+
+	job.Attempts++
+	if err == nil {
+		job.Status = domain.JobSucceeded
+		job.FinishedAt = &now
+		job.LastError = ""
+		return q.repo.Update(ctx, job)
+	}
+
+	job.LastError = err.Error()
+	var permanent *permanentError
+	if errors.As(err, &permanent) || job.Attempts >= job.MaxAttempts {
+		job.Status = domain.JobDead
+		job.FinishedAt = &now
+		logging.For(ctx, "jobs").Error("job is dead", "attempts", job.Attempts, "error", err)
+	} else {
+		job.Status = domain.JobPending
+		job.RunAt = now.Add(backoff.Exponential(q.baseBackoff, q.maxBackoff, job.Attempts))
+	}
+	return q.repo.Update(ctx, job)
+}
+
+func (q *Queue) handler(jobType string) Handler {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.handlers[jobType]
+}
+
+// Get devuelve un trabajo por ID
+func (q *Queue) Get(ctx context.Context, id uint) (*domain.Job, error) {
+	job, err := q.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrJobNotFound
+	}
+	return job, nil
+}
+
+// List devuelve los trabajos más recientes que cumplen el filtro
+func (q *Queue) List(ctx context.Context, filter domain.JobFilter) ([]domain.Job, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultListLimit
+	}
+	if filter.Limit > maxListLimit {
+		filter.Limit = maxListLimit
+	}
+	return q.repo.List(ctx, filter)
+}
+
+// Retry devuelve a la cola un trabajo muerto con los intentos reiniciados
+func (q *Queue) Retry(ctx context.Context, id uint) (*domain.Job, error) {
+	job, err := q.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != domain.JobDead {
+		return nil, ErrJobNotRetryable
+	}
+
+	job.Status = domain.JobPending
+	job.Attempts = 0
+	job.RunAt = q.now()
+	job.FinishedAt = nil
+	if err := q.repo.Update(ctx, job); err != nil {
+		return nil, err
+	}
+	q.notify()
+	return job, nil
+}
+
+// Purge borra los trabajos terminados con el estado indicado hace más de olderThan
+func (q *Queue) Purge(ctx context.Context, status domain.JobStatus, olderThan time.Duration) (int64, error) {
+	if status != domain.JobSucceeded && status != domain.JobDead {
+		return 0, ErrInvalidPurge
+	}
+	return q.repo.DeleteFinished(ctx, status, q.now().Add(-olderThan))
+}
+
+// safeRun convierte un panic del handler en error para no detener al worker
+func safeRun(ctx context.Context, handler Handler, job domain.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// noCancel conserva los valores de un contexto, como el logger, sin su cancelación
+type noCancel struct {
+	parent context.Context
+}
+
+// withoutCancel devuelve un contexto con los valores de ctx que nunca se cancela
+func withoutCancel(ctx context.Context) context.Context { return noCancel{parent: ctx} }
+
+func (noCancel) Deadline() (time.Time, bool)         { return time.Time{}, false }
+func (noCancel) Done() <-chan struct{}               { return nil }
+func (noCancel) Err() error                          { return nil }
+func (c noCancel) Value(key interface{}) interface{} { return c.parent.Value(key) }
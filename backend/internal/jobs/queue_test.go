@@ -0,0 +1,350 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"order-management-system/internal/clock"
+	"order-management-system/internal/domain"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type memoryJobs struct {
+	mu   sync.Mutex
+	jobs []domain.Job
+}
+
+func (m *memoryJobs) Create(ctx context.Context, job *domain.Job) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if job.UniqueKey != nil {
+		for _, existing := range m.jobs {
+			if existing.UniqueKey != nil && *existing.UniqueKey == *job.UniqueKey {
+				return false, nil
+			}
+		}
+	}
+	job.ID = uint(len(m.jobs) + 1)
+	m.jobs = append(m.jobs, *job)
+	return true, nil
+}
+
+func (m *memoryJobs) GetByID(ctx context.Context, id uint) (*domain.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if id == 0 || int(id) > len(m.jobs) || m.jobs[id-1].ID == 0 {
+		return nil, errors.New("record not found")
+	}
+	job := m.jobs[id-1]
+	return &job, nil
+}
+
+func (m *memoryJobs) List(ctx context.Context, filter domain.JobFilter) ([]domain.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var jobs []domain.Job
+	for i := len(m.jobs) - 1; i >= 0 && len(jobs) < filter.Limit; i-- {
+		job := m.jobs[i]
+		if job.ID != 0 && (filter.Status == "" || job.Status == filter.Status) && (filter.Type == "" || job.Type == filter.Type) {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func (m *memoryJobs) Claim(ctx context.Context, now time.Time, limit int) ([]domain.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var claimed []domain.Job
+	for i := range m.jobs {
+		job := &m.jobs[i]
+		if job.ID != 0 && job.Status == domain.JobPending && !job.RunAt.After(now) && len(claimed) < limit {
+			job.Status = domain.JobRunning
+			job.LockedAt = &now
+			claimed = append(claimed, *job)
+		}
+	}
+	return claimed, nil
+}
+
+func (m *memoryJobs) RequeueStale(ctx context.Context, before time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var requeued int64
+	for i := range m.jobs {
+		job := &m.jobs[i]
+		if job.Status == domain.JobRunning && job.LockedAt.Before(before) {
+			job.Status = domain.JobPending
+			job.LockedAt = nil
+			requeued++
+		}
+	}
+	return requeued, nil
+}
+
+func (m *memoryJobs) Update(ctx context.Context, job *domain.Job) error {
+	// Como GORM, no escribe con el contexto cancelado
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs[job.ID-1] = *job
+	return nil
+}
+
+func (m *memoryJobs) DeleteFinished(ctx context.Context, status domain.JobStatus, before time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var deleted int64
+	for i := range m.jobs {
+		job := &m.jobs[i]
+		if job.ID != 0 && job.Status == status && job.FinishedAt != nil && job.FinishedAt.Before(before) {
+			// Se deja el hueco para que los IDs sigan coincidiendo con la posición
+			m.jobs[i] = domain.Job{}
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (m *memoryJobs) get(id uint) domain.Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.jobs[id-1]
+}
+
+func newTestQueue(repo *memoryJobs, clk clock.Clock, opts ...Option) *Queue {
+	opts = append([]Option{
+		WithRetry(3, time.Minute, time.Hour),
+		WithClock(clk),
+	}, opts...)
+	return NewQueue(repo, opts...)
+}
+
+func TestQueue_RetriesWithBackoffAndDeadLetters(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryJobs{}
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	queue := newTestQueue(repo, clk)
+
+	var received []string
+	queue.Register("email", func(ctx context.Context, job domain.Job) error {
+		var payload struct{ To string }
+		job.Decode(&payload)
+		received = append(received, payload.To)
+		return errors.New("smtp down")
+	})
+
+	job, err := queue.Enqueue(ctx, "email", map[string]string{"To": "ana@example.com"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := queue.Enqueue(ctx, "unknown", nil); !errors.Is(err, ErrUnknownJobType) {
+		t.Errorf("Expected ErrUnknownJobType, got %v", err)
+	}
+
+	queue.RunDue(context.Background())
+	if got := repo.get(job.ID); got.Status != domain.JobPending || got.Attempts != 1 || !got.RunAt.Equal(clk.Now().Add(time.Minute)) || got.LastError != "smtp down" {
+		t.Fatalf("Expected retry in 1m, got %+v", got)
+	}
+	if processed, _ := queue.RunDue(context.Background()); processed != 0 {
+		t.Errorf("Expected no job before backoff, got %d", processed)
+	}
+
+	clk.Advance(time.Minute)
+	queue.RunDue(context.Background())
+	if got := repo.get(job.ID); !got.RunAt.Equal(clk.Now().Add(2 * time.Minute)) {
+		t.Errorf("Expected backoff to double, got %s", got.RunAt)
+	}
+	clk.Advance(2 * time.Minute)
+	queue.RunDue(context.Background())
+
+	dead, _ := queue.List(ctx, domain.JobFilter{Status: domain.JobDead})
+	if len(dead) != 1 || dead[0].Attempts != 3 || dead[0].FinishedAt == nil || len(received) != 3 || received[0] != "ana@example.com" {
+		t.Fatalf("Expected job dead after 3 attempts, got %+v (%v)", dead, received)
+	}
+
+	// Reintento manual desde la cola de muertos
+	queue.Register("email", func(ctx context.Context, job domain.Job) error { return nil })
+	if _, err := queue.Retry(ctx, job.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	queue.RunDue(context.Background())
+	if got := repo.get(job.ID); got.Status != domain.JobSucceeded || got.Attempts != 1 {
+		t.Errorf("Expected retried job to succeed, got %+v", got)
+	}
+	if _, err := queue.Retry(ctx, job.ID); err != ErrJobNotRetryable {
+		t.Errorf("Expected ErrJobNotRetryable, got %v", err)
+	}
+	if _, err := queue.Retry(ctx, 99); err != ErrJobNotFound {
+		t.Errorf("Expected ErrJobNotFound, got %v", err)
+	}
+}
+
+func TestQueue_PermanentErrorsAndPanicsSkipRetries(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryJobs{}
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	queue := newTestQueue(repo, clk)
+	queue.Register("invalid", func(ctx context.Context, job domain.Job) error {
+		return Permanent(errors.New("order not found"))
+	})
+	queue.Register("panics", func(ctx context.Context, job domain.Job) error {
+		panic("boom")
+	})
+
+	invalid, _ := queue.Enqueue(ctx, "invalid", nil)
+	panics, _ := queue.Enqueue(ctx, "panics", nil, MaxAttempts(1))
+	queue.RunDue(context.Background())
+
+	if got := repo.get(invalid.ID); got.Status != domain.JobDead || got.Attempts != 1 {
+		t.Errorf("Expected permanent error to dead-letter, got %+v", got)
+	}
+	if got := repo.get(panics.ID); got.Status != domain.JobDead || got.LastError != "panic: boom" {
+		t.Errorf("Expected panic to be recorded, got %+v", got)
+	}
+}
+
+func TestQueue_ScheduledJobsRunOncePerSlot(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryJobs{}
+	clk := clock.NewFake(time.Date(2024, 1, 1, 23, 59, 0, 0, time.UTC))
+	queue := newTestQueue(repo, clk)
+	// Otra instancia con la misma programación comparte la tabla
+	other := newTestQueue(repo, clk)
+
+	var runs int
+	for _, q := range []*Queue{queue, other} {
+		q.Register("report", func(ctx context.Context, job domain.Job) error { runs++; return nil })
+		if err := q.Schedule("daily-report", "5 0 * * *", "report", nil); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if err := queue.Schedule("broken", "* *", "report", nil); err == nil {
+		t.Error("Expected invalid spec to be rejected")
+	}
+
+	queue.RunScheduled(ctx)
+	if len(repo.jobs) != 0 {
+		t.Fatalf("Expected nothing before 00:05, got %d jobs", len(repo.jobs))
+	}
+
+	clk.Set(time.Date(2024, 1, 2, 0, 5, 0, 0, time.UTC))
+	queue.RunScheduled(ctx)
+	other.RunScheduled(ctx)
+	queue.RunDue(context.Background())
+	if len(repo.jobs) != 1 || runs != 1 || *repo.jobs[0].UniqueKey != "schedule:daily-report:2024-01-02T00:05:00Z" {
+		t.Fatalf("Expected a single run, got %d jobs and %d runs", len(repo.jobs), runs)
+	}
+
+	// Después de tres días sin correr sólo se encola la última ejecución
+	clk.Set(time.Date(2024, 1, 5, 0, 6, 0, 0, time.UTC))
+	queue.RunScheduled(ctx)
+	queue.RunScheduled(ctx)
+	if len(repo.jobs) != 2 {
+		t.Errorf("Expected one catch-up run, got %d jobs", len(repo.jobs))
+	}
+	if next := queue.Schedules()[0].NextRun; !next.Equal(time.Date(2024, 1, 6, 0, 5, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected next run %s", next)
+	}
+}
+
+func TestQueue_MaintenanceRequeuesStaleAndPurges(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryJobs{}
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	queue := newTestQueue(repo, clk, WithLockTimeout(10*time.Minute), WithRetention(time.Hour))
+	queue.Register("noop", func(ctx context.Context, job domain.Job) error { return nil })
+
+	done, _ := queue.Enqueue(ctx, "noop", nil)
+	queue.RunDue(context.Background())
+	// Un trabajo tomado por una instancia que se cayó
+	stale, _ := queue.Enqueue(ctx, "noop", nil)
+	repo.Claim(ctx, clk.Now(), 1)
+
+	clk.Advance(2 * time.Hour)
+	queue.RunScheduled(ctx)
+	if got := repo.get(stale.ID); got.Status != domain.JobPending {
+		t.Errorf("Expected stale job requeued, got %+v", got)
+	}
+	if _, err := queue.Get(ctx, done.ID); err != ErrJobNotFound {
+		t.Errorf("Expected old succeeded job purged, got %v", err)
+	}
+
+	if _, err := queue.Purge(ctx, domain.JobPending, 0); err != ErrInvalidPurge {
+		t.Errorf("Expected ErrInvalidPurge, got %v", err)
+	}
+}
+
+func TestQueue_WorkerPoolRunsConcurrently(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryJobs{}
+	queue := NewQueue(repo, WithConcurrency(3), WithPollInterval(10*time.Millisecond))
+
+	var running, peak, finished int32
+	release := make(chan struct{})
+	queue.Register("slow", func(ctx context.Context, job domain.Job) error {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			observed := atomic.LoadInt32(&peak)
+			if current <= observed || atomic.CompareAndSwapInt32(&peak, observed, current) {
+				break
+			}
+		}
+		<-release
+		atomic.AddInt32(&running, -1)
+		atomic.AddInt32(&finished, 1)
+		return nil
+	})
+	for i := 0; i < 6; i++ {
+		queue.Enqueue(ctx, "slow", i)
+	}
+
+	queue.Start()
+	deadline := time.Now().Add(2 * time.Second)
+	for atomic.LoadInt32(&peak) < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	close(release)
+	for atomic.LoadInt32(&finished) < 6 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	queue.Stop()
+
+	if peak != 3 || finished != 6 {
+		t.Errorf("Expected 6 jobs with 3 in parallel, got %d finished and peak %d", finished, peak)
+	}
+	if queue.LastHeartbeat().IsZero() {
+		t.Error("Expected the running queue to record a heartbeat")
+	}
+}
+
+func TestQueue_StopRequeuesInterruptedJobs(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryJobs{}
+	queue := NewQueue(repo, WithPollInterval(10*time.Millisecond))
+
+	started := make(chan struct{})
+	queue.Register("long", func(ctx context.Context, job domain.Job) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	job, _ := queue.Enqueue(ctx, "long", nil)
+
+	queue.Start()
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the job to start")
+	}
+	queue.Stop()
+
+	saved, _ := queue.Get(ctx, job.ID)
+	if saved.Status != domain.JobPending || saved.Attempts != 0 || saved.LockedAt != nil {
+		t.Errorf("Expected the interrupted job back in the queue, got %+v", saved)
+	}
+}
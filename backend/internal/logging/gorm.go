@@ -0,0 +1,81 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/exp/slog"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// GormLogger adapta GORM al logger del contexto con el componente gorm: las consultas más
+// lentas que el umbral salen en warn, los errores en error y el resto en debug, todas con
+// el request_id del request que las originó
+type GormLogger struct {
+	slowThreshold time.Duration
+	mode          gormlogger.LogLevel
+}
+
+// NewGormLogger crea el adaptador; slowThreshold en 0 deshabilita el aviso de consultas lentas
+func NewGormLogger(slowThreshold time.Duration) *GormLogger {
+	return &GormLogger{slowThreshold: slowThreshold, mode: gormlogger.Info}
+}
+
+// LogMode permite que GORM silencie el logger en una sesión; el nivel fino lo define LOG_LEVELS
+func (l *GormLogger) LogMode(mode gormlogger.LogLevel) gormlogger.Interface {
+	clone := *l
+	clone.mode = mode
+	return &clone
+}
+
+func (l *GormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.mode >= gormlogger.Info {
+		For(ctx, "gorm").InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *GormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.mode >= gormlogger.Warn {
+		For(ctx, "gorm").WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *GormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.mode >= gormlogger.Error {
+		For(ctx, "gorm").ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.mode <= gormlogger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	logger := For(ctx, "gorm")
+
+	var level slog.Level
+	var msg string
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.mode >= gormlogger.Error:
+		level, msg = slog.LevelError, "query failed"
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.mode >= gormlogger.Warn:
+		level, msg = slog.LevelWarn, "slow query"
+	case l.mode >= gormlogger.Info:
+		level, msg = slog.LevelDebug, "query"
+	default:
+		return
+	}
+	// Armar el SQL cuesta; sólo se hace si la línea se va a escribir
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+	sql, rows := fc()
+	attrs := []interface{}{"sql", sql, "rows", rows, "duration_ms", float64(elapsed.Microseconds()) / 1000}
+	if err != nil {
+		attrs = append(attrs, "error", err.Error())
+	}
+	logger.Log(ctx, level, msg, attrs...)
+}
@@ -0,0 +1,150 @@
+// Package logging arma el logger estructurado del servidor y lo propaga por context.Context.
+//
+// Cada línea lleva el componente que la emitió (http, gorm, jobs, outbox...) y, si viene de
+// un request, su request_id. El nivel mínimo es global (LOG_LEVEL) y se puede cambiar por
+// componente (LOG_LEVELS=gorm=debug,jobs=warn). Los paquetes obtienen el logger con
+// For(ctx, componente); sin logger en el contexto se usa slog.Default().
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/exp/slog"
+)
+
+// ComponentKey es el atributo que identifica al componente y define su nivel
+const ComponentKey = "component"
+
+// Levels es el nivel mínimo por defecto y el de cada componente que lo cambia
+type Levels struct {
+	Default     slog.Level
+	ByComponent map[string]slog.Level
+}
+
+// For devuelve el nivel mínimo del componente
+func (l Levels) For(component string) slog.Level {
+	if level, ok := l.ByComponent[component]; ok {
+		return level
+	}
+	return l.Default
+}
+
+// ParseLevel interpreta debug, info, warn o error
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q, use debug, info, warn or error", s)
+}
+
+// ParseLevels interpreta el nivel por defecto y una lista como "gorm=debug,jobs=warn"
+func ParseLevels(def, spec string) (Levels, error) {
+	level, err := ParseLevel(def)
+	if err != nil {
+		return Levels{}, err
+	}
+	levels := Levels{Default: level, ByComponent: make(map[string]slog.Level)}
+	for _, entry := range strings.Split(spec, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		component, raw, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(component) == "" {
+			return Levels{}, fmt.Errorf("invalid entry %q, use component=level", entry)
+		}
+		level, err := ParseLevel(raw)
+		if err != nil {
+			return Levels{}, fmt.Errorf("%s: %w", strings.TrimSpace(component), err)
+		}
+		levels.ByComponent[strings.TrimSpace(component)] = level
+	}
+	return levels, nil
+}
+
+// New arma un logger que escribe en w con formato text o json y filtra por componente
+func New(w io.Writer, format string, levels Levels) (*slog.Logger, error) {
+	// El handler de base deja pasar todo; el nivel lo decide componentHandler
+	opts := &slog.HandlerOptions{Level: slog.LevelDebug}
+	var inner slog.Handler
+	switch strings.ToLower(format) {
+	case "text", "":
+		inner = slog.NewTextHandler(w, opts)
+	case "json":
+		inner = slog.NewJSONHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("unknown log format %q, use text or json", format)
+	}
+	return slog.New(&componentHandler{inner: inner, levels: levels}), nil
+}
+
+// componentHandler aplica el nivel del componente indicado con ComponentKey
+type componentHandler struct {
+	inner     slog.Handler
+	levels    Levels
+	component string
+}
+
+func (h *componentHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.levels.For(h.component)
+}
+
+func (h *componentHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.inner.Handle(ctx, record)
+}
+
+func (h *componentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	component := h.component
+	for _, attr := range attrs {
+		if attr.Key == ComponentKey {
+			component = attr.Value.String()
+		}
+	}
+	return &componentHandler{inner: h.inner.WithAttrs(attrs), levels: h.levels, component: component}
+}
+
+func (h *componentHandler) WithGroup(name string) slog.Handler {
+	return &componentHandler{inner: h.inner.WithGroup(name), levels: h.levels, component: h.component}
+}
+
+type loggerKey struct{}
+
+type requestIDKey struct{}
+
+// WithLogger guarda logger en el contexto para las capas de abajo
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext devuelve el logger del contexto, o slog.Default() si no hay
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// For devuelve el logger del contexto con el componente indicado
+func For(ctx context.Context, component string) *slog.Logger {
+	return FromContext(ctx).With(ComponentKey, component)
+}
+
+// WithRequestID guarda el ID del request en el contexto
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID devuelve el ID del request del contexto, o vacío si no viene de un request
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
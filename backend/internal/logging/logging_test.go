@@ -0,0 +1,188 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slog"
+	"gorm.io/gorm"
+)
+
+type fixedID string
+
+func (f fixedID) NewID() string { return string(f) }
+
+// lines decodifica cada línea JSON escrita por el logger
+func lines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var out []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Invalid JSON log line %q: %v", line, err)
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+func newTestLogger(t *testing.T, buf *bytes.Buffer, def, spec string) *slog.Logger {
+	t.Helper()
+	levels, err := ParseLevels(def, spec)
+	if err != nil {
+		t.Fatalf("Expected valid levels, got %v", err)
+	}
+	logger, err := New(buf, "json", levels)
+	if err != nil {
+		t.Fatalf("Expected a logger, got %v", err)
+	}
+	return logger
+}
+
+func TestParseLevels(t *testing.T) {
+	levels, err := ParseLevels("warn", " gorm=debug, jobs=ERROR ,")
+	if err != nil {
+		t.Fatalf("Expected valid levels, got %v", err)
+	}
+	if levels.For("gorm") != slog.LevelDebug || levels.For("jobs") != slog.LevelError || levels.For("http") != slog.LevelWarn {
+		t.Errorf("Unexpected levels %+v", levels)
+	}
+
+	for _, spec := range []string{"gorm", "=debug", "gorm=loud"} {
+		if _, err := ParseLevels("info", spec); err == nil {
+			t.Errorf("Expected %q to be rejected", spec)
+		}
+	}
+	if _, err := ParseLevels("verbose", ""); err == nil {
+		t.Error("Expected an unknown default level to be rejected")
+	}
+	if _, err := New(&bytes.Buffer{}, "xml", Levels{}); err == nil {
+		t.Error("Expected an unknown format to be rejected")
+	}
+}
+
+func TestNew_FiltersByComponent(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := WithLogger(context.Background(), newTestLogger(t, &buf, "info", "gorm=debug,jobs=error"))
+
+	For(ctx, "gorm").Debug("query")
+	For(ctx, "jobs").Warn("requeued stale jobs")
+	For(ctx, "jobs").Error("job is dead")
+	For(ctx, "http").Debug("request")
+	For(ctx, "http").Info("request")
+
+	got := lines(t, &buf)
+	if len(got) != 3 {
+		t.Fatalf("Expected 3 lines, got %v", got)
+	}
+	for i, want := range []string{"gorm", "jobs", "http"} {
+		if got[i][ComponentKey] != want {
+			t.Errorf("Expected line %d from %s, got %v", i, want, got[i])
+		}
+	}
+}
+
+func TestMiddleware_PropagatesRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(newTestLogger(t, &buf, "info", ""))
+	defer slog.SetDefault(previous)
+
+	router := gin.New()
+	router.Use(Middleware(fixedID("generated"), "/health"))
+	router.GET("/orders/:id", func(c *gin.Context) {
+		For(c.Request.Context(), "handlers").Info("loading order")
+		c.String(http.StatusNotFound, RequestID(c.Request.Context()))
+	})
+	router.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, tc := range []struct{ header, want string }{
+		{"abc-123", "abc-123"},
+		{"", "generated"},
+		{"bad id\n", "generated"},
+		{strings.Repeat("x", 200), "generated"},
+	} {
+		buf.Reset()
+		req := httptest.NewRequest(http.MethodGet, "/orders/7", nil)
+		if tc.header != "" {
+			req.Header.Set(RequestIDHeader, tc.header)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if got := rec.Header().Get(RequestIDHeader); got != tc.want || rec.Body.String() != tc.want {
+			t.Errorf("Expected request ID %q, got header %q and body %q", tc.want, got, rec.Body.String())
+		}
+		got := lines(t, &buf)
+		if len(got) != 2 {
+			t.Fatalf("Expected handler and access log lines, got %v", got)
+		}
+		for _, entry := range got {
+			if entry["request_id"] != tc.want {
+				t.Errorf("Expected request_id %q, got %v", tc.want, entry)
+			}
+		}
+		access := got[1]
+		if access[ComponentKey] != "http" || access["level"] != "WARN" || access["route"] != "/orders/:id" || access["status"] != float64(404) {
+			t.Errorf("Unexpected access log %v", access)
+		}
+	}
+
+	buf.Reset()
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+	if buf.Len() != 0 {
+		t.Errorf("Expected health probes to log only in debug, got %s", buf.String())
+	}
+}
+
+func TestGormLogger_LogsSlowQueriesWithRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(t, &buf, "info", "")
+	ctx := WithRequestID(WithLogger(context.Background(), logger.With("request_id", "req-1")), "req-1")
+	gormLogger := NewGormLogger(100 * time.Millisecond)
+
+	built := 0
+	sql := func() (string, int64) {
+		built++
+		return "SELECT * FROM orders", 3
+	}
+
+	gormLogger.Trace(ctx, time.Now(), sql, nil)
+	gormLogger.Trace(ctx, time.Now(), sql, gorm.ErrRecordNotFound)
+	if buf.Len() != 0 || built != 0 {
+		t.Errorf("Expected fast queries to stay quiet without building the SQL, got %s", buf.String())
+	}
+
+	gormLogger.Trace(ctx, time.Now().Add(-time.Second), sql, nil)
+	gormLogger.Trace(ctx, time.Now(), sql, errors.New("deadlock"))
+	got := lines(t, &buf)
+	if len(got) != 2 {
+		t.Fatalf("Expected slow query and error lines, got %v", got)
+	}
+	slow := got[0]
+	if slow["msg"] != "slow query" || slow["level"] != "WARN" || slow["request_id"] != "req-1" ||
+		slow[ComponentKey] != "gorm" || slow["sql"] != "SELECT * FROM orders" || slow["duration_ms"].(float64) < 1000 {
+		t.Errorf("Unexpected slow query line %v", slow)
+	}
+	if failed := got[1]; failed["msg"] != "query failed" || failed["level"] != "ERROR" || failed["error"] != "deadlock" {
+		t.Errorf("Unexpected error line %v", failed)
+	}
+
+	buf.Reset()
+	NewGormLogger(0).Trace(ctx, time.Now().Add(-time.Second), sql, nil)
+	if buf.Len() != 0 {
+		t.Errorf("Expected a zero threshold to disable slow query logs, got %s", buf.String())
+	}
+}
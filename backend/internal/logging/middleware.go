@@ -0,0 +1,87 @@
+package logging
+
+import (
+	"fmt"
+	"net/http"
+	"order-management-system/internal/ids"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slog"
+)
+
+// RequestIDHeader es el header con el que se recibe y se devuelve el ID del request
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLength = 128
+
+// Middleware asigna un ID a cada request (el de X-Request-ID si viene uno válido), lo
+// devuelve en la respuesta, deja en el contexto un logger con ese request_id y registra el
+// request al terminar. Los requests a quietPaths, como las sondas de salud, se registran
+// en debug.
+func Middleware(generator ids.Generator, quietPaths ...string) gin.HandlerFunc {
+	quiet := make(map[string]bool, len(quietPaths))
+	for _, path := range quietPaths {
+		quiet[path] = true
+	}
+	return func(c *gin.Context) {
+		start := time.Now()
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = generator.NewID()
+		}
+		c.Header(RequestIDHeader, id)
+
+		ctx := c.Request.Context()
+		logger := FromContext(ctx).With("request_id", id)
+		ctx = WithRequestID(WithLogger(ctx, logger), id)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case quiet[c.Request.URL.Path]:
+			level = slog.LevelDebug
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		attrs := []interface{}{
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"route", c.FullPath(),
+			"status", status,
+			"duration_ms", time.Since(start).Milliseconds(),
+			"bytes", c.Writer.Size(),
+			"client_ip", c.ClientIP(),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, "errors", c.Errors.String())
+		}
+		logger.With(ComponentKey, "http").Log(ctx, level, "request", attrs...)
+	}
+}
+
+// Recovery responde 500 ante un panic y lo registra con el request_id y el stack
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, recovered interface{}) {
+		For(c.Request.Context(), "http").Error("panic serving request",
+			"error", fmt.Sprint(recovered), "stack", string(debug.Stack()))
+		c.AbortWithStatus(http.StatusInternalServerError)
+	})
+}
+
+// validRequestID acepta IDs razonables para no copiar cualquier cosa a los logs
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	return strings.IndexFunc(id, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_.:", r))
+	}) < 0
+}
@@ -0,0 +1,195 @@
+// Package migrations versiona el esquema de la base con scripts SQL por dialecto.
+//
+// Cada migración es un par de archivos <versión>_<nombre>.up.sql y .down.sql dentro de
+// sql/<dialecto>/ (postgres, mysql o sqlite), embebidos en el binario. Las aplicadas se
+// registran en la tabla schema_migrations junto con el checksum del script, para detectar
+// archivos editados después de aplicarse. Un lock a nivel de base evita que dos instancias
+// migren a la vez.
+package migrations
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed sql
+var embedded embed.FS
+
+// Embedded son los scripts incluidos en el binario, organizados por dialecto
+func Embedded() fs.FS {
+	sub, err := fs.Sub(embedded, "sql")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+var (
+	ErrInvalidMigration = errors.New("invalid migration")
+	ErrUnknownDialect   = errors.New("unknown database dialect")
+)
+
+// Dialectos soportados; coinciden con el nombre del dialector de GORM
+const (
+	Postgres = "postgres"
+	MySQL    = "mysql"
+	SQLite   = "sqlite"
+)
+
+// Dialects son los dialectos para los que cada migración necesita scripts
+var Dialects = []string{Postgres, MySQL, SQLite}
+
+// Migration es una versión del esquema con sus scripts para un dialecto
+type Migration struct {
+	Version  uint64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Load lee las migraciones de un dialecto ordenadas por versión. Cada versión debe tener
+// su script up y su script down.
+func Load(fsys fs.FS, dialect string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dialect)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s migrations: %w", dialect, err)
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("%w: unexpected file %s/%s", ErrInvalidMigration, dialect, entry.Name())
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("%w: invalid version in %s", ErrInvalidMigration, entry.Name())
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dialect, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("%w: version %d used by %s and %s", ErrInvalidMigration, version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if strings.TrimSpace(migration.Up) == "" || strings.TrimSpace(migration.Down) == "" {
+			return nil, fmt.Errorf("%w: %04d_%s needs both up and down scripts", ErrInvalidMigration, migration.Version, migration.Name)
+		}
+		sum := sha256.Sum256([]byte(migration.Up))
+		migration.Checksum = hex.EncodeToString(sum[:])
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+var validName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// Create agrega en dir los archivos vacíos de una nueva migración para cada dialecto, con
+// la versión siguiente a la mayor existente, y devuelve las rutas creadas
+func Create(dir, name string) ([]string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+	if !validName.MatchString(name) {
+		return nil, fmt.Errorf("%w: name %q must use letters, digits and underscores", ErrInvalidMigration, name)
+	}
+
+	var latest uint64
+	for _, dialect := range Dialects {
+		migrations, err := Load(os.DirFS(dir), dialect)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		for _, migration := range migrations {
+			if migration.Version > latest {
+				latest = migration.Version
+			}
+		}
+	}
+
+	var created []string
+	for _, dialect := range Dialects {
+		if err := os.MkdirAll(filepath.Join(dir, dialect), 0o755); err != nil {
+			return nil, err
+		}
+		for _, direction := range []string{"up", "down"} {
+			file := filepath.Join(dir, dialect, fmt.Sprintf("%04d_%s.%s.sql", latest+1, name, direction))
+			header := fmt.Sprintf("-- %s (%s)\n", strings.ReplaceAll(name, "_", " "), direction)
+			if err := os.WriteFile(file, []byte(header), 0o644); err != nil {
+				return nil, err
+			}
+			created = append(created, file)
+		}
+	}
+	return created, nil
+}
+
+// splitStatements separa un script en sentencias terminadas en ";", ignorando los que
+// aparecen dentro de literales y comentarios. Así no hace falta habilitar multi-statements
+// en los drivers.
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	var quote byte
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case quote != 0:
+			current.WriteByte(c)
+			if c == quote {
+				quote = 0
+			}
+			continue
+		case c == '-' && i+1 < len(script) && script[i+1] == '-':
+			for i < len(script) && script[i] != '\n' {
+				i++
+			}
+			current.WriteByte('\n')
+			continue
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == ';':
+			if statement := strings.TrimSpace(current.String()); statement != "" {
+				statements = append(statements, statement)
+			}
+			current.Reset()
+			continue
+		}
+		current.WriteByte(c)
+	}
+	if statement := strings.TrimSpace(current.String()); statement != "" {
+		statements = append(statements, statement)
+	}
+	return statements
+}
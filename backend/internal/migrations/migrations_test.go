@@ -0,0 +1,317 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"order-management-system/internal/domain"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+func TestEmbedded_DialectsShareVersions(t *testing.T) {
+	var reference []Migration
+	for _, dialect := range Dialects {
+		migrations, err := Load(Embedded(), dialect)
+		if err != nil {
+			t.Fatalf("Expected %s migrations to load, got %v", dialect, err)
+		}
+		if len(migrations) == 0 {
+			t.Fatalf("Expected %s migrations", dialect)
+		}
+		if reference == nil {
+			reference = migrations
+			continue
+		}
+		if len(migrations) != len(reference) {
+			t.Fatalf("Expected %d %s migrations, got %d", len(reference), dialect, len(migrations))
+		}
+		for i := range migrations {
+			if migrations[i].Version != reference[i].Version || migrations[i].Name != reference[i].Name {
+				t.Errorf("Expected %s to have %04d_%s, got %04d_%s", dialect, reference[i].Version, reference[i].Name, migrations[i].Version, migrations[i].Name)
+			}
+		}
+	}
+}
+
+// Cada columna de los modelos tiene que existir en los scripts de ambos dialectos, así un
+// campo nuevo sin su migración falla acá y no en producción
+func TestEmbedded_CoverEveryModelColumn(t *testing.T) {
+	models := []interface{}{
+		&domain.User{}, &domain.Product{}, &domain.Order{}, &domain.OrderItem{}, &domain.OrderItemDiscount{},
+		&domain.Promotion{}, &domain.PromotionRedemption{}, &domain.PromotionUserUsage{}, &domain.TaxRule{}, &domain.OrderTaxLine{},
+		&domain.Address{}, &domain.Payment{}, &domain.Refund{}, &domain.RefundLine{},
+		&domain.ReturnAuthorization{}, &domain.ReturnItem{}, &domain.ReturnTransition{}, &domain.OrderChange{},
+		&domain.OutboxEvent{}, &domain.WebhookSubscription{}, &domain.WebhookDelivery{},
+		&domain.NotificationPreference{}, &domain.Notification{}, &domain.Job{},
+		&domain.ShippingLabel{}, &domain.SimulatedAuthorization{},
+	}
+
+	for _, dialect := range Dialects {
+		migrations, _ := Load(Embedded(), dialect)
+		var statements []string
+		for _, migration := range migrations {
+			statements = append(statements, splitStatements(migration.Up)...)
+		}
+
+		for _, model := range models {
+			s, err := schema.Parse(model, &sync.Map{}, schema.NamingStrategy{})
+			if err != nil {
+				t.Fatalf("Expected %T to parse, got %v", model, err)
+			}
+			table := regexp.MustCompile(`(?i)^(CREATE TABLE IF NOT EXISTS|ALTER TABLE) ` + s.Table + `\b`)
+			var definition strings.Builder
+			for _, statement := range statements {
+				if table.MatchString(statement) {
+					definition.WriteString(statement)
+				}
+			}
+			for _, column := range s.DBNames {
+				if !regexp.MustCompile(`\b` + column + `\b`).MatchString(definition.String()) {
+					t.Errorf("Expected %s migrations to define %s.%s", dialect, s.Table, column)
+				}
+			}
+		}
+	}
+}
+
+func TestLoad_RejectsIncompleteMigrations(t *testing.T) {
+	cases := map[string]fstest.MapFS{
+		"missing down": {
+			"postgres/0001_init.up.sql": {Data: []byte("CREATE TABLE a (id INT);")},
+		},
+		"unexpected file": {
+			"postgres/0001_init.up.sql":   {Data: []byte("CREATE TABLE a (id INT);")},
+			"postgres/0001_init.down.sql": {Data: []byte("DROP TABLE a;")},
+			"postgres/notes.txt":          {Data: []byte("todo")},
+		},
+		"duplicated version": {
+			"postgres/0001_init.up.sql":    {Data: []byte("CREATE TABLE a (id INT);")},
+			"postgres/0001_init.down.sql":  {Data: []byte("DROP TABLE a;")},
+			"postgres/0001_other.up.sql":   {Data: []byte("CREATE TABLE b (id INT);")},
+			"postgres/0001_other.down.sql": {Data: []byte("DROP TABLE b;")},
+		},
+	}
+	for name, fsys := range cases {
+		if _, err := Load(fsys, Postgres); !errors.Is(err, ErrInvalidMigration) {
+			t.Errorf("%s: expected ErrInvalidMigration, got %v", name, err)
+		}
+	}
+
+	migrations, err := Load(fstest.MapFS{
+		"postgres/0010_second.up.sql":   {Data: []byte("ALTER TABLE a ADD COLUMN b INT;")},
+		"postgres/0010_second.down.sql": {Data: []byte("ALTER TABLE a DROP COLUMN b;")},
+		"postgres/0002_first.up.sql":    {Data: []byte("CREATE TABLE a (id INT);")},
+		"postgres/0002_first.down.sql":  {Data: []byte("DROP TABLE a;")},
+	}, Postgres)
+	if err != nil || len(migrations) != 2 || migrations[0].Version != 2 || migrations[1].Name != "second" || migrations[0].Checksum == "" {
+		t.Errorf("Expected migrations sorted by version, got %+v (%v)", migrations, err)
+	}
+}
+
+func TestSplitStatements_IgnoresSemicolonsInLiteralsAndComments(t *testing.T) {
+	statements := splitStatements(`
+		-- backfill; en dos pasos
+		UPDATE products SET category = 'a;b' WHERE category IS NULL;
+		ALTER TABLE products ADD COLUMN note TEXT DEFAULT ';'
+	`)
+	if len(statements) != 2 || !strings.HasPrefix(statements[0], "UPDATE") || !strings.HasSuffix(statements[1], "DEFAULT ';'") {
+		t.Errorf("Unexpected statements %q", statements)
+	}
+}
+
+func TestCreate_AddsNextVersionForEveryDialect(t *testing.T) {
+	dir := t.TempDir()
+	for _, dialect := range Dialects {
+		os.MkdirAll(filepath.Join(dir, dialect), 0o755)
+		os.WriteFile(filepath.Join(dir, dialect, "0007_init.up.sql"), []byte("SELECT 1;"), 0o644)
+		os.WriteFile(filepath.Join(dir, dialect, "0007_init.down.sql"), []byte("SELECT 1;"), 0o644)
+	}
+
+	created, err := Create(dir, "Add order notes")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(created) != 2*len(Dialects) || filepath.Base(created[0]) != "0008_add_order_notes.up.sql" || filepath.Base(created[3]) != "0008_add_order_notes.down.sql" {
+		t.Errorf("Unexpected files %v", created)
+	}
+	if _, err := Create(dir, "drop; users"); !errors.Is(err, ErrInvalidMigration) {
+		t.Errorf("Expected invalid name to be rejected, got %v", err)
+	}
+}
+
+func TestStatus_FlagsModifiedAndMissingMigrations(t *testing.T) {
+	migrations := []Migration{
+		{Version: 1, Name: "init", Checksum: "a"},
+		{Version: 2, Name: "notes", Checksum: "b"},
+		{Version: 4, Name: "later", Checksum: "d"},
+	}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	done := map[uint64]appliedMigration{
+		1: {Version: 1, Name: "init", Checksum: "a", AppliedAt: now},
+		2: {Version: 2, Name: "notes", Checksum: "changed", AppliedAt: now},
+		3: {Version: 3, Name: "removed", Checksum: "c", AppliedAt: now},
+	}
+
+	statuses := status(migrations, done)
+	if len(statuses) != 4 || !statuses[0].Applied || statuses[0].Modified || !statuses[1].Modified ||
+		!statuses[2].Missing || statuses[2].Version != 3 || statuses[3].Applied {
+		t.Errorf("Unexpected statuses %+v", statuses)
+	}
+	if todo := pending(migrations, done); len(todo) != 1 || todo[0].Version != 4 {
+		t.Errorf("Expected only version 4 pending, got %+v", todo)
+	}
+}
+
+func TestMigrator_StatusAfterUpOnSQLite(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	migrator, err := New(db)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := migrator.Up(context.Background(), 0); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	statuses, err := migrator.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Expected status to read applied_at, got %v", err)
+	}
+	for _, s := range statuses {
+		if !s.Applied || s.AppliedAt == nil || s.AppliedAt.IsZero() {
+			t.Errorf("Expected version %d applied with its time, got %+v", s.Version, s)
+		}
+	}
+}
+
+// Modelos tal como estaban antes de las migraciones, para sembrar la base con el
+// esquema que dejaba AutoMigrate
+type baselineUser struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"not null"`
+	Email     string `gorm:"unique;not null"`
+	CreatedAt time.Time
+}
+
+func (baselineUser) TableName() string { return "users" }
+
+type baselineProduct struct {
+	ID        uint    `gorm:"primaryKey"`
+	Name      string  `gorm:"not null"`
+	Price     float64 `gorm:"not null"`
+	Stock     int     `gorm:"not null"`
+	CreatedAt time.Time
+}
+
+func (baselineProduct) TableName() string { return "products" }
+
+type baselineOrder struct {
+	ID        uint                `gorm:"primaryKey"`
+	UserID    uint                `gorm:"not null"`
+	User      baselineUser        `gorm:"foreignKey:UserID"`
+	Total     float64             `gorm:"not null"`
+	Status    string              `gorm:"type:varchar(20);not null"`
+	Items     []baselineOrderItem `gorm:"foreignKey:OrderID"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (baselineOrder) TableName() string { return "orders" }
+
+type baselineOrderItem struct {
+	ID        uint            `gorm:"primaryKey"`
+	OrderID   uint            `gorm:"not null"`
+	ProductID uint            `gorm:"not null"`
+	Product   baselineProduct `gorm:"foreignKey:ProductID"`
+	Quantity  int             `gorm:"not null"`
+	Price     float64         `gorm:"not null"`
+}
+
+func (baselineOrderItem) TableName() string { return "order_items" }
+
+// Una base creada por AutoMigrate antes de las migraciones adopta 0001 y recibe las
+// columnas nuevas de 0002 sin perder datos; revertir hasta 0001 la deja como estaba
+func TestMigrator_UpgradesBaselineSchema(t *testing.T) {
+	ctx := context.Background()
+	db, err := gorm.Open(sqlite.Open("file::memory:?_pragma=foreign_keys(1)"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	if err := db.AutoMigrate(&baselineUser{}, &baselineProduct{}, &baselineOrder{}, &baselineOrderItem{}); err != nil {
+		t.Fatalf("Expected baseline schema, got %v", err)
+	}
+	user := baselineUser{Name: "Ana", Email: "ana@example.com"}
+	product := baselineProduct{Name: "Laptop", Price: 1000, Stock: 5}
+	db.Create(&user)
+	db.Create(&product)
+	order := baselineOrder{UserID: user.ID, Total: 2000, Status: "PENDING",
+		Items: []baselineOrderItem{{ProductID: product.ID, Quantity: 2, Price: 1000}}}
+	if err := db.Create(&order).Error; err != nil {
+		t.Fatalf("Expected baseline order, got %v", err)
+	}
+
+	migrator, err := New(db)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := migrator.Up(ctx, 0); err != nil {
+		t.Fatalf("Expected migrations to apply on the baseline schema, got %v", err)
+	}
+
+	var upgraded domain.Order
+	if err := db.Preload("Items").First(&upgraded, order.ID).Error; err != nil {
+		t.Fatalf("Expected baseline order to load with the new model, got %v", err)
+	}
+	if upgraded.Total != 2000 || upgraded.Subtotal != 0 || len(upgraded.Items) != 1 || upgraded.Items[0].Quantity != 2 {
+		t.Errorf("Unexpected upgraded order %+v", upgraded)
+	}
+	var upgradedProduct domain.Product
+	db.First(&upgradedProduct, product.ID)
+	if upgradedProduct.TaxClass != domain.TaxClassStandard || upgradedProduct.Stock != 5 {
+		t.Errorf("Expected existing product to default to STANDARD, got %+v", upgradedProduct)
+	}
+
+	publicID, number := "01HZX3Y4Z5A6B7C8D9E0F1G2H3", "ORD-2024-7K3QX9MZ-4"
+	created := domain.Order{UserID: user.ID, PublicID: &publicID, Number: &number,
+		Total: 10, Status: domain.StatusPending, ParentOrderID: &upgraded.ID}
+	if err := db.Create(&created).Error; err != nil {
+		t.Fatalf("Expected order with the new columns, got %v", err)
+	}
+
+	// Revertir todo salvo 0001 deja el esquema original
+	all, _ := Load(Embedded(), SQLite)
+	if _, err := migrator.Down(ctx, len(all)-1); err != nil {
+		t.Fatalf("Expected migrations after 0001 to revert, got %v", err)
+	}
+	for _, column := range []string{"public_id", "number", "subtotal", "parent_order_id", "cancelled_at"} {
+		if db.Migrator().HasColumn("orders", column) {
+			t.Errorf("Expected orders.%s to be dropped", column)
+		}
+	}
+	if db.Migrator().HasTable("payments") {
+		t.Error("Expected payments to be dropped")
+	}
+	var count int64
+	db.Table("orders").Count(&count)
+	if count != 2 {
+		t.Errorf("Expected orders to survive the rollback, got %d", count)
+	}
+}
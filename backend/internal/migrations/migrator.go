@@ -0,0 +1,367 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"order-management-system/internal/logging"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrLockTimeout      = errors.New("timed out waiting for the migration lock")
+	ErrChecksumMismatch = errors.New("applied migration was modified")
+	ErrMissingMigration = errors.New("applied migration has no script")
+)
+
+const (
+	DefaultLockTimeout = time.Minute
+
+	// lockKey identifica el lock de migraciones en pg_advisory_lock y GET_LOCK
+	lockKey      = 72570113
+	lockName     = "schema_migrations"
+	lockPollWait = 500 * time.Millisecond
+)
+
+// Status describe una migración conocida o aplicada
+type Status struct {
+	Version   uint64     `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	// Modified indica que el script cambió después de aplicarse
+	Modified bool `json:"modified,omitempty"`
+	// Missing indica una versión aplicada cuyo script ya no existe
+	Missing bool `json:"missing,omitempty"`
+}
+
+// appliedMigration es una fila de schema_migrations
+type appliedMigration struct {
+	Version   uint64 `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+func (appliedMigration) TableName() string { return "schema_migrations" }
+
+// Migrator aplica y revierte las migraciones de un dialecto
+type Migrator struct {
+	db          *gorm.DB
+	dialect     string
+	source      fs.FS
+	lockTimeout time.Duration
+	now         func() time.Time
+}
+
+// Option configura parámetros opcionales del Migrator
+type Option func(*Migrator)
+
+// WithSource reemplaza los scripts embebidos, por ejemplo por un directorio en disco
+func WithSource(source fs.FS) Option {
+	return func(m *Migrator) {
+		m.source = source
+	}
+}
+
+// WithLockTimeout define cuánto se espera a que otra instancia termine de migrar
+func WithLockTimeout(timeout time.Duration) Option {
+	return func(m *Migrator) {
+		m.lockTimeout = timeout
+	}
+}
+
+// WithClock reemplaza el reloj con el que se registra applied_at
+func WithClock(now func() time.Time) Option {
+	return func(m *Migrator) {
+		m.now = now
+	}
+}
+
+// New crea el Migrator para el dialecto de la conexión (postgres, mysql o sqlite)
+func New(db *gorm.DB, opts ...Option) (*Migrator, error) {
+	m := &Migrator{
+		db:          db,
+		dialect:     db.Dialector.Name(),
+		source:      Embedded(),
+		lockTimeout: DefaultLockTimeout,
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.dialect != Postgres && m.dialect != MySQL && m.dialect != SQLite {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDialect, m.dialect)
+	}
+	return m, nil
+}
+
+// Up aplica hasta steps migraciones pendientes (todas si steps es 0) y devuelve las
+// aplicadas. Se niega a avanzar si una migración aplicada fue modificada o ya no existe.
+func (m *Migrator) Up(ctx context.Context, steps int) ([]Migration, error) {
+	var applied []Migration
+	err := m.locked(ctx, func(conn *gorm.DB, migrations []Migration, done map[uint64]appliedMigration) error {
+		statuses := status(migrations, done)
+		for _, s := range statuses {
+			switch {
+			case s.Modified:
+				return fmt.Errorf("%w: %04d_%s", ErrChecksumMismatch, s.Version, s.Name)
+			case s.Missing:
+				return fmt.Errorf("%w: %d", ErrMissingMigration, s.Version)
+			}
+		}
+
+		for _, migration := range pending(migrations, done) {
+			if steps > 0 && len(applied) == steps {
+				break
+			}
+			if err := m.apply(conn, migration, migration.Up, func(tx *gorm.DB) error {
+				return tx.Create(&appliedMigration{
+					Version:   migration.Version,
+					Name:      migration.Name,
+					Checksum:  migration.Checksum,
+					AppliedAt: m.now(),
+				}).Error
+			}); err != nil {
+				return err
+			}
+			logging.For(ctx, "migrations").Info("applied migration", "version", migration.Version, "name", migration.Name)
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down revierte las últimas steps migraciones aplicadas (1 si steps es 0)
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		steps = 1
+	}
+	var reverted []Migration
+	err := m.locked(ctx, func(conn *gorm.DB, migrations []Migration, done map[uint64]appliedMigration) error {
+		byVersion := make(map[uint64]Migration, len(migrations))
+		for _, migration := range migrations {
+			byVersion[migration.Version] = migration
+		}
+
+		statuses := status(migrations, done)
+		for i := len(statuses) - 1; i >= 0 && len(reverted) < steps; i-- {
+			s := statuses[i]
+			if !s.Applied {
+				continue
+			}
+			migration, ok := byVersion[s.Version]
+			if !ok {
+				return fmt.Errorf("%w: %d", ErrMissingMigration, s.Version)
+			}
+			if err := m.apply(conn, migration, migration.Down, func(tx *gorm.DB) error {
+				return tx.Delete(&appliedMigration{Version: migration.Version}).Error
+			}); err != nil {
+				return err
+			}
+			logging.For(ctx, "migrations").Info("reverted migration", "version", migration.Version, "name", migration.Name)
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status lista las migraciones conocidas y aplicadas ordenadas por versión
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	migrations, err := Load(m.source, m.dialect)
+	if err != nil {
+		return nil, err
+	}
+	db := m.db.WithContext(ctx)
+	if err := m.ensureTable(db); err != nil {
+		return nil, err
+	}
+	done, err := m.applied(db)
+	if err != nil {
+		return nil, err
+	}
+	return status(migrations, done), nil
+}
+
+// locked toma el lock de migraciones en una conexión dedicada y ejecuta fn con las
+// migraciones del dialecto y las ya aplicadas
+func (m *Migrator) locked(ctx context.Context, fn func(conn *gorm.DB, migrations []Migration, done map[uint64]appliedMigration) error) error {
+	migrations, err := Load(m.source, m.dialect)
+	if err != nil {
+		return err
+	}
+
+	// Los locks de sesión viven en la conexión, así que todo corre sobre la misma
+	return m.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if err := m.lock(ctx, conn); err != nil {
+			return err
+		}
+		defer m.unlock(ctx, conn)
+
+		if err := m.ensureTable(conn); err != nil {
+			return err
+		}
+		done, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+		return fn(conn, migrations, done)
+	})
+}
+
+// apply ejecuta un script y registra el cambio en schema_migrations. En Postgres y SQLite
+// todo va en una transacción; MySQL confirma cada sentencia DDL por su cuenta, así que un
+// error a mitad de un script deja aplicadas las sentencias anteriores y hay que corregirlo
+// a mano.
+func (m *Migrator) apply(conn *gorm.DB, migration Migration, script string, record func(tx *gorm.DB) error) error {
+	run := func(tx *gorm.DB) error {
+		for i, statement := range splitStatements(script) {
+			if err := tx.Exec(statement).Error; err != nil {
+				return fmt.Errorf("migration %04d_%s, statement %d: %w", migration.Version, migration.Name, i+1, err)
+			}
+		}
+		return record(tx)
+	}
+	if m.dialect == MySQL {
+		return run(conn)
+	}
+	return conn.Transaction(run)
+}
+
+func (m *Migrator) lock(ctx context.Context, conn *gorm.DB) error {
+	switch m.dialect {
+	case SQLite:
+		// SQLite es para correr en local con una sola instancia; la escritura ya es exclusiva
+		return nil
+	case MySQL:
+		var acquired int
+		seconds := int(m.lockTimeout.Seconds())
+		if err := conn.Raw("SELECT GET_LOCK(?, ?)", lockName, seconds).Scan(&acquired).Error; err != nil {
+			return fmt.Errorf("acquiring migration lock: %w", err)
+		}
+		if acquired != 1 {
+			return ErrLockTimeout
+		}
+		return nil
+	}
+
+	deadline := time.Now().Add(m.lockTimeout)
+	for {
+		var acquired bool
+		if err := conn.Raw("SELECT pg_try_advisory_lock(?)", lockKey).Scan(&acquired).Error; err != nil {
+			return fmt.Errorf("acquiring migration lock: %w", err)
+		}
+		if acquired {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return ErrLockTimeout
+		}
+		logging.For(ctx, "migrations").Info("waiting for another instance to finish migrating")
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(lockPollWait):
+		}
+	}
+}
+
+func (m *Migrator) unlock(ctx context.Context, conn *gorm.DB) {
+	var err error
+	switch m.dialect {
+	case SQLite:
+		return
+	case MySQL:
+		err = conn.Exec("SELECT RELEASE_LOCK(?)", lockName).Error
+	default:
+		err = conn.Exec("SELECT pg_advisory_unlock(?)", lockKey).Error
+	}
+	if err != nil {
+		logging.For(ctx, "migrations").Error("releasing lock", "error", err)
+	}
+}
+
+func (m *Migrator) ensureTable(conn *gorm.DB) error {
+	ddl := `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT NOT NULL PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		checksum VARCHAR(64) NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL
+	)`
+	switch m.dialect {
+	case MySQL:
+		ddl = `CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT UNSIGNED NOT NULL PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			checksum VARCHAR(64) NOT NULL,
+			applied_at DATETIME(3) NOT NULL
+		)`
+	case SQLite:
+		// El driver sólo convierte a time.Time las columnas declaradas como DATETIME o TIMESTAMP
+		ddl = `CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT NOT NULL PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			checksum VARCHAR(64) NOT NULL,
+			applied_at DATETIME NOT NULL
+		)`
+	}
+	if err := conn.Exec(ddl).Error; err != nil {
+		return fmt.Errorf("creating schema_migrations: %w", err)
+	}
+	return nil
+}
+
+func (m *Migrator) applied(conn *gorm.DB) (map[uint64]appliedMigration, error) {
+	var rows []appliedMigration
+	if err := conn.Order("version").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("reading schema_migrations: %w", err)
+	}
+	done := make(map[uint64]appliedMigration, len(rows))
+	for _, row := range rows {
+		done[row.Version] = row
+	}
+	return done, nil
+}
+
+// pending devuelve las migraciones sin aplicar en orden de versión. Una migración más
+// vieja que la última aplicada (por ejemplo traída de otra rama) también se aplica.
+func pending(migrations []Migration, done map[uint64]appliedMigration) []Migration {
+	var result []Migration
+	for _, migration := range migrations {
+		if _, ok := done[migration.Version]; !ok {
+			result = append(result, migration)
+		}
+	}
+	return result
+}
+
+// status combina los scripts con las filas de schema_migrations
+func status(migrations []Migration, done map[uint64]appliedMigration) []Status {
+	known := make(map[uint64]bool, len(migrations))
+	statuses := make([]Status, 0, len(migrations))
+	for _, migration := range migrations {
+		known[migration.Version] = true
+		s := Status{Version: migration.Version, Name: migration.Name}
+		if row, ok := done[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			s.Applied = true
+			s.AppliedAt = &appliedAt
+			s.Modified = row.Checksum != migration.Checksum
+		}
+		statuses = append(statuses, s)
+	}
+	for version, row := range done {
+		if !known[version] {
+			appliedAt := row.AppliedAt
+			statuses = append(statuses, Status{Version: version, Name: row.Name, Applied: true, AppliedAt: &appliedAt, Missing: true})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses
+}
@@ -0,0 +1,39 @@
+-- Esquema inicial: el mismo que generaba AutoMigrate. Usa IF NOT EXISTS para que las
+-- bases creadas por AutoMigrate lo adopten sin cambios; los índices van dentro de cada
+-- CREATE TABLE porque MySQL no tiene CREATE INDEX IF NOT EXISTS.
+
+CREATE TABLE IF NOT EXISTS users (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    name LONGTEXT NOT NULL,
+    email VARCHAR(191) NOT NULL,
+    created_at DATETIME(3),
+    UNIQUE INDEX idx_users_email (email)
+);
+
+CREATE TABLE IF NOT EXISTS products (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    name LONGTEXT NOT NULL,
+    price DOUBLE NOT NULL,
+    stock BIGINT NOT NULL,
+    created_at DATETIME(3)
+);
+
+CREATE TABLE IF NOT EXISTS orders (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT UNSIGNED NOT NULL,
+    total DOUBLE NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    created_at DATETIME(3),
+    updated_at DATETIME(3),
+    CONSTRAINT fk_orders_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE TABLE IF NOT EXISTS order_items (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    order_id BIGINT UNSIGNED NOT NULL,
+    product_id BIGINT UNSIGNED NOT NULL,
+    quantity BIGINT NOT NULL,
+    price DOUBLE NOT NULL,
+    CONSTRAINT fk_orders_items FOREIGN KEY (order_id) REFERENCES orders (id),
+    CONSTRAINT fk_order_items_product FOREIGN KEY (product_id) REFERENCES products (id)
+);
@@ -0,0 +1,336 @@
+-- Columnas y tablas que se agregaron al esquema inicial: catálogo, descuentos, impuestos,
+-- direcciones, envíos, pagos, devoluciones, eventos, webhooks, notificaciones y jobs.
+-- Los índices van dentro de cada ALTER o CREATE TABLE porque MySQL no tiene
+-- CREATE INDEX IF NOT EXISTS.
+
+ALTER TABLE products
+    ADD COLUMN category VARCHAR(100),
+    ADD COLUMN tax_class VARCHAR(20) NOT NULL DEFAULT 'STANDARD',
+    ADD COLUMN weight_grams BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN length_cm DOUBLE NOT NULL DEFAULT 0,
+    ADD COLUMN width_cm DOUBLE NOT NULL DEFAULT 0,
+    ADD COLUMN height_cm DOUBLE NOT NULL DEFAULT 0,
+    ADD INDEX idx_products_category (category);
+
+ALTER TABLE orders
+    ADD COLUMN public_id VARCHAR(26),
+    ADD COLUMN number VARCHAR(32),
+    ADD COLUMN subtotal DOUBLE NOT NULL DEFAULT 0,
+    ADD COLUMN discount_total DOUBLE NOT NULL DEFAULT 0,
+    ADD COLUMN tax_total DOUBLE NOT NULL DEFAULT 0,
+    ADD COLUMN tax_country VARCHAR(2),
+    ADD COLUMN tax_region VARCHAR(100),
+    ADD COLUMN shipping_recipient_name VARCHAR(150),
+    ADD COLUMN shipping_line1 VARCHAR(200),
+    ADD COLUMN shipping_line2 VARCHAR(200),
+    ADD COLUMN shipping_city VARCHAR(100),
+    ADD COLUMN shipping_region VARCHAR(100),
+    ADD COLUMN shipping_postal_code VARCHAR(20),
+    ADD COLUMN shipping_country VARCHAR(2),
+    ADD COLUMN shipping_phone VARCHAR(30),
+    ADD COLUMN billing_recipient_name VARCHAR(150),
+    ADD COLUMN billing_line1 VARCHAR(200),
+    ADD COLUMN billing_line2 VARCHAR(200),
+    ADD COLUMN billing_city VARCHAR(100),
+    ADD COLUMN billing_region VARCHAR(100),
+    ADD COLUMN billing_postal_code VARCHAR(20),
+    ADD COLUMN billing_country VARCHAR(2),
+    ADD COLUMN billing_phone VARCHAR(30),
+    ADD COLUMN shipping_carrier VARCHAR(50),
+    ADD COLUMN shipping_method VARCHAR(50),
+    ADD COLUMN shipping_cost DOUBLE NOT NULL DEFAULT 0,
+    ADD COLUMN tracking_number VARCHAR(100),
+    ADD COLUMN label_url VARCHAR(255),
+    ADD COLUMN parent_order_id BIGINT UNSIGNED,
+    ADD COLUMN merged_into_id BIGINT UNSIGNED,
+    ADD COLUMN cancelled_by VARCHAR(50),
+    ADD COLUMN cancellation_reason LONGTEXT,
+    ADD COLUMN cancelled_at DATETIME(3),
+    ADD CONSTRAINT fk_orders_child_orders FOREIGN KEY (parent_order_id) REFERENCES orders (id),
+    ADD CONSTRAINT fk_orders_merged_orders FOREIGN KEY (merged_into_id) REFERENCES orders (id),
+    ADD UNIQUE INDEX idx_orders_public_id (public_id),
+    ADD UNIQUE INDEX idx_orders_number (number),
+    ADD INDEX idx_orders_parent_order_id (parent_order_id),
+    ADD INDEX idx_orders_merged_into_id (merged_into_id);
+
+ALTER TABLE order_items
+    ADD COLUMN discount DOUBLE NOT NULL DEFAULT 0,
+    ADD COLUMN tax_class VARCHAR(20),
+    ADD COLUMN tax_rate DOUBLE NOT NULL DEFAULT 0,
+    ADD COLUMN tax_amount DOUBLE NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS order_item_discounts (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    order_item_id BIGINT UNSIGNED NOT NULL,
+    promotion_id BIGINT UNSIGNED NOT NULL,
+    code VARCHAR(50),
+    description LONGTEXT,
+    type VARCHAR(20) NOT NULL,
+    amount DOUBLE NOT NULL,
+    CONSTRAINT fk_order_items_discounts FOREIGN KEY (order_item_id) REFERENCES order_items (id),
+    INDEX idx_order_item_discounts_order_item_id (order_item_id)
+);
+
+CREATE TABLE IF NOT EXISTS promotions (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    code VARCHAR(50),
+    name LONGTEXT NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    value DOUBLE,
+    buy_quantity BIGINT,
+    get_quantity BIGINT,
+    product_id BIGINT UNSIGNED,
+    category VARCHAR(100),
+    min_subtotal DOUBLE,
+    max_uses BIGINT,
+    max_uses_per_user BIGINT,
+    used_count BIGINT NOT NULL DEFAULT 0,
+    starts_at DATETIME(3),
+    ends_at DATETIME(3),
+    stackable BOOLEAN,
+    priority BIGINT,
+    disabled BOOLEAN,
+    created_at DATETIME(3),
+    UNIQUE INDEX idx_promotions_code (code)
+);
+
+CREATE TABLE IF NOT EXISTS promotion_redemptions (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    promotion_id BIGINT UNSIGNED NOT NULL,
+    user_id BIGINT UNSIGNED NOT NULL,
+    order_id BIGINT UNSIGNED NOT NULL,
+    created_at DATETIME(3),
+    INDEX idx_promotion_redemptions_promotion_id (promotion_id),
+    INDEX idx_promotion_redemptions_user_id (user_id),
+    INDEX idx_promotion_redemptions_order_id (order_id)
+);
+
+CREATE TABLE IF NOT EXISTS tax_rules (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    country VARCHAR(2) NOT NULL,
+    region VARCHAR(100),
+    tax_class VARCHAR(20) NOT NULL,
+    name LONGTEXT NOT NULL,
+    rate DOUBLE NOT NULL,
+    INDEX idx_tax_rules_country (country)
+);
+
+CREATE TABLE IF NOT EXISTS order_tax_lines (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    order_id BIGINT UNSIGNED NOT NULL,
+    tax_class VARCHAR(20) NOT NULL,
+    name LONGTEXT NOT NULL,
+    rate DOUBLE NOT NULL,
+    base DOUBLE NOT NULL,
+    amount DOUBLE NOT NULL,
+    CONSTRAINT fk_orders_tax_lines FOREIGN KEY (order_id) REFERENCES orders (id),
+    INDEX idx_order_tax_lines_order_id (order_id)
+);
+
+CREATE TABLE IF NOT EXISTS addresses (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT UNSIGNED NOT NULL,
+    label VARCHAR(50),
+    recipient_name VARCHAR(150),
+    line1 VARCHAR(200),
+    line2 VARCHAR(200),
+    city VARCHAR(100),
+    region VARCHAR(100),
+    postal_code VARCHAR(20),
+    country VARCHAR(2),
+    phone VARCHAR(30),
+    is_default_shipping BOOLEAN,
+    is_default_billing BOOLEAN,
+    created_at DATETIME(3),
+    updated_at DATETIME(3),
+    INDEX idx_addresses_user_id (user_id)
+);
+
+CREATE TABLE IF NOT EXISTS payments (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    order_id BIGINT UNSIGNED NOT NULL,
+    gateway VARCHAR(50) NOT NULL,
+    authorization_id VARCHAR(100),
+    status VARCHAR(20) NOT NULL,
+    amount DOUBLE NOT NULL,
+    captured_amount DOUBLE NOT NULL DEFAULT 0,
+    refunded_amount DOUBLE NOT NULL DEFAULT 0,
+    card_last4 VARCHAR(4),
+    failure_code VARCHAR(50),
+    failure_message LONGTEXT,
+    created_at DATETIME(3),
+    updated_at DATETIME(3),
+    CONSTRAINT fk_orders_payments FOREIGN KEY (order_id) REFERENCES orders (id),
+    INDEX idx_payments_order_id (order_id)
+);
+
+CREATE TABLE IF NOT EXISTS refunds (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    order_id BIGINT UNSIGNED NOT NULL,
+    payment_id BIGINT UNSIGNED NOT NULL,
+    gateway_refund_id VARCHAR(100),
+    amount DOUBLE NOT NULL,
+    reason LONGTEXT,
+    created_at DATETIME(3),
+    INDEX idx_refunds_order_id (order_id)
+);
+
+CREATE TABLE IF NOT EXISTS refund_lines (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    refund_id BIGINT UNSIGNED NOT NULL,
+    order_item_id BIGINT UNSIGNED NOT NULL,
+    quantity BIGINT NOT NULL,
+    amount DOUBLE NOT NULL,
+    restocked BOOLEAN,
+    CONSTRAINT fk_refunds_lines FOREIGN KEY (refund_id) REFERENCES refunds (id),
+    INDEX idx_refund_lines_refund_id (refund_id),
+    INDEX idx_refund_lines_order_item_id (order_item_id)
+);
+
+CREATE TABLE IF NOT EXISTS return_authorizations (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    order_id BIGINT UNSIGNED NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    reason LONGTEXT NOT NULL,
+    refund_id BIGINT UNSIGNED,
+    created_at DATETIME(3),
+    updated_at DATETIME(3),
+    INDEX idx_return_authorizations_order_id (order_id)
+);
+
+CREATE TABLE IF NOT EXISTS return_items (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    return_id BIGINT UNSIGNED NOT NULL,
+    order_item_id BIGINT UNSIGNED NOT NULL,
+    quantity BIGINT NOT NULL,
+    reason LONGTEXT,
+    disposition VARCHAR(20),
+    CONSTRAINT fk_return_authorizations_items FOREIGN KEY (return_id) REFERENCES return_authorizations (id),
+    INDEX idx_return_items_return_id (return_id)
+);
+
+CREATE TABLE IF NOT EXISTS return_transitions (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    return_id BIGINT UNSIGNED NOT NULL,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    note LONGTEXT,
+    created_at DATETIME(3),
+    CONSTRAINT fk_return_authorizations_transitions FOREIGN KEY (return_id) REFERENCES return_authorizations (id),
+    INDEX idx_return_transitions_return_id (return_id)
+);
+
+CREATE TABLE IF NOT EXISTS order_changes (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    order_id BIGINT UNSIGNED NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    product_id BIGINT UNSIGNED NOT NULL,
+    old_quantity BIGINT,
+    new_quantity BIGINT,
+    previous_total DOUBLE,
+    new_total DOUBLE,
+    created_at DATETIME(3),
+    INDEX idx_order_changes_order_id (order_id)
+);
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    event_type VARCHAR(50) NOT NULL,
+    aggregate_type VARCHAR(50) NOT NULL,
+    aggregate_id BIGINT UNSIGNED NOT NULL,
+    payload LONGTEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts BIGINT NOT NULL DEFAULT 0,
+    last_error LONGTEXT,
+    next_attempt_at DATETIME(3) NOT NULL,
+    delivered_at DATETIME(3),
+    created_at DATETIME(3),
+    INDEX idx_outbox_events_event_type (event_type),
+    INDEX idx_outbox_due (status, next_attempt_at)
+);
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    url VARCHAR(500) NOT NULL,
+    event_types LONGTEXT,
+    secret VARCHAR(100) NOT NULL,
+    disabled BOOLEAN,
+    consecutive_failures BIGINT,
+    disabled_at DATETIME(3),
+    created_at DATETIME(3),
+    updated_at DATETIME(3)
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    subscription_id BIGINT UNSIGNED NOT NULL,
+    event_id BIGINT UNSIGNED NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload LONGTEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts BIGINT,
+    next_attempt_at DATETIME(3) NOT NULL,
+    response_status BIGINT,
+    response_body LONGTEXT,
+    last_error LONGTEXT,
+    delivered_at DATETIME(3),
+    created_at DATETIME(3),
+    updated_at DATETIME(3),
+    INDEX idx_webhook_deliveries_subscription_id (subscription_id),
+    INDEX idx_webhook_deliveries_event_id (event_id),
+    INDEX idx_webhook_deliveries_status (status),
+    INDEX idx_webhook_deliveries_next_attempt_at (next_attempt_at)
+);
+
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id BIGINT UNSIGNED PRIMARY KEY,
+    locale VARCHAR(5) NOT NULL,
+    email_enabled BOOLEAN,
+    muted_events LONGTEXT,
+    updated_at DATETIME(3)
+);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT UNSIGNED NOT NULL,
+    order_id BIGINT UNSIGNED NOT NULL,
+    event_id BIGINT UNSIGNED NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    locale VARCHAR(5) NOT NULL,
+    recipient VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    text_body LONGTEXT,
+    html_body LONGTEXT,
+    status VARCHAR(20) NOT NULL,
+    attempts BIGINT,
+    last_error LONGTEXT,
+    next_attempt_at DATETIME(3) NOT NULL,
+    sent_at DATETIME(3),
+    created_at DATETIME(3),
+    updated_at DATETIME(3),
+    INDEX idx_notifications_user_id (user_id),
+    INDEX idx_notifications_order_id (order_id),
+    INDEX idx_notifications_event_id (event_id),
+    INDEX idx_notifications_status (status),
+    INDEX idx_notifications_next_attempt_at (next_attempt_at)
+);
+
+CREATE TABLE IF NOT EXISTS jobs (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    type VARCHAR(100) NOT NULL,
+    payload LONGTEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts BIGINT NOT NULL DEFAULT 0,
+    max_attempts BIGINT NOT NULL,
+    last_error LONGTEXT,
+    run_at DATETIME(3) NOT NULL,
+    locked_at DATETIME(3),
+    finished_at DATETIME(3),
+    unique_key VARCHAR(200),
+    created_at DATETIME(3),
+    updated_at DATETIME(3),
+    INDEX idx_jobs_type (type),
+    INDEX idx_jobs_due (status, run_at),
+    UNIQUE INDEX idx_jobs_unique_key (unique_key)
+);
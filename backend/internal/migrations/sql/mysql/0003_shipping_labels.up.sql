@@ -0,0 +1,13 @@
+-- Etiquetas del transportista local, para que el seguimiento sobreviva a los reinicios.
+
+CREATE TABLE IF NOT EXISTS shipping_labels (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    tracking_number VARCHAR(100) NOT NULL,
+    reference VARCHAR(100),
+    method VARCHAR(50),
+    status VARCHAR(30) NOT NULL,
+    description LONGTEXT,
+    created_at DATETIME(3),
+    updated_at DATETIME(3),
+    UNIQUE INDEX idx_shipping_labels_tracking_number (tracking_number)
+);
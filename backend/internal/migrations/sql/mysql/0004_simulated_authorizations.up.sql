@@ -0,0 +1,13 @@
+-- Autorizaciones del simulador de pagos, para que capturas y reembolsos sobrevivan a los reinicios.
+
+CREATE TABLE IF NOT EXISTS simulated_authorizations (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    authorization_id VARCHAR(100) NOT NULL,
+    amount DOUBLE NOT NULL,
+    captured DOUBLE NOT NULL DEFAULT 0,
+    refunded DOUBLE NOT NULL DEFAULT 0,
+    voided BOOLEAN NOT NULL DEFAULT false,
+    created_at DATETIME(3),
+    updated_at DATETIME(3),
+    UNIQUE INDEX idx_simulated_authorizations_authorization_id (authorization_id)
+);
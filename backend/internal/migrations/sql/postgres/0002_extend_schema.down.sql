@@ -0,0 +1,70 @@
+DROP TABLE IF EXISTS jobs;
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
+DROP TABLE IF EXISTS outbox_events;
+DROP TABLE IF EXISTS order_changes;
+DROP TABLE IF EXISTS return_transitions;
+DROP TABLE IF EXISTS return_items;
+DROP TABLE IF EXISTS return_authorizations;
+DROP TABLE IF EXISTS refund_lines;
+DROP TABLE IF EXISTS refunds;
+DROP TABLE IF EXISTS payments;
+DROP TABLE IF EXISTS addresses;
+DROP TABLE IF EXISTS order_tax_lines;
+DROP TABLE IF EXISTS tax_rules;
+DROP TABLE IF EXISTS promotion_redemptions;
+DROP TABLE IF EXISTS promotions;
+DROP TABLE IF EXISTS order_item_discounts;
+
+ALTER TABLE order_items
+    DROP COLUMN tax_amount,
+    DROP COLUMN tax_rate,
+    DROP COLUMN tax_class,
+    DROP COLUMN discount;
+
+ALTER TABLE orders
+    DROP CONSTRAINT fk_orders_child_orders,
+    DROP CONSTRAINT fk_orders_merged_orders,
+    DROP COLUMN cancelled_at,
+    DROP COLUMN cancellation_reason,
+    DROP COLUMN cancelled_by,
+    DROP COLUMN merged_into_id,
+    DROP COLUMN parent_order_id,
+    DROP COLUMN label_url,
+    DROP COLUMN tracking_number,
+    DROP COLUMN shipping_cost,
+    DROP COLUMN shipping_method,
+    DROP COLUMN shipping_carrier,
+    DROP COLUMN billing_phone,
+    DROP COLUMN billing_country,
+    DROP COLUMN billing_postal_code,
+    DROP COLUMN billing_region,
+    DROP COLUMN billing_city,
+    DROP COLUMN billing_line2,
+    DROP COLUMN billing_line1,
+    DROP COLUMN billing_recipient_name,
+    DROP COLUMN shipping_phone,
+    DROP COLUMN shipping_country,
+    DROP COLUMN shipping_postal_code,
+    DROP COLUMN shipping_region,
+    DROP COLUMN shipping_city,
+    DROP COLUMN shipping_line2,
+    DROP COLUMN shipping_line1,
+    DROP COLUMN shipping_recipient_name,
+    DROP COLUMN tax_region,
+    DROP COLUMN tax_country,
+    DROP COLUMN tax_total,
+    DROP COLUMN discount_total,
+    DROP COLUMN subtotal,
+    DROP COLUMN number,
+    DROP COLUMN public_id;
+
+ALTER TABLE products
+    DROP COLUMN height_cm,
+    DROP COLUMN width_cm,
+    DROP COLUMN length_cm,
+    DROP COLUMN weight_grams,
+    DROP COLUMN tax_class,
+    DROP COLUMN category;
@@ -0,0 +1,334 @@
+-- Columnas y tablas que se agregaron al esquema inicial: catálogo, descuentos, impuestos,
+-- direcciones, envíos, pagos, devoluciones, eventos, webhooks, notificaciones y jobs.
+
+ALTER TABLE products
+    ADD COLUMN category VARCHAR(100),
+    ADD COLUMN tax_class VARCHAR(20) NOT NULL DEFAULT 'STANDARD',
+    ADD COLUMN weight_grams BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN length_cm DECIMAL NOT NULL DEFAULT 0,
+    ADD COLUMN width_cm DECIMAL NOT NULL DEFAULT 0,
+    ADD COLUMN height_cm DECIMAL NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_products_category ON products (category);
+
+ALTER TABLE orders
+    ADD COLUMN public_id VARCHAR(26),
+    ADD COLUMN number VARCHAR(32),
+    ADD COLUMN subtotal DECIMAL NOT NULL DEFAULT 0,
+    ADD COLUMN discount_total DECIMAL NOT NULL DEFAULT 0,
+    ADD COLUMN tax_total DECIMAL NOT NULL DEFAULT 0,
+    ADD COLUMN tax_country VARCHAR(2),
+    ADD COLUMN tax_region VARCHAR(100),
+    ADD COLUMN shipping_recipient_name VARCHAR(150),
+    ADD COLUMN shipping_line1 VARCHAR(200),
+    ADD COLUMN shipping_line2 VARCHAR(200),
+    ADD COLUMN shipping_city VARCHAR(100),
+    ADD COLUMN shipping_region VARCHAR(100),
+    ADD COLUMN shipping_postal_code VARCHAR(20),
+    ADD COLUMN shipping_country VARCHAR(2),
+    ADD COLUMN shipping_phone VARCHAR(30),
+    ADD COLUMN billing_recipient_name VARCHAR(150),
+    ADD COLUMN billing_line1 VARCHAR(200),
+    ADD COLUMN billing_line2 VARCHAR(200),
+    ADD COLUMN billing_city VARCHAR(100),
+    ADD COLUMN billing_region VARCHAR(100),
+    ADD COLUMN billing_postal_code VARCHAR(20),
+    ADD COLUMN billing_country VARCHAR(2),
+    ADD COLUMN billing_phone VARCHAR(30),
+    ADD COLUMN shipping_carrier VARCHAR(50),
+    ADD COLUMN shipping_method VARCHAR(50),
+    ADD COLUMN shipping_cost DECIMAL NOT NULL DEFAULT 0,
+    ADD COLUMN tracking_number VARCHAR(100),
+    ADD COLUMN label_url VARCHAR(255),
+    ADD COLUMN parent_order_id BIGINT,
+    ADD COLUMN merged_into_id BIGINT,
+    ADD COLUMN cancelled_by VARCHAR(50),
+    ADD COLUMN cancellation_reason TEXT,
+    ADD COLUMN cancelled_at TIMESTAMPTZ,
+    ADD CONSTRAINT fk_orders_child_orders FOREIGN KEY (parent_order_id) REFERENCES orders (id),
+    ADD CONSTRAINT fk_orders_merged_orders FOREIGN KEY (merged_into_id) REFERENCES orders (id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_public_id ON orders (public_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_number ON orders (number);
+CREATE INDEX IF NOT EXISTS idx_orders_parent_order_id ON orders (parent_order_id);
+CREATE INDEX IF NOT EXISTS idx_orders_merged_into_id ON orders (merged_into_id);
+
+ALTER TABLE order_items
+    ADD COLUMN discount DECIMAL NOT NULL DEFAULT 0,
+    ADD COLUMN tax_class VARCHAR(20),
+    ADD COLUMN tax_rate DECIMAL NOT NULL DEFAULT 0,
+    ADD COLUMN tax_amount DECIMAL NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS order_item_discounts (
+    id BIGSERIAL PRIMARY KEY,
+    order_item_id BIGINT NOT NULL,
+    promotion_id BIGINT NOT NULL,
+    code VARCHAR(50),
+    description TEXT,
+    type VARCHAR(20) NOT NULL,
+    amount DECIMAL NOT NULL,
+    CONSTRAINT fk_order_items_discounts FOREIGN KEY (order_item_id) REFERENCES order_items (id)
+);
+CREATE INDEX IF NOT EXISTS idx_order_item_discounts_order_item_id ON order_item_discounts (order_item_id);
+
+CREATE TABLE IF NOT EXISTS promotions (
+    id BIGSERIAL PRIMARY KEY,
+    code VARCHAR(50),
+    name TEXT NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    value DECIMAL,
+    buy_quantity BIGINT,
+    get_quantity BIGINT,
+    product_id BIGINT,
+    category VARCHAR(100),
+    min_subtotal DECIMAL,
+    max_uses BIGINT,
+    max_uses_per_user BIGINT,
+    used_count BIGINT NOT NULL DEFAULT 0,
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ,
+    stackable BOOLEAN,
+    priority BIGINT,
+    disabled BOOLEAN,
+    created_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_promotions_code ON promotions (code);
+
+CREATE TABLE IF NOT EXISTS promotion_redemptions (
+    id BIGSERIAL PRIMARY KEY,
+    promotion_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    order_id BIGINT NOT NULL,
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_promotion_redemptions_promotion_id ON promotion_redemptions (promotion_id);
+CREATE INDEX IF NOT EXISTS idx_promotion_redemptions_user_id ON promotion_redemptions (user_id);
+CREATE INDEX IF NOT EXISTS idx_promotion_redemptions_order_id ON promotion_redemptions (order_id);
+
+CREATE TABLE IF NOT EXISTS tax_rules (
+    id BIGSERIAL PRIMARY KEY,
+    country VARCHAR(2) NOT NULL,
+    region VARCHAR(100),
+    tax_class VARCHAR(20) NOT NULL,
+    name TEXT NOT NULL,
+    rate DECIMAL NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_tax_rules_country ON tax_rules (country);
+
+CREATE TABLE IF NOT EXISTS order_tax_lines (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL,
+    tax_class VARCHAR(20) NOT NULL,
+    name TEXT NOT NULL,
+    rate DECIMAL NOT NULL,
+    base DECIMAL NOT NULL,
+    amount DECIMAL NOT NULL,
+    CONSTRAINT fk_orders_tax_lines FOREIGN KEY (order_id) REFERENCES orders (id)
+);
+CREATE INDEX IF NOT EXISTS idx_order_tax_lines_order_id ON order_tax_lines (order_id);
+
+CREATE TABLE IF NOT EXISTS addresses (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    label VARCHAR(50),
+    recipient_name VARCHAR(150),
+    line1 VARCHAR(200),
+    line2 VARCHAR(200),
+    city VARCHAR(100),
+    region VARCHAR(100),
+    postal_code VARCHAR(20),
+    country VARCHAR(2),
+    phone VARCHAR(30),
+    is_default_shipping BOOLEAN,
+    is_default_billing BOOLEAN,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_addresses_user_id ON addresses (user_id);
+
+CREATE TABLE IF NOT EXISTS payments (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL,
+    gateway VARCHAR(50) NOT NULL,
+    authorization_id VARCHAR(100),
+    status VARCHAR(20) NOT NULL,
+    amount DECIMAL NOT NULL,
+    captured_amount DECIMAL NOT NULL DEFAULT 0,
+    refunded_amount DECIMAL NOT NULL DEFAULT 0,
+    card_last4 VARCHAR(4),
+    failure_code VARCHAR(50),
+    failure_message TEXT,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    CONSTRAINT fk_orders_payments FOREIGN KEY (order_id) REFERENCES orders (id)
+);
+CREATE INDEX IF NOT EXISTS idx_payments_order_id ON payments (order_id);
+
+CREATE TABLE IF NOT EXISTS refunds (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL,
+    payment_id BIGINT NOT NULL,
+    gateway_refund_id VARCHAR(100),
+    amount DECIMAL NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_refunds_order_id ON refunds (order_id);
+
+CREATE TABLE IF NOT EXISTS refund_lines (
+    id BIGSERIAL PRIMARY KEY,
+    refund_id BIGINT NOT NULL,
+    order_item_id BIGINT NOT NULL,
+    quantity BIGINT NOT NULL,
+    amount DECIMAL NOT NULL,
+    restocked BOOLEAN,
+    CONSTRAINT fk_refunds_lines FOREIGN KEY (refund_id) REFERENCES refunds (id)
+);
+CREATE INDEX IF NOT EXISTS idx_refund_lines_refund_id ON refund_lines (refund_id);
+CREATE INDEX IF NOT EXISTS idx_refund_lines_order_item_id ON refund_lines (order_item_id);
+
+CREATE TABLE IF NOT EXISTS return_authorizations (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    reason TEXT NOT NULL,
+    refund_id BIGINT,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_return_authorizations_order_id ON return_authorizations (order_id);
+
+CREATE TABLE IF NOT EXISTS return_items (
+    id BIGSERIAL PRIMARY KEY,
+    return_id BIGINT NOT NULL,
+    order_item_id BIGINT NOT NULL,
+    quantity BIGINT NOT NULL,
+    reason TEXT,
+    disposition VARCHAR(20),
+    CONSTRAINT fk_return_authorizations_items FOREIGN KEY (return_id) REFERENCES return_authorizations (id)
+);
+CREATE INDEX IF NOT EXISTS idx_return_items_return_id ON return_items (return_id);
+
+CREATE TABLE IF NOT EXISTS return_transitions (
+    id BIGSERIAL PRIMARY KEY,
+    return_id BIGINT NOT NULL,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    note TEXT,
+    created_at TIMESTAMPTZ,
+    CONSTRAINT fk_return_authorizations_transitions FOREIGN KEY (return_id) REFERENCES return_authorizations (id)
+);
+CREATE INDEX IF NOT EXISTS idx_return_transitions_return_id ON return_transitions (return_id);
+
+CREATE TABLE IF NOT EXISTS order_changes (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    product_id BIGINT NOT NULL,
+    old_quantity BIGINT,
+    new_quantity BIGINT,
+    previous_total DECIMAL,
+    new_total DECIMAL,
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_order_changes_order_id ON order_changes (order_id);
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(50) NOT NULL,
+    aggregate_type VARCHAR(50) NOT NULL,
+    aggregate_id BIGINT NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts BIGINT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    delivered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_event_type ON outbox_events (event_type);
+CREATE INDEX IF NOT EXISTS idx_outbox_due ON outbox_events (status, next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id BIGSERIAL PRIMARY KEY,
+    url VARCHAR(500) NOT NULL,
+    event_types TEXT,
+    secret VARCHAR(100) NOT NULL,
+    disabled BOOLEAN,
+    consecutive_failures BIGINT,
+    disabled_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id BIGINT NOT NULL,
+    event_id BIGINT NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts BIGINT,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    response_status BIGINT,
+    response_body TEXT,
+    last_error TEXT,
+    delivered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription_id ON webhook_deliveries (subscription_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_event_id ON webhook_deliveries (event_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_status ON webhook_deliveries (status);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_next_attempt_at ON webhook_deliveries (next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id BIGINT PRIMARY KEY,
+    locale VARCHAR(5) NOT NULL,
+    email_enabled BOOLEAN,
+    muted_events TEXT,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    order_id BIGINT NOT NULL,
+    event_id BIGINT NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    locale VARCHAR(5) NOT NULL,
+    recipient VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    text_body TEXT,
+    html_body TEXT,
+    status VARCHAR(20) NOT NULL,
+    attempts BIGINT,
+    last_error TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications (user_id);
+CREATE INDEX IF NOT EXISTS idx_notifications_order_id ON notifications (order_id);
+CREATE INDEX IF NOT EXISTS idx_notifications_event_id ON notifications (event_id);
+CREATE INDEX IF NOT EXISTS idx_notifications_status ON notifications (status);
+CREATE INDEX IF NOT EXISTS idx_notifications_next_attempt_at ON notifications (next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS jobs (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(100) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts BIGINT NOT NULL DEFAULT 0,
+    max_attempts BIGINT NOT NULL,
+    last_error TEXT,
+    run_at TIMESTAMPTZ NOT NULL,
+    locked_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ,
+    unique_key VARCHAR(200),
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_jobs_type ON jobs (type);
+CREATE INDEX IF NOT EXISTS idx_jobs_due ON jobs (status, run_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_unique_key ON jobs (unique_key);
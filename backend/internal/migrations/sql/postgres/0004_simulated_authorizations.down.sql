@@ -0,0 +1 @@
+DROP TABLE IF EXISTS simulated_authorizations;
@@ -0,0 +1,13 @@
+-- Autorizaciones del simulador de pagos, para que capturas y reembolsos sobrevivan a los reinicios.
+
+CREATE TABLE IF NOT EXISTS simulated_authorizations (
+    id BIGSERIAL PRIMARY KEY,
+    authorization_id VARCHAR(100) NOT NULL,
+    amount DECIMAL NOT NULL,
+    captured DECIMAL NOT NULL DEFAULT 0,
+    refunded DECIMAL NOT NULL DEFAULT 0,
+    voided BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_simulated_authorizations_authorization_id ON simulated_authorizations (authorization_id);
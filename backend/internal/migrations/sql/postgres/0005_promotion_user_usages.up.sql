@@ -0,0 +1,12 @@
+-- Usos de cada promoción por usuario: se reservan con un UPDATE condicional, así dos pedidos
+-- simultáneos del mismo usuario no superan max_uses_per_user. Arranca con los usos ya registrados.
+
+CREATE TABLE IF NOT EXISTS promotion_user_usages (
+    promotion_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    used_count BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (promotion_id, user_id)
+);
+
+INSERT INTO promotion_user_usages (promotion_id, user_id, used_count)
+SELECT promotion_id, user_id, COUNT(*) FROM promotion_redemptions GROUP BY promotion_id, user_id;
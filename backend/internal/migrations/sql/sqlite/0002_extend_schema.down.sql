@@ -0,0 +1,70 @@
+DROP TABLE IF EXISTS jobs;
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
+DROP TABLE IF EXISTS outbox_events;
+DROP TABLE IF EXISTS order_changes;
+DROP TABLE IF EXISTS return_transitions;
+DROP TABLE IF EXISTS return_items;
+DROP TABLE IF EXISTS return_authorizations;
+DROP TABLE IF EXISTS refund_lines;
+DROP TABLE IF EXISTS refunds;
+DROP TABLE IF EXISTS payments;
+DROP TABLE IF EXISTS addresses;
+DROP TABLE IF EXISTS order_tax_lines;
+DROP TABLE IF EXISTS tax_rules;
+DROP TABLE IF EXISTS promotion_redemptions;
+DROP TABLE IF EXISTS promotions;
+DROP TABLE IF EXISTS order_item_discounts;
+
+ALTER TABLE order_items DROP COLUMN tax_amount;
+ALTER TABLE order_items DROP COLUMN tax_rate;
+ALTER TABLE order_items DROP COLUMN tax_class;
+ALTER TABLE order_items DROP COLUMN discount;
+
+DROP INDEX IF EXISTS idx_orders_public_id;
+DROP INDEX IF EXISTS idx_orders_number;
+DROP INDEX IF EXISTS idx_orders_parent_order_id;
+DROP INDEX IF EXISTS idx_orders_merged_into_id;
+ALTER TABLE orders DROP COLUMN cancelled_at;
+ALTER TABLE orders DROP COLUMN cancellation_reason;
+ALTER TABLE orders DROP COLUMN cancelled_by;
+ALTER TABLE orders DROP COLUMN merged_into_id;
+ALTER TABLE orders DROP COLUMN parent_order_id;
+ALTER TABLE orders DROP COLUMN label_url;
+ALTER TABLE orders DROP COLUMN tracking_number;
+ALTER TABLE orders DROP COLUMN shipping_cost;
+ALTER TABLE orders DROP COLUMN shipping_method;
+ALTER TABLE orders DROP COLUMN shipping_carrier;
+ALTER TABLE orders DROP COLUMN billing_phone;
+ALTER TABLE orders DROP COLUMN billing_country;
+ALTER TABLE orders DROP COLUMN billing_postal_code;
+ALTER TABLE orders DROP COLUMN billing_region;
+ALTER TABLE orders DROP COLUMN billing_city;
+ALTER TABLE orders DROP COLUMN billing_line2;
+ALTER TABLE orders DROP COLUMN billing_line1;
+ALTER TABLE orders DROP COLUMN billing_recipient_name;
+ALTER TABLE orders DROP COLUMN shipping_phone;
+ALTER TABLE orders DROP COLUMN shipping_country;
+ALTER TABLE orders DROP COLUMN shipping_postal_code;
+ALTER TABLE orders DROP COLUMN shipping_region;
+ALTER TABLE orders DROP COLUMN shipping_city;
+ALTER TABLE orders DROP COLUMN shipping_line2;
+ALTER TABLE orders DROP COLUMN shipping_line1;
+ALTER TABLE orders DROP COLUMN shipping_recipient_name;
+ALTER TABLE orders DROP COLUMN tax_region;
+ALTER TABLE orders DROP COLUMN tax_country;
+ALTER TABLE orders DROP COLUMN tax_total;
+ALTER TABLE orders DROP COLUMN discount_total;
+ALTER TABLE orders DROP COLUMN subtotal;
+ALTER TABLE orders DROP COLUMN number;
+ALTER TABLE orders DROP COLUMN public_id;
+
+DROP INDEX IF EXISTS idx_products_category;
+ALTER TABLE products DROP COLUMN height_cm;
+ALTER TABLE products DROP COLUMN width_cm;
+ALTER TABLE products DROP COLUMN length_cm;
+ALTER TABLE products DROP COLUMN weight_grams;
+ALTER TABLE products DROP COLUMN tax_class;
+ALTER TABLE products DROP COLUMN category;
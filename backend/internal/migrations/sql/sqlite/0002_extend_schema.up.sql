@@ -0,0 +1,330 @@
+-- Columnas y tablas que se agregaron al esquema inicial, equivalente al de postgres y mysql.
+-- SQLite agrega una columna por ALTER TABLE y no puede borrar columnas con FOREIGN KEY,
+-- así que parent_order_id y merged_into_id no declaran la referencia.
+
+ALTER TABLE products ADD COLUMN category VARCHAR(100);
+ALTER TABLE products ADD COLUMN tax_class VARCHAR(20) NOT NULL DEFAULT 'STANDARD';
+ALTER TABLE products ADD COLUMN weight_grams INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN length_cm REAL NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN width_cm REAL NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN height_cm REAL NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_products_category ON products (category);
+
+ALTER TABLE orders ADD COLUMN public_id VARCHAR(26);
+ALTER TABLE orders ADD COLUMN number VARCHAR(32);
+ALTER TABLE orders ADD COLUMN subtotal REAL NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN discount_total REAL NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN tax_total REAL NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN tax_country VARCHAR(2);
+ALTER TABLE orders ADD COLUMN tax_region VARCHAR(100);
+ALTER TABLE orders ADD COLUMN shipping_recipient_name VARCHAR(150);
+ALTER TABLE orders ADD COLUMN shipping_line1 VARCHAR(200);
+ALTER TABLE orders ADD COLUMN shipping_line2 VARCHAR(200);
+ALTER TABLE orders ADD COLUMN shipping_city VARCHAR(100);
+ALTER TABLE orders ADD COLUMN shipping_region VARCHAR(100);
+ALTER TABLE orders ADD COLUMN shipping_postal_code VARCHAR(20);
+ALTER TABLE orders ADD COLUMN shipping_country VARCHAR(2);
+ALTER TABLE orders ADD COLUMN shipping_phone VARCHAR(30);
+ALTER TABLE orders ADD COLUMN billing_recipient_name VARCHAR(150);
+ALTER TABLE orders ADD COLUMN billing_line1 VARCHAR(200);
+ALTER TABLE orders ADD COLUMN billing_line2 VARCHAR(200);
+ALTER TABLE orders ADD COLUMN billing_city VARCHAR(100);
+ALTER TABLE orders ADD COLUMN billing_region VARCHAR(100);
+ALTER TABLE orders ADD COLUMN billing_postal_code VARCHAR(20);
+ALTER TABLE orders ADD COLUMN billing_country VARCHAR(2);
+ALTER TABLE orders ADD COLUMN billing_phone VARCHAR(30);
+ALTER TABLE orders ADD COLUMN shipping_carrier VARCHAR(50);
+ALTER TABLE orders ADD COLUMN shipping_method VARCHAR(50);
+ALTER TABLE orders ADD COLUMN shipping_cost REAL NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN tracking_number VARCHAR(100);
+ALTER TABLE orders ADD COLUMN label_url VARCHAR(255);
+ALTER TABLE orders ADD COLUMN parent_order_id INTEGER;
+ALTER TABLE orders ADD COLUMN merged_into_id INTEGER;
+ALTER TABLE orders ADD COLUMN cancelled_by VARCHAR(50);
+ALTER TABLE orders ADD COLUMN cancellation_reason TEXT;
+ALTER TABLE orders ADD COLUMN cancelled_at DATETIME;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_public_id ON orders (public_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_number ON orders (number);
+CREATE INDEX IF NOT EXISTS idx_orders_parent_order_id ON orders (parent_order_id);
+CREATE INDEX IF NOT EXISTS idx_orders_merged_into_id ON orders (merged_into_id);
+
+ALTER TABLE order_items ADD COLUMN discount REAL NOT NULL DEFAULT 0;
+ALTER TABLE order_items ADD COLUMN tax_class VARCHAR(20);
+ALTER TABLE order_items ADD COLUMN tax_rate REAL NOT NULL DEFAULT 0;
+ALTER TABLE order_items ADD COLUMN tax_amount REAL NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS order_item_discounts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    order_item_id INTEGER NOT NULL,
+    promotion_id INTEGER NOT NULL,
+    code VARCHAR(50),
+    description TEXT,
+    type VARCHAR(20) NOT NULL,
+    amount REAL NOT NULL,
+    CONSTRAINT fk_order_items_discounts FOREIGN KEY (order_item_id) REFERENCES order_items (id)
+);
+CREATE INDEX IF NOT EXISTS idx_order_item_discounts_order_item_id ON order_item_discounts (order_item_id);
+
+CREATE TABLE IF NOT EXISTS promotions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    code VARCHAR(50),
+    name TEXT NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    value REAL,
+    buy_quantity INTEGER,
+    get_quantity INTEGER,
+    product_id INTEGER,
+    category VARCHAR(100),
+    min_subtotal REAL,
+    max_uses INTEGER,
+    max_uses_per_user INTEGER,
+    used_count INTEGER NOT NULL DEFAULT 0,
+    starts_at DATETIME,
+    ends_at DATETIME,
+    stackable NUMERIC,
+    priority INTEGER,
+    disabled NUMERIC,
+    created_at DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_promotions_code ON promotions (code);
+
+CREATE TABLE IF NOT EXISTS promotion_redemptions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    promotion_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    order_id INTEGER NOT NULL,
+    created_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_promotion_redemptions_promotion_id ON promotion_redemptions (promotion_id);
+CREATE INDEX IF NOT EXISTS idx_promotion_redemptions_user_id ON promotion_redemptions (user_id);
+CREATE INDEX IF NOT EXISTS idx_promotion_redemptions_order_id ON promotion_redemptions (order_id);
+
+CREATE TABLE IF NOT EXISTS tax_rules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    country VARCHAR(2) NOT NULL,
+    region VARCHAR(100),
+    tax_class VARCHAR(20) NOT NULL,
+    name TEXT NOT NULL,
+    rate REAL NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_tax_rules_country ON tax_rules (country);
+
+CREATE TABLE IF NOT EXISTS order_tax_lines (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    order_id INTEGER NOT NULL,
+    tax_class VARCHAR(20) NOT NULL,
+    name TEXT NOT NULL,
+    rate REAL NOT NULL,
+    base REAL NOT NULL,
+    amount REAL NOT NULL,
+    CONSTRAINT fk_orders_tax_lines FOREIGN KEY (order_id) REFERENCES orders (id)
+);
+CREATE INDEX IF NOT EXISTS idx_order_tax_lines_order_id ON order_tax_lines (order_id);
+
+CREATE TABLE IF NOT EXISTS addresses (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    label VARCHAR(50),
+    recipient_name VARCHAR(150),
+    line1 VARCHAR(200),
+    line2 VARCHAR(200),
+    city VARCHAR(100),
+    region VARCHAR(100),
+    postal_code VARCHAR(20),
+    country VARCHAR(2),
+    phone VARCHAR(30),
+    is_default_shipping NUMERIC,
+    is_default_billing NUMERIC,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_addresses_user_id ON addresses (user_id);
+
+CREATE TABLE IF NOT EXISTS payments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    order_id INTEGER NOT NULL,
+    gateway VARCHAR(50) NOT NULL,
+    authorization_id VARCHAR(100),
+    status VARCHAR(20) NOT NULL,
+    amount REAL NOT NULL,
+    captured_amount REAL NOT NULL DEFAULT 0,
+    refunded_amount REAL NOT NULL DEFAULT 0,
+    card_last4 VARCHAR(4),
+    failure_code VARCHAR(50),
+    failure_message TEXT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    CONSTRAINT fk_orders_payments FOREIGN KEY (order_id) REFERENCES orders (id)
+);
+CREATE INDEX IF NOT EXISTS idx_payments_order_id ON payments (order_id);
+
+CREATE TABLE IF NOT EXISTS refunds (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    order_id INTEGER NOT NULL,
+    payment_id INTEGER NOT NULL,
+    gateway_refund_id VARCHAR(100),
+    amount REAL NOT NULL,
+    reason TEXT,
+    created_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_refunds_order_id ON refunds (order_id);
+
+CREATE TABLE IF NOT EXISTS refund_lines (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    refund_id INTEGER NOT NULL,
+    order_item_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL,
+    amount REAL NOT NULL,
+    restocked NUMERIC,
+    CONSTRAINT fk_refunds_lines FOREIGN KEY (refund_id) REFERENCES refunds (id)
+);
+CREATE INDEX IF NOT EXISTS idx_refund_lines_refund_id ON refund_lines (refund_id);
+CREATE INDEX IF NOT EXISTS idx_refund_lines_order_item_id ON refund_lines (order_item_id);
+
+CREATE TABLE IF NOT EXISTS return_authorizations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    order_id INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    reason TEXT NOT NULL,
+    refund_id INTEGER,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_return_authorizations_order_id ON return_authorizations (order_id);
+
+CREATE TABLE IF NOT EXISTS return_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    return_id INTEGER NOT NULL,
+    order_item_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL,
+    reason TEXT,
+    disposition VARCHAR(20),
+    CONSTRAINT fk_return_authorizations_items FOREIGN KEY (return_id) REFERENCES return_authorizations (id)
+);
+CREATE INDEX IF NOT EXISTS idx_return_items_return_id ON return_items (return_id);
+
+CREATE TABLE IF NOT EXISTS return_transitions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    return_id INTEGER NOT NULL,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    note TEXT,
+    created_at DATETIME,
+    CONSTRAINT fk_return_authorizations_transitions FOREIGN KEY (return_id) REFERENCES return_authorizations (id)
+);
+CREATE INDEX IF NOT EXISTS idx_return_transitions_return_id ON return_transitions (return_id);
+
+CREATE TABLE IF NOT EXISTS order_changes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    order_id INTEGER NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    product_id INTEGER NOT NULL,
+    old_quantity INTEGER,
+    new_quantity INTEGER,
+    previous_total REAL,
+    new_total REAL,
+    created_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_order_changes_order_id ON order_changes (order_id);
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_type VARCHAR(50) NOT NULL,
+    aggregate_type VARCHAR(50) NOT NULL,
+    aggregate_id INTEGER NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at DATETIME NOT NULL,
+    delivered_at DATETIME,
+    created_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_event_type ON outbox_events (event_type);
+CREATE INDEX IF NOT EXISTS idx_outbox_due ON outbox_events (status, next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url VARCHAR(500) NOT NULL,
+    event_types TEXT,
+    secret VARCHAR(100) NOT NULL,
+    disabled NUMERIC,
+    consecutive_failures INTEGER,
+    disabled_at DATETIME,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    subscription_id INTEGER NOT NULL,
+    event_id INTEGER NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts INTEGER,
+    next_attempt_at DATETIME NOT NULL,
+    response_status INTEGER,
+    response_body TEXT,
+    last_error TEXT,
+    delivered_at DATETIME,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription_id ON webhook_deliveries (subscription_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_event_id ON webhook_deliveries (event_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_status ON webhook_deliveries (status);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_next_attempt_at ON webhook_deliveries (next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id INTEGER PRIMARY KEY,
+    locale VARCHAR(5) NOT NULL,
+    email_enabled NUMERIC,
+    muted_events TEXT,
+    updated_at DATETIME
+);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    order_id INTEGER NOT NULL,
+    event_id INTEGER NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    locale VARCHAR(5) NOT NULL,
+    recipient VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    text_body TEXT,
+    html_body TEXT,
+    status VARCHAR(20) NOT NULL,
+    attempts INTEGER,
+    last_error TEXT,
+    next_attempt_at DATETIME NOT NULL,
+    sent_at DATETIME,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications (user_id);
+CREATE INDEX IF NOT EXISTS idx_notifications_order_id ON notifications (order_id);
+CREATE INDEX IF NOT EXISTS idx_notifications_event_id ON notifications (event_id);
+CREATE INDEX IF NOT EXISTS idx_notifications_status ON notifications (status);
+CREATE INDEX IF NOT EXISTS idx_notifications_next_attempt_at ON notifications (next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS jobs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    type VARCHAR(100) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL,
+    last_error TEXT,
+    run_at DATETIME NOT NULL,
+    locked_at DATETIME,
+    finished_at DATETIME,
+    unique_key VARCHAR(200),
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_jobs_type ON jobs (type);
+CREATE INDEX IF NOT EXISTS idx_jobs_due ON jobs (status, run_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_unique_key ON jobs (unique_key);
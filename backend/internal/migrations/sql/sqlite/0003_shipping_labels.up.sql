@@ -0,0 +1,13 @@
+-- Etiquetas del transportista local, para que el seguimiento sobreviva a los reinicios.
+
+CREATE TABLE IF NOT EXISTS shipping_labels (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tracking_number VARCHAR(100) NOT NULL,
+    reference VARCHAR(100),
+    method VARCHAR(50),
+    status VARCHAR(30) NOT NULL,
+    description TEXT,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shipping_labels_tracking_number ON shipping_labels (tracking_number);
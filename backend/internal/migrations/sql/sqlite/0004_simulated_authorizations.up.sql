@@ -0,0 +1,13 @@
+-- Autorizaciones del simulador de pagos, para que capturas y reembolsos sobrevivan a los reinicios.
+
+CREATE TABLE IF NOT EXISTS simulated_authorizations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    authorization_id VARCHAR(100) NOT NULL,
+    amount REAL NOT NULL,
+    captured REAL NOT NULL DEFAULT 0,
+    refunded REAL NOT NULL DEFAULT 0,
+    voided NUMERIC NOT NULL DEFAULT false,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_simulated_authorizations_authorization_id ON simulated_authorizations (authorization_id);
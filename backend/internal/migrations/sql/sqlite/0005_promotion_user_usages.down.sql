@@ -0,0 +1 @@
+DROP TABLE IF EXISTS promotion_user_usages;
@@ -0,0 +1,247 @@
+// Package notifications arma y envía los emails a los clientes.
+//
+// Los templates (es/en, HTML y texto) están embebidos en templates/. Para enviar hay un
+// Mailer SMTP y un Mailbox que guarda los mensajes en disco, pensado para correr en local
+// y para los tests.
+package notifications
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Email es un mensaje con versión de texto y HTML
+type Email struct {
+	From    string
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer envía un email
+type Mailer interface {
+	Send(email Email) error
+}
+
+// SMTPMailer envía por SMTP; usa STARTTLS si el servidor lo ofrece y autenticación PLAIN si hay usuario
+type SMTPMailer struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+	timeout  time.Duration
+}
+
+// SMTPOption configura parámetros opcionales del SMTPMailer
+type SMTPOption func(*SMTPMailer)
+
+// WithSMTPTimeout limita la conexión y el envío de cada email; por defecto 10s
+func WithSMTPTimeout(timeout time.Duration) SMTPOption {
+	return func(m *SMTPMailer) {
+		m.timeout = timeout
+	}
+}
+
+func NewSMTPMailer(host string, port int, username, password, from string, opts ...SMTPOption) *SMTPMailer {
+	m := &SMTPMailer{
+		addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		host:     host,
+		username: username,
+		password: password,
+		from:     from,
+		timeout:  10 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Send hace lo mismo que smtp.SendMail pero con un plazo para toda la conversación, para que un
+// servidor que no responde no deje colgado al worker de notificaciones
+func (m *SMTPMailer) Send(email Email) error {
+	if email.From == "" {
+		email.From = m.from
+	}
+	message, err := BuildMessage(email, time.Now())
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.DialTimeout("tcp", m.addr, m.timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(m.timeout)); err != nil {
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+			return err
+		}
+	}
+	if m.username != "" {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("smtp: server doesn't support AUTH")
+		}
+		if err := client.Auth(smtp.PlainAuth("", m.username, m.password, m.host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(addressOf(email.From)); err != nil {
+		return err
+	}
+	if err := client.Rcpt(addressOf(email.To)); err != nil {
+		return err
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(message); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// mailboxRecent es cuántos mensajes conserva en memoria un mailbox que escribe a disco
+const mailboxRecent = 100
+
+// Mailbox guarda cada email como archivo .eml en un directorio. Sin directorio conserva todos
+// los mensajes en memoria (para los tests); con directorio sólo los últimos mailboxRecent.
+type Mailbox struct {
+	dir  string
+	from string
+
+	mu       sync.Mutex
+	sent     int
+	messages []Email
+}
+
+// NewMailbox crea el mailbox; con dir vacío los mensajes sólo quedan en memoria
+func NewMailbox(dir, from string) (*Mailbox, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("creating mailbox dir: %w", err)
+		}
+	}
+	return &Mailbox{dir: dir, from: from}, nil
+}
+
+func (m *Mailbox) Send(email Email) error {
+	if email.From == "" {
+		email.From = m.from
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sent++
+	if m.dir != "" {
+		now := time.Now()
+		message, err := BuildMessage(email, now)
+		if err != nil {
+			return err
+		}
+		name := fmt.Sprintf("%s-%03d.eml", now.Format("20060102T150405"), m.sent)
+		if err := os.WriteFile(filepath.Join(m.dir, name), message, 0o644); err != nil {
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+		if len(m.messages) == mailboxRecent {
+			m.messages = append(m.messages[:0], m.messages[1:]...)
+		}
+	}
+	m.messages = append(m.messages, email)
+	return nil
+}
+
+// Messages devuelve los emails recibidos por el mailbox; con directorio, sólo los más recientes
+func (m *Mailbox) Messages() []Email {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Email(nil), m.messages...)
+}
+
+// BuildMessage arma el mensaje MIME multipart/alternative con la parte de texto y la HTML
+func BuildMessage(email Email, date time.Time) ([]byte, error) {
+	boundary, err := randomBoundary()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", headerValue(email.From))
+	fmt.Fprintf(&buf, "To: %s\r\n", headerValue(email.To))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", email.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain", email.Text},
+		{"text/html", email.HTML},
+	} {
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		fmt.Fprintf(&buf, "Content-Type: %s; charset=utf-8\r\n", part.contentType)
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		writer := quotedprintable.NewWriter(&buf)
+		if _, err := writer.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+	return buf.Bytes(), nil
+}
+
+func randomBoundary() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// headerValue evita que un valor con saltos de línea agregue headers al mensaje
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
+
+// addressOf extrae la dirección de "Nombre <dir@dominio>"
+func addressOf(value string) string {
+	if start := strings.LastIndex(value, "<"); start >= 0 {
+		if end := strings.LastIndex(value, ">"); end > start {
+			return value[start+1 : end]
+		}
+	}
+	return value
+}
@@ -0,0 +1,179 @@
+package notifications
+
+import (
+	"net"
+	"order-management-system/internal/domain"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testOrder() *domain.Order {
+	return &domain.Order{
+		ID:              42,
+		Total:           1234.5,
+		TrackingNumber:  "TRK-1",
+		ShippingCarrier: "local",
+		ShippingAddress: domain.AddressSnapshot{Line1: "Av. Siempre Viva 742", City: "Córdoba"},
+		Items: []domain.OrderItem{
+			{Product: domain.Product{Name: "Mate <premium>"}, Quantity: 2, Price: 600},
+		},
+	}
+}
+
+func TestRenderer_RendersEveryEventAndLocale(t *testing.T) {
+	renderer, err := NewRenderer()
+	if err != nil {
+		t.Fatalf("Expected templates to parse, got %v", err)
+	}
+
+	data := TemplateData{CustomerName: "Ana", Order: testOrder()}
+	for _, eventType := range TemplateEvents {
+		for _, locale := range []string{domain.LocaleES, domain.LocaleEN} {
+			subject, text, html, err := renderer.Render(eventType, locale, data)
+			if err != nil {
+				t.Fatalf("Expected %s/%s to render, got %v", eventType, locale, err)
+			}
+			if !strings.Contains(subject, "#42") || !strings.Contains(text, "Ana") || !strings.Contains(html, "<strong>#42</strong>") {
+				t.Errorf("Unexpected %s/%s email: %q %q %q", eventType, locale, subject, text, html)
+			}
+		}
+	}
+
+	subject, text, html, _ := renderer.Render(domain.EventOrderConfirmed, domain.LocaleES, data)
+	if subject != "Tu pedido #42 está confirmado" || !strings.Contains(text, "Total: $ 1.234,50") {
+		t.Errorf("Unexpected spanish email: %q %q", subject, text)
+	}
+	if !strings.Contains(html, "Mate &lt;premium&gt;") || !strings.Contains(text, "Mate <premium>") {
+		t.Errorf("Expected HTML escaped and text raw, got %q / %q", html, text)
+	}
+
+	// Con número de pedido el cliente no ve el ID interno
+	number := "ORD-2024-01234567-8"
+	numbered := testOrder()
+	numbered.Number = &number
+	subject, _, html, _ = renderer.Render(domain.EventOrderShipped, domain.LocaleEN, TemplateData{CustomerName: "Ana", Order: numbered})
+	if subject != "Your order ORD-2024-01234567-8 is on its way" || strings.Contains(html, "#42") {
+		t.Errorf("Expected the order number instead of the ID, got %q %q", subject, html)
+	}
+
+	_, text, _, _ = renderer.Render(domain.EventOrderShipped, domain.LocaleEN, data)
+	if !strings.Contains(text, "Tracking number: TRK-1") || !strings.Contains(text, "Shipping to: Av. Siempre Viva 742, Córdoba") {
+		t.Errorf("Unexpected shipped email: %q", text)
+	}
+
+	// Un idioma sin templates usa el español
+	if subject, _, _, _ = renderer.Render(domain.EventOrderCancelled, "pt", data); subject != "Tu pedido #42 fue cancelado" {
+		t.Errorf("Expected fallback to spanish, got %q", subject)
+	}
+	if renderer.Supports(domain.EventOrderCreated) {
+		t.Error("Expected order.created to have no template")
+	}
+}
+
+func TestFormatMoney(t *testing.T) {
+	cases := []struct {
+		locale   string
+		amount   float64
+		expected string
+	}{
+		{domain.LocaleEN, 1234567.891, "$1,234,567.89"},
+		{domain.LocaleES, 1234567.891, "$ 1.234.567,89"},
+		{domain.LocaleES, 5, "$ 5,00"},
+		{domain.LocaleEN, -20.5, "-$20.50"},
+	}
+	for _, c := range cases {
+		if got := formatMoney(c.locale, c.amount); got != c.expected {
+			t.Errorf("formatMoney(%s, %v): expected %s, got %s", c.locale, c.amount, c.expected, got)
+		}
+	}
+}
+
+func TestMailbox_WritesMessages(t *testing.T) {
+	dir := t.TempDir()
+	mailbox, err := NewMailbox(dir, "Tienda <tienda@example.com>")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	err = mailbox.Send(Email{To: "ana@example.com\r\nBcc: evil@example.com", Subject: "Pedido confirmado", Text: "hola", HTML: "<p>hola</p>"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 1 || len(mailbox.Messages()) != 1 {
+		t.Fatalf("Expected 1 stored message, got %d files", len(files))
+	}
+	raw, _ := os.ReadFile(files[0])
+	message := string(raw)
+	for _, expected := range []string{
+		"From: Tienda <tienda@example.com>\r\n",
+		"To: ana@example.comBcc: evil@example.com\r\n",
+		"Subject: Pedido confirmado\r\n",
+		"Content-Type: multipart/alternative;",
+		"Content-Type: text/html; charset=utf-8",
+	} {
+		if !strings.Contains(message, expected) {
+			t.Errorf("Expected message to contain %q, got:\n%s", expected, message)
+		}
+	}
+}
+
+func TestBuildMessage_EncodesNonASCII(t *testing.T) {
+	message, err := BuildMessage(Email{From: "a@example.com", To: "b@example.com", Subject: "Envío", Text: "Dirección", HTML: "<p>Dirección</p>"}, time.Now())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(string(message), "Subject: =?utf-8?q?Env=C3=ADo?=") || !strings.Contains(string(message), "Direcci=C3=B3n") {
+		t.Errorf("Expected encoded subject and body, got:\n%s", message)
+	}
+}
+
+func TestMailbox_KeepsOnlyRecentMessagesWhenWritingToDisk(t *testing.T) {
+	dir := t.TempDir()
+	mailbox, _ := NewMailbox(dir, "tienda@example.com")
+	for i := 0; i < mailboxRecent+5; i++ {
+		if err := mailbox.Send(Email{To: "ana@example.com", Subject: strconv.Itoa(i)}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	messages := mailbox.Messages()
+	if len(files) != mailboxRecent+5 || len(messages) != mailboxRecent {
+		t.Fatalf("Expected %d files and %d messages in memory, got %d and %d", mailboxRecent+5, mailboxRecent, len(files), len(messages))
+	}
+	if messages[0].Subject != "5" {
+		t.Errorf("Expected the oldest messages to be dropped, got %q first", messages[0].Subject)
+	}
+}
+
+func TestSMTPMailer_TimesOutOnSilentServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		// Acepta la conexión pero nunca envía el saludo SMTP
+		conn, err := listener.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(2 * time.Second)
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	mailer := NewSMTPMailer("127.0.0.1", addr.Port, "", "", "tienda@example.com", WithSMTPTimeout(100*time.Millisecond))
+	start := time.Now()
+	if err := mailer.Send(Email{To: "ana@example.com", Subject: "hola"}); err == nil {
+		t.Fatal("Expected a timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected Send to give up after the timeout, took %s", elapsed)
+	}
+}
@@ -0,0 +1,139 @@
+package notifications
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"order-management-system/internal/domain"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// DefaultLocale se usa cuando no hay templates para el idioma pedido
+const DefaultLocale = domain.LocaleES
+
+// TemplateEvents son los eventos de pedido que tienen email
+var TemplateEvents = []domain.EventType{
+	domain.EventOrderConfirmed,
+	domain.EventOrderShipped,
+	domain.EventOrderCancelled,
+}
+
+// TemplateData son los datos disponibles en los templates
+type TemplateData struct {
+	CustomerName string
+	Order        *domain.Order
+}
+
+type localizedTemplates struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// Renderer arma asunto, texto y HTML de un email a partir de los templates embebidos.
+// Cada evento tiene, por idioma, un archivo .txt con los bloques "subject" y "body" y
+// un archivo .html.
+type Renderer struct {
+	templates map[string]localizedTemplates
+}
+
+func NewRenderer() (*Renderer, error) {
+	r := &Renderer{templates: make(map[string]localizedTemplates)}
+	for _, eventType := range TemplateEvents {
+		for _, locale := range []string{domain.LocaleES, domain.LocaleEN} {
+			name := templateName(eventType, locale)
+			funcs := templateFuncs(locale)
+
+			text, err := texttemplate.New(name+".txt").Funcs(funcs).ParseFS(templateFS, "templates/"+name+".txt")
+			if err != nil {
+				return nil, fmt.Errorf("parsing %s.txt: %w", name, err)
+			}
+			if text.Lookup("subject") == nil || text.Lookup("body") == nil {
+				return nil, fmt.Errorf("%s.txt must define subject and body", name)
+			}
+			html, err := htmltemplate.New(name+".html").Funcs(htmltemplate.FuncMap(funcs)).ParseFS(templateFS, "templates/"+name+".html")
+			if err != nil {
+				return nil, fmt.Errorf("parsing %s.html: %w", name, err)
+			}
+			r.templates[name] = localizedTemplates{text: text, html: html}
+		}
+	}
+	return r, nil
+}
+
+// Supports indica si el evento tiene templates de email
+func (r *Renderer) Supports(eventType domain.EventType) bool {
+	_, ok := r.templates[templateName(eventType, DefaultLocale)]
+	return ok
+}
+
+// Render devuelve asunto, texto y HTML del email del evento en el idioma indicado
+func (r *Renderer) Render(eventType domain.EventType, locale string, data TemplateData) (subject, text, html string, err error) {
+	tmpl, ok := r.templates[templateName(eventType, locale)]
+	if !ok {
+		if tmpl, ok = r.templates[templateName(eventType, DefaultLocale)]; !ok {
+			return "", "", "", fmt.Errorf("no email template for %s", eventType)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&buf, "subject", data); err != nil {
+		return "", "", "", err
+	}
+	subject = strings.TrimSpace(buf.String())
+
+	buf.Reset()
+	if err := tmpl.text.ExecuteTemplate(&buf, "body", data); err != nil {
+		return "", "", "", err
+	}
+	text = strings.TrimSpace(buf.String()) + "\n"
+
+	buf.Reset()
+	if err := tmpl.html.Execute(&buf, data); err != nil {
+		return "", "", "", err
+	}
+	return subject, text, buf.String(), nil
+}
+
+// templateName convierte order.confirmed + es en order_confirmed.es
+func templateName(eventType domain.EventType, locale string) string {
+	return strings.ReplaceAll(string(eventType), ".", "_") + "." + locale
+}
+
+func templateFuncs(locale string) texttemplate.FuncMap {
+	return texttemplate.FuncMap{
+		"money": func(amount float64) string { return formatMoney(locale, amount) },
+	}
+}
+
+// formatMoney usa los separadores del idioma: $1,234.50 en inglés y $ 1.234,50 en español
+func formatMoney(locale string, amount float64) string {
+	negative := amount < 0
+	if negative {
+		amount = -amount
+	}
+	formatted := fmt.Sprintf("%.2f", amount)
+	whole, cents := formatted[:len(formatted)-3], formatted[len(formatted)-2:]
+
+	thousands, decimal, prefix := ",", ".", "$"
+	if locale != domain.LocaleEN {
+		thousands, decimal, prefix = ".", ",", "$ "
+	}
+
+	var groups []string
+	for len(whole) > 3 {
+		groups = append([]string{whole[len(whole)-3:]}, groups...)
+		whole = whole[:len(whole)-3]
+	}
+	groups = append([]string{whole}, groups...)
+
+	result := prefix + strings.Join(groups, thousands) + decimal + cents
+	if negative {
+		result = "-" + result
+	}
+	return result
+}
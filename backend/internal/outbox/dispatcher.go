@@ -0,0 +1,256 @@
+// Package outbox entrega a los suscriptores los eventos de dominio guardados en la tabla outbox.
+//
+// La entrega es al menos una vez: un evento se marca DELIVERED sólo cuando todos sus
+// suscriptores lo procesaron sin error; si alguno falla el evento completo se reintenta,
+// por lo que los suscriptores deben ser idempotentes (pueden usar el ID del evento).
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"order-management-system/internal/backoff"
+	"order-management-system/internal/clock"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/logging"
+	"order-management-system/internal/repositories"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultInterval    = time.Second
+	DefaultBatchSize   = 100
+	DefaultMaxAttempts = 10
+	DefaultBaseBackoff = 2 * time.Second
+	DefaultMaxBackoff  = 5 * time.Minute
+)
+
+// Handler procesa un evento; un error provoca el reintento del evento
+type Handler func(ctx context.Context, event domain.OutboxEvent) error
+
+type subscription struct {
+	name       string
+	eventTypes map[domain.EventType]bool
+	handler    Handler
+}
+
+func (s subscription) matches(eventType domain.EventType) bool {
+	return len(s.eventTypes) == 0 || s.eventTypes[eventType]
+}
+
+// Dispatcher lee periódicamente los eventos pendientes y los entrega a los suscriptores
+type Dispatcher struct {
+	repo        repositories.OutboxRepository
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+	now         func() time.Time
+
+	mu            sync.RWMutex
+	subscriptions []subscription
+
+	dispatching sync.Mutex
+	heartbeat   atomic.Int64
+	stop        chan struct{}
+	wake        chan struct{}
+	done        sync.WaitGroup
+}
+
+// Option configura parámetros opcionales del Dispatcher
+type Option func(*Dispatcher)
+
+// WithInterval define cada cuánto se buscan eventos pendientes
+func WithInterval(interval time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.interval = interval
+	}
+}
+
+// WithBatchSize define cuántos eventos se procesan por ciclo
+func WithBatchSize(size int) Option {
+	return func(d *Dispatcher) {
+		d.batchSize = size
+	}
+}
+
+// WithRetry define la cantidad máxima de intentos y el backoff exponencial entre ellos
+func WithRetry(maxAttempts int, baseBackoff, maxBackoff time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.maxAttempts = maxAttempts
+		d.baseBackoff = baseBackoff
+		d.maxBackoff = maxBackoff
+	}
+}
+
+// WithClock reemplaza el reloj usado para programar reintentos
+func WithClock(clk clock.Clock) Option {
+	return func(d *Dispatcher) {
+		d.now = clk.Now
+	}
+}
+
+func NewDispatcher(repo repositories.OutboxRepository, opts ...Option) *Dispatcher {
+	d := &Dispatcher{
+		repo:        repo,
+		interval:    DefaultInterval,
+		batchSize:   DefaultBatchSize,
+		maxAttempts: DefaultMaxAttempts,
+		baseBackoff: DefaultBaseBackoff,
+		maxBackoff:  DefaultMaxBackoff,
+		now:         clock.System().Now,
+		wake:        make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Subscribe registra un suscriptor para los tipos de evento indicados; sin tipos recibe todos
+func (d *Dispatcher) Subscribe(name string, handler Handler, eventTypes ...domain.EventType) {
+	sub := subscription{name: name, handler: handler, eventTypes: make(map[domain.EventType]bool)}
+	for _, eventType := range eventTypes {
+		sub.eventTypes[eventType] = true
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.subscriptions = append(d.subscriptions, sub)
+}
+
+// Start lanza la goroutine de entrega. Stop la detiene y espera el ciclo en curso.
+func (d *Dispatcher) Start() {
+	d.stop = make(chan struct{})
+	d.done.Add(1)
+	go func() {
+		defer d.done.Done()
+		ctx := context.Background()
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+		for {
+			if _, err := d.DispatchPending(ctx); err != nil {
+				logging.For(ctx, "outbox").Error("dispatching events", "error", err)
+			}
+			d.heartbeat.Store(d.now().UnixNano())
+			select {
+			case <-d.stop:
+				return
+			case <-ticker.C:
+			case <-d.wake:
+			}
+		}
+	}()
+}
+
+func (d *Dispatcher) Stop() {
+	if d.stop == nil {
+		return
+	}
+	close(d.stop)
+	d.done.Wait()
+	d.stop = nil
+}
+
+// Notify adelanta el próximo ciclo de entrega sin esperar el intervalo
+func (d *Dispatcher) Notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// LastHeartbeat devuelve cuándo terminó el último ciclo de entrega, o cero si no arrancó
+func (d *Dispatcher) LastHeartbeat() time.Time {
+	if nanos := d.heartbeat.Load(); nanos != 0 {
+		return time.Unix(0, nanos)
+	}
+	return time.Time{}
+}
+
+// Lag devuelve la antigüedad del evento pendiente más viejo, o cero si no hay pendientes
+func (d *Dispatcher) Lag(ctx context.Context) (time.Duration, error) {
+	event, err := d.repo.OldestPending(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("fetching oldest pending event: %w", err)
+	}
+	if event == nil {
+		return 0, nil
+	}
+	if lag := d.now().Sub(event.CreatedAt); lag > 0 {
+		return lag, nil
+	}
+	return 0, nil
+}
+
+// DispatchPending entrega un lote de eventos vencidos y devuelve cuántos quedaron entregados
+func (d *Dispatcher) DispatchPending(ctx context.Context) (int, error) {
+	d.dispatching.Lock()
+	defer d.dispatching.Unlock()
+
+	events, err := d.repo.GetDue(ctx, d.now(), d.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("fetching pending events: %w", err)
+	}
+
+	delivered := 0
+	for i := range events {
+		event := &events[i]
+		eventCtx := logging.WithLogger(ctx, logging.FromContext(ctx).With("event_id", event.ID, "event_type", event.EventType))
+		if failures := d.deliver(eventCtx, *event); len(failures) > 0 {
+			d.scheduleRetry(eventCtx, event, strings.Join(failures, "; "))
+		} else {
+			now := d.now()
+			event.Status = domain.OutboxDelivered
+			event.DeliveredAt = &now
+			event.LastError = ""
+			delivered++
+		}
+		event.Attempts++
+		if err := d.repo.Update(ctx, event); err != nil {
+			return delivered, fmt.Errorf("updating event %d: %w", event.ID, err)
+		}
+	}
+	return delivered, nil
+}
+
+// deliver invoca a los suscriptores del evento y devuelve los errores de los que fallaron
+func (d *Dispatcher) deliver(ctx context.Context, event domain.OutboxEvent) []string {
+	d.mu.RLock()
+	subscriptions := append([]subscription(nil), d.subscriptions...)
+	d.mu.RUnlock()
+
+	var failures []string
+	for _, sub := range subscriptions {
+		if !sub.matches(event.EventType) {
+			continue
+		}
+		if err := safeHandle(ctx, sub.handler, event); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", sub.name, err))
+		}
+	}
+	return failures
+}
+
+func (d *Dispatcher) scheduleRetry(ctx context.Context, event *domain.OutboxEvent, lastError string) {
+	event.LastError = lastError
+	if event.Attempts+1 >= d.maxAttempts {
+		event.Status = domain.OutboxDead
+		logging.For(ctx, "outbox").Error("event gave up", "attempts", event.Attempts+1, "error", lastError)
+		return
+	}
+	event.NextAttemptAt = d.now().Add(backoff.Exponential(d.baseBackoff, d.maxBackoff, event.Attempts+1))
+}
+
+// safeHandle convierte un panic del suscriptor en error para no detener el dispatcher
+func safeHandle(ctx context.Context, handler Handler, event domain.OutboxEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, event)
+}
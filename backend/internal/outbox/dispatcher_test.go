@@ -0,0 +1,196 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"order-management-system/internal/clock"
+	"order-management-system/internal/domain"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memoryOutbox struct {
+	mu     sync.Mutex
+	events []domain.OutboxEvent
+}
+
+func (m *memoryOutbox) Create(ctx context.Context, event *domain.OutboxEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	event.ID = uint(len(m.events) + 1)
+	m.events = append(m.events, *event)
+	return nil
+}
+
+func (m *memoryOutbox) GetDue(ctx context.Context, now time.Time, limit int) ([]domain.OutboxEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var due []domain.OutboxEvent
+	for _, e := range m.events {
+		if e.Status == domain.OutboxPending && !e.NextAttemptAt.After(now) && len(due) < limit {
+			due = append(due, e)
+		}
+	}
+	return due, nil
+}
+
+func (m *memoryOutbox) OldestPending(ctx context.Context) (*domain.OutboxEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.events {
+		if e.Status == domain.OutboxPending {
+			return &e, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryOutbox) Update(ctx context.Context, event *domain.OutboxEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events[event.ID-1] = *event
+	return nil
+}
+
+func (m *memoryOutbox) get(id uint) domain.OutboxEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.events[id-1]
+}
+
+func addEvent(t *testing.T, repo *memoryOutbox, eventType domain.EventType, at time.Time) *domain.OutboxEvent {
+	t.Helper()
+	ctx := context.Background()
+	event, err := domain.NewOutboxEvent(eventType, "order", 1, domain.OrderEvent{OrderID: 1, Status: domain.StatusConfirmed})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	event.NextAttemptAt = at
+	repo.Create(ctx, event)
+	return event
+}
+
+func TestDispatcher_DeliversToMatchingSubscribers(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryOutbox{}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	dispatcher := NewDispatcher(repo, WithClock(clock.NewFake(now)))
+
+	var all, confirmed []domain.EventType
+	dispatcher.Subscribe("all", func(ctx context.Context, e domain.OutboxEvent) error { all = append(all, e.EventType); return nil })
+	dispatcher.Subscribe("confirmed", func(ctx context.Context, e domain.OutboxEvent) error {
+		var payload domain.OrderEvent
+		if err := e.Decode(&payload); err != nil || payload.OrderID != 1 {
+			t.Errorf("Unexpected payload %s (%v)", e.Payload, err)
+		}
+		confirmed = append(confirmed, e.EventType)
+		return nil
+	}, domain.EventOrderConfirmed)
+
+	addEvent(t, repo, domain.EventOrderCreated, now)
+	addEvent(t, repo, domain.EventOrderConfirmed, now)
+
+	delivered, err := dispatcher.DispatchPending(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if delivered != 2 || len(all) != 2 || len(confirmed) != 1 {
+		t.Errorf("Expected 2 delivered, got %d (all=%v confirmed=%v)", delivered, all, confirmed)
+	}
+	if event := repo.get(2); event.Status != domain.OutboxDelivered || event.Attempts != 1 || event.DeliveredAt == nil {
+		t.Errorf("Expected delivered bookkeeping, got %+v", event)
+	}
+}
+
+func TestDispatcher_RetriesWithBackoffUntilDead(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryOutbox{}
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	dispatcher := NewDispatcher(repo,
+		WithClock(clk),
+		WithRetry(3, time.Second, time.Minute),
+	)
+
+	calls := 0
+	dispatcher.Subscribe("flaky", func(ctx context.Context, e domain.OutboxEvent) error {
+		calls++
+		if calls == 1 {
+			panic("boom")
+		}
+		return errors.New("unavailable")
+	})
+	addEvent(t, repo, domain.EventOrderShipped, clk.Now())
+
+	dispatcher.DispatchPending(ctx)
+	event := repo.get(1)
+	if event.Status != domain.OutboxPending || event.Attempts != 1 || !event.NextAttemptAt.Equal(clk.Now().Add(time.Second)) {
+		t.Fatalf("Expected retry in 1s after first failure, got %+v", event)
+	}
+
+	// Antes del próximo intento no se vuelve a entregar
+	dispatcher.DispatchPending(ctx)
+	if calls != 1 {
+		t.Errorf("Expected no delivery before backoff, got %d calls", calls)
+	}
+
+	clk.Advance(time.Second)
+	dispatcher.DispatchPending(ctx)
+	if event = repo.get(1); !event.NextAttemptAt.Equal(clk.Now().Add(2 * time.Second)) {
+		t.Errorf("Expected backoff of 2s, got %v", event.NextAttemptAt.Sub(clk.Now()))
+	}
+
+	clk.Advance(2 * time.Second)
+	dispatcher.DispatchPending(ctx)
+	if event = repo.get(1); event.Status != domain.OutboxDead || event.Attempts != 3 || event.LastError != "flaky: unavailable" {
+		t.Errorf("Expected DEAD after 3 attempts, got %+v", event)
+	}
+}
+
+func TestDispatcher_StartDeliversInBackground(t *testing.T) {
+	repo := &memoryOutbox{}
+	dispatcher := NewDispatcher(repo, WithInterval(time.Hour))
+
+	received := make(chan domain.EventType, 1)
+	dispatcher.Subscribe("chan", func(ctx context.Context, e domain.OutboxEvent) error { received <- e.EventType; return nil })
+	dispatcher.Start()
+	defer dispatcher.Stop()
+
+	addEvent(t, repo, domain.EventOrderCancelled, time.Now())
+	dispatcher.Notify()
+
+	select {
+	case eventType := <-received:
+		if eventType != domain.EventOrderCancelled {
+			t.Errorf("Expected order.cancelled, got %s", eventType)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected event to be delivered after Notify")
+	}
+}
+
+func TestDispatcher_LagIsAgeOfOldestPendingEvent(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryOutbox{}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	dispatcher := NewDispatcher(repo, WithClock(clock.NewFake(now)))
+
+	if lag, err := dispatcher.Lag(ctx); err != nil || lag != 0 {
+		t.Errorf("Expected no lag without events, got %s (%v)", lag, err)
+	}
+	if !dispatcher.LastHeartbeat().IsZero() {
+		t.Error("Expected no heartbeat before Start")
+	}
+
+	delivered := addEvent(t, repo, domain.EventOrderCreated, now)
+	delivered.Status = domain.OutboxDelivered
+	delivered.CreatedAt = now.Add(-time.Hour)
+	repo.Update(ctx, delivered)
+	pending := addEvent(t, repo, domain.EventOrderCancelled, now.Add(time.Minute))
+	pending.CreatedAt = now.Add(-90 * time.Second)
+	repo.Update(ctx, pending)
+
+	if lag, err := dispatcher.Lag(ctx); err != nil || lag != 90*time.Second {
+		t.Errorf("Expected 90s lag from the pending event, got %s (%v)", lag, err)
+	}
+}
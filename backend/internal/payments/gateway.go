@@ -0,0 +1,69 @@
+// Package payments define la integración con pasarelas de pago: autorización,
+// captura, anulación y reembolso.
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrCardDeclined          = errors.New("card declined")
+	ErrGatewayUnavailable    = errors.New("payment gateway unavailable")
+	ErrAuthorizationNotFound = errors.New("authorization not found")
+	ErrInvalidOperation      = errors.New("invalid payment operation")
+)
+
+// Card son los datos de tarjeta enviados a la pasarela. Nunca se persisten completos.
+type Card struct {
+	Number   string `json:"number" binding:"required"`
+	ExpMonth int    `json:"exp_month" binding:"required,min=1,max=12"`
+	ExpYear  int    `json:"exp_year" binding:"required"`
+	CVC      string `json:"cvc" binding:"required"`
+	Holder   string `json:"holder"`
+}
+
+// Last4 devuelve los últimos cuatro dígitos de la tarjeta
+func (c Card) Last4() string {
+	if len(c.Number) < 4 {
+		return c.Number
+	}
+	return c.Number[len(c.Number)-4:]
+}
+
+type AuthorizeRequest struct {
+	Reference string
+	Amount    float64
+	Currency  string
+	Card      Card
+}
+
+// Authorization es una retención de fondos aprobada por la pasarela.
+type Authorization struct {
+	ID     string
+	Amount float64
+}
+
+// DeclineError describe un rechazo de la pasarela con su código.
+type DeclineError struct {
+	Code    string
+	Message string
+}
+
+func (e *DeclineError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrCardDeclined, e.Message)
+}
+
+func (e *DeclineError) Unwrap() error {
+	return ErrCardDeclined
+}
+
+// Gateway es la interfaz que implementa cada pasarela de pago.
+type Gateway interface {
+	Name() string
+	Authorize(ctx context.Context, req AuthorizeRequest) (*Authorization, error)
+	Capture(ctx context.Context, authorizationID string, amount float64) error
+	Void(ctx context.Context, authorizationID string) error
+	Refund(ctx context.Context, authorizationID string, amount float64) (refundID string, err error)
+}
@@ -0,0 +1,132 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HTTPGateway integra una pasarela externa con una API JSON:
+//
+//	POST /authorizations               {reference, amount, currency, card} -> {id, amount}
+//	POST /authorizations/{id}/capture  {amount}                            -> 204
+//	POST /authorizations/{id}/void                                         -> 204
+//	POST /authorizations/{id}/refunds  {amount}                            -> {id}
+//
+// Los rechazos responden 402 con {code, message}.
+type HTTPGateway struct {
+	name    string
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+func NewHTTPGateway(name, baseURL, apiKey string, client *http.Client) *HTTPGateway {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &HTTPGateway{
+		name:    name,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		client:  client,
+	}
+}
+
+func (g *HTTPGateway) Name() string {
+	return g.name
+}
+
+func (g *HTTPGateway) Authorize(ctx context.Context, req AuthorizeRequest) (*Authorization, error) {
+	body := struct {
+		Reference string  `json:"reference"`
+		Amount    float64 `json:"amount"`
+		Currency  string  `json:"currency"`
+		Card      Card    `json:"card"`
+	}{req.Reference, req.Amount, req.Currency, req.Card}
+
+	var response struct {
+		ID     string  `json:"id"`
+		Amount float64 `json:"amount"`
+	}
+	if err := g.do(ctx, "/authorizations", body, &response); err != nil {
+		return nil, err
+	}
+	return &Authorization{ID: response.ID, Amount: response.Amount}, nil
+}
+
+func (g *HTTPGateway) Capture(ctx context.Context, authorizationID string, amount float64) error {
+	return g.do(ctx, "/authorizations/"+url.PathEscape(authorizationID)+"/capture", map[string]float64{"amount": amount}, nil)
+}
+
+func (g *HTTPGateway) Void(ctx context.Context, authorizationID string) error {
+	return g.do(ctx, "/authorizations/"+url.PathEscape(authorizationID)+"/void", nil, nil)
+}
+
+func (g *HTTPGateway) Refund(ctx context.Context, authorizationID string, amount float64) (string, error) {
+	var response struct {
+		ID string `json:"id"`
+	}
+	if err := g.do(ctx, "/authorizations/"+url.PathEscape(authorizationID)+"/refunds", map[string]float64{"amount": amount}, &response); err != nil {
+		return "", err
+	}
+	return response.ID, nil
+}
+
+func (g *HTTPGateway) do(ctx context.Context, path string, body, out interface{}) error {
+	payload := []byte("{}")
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if g.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+g.apiKey)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrGatewayUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusPaymentRequired:
+		var decline struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&decline)
+		return &DeclineError{Code: decline.Code, Message: decline.Message}
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrAuthorizationNotFound
+	case resp.StatusCode == http.StatusConflict || resp.StatusCode == http.StatusUnprocessableEntity:
+		return ErrInvalidOperation
+	case resp.StatusCode >= 500:
+		return fmt.Errorf("%w: status %d", ErrGatewayUnavailable, resp.StatusCode)
+	case resp.StatusCode >= 300:
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("payment gateway %s returned %d: %s", g.name, resp.StatusCode, apiErr.Error)
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
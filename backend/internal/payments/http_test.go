@@ -0,0 +1,57 @@
+package payments_test
+
+import (
+	"context"
+	"errors"
+	"order-management-system/internal/payments"
+	"order-management-system/internal/payments/paymentstest"
+	"testing"
+)
+
+func TestHTTPGateway_AgainstFakeServer(t *testing.T) {
+	ctx := context.Background()
+	server := paymentstest.NewServer("secret")
+	defer server.Close()
+
+	gateway := payments.NewHTTPGateway("fake", server.URL, "secret", server.Client())
+	auth, err := gateway.Authorize(ctx, payments.AuthorizeRequest{Reference: "order-7", Amount: 100, Currency: "ARS", Card: payments.Card{Number: payments.CardApproved}})
+	if err != nil || auth.ID == "" || auth.Amount != 100 {
+		t.Fatalf("Expected authorization, got %+v (%v)", auth, err)
+	}
+	if err := gateway.Capture(ctx, auth.ID, 150); err != payments.ErrInvalidOperation {
+		t.Errorf("Expected ErrInvalidOperation capturing above the authorization, got %v", err)
+	}
+	if err := gateway.Capture(ctx, auth.ID, 100); err != nil {
+		t.Fatalf("Expected capture to succeed, got %v", err)
+	}
+	if refundID, err := gateway.Refund(ctx, auth.ID, 40); err != nil || refundID == "" {
+		t.Errorf("Expected refund id, got %q (%v)", refundID, err)
+	}
+	if err := gateway.Void(ctx, "missing"); err != payments.ErrAuthorizationNotFound {
+		t.Errorf("Expected ErrAuthorizationNotFound, got %v", err)
+	}
+
+	_, err = gateway.Authorize(ctx, payments.AuthorizeRequest{Amount: 100, Card: payments.Card{Number: payments.CardInsufficientFunds}})
+	var decline *payments.DeclineError
+	if !errors.As(err, &decline) || decline.Code != "insufficient_funds" {
+		t.Errorf("Expected insufficient_funds decline, got %v", err)
+	}
+}
+
+func TestHTTPGateway_Failures(t *testing.T) {
+	ctx := context.Background()
+	server := paymentstest.NewServer("secret")
+	defer server.Close()
+
+	request := payments.AuthorizeRequest{Amount: 100, Card: payments.Card{Number: payments.CardApproved}}
+	unauthorized := payments.NewHTTPGateway("fake", server.URL, "wrong", server.Client())
+	if _, err := unauthorized.Authorize(ctx, request); err == nil {
+		t.Error("Expected unauthorized error")
+	}
+
+	server.SetFailing(true)
+	gateway := payments.NewHTTPGateway("fake", server.URL, "secret", server.Client())
+	if _, err := gateway.Authorize(ctx, request); !errors.Is(err, payments.ErrGatewayUnavailable) {
+		t.Errorf("Expected ErrGatewayUnavailable, got %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+// Package paymentstest provee una pasarela HTTP falsa para tests.
+package paymentstest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"order-management-system/internal/payments"
+	"strings"
+	"sync"
+)
+
+// Server es una API de pasarela falsa respaldada por un Simulator.
+// Permite forzar fallas.
+type Server struct {
+	*httptest.Server
+	APIKey string
+
+	mu        sync.Mutex
+	simulator *payments.Simulator
+	fail      bool
+}
+
+func NewServer(apiKey string) *Server {
+	s := &Server{
+		APIKey:    apiKey,
+		simulator: payments.NewSimulator(),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/authorizations", s.authorize)
+	mux.HandleFunc("/authorizations/", s.operate)
+	s.Server = httptest.NewServer(s.check(mux))
+	return s
+}
+
+// SetFailing hace que todas las respuestas sean 503
+func (s *Server) SetFailing(fail bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fail = fail
+}
+
+func (s *Server) check(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		fail := s.fail
+		s.mu.Unlock()
+		if fail {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "unavailable"})
+			return
+		}
+		if s.APIKey != "" && r.Header.Get("Authorization") != "Bearer "+s.APIKey {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	var req payments.AuthorizeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	auth, err := s.simulator.Authorize(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"id": auth.ID, "amount": auth.Amount})
+}
+
+// operate atiende /authorizations/{id}/capture, /void y /refunds
+func (s *Server) operate(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/authorizations/"), "/")
+	if len(parts) != 2 {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+		return
+	}
+	var body struct {
+		Amount float64 `json:"amount"`
+	}
+	json.NewDecoder(r.Body).Decode(&body)
+
+	id := parts[0]
+	switch parts[1] {
+	case "capture":
+		if err := s.simulator.Capture(r.Context(), id, body.Amount); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case "void":
+		if err := s.simulator.Void(r.Context(), id); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case "refunds":
+		refundID, err := s.simulator.Refund(r.Context(), id, body.Amount)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, map[string]string{"id": refundID})
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	var decline *payments.DeclineError
+	switch {
+	case errors.As(err, &decline):
+		writeJSON(w, http.StatusPaymentRequired, map[string]string{"code": decline.Code, "message": decline.Message})
+	case errors.Is(err, payments.ErrAuthorizationNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, payments.ErrInvalidOperation):
+		writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		writeJSON(w, http.StatusBadGateway, map[string]string{"error": err.Error()})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,208 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"order-management-system/internal/clock"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/ids"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tarjetas de prueba con resultado determinístico
+const (
+	CardApproved          = "4242424242424242"
+	CardDeclined          = "4000000000000002"
+	CardInsufficientFunds = "4000000000009995"
+	CardExpired           = "4000000000000069"
+	CardProcessingError   = "4000000000000119"
+)
+
+// AuthorizationStore guarda el estado de las autorizaciones del Simulator.
+// repositories.SimulatedAuthorizationRepository la implementa sobre la base.
+type AuthorizationStore interface {
+	Create(ctx context.Context, auth *domain.SimulatedAuthorization) error
+	// GetByAuthorizationID devuelve nil sin error si la autorización no existe
+	GetByAuthorizationID(ctx context.Context, authorizationID string) (*domain.SimulatedAuthorization, error)
+	Update(ctx context.Context, auth *domain.SimulatedAuthorization) error
+}
+
+// Simulator es una pasarela para desarrollo local y tests. Aprueba cualquier número
+// que pase el control de Luhn salvo las tarjetas de prueba de rechazo.
+type Simulator struct {
+	// mu serializa las operaciones de esta instancia sobre el store
+	mu             sync.Mutex
+	authorizations AuthorizationStore
+	ids            ids.Generator
+}
+
+// SimulatorOption configura parámetros opcionales del Simulator
+type SimulatorOption func(*Simulator)
+
+// WithAuthorizationStore persiste las autorizaciones; sin ella se guardan en memoria y
+// se pierden al reiniciar
+func WithAuthorizationStore(store AuthorizationStore) SimulatorOption {
+	return func(s *Simulator) {
+		s.authorizations = store
+	}
+}
+
+func NewSimulator(opts ...SimulatorOption) *Simulator {
+	s := &Simulator{
+		authorizations: newMemoryAuthorizationStore(),
+		ids:            ids.NewULIDGenerator(clock.System()),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *Simulator) Name() string {
+	return "simulator"
+}
+
+func (s *Simulator) Authorize(ctx context.Context, req AuthorizeRequest) (*Authorization, error) {
+	number := strings.ReplaceAll(req.Card.Number, " ", "")
+	switch {
+	case number == CardDeclined:
+		return nil, &DeclineError{Code: "card_declined", Message: "the card was declined"}
+	case number == CardInsufficientFunds:
+		return nil, &DeclineError{Code: "insufficient_funds", Message: "the card has insufficient funds"}
+	case number == CardExpired:
+		return nil, &DeclineError{Code: "expired_card", Message: "the card has expired"}
+	case number == CardProcessingError:
+		return nil, fmt.Errorf("%w: processing error", ErrGatewayUnavailable)
+	case !luhnValid(number):
+		return nil, &DeclineError{Code: "invalid_number", Message: "the card number is invalid"}
+	case req.Amount <= 0:
+		return nil, fmt.Errorf("%w: amount must be positive", ErrInvalidOperation)
+	}
+
+	// Los IDs salen de un ULID para no repetirse entre reinicios
+	auth := &domain.SimulatedAuthorization{AuthorizationID: "sim_auth_" + s.ids.NewID(), Amount: req.Amount}
+	if err := s.authorizations.Create(ctx, auth); err != nil {
+		return nil, err
+	}
+	return &Authorization{ID: auth.AuthorizationID, Amount: req.Amount}, nil
+}
+
+func (s *Simulator) Capture(ctx context.Context, authorizationID string, amount float64) error {
+	return s.update(ctx, authorizationID, func(auth *domain.SimulatedAuthorization) error {
+		if auth.Voided || auth.Captured > 0 || exceeds(amount, auth.Amount) {
+			return ErrInvalidOperation
+		}
+		auth.Captured = amount
+		return nil
+	})
+}
+
+func (s *Simulator) Void(ctx context.Context, authorizationID string) error {
+	return s.update(ctx, authorizationID, func(auth *domain.SimulatedAuthorization) error {
+		if auth.Voided || auth.Captured > 0 {
+			return ErrInvalidOperation
+		}
+		auth.Voided = true
+		return nil
+	})
+}
+
+func (s *Simulator) Refund(ctx context.Context, authorizationID string, amount float64) (string, error) {
+	err := s.update(ctx, authorizationID, func(auth *domain.SimulatedAuthorization) error {
+		if amount <= 0 || exceeds(auth.Refunded+amount, auth.Captured) {
+			return ErrInvalidOperation
+		}
+		auth.Refunded += amount
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return "sim_refund_" + s.ids.NewID(), nil
+}
+
+// update aplica apply a la autorización y guarda el resultado si no falla
+func (s *Simulator) update(ctx context.Context, authorizationID string, apply func(auth *domain.SimulatedAuthorization) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	auth, err := s.authorizations.GetByAuthorizationID(ctx, authorizationID)
+	if err != nil {
+		return err
+	}
+	if auth == nil {
+		return ErrAuthorizationNotFound
+	}
+	if err := apply(auth); err != nil {
+		return err
+	}
+	return s.authorizations.Update(ctx, auth)
+}
+
+func exceeds(amount, limit float64) bool {
+	return math.Round(amount*100) > math.Round(limit*100)
+}
+
+func luhnValid(number string) bool {
+	if len(number) < 12 || len(number) > 19 {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// memoryAuthorizationStore guarda las autorizaciones en memoria, para tests
+type memoryAuthorizationStore struct {
+	mu             sync.Mutex
+	authorizations map[string]domain.SimulatedAuthorization
+}
+
+func newMemoryAuthorizationStore() *memoryAuthorizationStore {
+	return &memoryAuthorizationStore{authorizations: make(map[string]domain.SimulatedAuthorization)}
+}
+
+func (m *memoryAuthorizationStore) Create(ctx context.Context, auth *domain.SimulatedAuthorization) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	auth.ID = uint(len(m.authorizations) + 1)
+	auth.CreatedAt = time.Now()
+	auth.UpdatedAt = auth.CreatedAt
+	m.authorizations[auth.AuthorizationID] = *auth
+	return nil
+}
+
+func (m *memoryAuthorizationStore) GetByAuthorizationID(ctx context.Context, authorizationID string) (*domain.SimulatedAuthorization, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	auth, ok := m.authorizations[authorizationID]
+	if !ok {
+		return nil, nil
+	}
+	return &auth, nil
+}
+
+func (m *memoryAuthorizationStore) Update(ctx context.Context, auth *domain.SimulatedAuthorization) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	auth.UpdatedAt = time.Now()
+	m.authorizations[auth.AuthorizationID] = *auth
+	return nil
+}
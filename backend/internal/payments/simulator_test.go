@@ -0,0 +1,77 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func authorize(t *testing.T, s *Simulator, number string, amount float64) (*Authorization, error) {
+	t.Helper()
+	return s.Authorize(context.Background(), AuthorizeRequest{Reference: "order-1", Amount: amount, Currency: "ARS", Card: Card{Number: number}})
+}
+
+func TestSimulator_DeterministicCards(t *testing.T) {
+	tests := []struct {
+		number string
+		code   string
+		err    error
+	}{
+		{CardApproved, "", nil},
+		{"5555 5555 5555 4444", "", nil},
+		{CardDeclined, "card_declined", ErrCardDeclined},
+		{CardInsufficientFunds, "insufficient_funds", ErrCardDeclined},
+		{CardExpired, "expired_card", ErrCardDeclined},
+		{"4242424242424241", "invalid_number", ErrCardDeclined},
+		{CardProcessingError, "", ErrGatewayUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.number, func(t *testing.T) {
+			_, err := authorize(t, NewSimulator(), tt.number, 100)
+			if !errors.Is(err, tt.err) && !(tt.err == nil && err == nil) {
+				t.Fatalf("Expected %v, got %v", tt.err, err)
+			}
+			var decline *DeclineError
+			if tt.code != "" && (!errors.As(err, &decline) || decline.Code != tt.code) {
+				t.Errorf("Expected decline code %s, got %v", tt.code, err)
+			}
+		})
+	}
+}
+
+func TestSimulator_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	s := NewSimulator()
+	auth, err := authorize(t, s, CardApproved, 100)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err := s.Capture(ctx, auth.ID, 150); err != ErrInvalidOperation {
+		t.Errorf("Expected capture above authorization to fail, got %v", err)
+	}
+	if err := s.Capture(ctx, auth.ID, 100); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := s.Void(ctx, auth.ID); err != ErrInvalidOperation {
+		t.Errorf("Expected void after capture to fail, got %v", err)
+	}
+	if _, err := s.Refund(ctx, auth.ID, 60); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := s.Refund(ctx, auth.ID, 50); err != ErrInvalidOperation {
+		t.Errorf("Expected refund above captured to fail, got %v", err)
+	}
+	if _, err := s.Refund(ctx, auth.ID, 40); err != nil {
+		t.Errorf("Expected remaining refund to succeed, got %v", err)
+	}
+
+	other, _ := authorize(t, s, CardApproved, 50)
+	if err := s.Void(ctx, other.ID); err != nil {
+		t.Errorf("Expected void to succeed, got %v", err)
+	}
+	if err := s.Capture(ctx, other.ID, 50); err != ErrInvalidOperation {
+		t.Errorf("Expected capture after void to fail, got %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+// Package realtime publica los cambios de estado de los pedidos a los clientes conectados.
+//
+// El Hub guarda las últimas actualizaciones en un buffer acotado para que un cliente que
+// se reconecta con Last-Event-ID reciba lo que se perdió. Un suscriptor que no consume a
+// tiempo se desconecta en lugar de frenar la publicación; al reconectarse retoma del buffer.
+package realtime
+
+import (
+	"order-management-system/internal/domain"
+	"sync"
+)
+
+const (
+	DefaultBufferSize       = 256
+	DefaultSubscriberBuffer = 64
+)
+
+// Update es un cambio de estado de un pedido con un ID creciente dentro del proceso
+type Update struct {
+	ID    uint64            `json:"id"`
+	Type  domain.EventType  `json:"type"`
+	Order domain.OrderEvent `json:"order"`
+}
+
+// Filter limita las actualizaciones a un usuario y/o a un pedido; los valores en cero no filtran
+type Filter struct {
+	UserID  uint
+	OrderID uint
+}
+
+func (f Filter) Matches(update Update) bool {
+	if f.UserID != 0 && update.Order.UserID != f.UserID {
+		return false
+	}
+	if f.OrderID != 0 && update.Order.OrderID != f.OrderID {
+		return false
+	}
+	return true
+}
+
+// Subscription recibe las actualizaciones que cumplen su filtro hasta que se cierra
+type Subscription struct {
+	hub     *Hub
+	filter  Filter
+	updates chan Update
+	once    sync.Once
+}
+
+// Updates se cierra cuando la suscripción termina (Close, Hub.Close o cliente lento)
+func (s *Subscription) Updates() <-chan Update {
+	return s.updates
+}
+
+func (s *Subscription) Close() {
+	s.hub.remove(s)
+}
+
+func (s *Subscription) end() {
+	s.once.Do(func() { close(s.updates) })
+}
+
+type Hub struct {
+	bufferSize       int
+	subscriberBuffer int
+
+	mu          sync.Mutex
+	lastID      uint64
+	buffer      []Update
+	subscribers map[*Subscription]struct{}
+	closed      bool
+}
+
+// Option configura parámetros opcionales del Hub
+type Option func(*Hub)
+
+// WithBufferSize define cuántas actualizaciones se conservan para reanudar con Last-Event-ID
+func WithBufferSize(size int) Option {
+	return func(h *Hub) {
+		h.bufferSize = size
+	}
+}
+
+// WithSubscriberBuffer define cuántas actualizaciones puede acumular un cliente antes de desconectarlo
+func WithSubscriberBuffer(size int) Option {
+	return func(h *Hub) {
+		h.subscriberBuffer = size
+	}
+}
+
+func NewHub(opts ...Option) *Hub {
+	h := &Hub{
+		bufferSize:       DefaultBufferSize,
+		subscriberBuffer: DefaultSubscriberBuffer,
+		subscribers:      make(map[*Subscription]struct{}),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// PublishOrder publica un cambio de estado de un pedido
+func (h *Hub) PublishOrder(eventType domain.EventType, order domain.OrderEvent) {
+	h.Publish(Update{Type: eventType, Order: order})
+}
+
+// Publish asigna el ID a la actualización, la guarda en el buffer y la entrega a los suscriptores
+func (h *Hub) Publish(update Update) Update {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return update
+	}
+
+	h.lastID++
+	update.ID = h.lastID
+	h.buffer = append(h.buffer, update)
+	if len(h.buffer) > h.bufferSize {
+		h.buffer = append([]Update(nil), h.buffer[len(h.buffer)-h.bufferSize:]...)
+	}
+
+	for sub := range h.subscribers {
+		if !sub.filter.Matches(update) {
+			continue
+		}
+		select {
+		case sub.updates <- update:
+		default:
+			delete(h.subscribers, sub)
+			sub.end()
+		}
+	}
+	return update
+}
+
+// Subscribe registra un suscriptor y devuelve las actualizaciones posteriores a lastEventID
+// que siguen en el buffer. complete es false si algunas ya salieron del buffer (o el ID es
+// de otra ejecución del proceso) y el cliente debe volver a cargar el estado completo.
+func (h *Hub) Subscribe(filter Filter, lastEventID uint64) (sub *Subscription, missed []Update, complete bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub = &Subscription{hub: h, filter: filter, updates: make(chan Update, h.subscriberBuffer)}
+	if h.closed {
+		sub.end()
+		return sub, nil, false
+	}
+	h.subscribers[sub] = struct{}{}
+
+	complete = true
+	if lastEventID > 0 {
+		oldest := h.lastID + 1
+		if len(h.buffer) > 0 {
+			oldest = h.buffer[0].ID
+		}
+		complete = lastEventID <= h.lastID && lastEventID+1 >= oldest
+		for _, update := range h.buffer {
+			if update.ID > lastEventID && filter.Matches(update) {
+				missed = append(missed, update)
+			}
+		}
+	}
+	return sub, missed, complete
+}
+
+// Subscribers devuelve la cantidad de suscriptores conectados
+func (h *Hub) Subscribers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers)
+}
+
+// Close termina todas las suscripciones; las publicaciones posteriores se descartan
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for sub := range h.subscribers {
+		delete(h.subscribers, sub)
+		sub.end()
+	}
+}
+
+func (h *Hub) remove(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.subscribers, sub)
+	sub.end()
+}
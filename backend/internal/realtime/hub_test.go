@@ -0,0 +1,87 @@
+package realtime
+
+import (
+	"fmt"
+	"order-management-system/internal/domain"
+	"testing"
+)
+
+func publish(h *Hub, orderID, userID uint, status domain.OrderStatus) Update {
+	return h.Publish(Update{
+		Type:  domain.EventOrderConfirmed,
+		Order: domain.OrderEvent{OrderID: orderID, PublicID: fmt.Sprintf("order-%d", orderID), UserID: userID, Status: status},
+	})
+}
+
+func TestHub_FiltersByUserAndOrder(t *testing.T) {
+	hub := NewHub()
+	byUser, _, _ := hub.Subscribe(Filter{UserID: 1}, 0)
+	byOrder, _, _ := hub.Subscribe(Filter{OrderID: 20}, 0)
+
+	publish(hub, 10, 1, domain.StatusConfirmed)
+	publish(hub, 20, 2, domain.StatusShipped)
+
+	if update := <-byUser.Updates(); update.Order.OrderID != 10 || update.ID != 1 {
+		t.Errorf("Expected order 10 for user 1, got %+v", update)
+	}
+	if update := <-byOrder.Updates(); update.Order.OrderID != 20 || update.ID != 2 {
+		t.Errorf("Expected order 20, got %+v", update)
+	}
+	if len(byUser.Updates()) != 0 || len(byOrder.Updates()) != 0 {
+		t.Error("Expected filtered updates not to be delivered")
+	}
+}
+
+func TestHub_ResumesFromBoundedBuffer(t *testing.T) {
+	hub := NewHub(WithBufferSize(3))
+	for i := uint(1); i <= 5; i++ {
+		publish(hub, i, 1, domain.StatusPending)
+	}
+
+	// El buffer conserva 3..5: desde el 2 se puede reanudar sin huecos
+	_, missed, complete := hub.Subscribe(Filter{}, 2)
+	if !complete || len(missed) != 3 || missed[0].ID != 3 {
+		t.Errorf("Expected complete resume with 3 updates, got %v %+v", complete, missed)
+	}
+
+	_, missed, complete = hub.Subscribe(Filter{}, 1)
+	if complete || len(missed) != 3 {
+		t.Errorf("Expected incomplete resume after buffer overflow, got %v %+v", complete, missed)
+	}
+
+	// Un ID posterior al último es de otra ejecución del proceso
+	if _, _, complete = hub.Subscribe(Filter{}, 99); complete {
+		t.Error("Expected unknown Last-Event-ID to require a reset")
+	}
+
+	if _, missed, complete = hub.Subscribe(Filter{OrderID: 4}, 2); !complete || len(missed) != 1 {
+		t.Errorf("Expected only order 4 replayed, got %+v", missed)
+	}
+}
+
+func TestHub_DropsSlowSubscribersAndCloses(t *testing.T) {
+	hub := NewHub(WithSubscriberBuffer(1))
+	slow, _, _ := hub.Subscribe(Filter{}, 0)
+	fast, _, _ := hub.Subscribe(Filter{}, 0)
+
+	publish(hub, 1, 1, domain.StatusPending)
+	<-fast.Updates()
+	publish(hub, 2, 1, domain.StatusPending)
+
+	if hub.Subscribers() != 1 {
+		t.Errorf("Expected slow subscriber removed, got %d subscribers", hub.Subscribers())
+	}
+	<-slow.Updates()
+	if _, ok := <-slow.Updates(); ok {
+		t.Error("Expected slow subscription channel to be closed")
+	}
+
+	// Close es idempotente aunque el hub ya haya cerrado la suscripción
+	slow.Close()
+	hub.Close()
+	<-fast.Updates()
+	if _, ok := <-fast.Updates(); ok || hub.Subscribers() != 0 {
+		t.Error("Expected all subscriptions closed with the hub")
+	}
+	fast.Close()
+}
@@ -0,0 +1,93 @@
+package realtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultHeartbeat = 15 * time.Second
+
+	// EventReset avisa al cliente que se perdieron actualizaciones y debe recargar los pedidos
+	EventReset = "reset"
+
+	// retryMillis es la espera que se sugiere al EventSource antes de reconectarse
+	retryMillis = 3000
+)
+
+// LastEventID lee el ID desde el header Last-Event-ID o, si no está, desde el query last_event_id
+func LastEventID(r *http.Request) uint64 {
+	raw := r.Header.Get("Last-Event-ID")
+	if raw == "" {
+		raw = r.URL.Query().Get("last_event_id")
+	}
+	id, _ := strconv.ParseUint(raw, 10, 64)
+	return id
+}
+
+// ServeSSE transmite las actualizaciones que cumplen el filtro como Server-Sent Events.
+// Envía un comentario de heartbeat cada intervalo y termina cuando el cliente se
+// desconecta, cuando el Hub se cierra o cuando el cliente no consume a tiempo.
+func (h *Hub) ServeSSE(w http.ResponseWriter, r *http.Request, filter Filter, heartbeat time.Duration) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	sub, missed, complete := h.Subscribe(filter, LastEventID(r))
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", retryMillis)
+	if !complete {
+		fmt.Fprintf(w, "event: %s\ndata: {}\n\n", EventReset)
+	}
+	for _, update := range missed {
+		if err := writeUpdate(w, update); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case update, ok := <-sub.Updates():
+			if !ok {
+				return
+			}
+			if err := writeUpdate(w, update); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+func writeUpdate(w http.ResponseWriter, update Update) error {
+	// El stream llega al navegador: el pedido viaja con public_id y number, sin la clave interna
+	order := update.Order
+	order.OrderID = 0
+	data, err := json.Marshal(order)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", update.ID, update.Type, data)
+	return err
+}
@@ -0,0 +1,129 @@
+package realtime
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"order-management-system/internal/domain"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readEvent lee líneas hasta el próximo evento con datos (ignora retry y comentarios)
+func readEvent(t *testing.T, reader *bufio.Reader) map[string]string {
+	t.Helper()
+	event := make(map[string]string)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Expected event, got %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			if event["data"] != "" {
+				return event
+			}
+			event = make(map[string]string)
+			continue
+		}
+		if key, value, ok := strings.Cut(line, ": "); ok {
+			event[key] = value
+		}
+	}
+}
+
+func waitForSubscribers(t *testing.T, hub *Hub, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for hub.Subscribers() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d subscribers, got %d", n, hub.Subscribers())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestServeSSE_StreamsAndResumes(t *testing.T) {
+	hub := NewHub()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hub.ServeSSE(w, r, Filter{UserID: 1}, 20*time.Millisecond)
+	}))
+	defer server.Close()
+
+	first := publish(hub, 10, 1, domain.StatusConfirmed)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	req.Header.Set("Last-Event-ID", "0")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Errorf("Expected text/event-stream, got %s", resp.Header.Get("Content-Type"))
+	}
+
+	waitForSubscribers(t, hub, 1)
+	publish(hub, 11, 2, domain.StatusShipped)
+	publish(hub, 12, 1, domain.StatusShipped)
+
+	reader := bufio.NewReader(resp.Body)
+	event := readEvent(t, reader)
+	if event["id"] != "3" || event["event"] != "order.confirmed" || !strings.Contains(event["data"], `"public_id":"order-12"`) {
+		t.Errorf("Expected order 12 update, got %v", event)
+	}
+	if strings.Contains(event["data"], "order_id") {
+		t.Errorf("Expected the internal order ID to stay out of the stream, got %s", event["data"])
+	}
+
+	// Al cortar la conexión el handler libera la suscripción
+	cancel()
+	waitForSubscribers(t, hub, 0)
+
+	// Sin header se acepta last_event_id en el query
+	resumed, err := http.Get(server.URL + "?last_event_id=" + strconv.FormatUint(first.ID, 10))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer resumed.Body.Close()
+	if event := readEvent(t, bufio.NewReader(resumed.Body)); event["id"] != "3" {
+		t.Errorf("Expected resume after event %d to replay event 3, got %v", first.ID, event)
+	}
+
+	// Cerrar el hub termina los streams abiertos
+	hub.Close()
+	waitForSubscribers(t, hub, 0)
+}
+
+func TestServeSSE_HeartbeatAndReset(t *testing.T) {
+	hub := NewHub(WithBufferSize(1))
+	publish(hub, 1, 1, domain.StatusPending)
+	publish(hub, 2, 1, domain.StatusPending)
+	publish(hub, 3, 1, domain.StatusPending)
+
+	recorder := httptest.NewRecorder()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req := httptest.NewRequest(http.MethodGet, "/stream", nil).WithContext(ctx)
+	req.Header.Set("Last-Event-ID", "1")
+
+	hub.ServeSSE(recorder, req, Filter{}, 10*time.Millisecond)
+
+	body := recorder.Body.String()
+	if !strings.Contains(body, "event: reset\n") {
+		t.Errorf("Expected reset event when updates were lost, got %q", body)
+	}
+	if !strings.Contains(body, "id: 3\n") || strings.Contains(body, "id: 2\n") {
+		t.Errorf("Expected only buffered update 3 replayed, got %q", body)
+	}
+	if !strings.Contains(body, ": heartbeat\n\n") {
+		t.Errorf("Expected heartbeat comment, got %q", body)
+	}
+	if hub.Subscribers() != 0 {
+		t.Errorf("Expected subscription released, got %d", hub.Subscribers())
+	}
+}
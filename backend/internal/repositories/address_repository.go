@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"context"
+	"order-management-system/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type addressRepository struct {
+	db *gorm.DB
+}
+
+func NewAddressRepository(db *gorm.DB) AddressRepository {
+	return &addressRepository{db: db}
+}
+
+func (r *addressRepository) Create(ctx context.Context, address *domain.Address) error {
+	return r.db.WithContext(ctx).Create(address).Error
+}
+
+func (r *addressRepository) GetByID(ctx context.Context, id uint) (*domain.Address, error) {
+	var address domain.Address
+	if err := r.db.WithContext(ctx).First(&address, id).Error; err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+func (r *addressRepository) GetByUserID(ctx context.Context, userID uint) ([]domain.Address, error) {
+	var addresses []domain.Address
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&addresses).Error; err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
+
+func (r *addressRepository) Update(ctx context.Context, address *domain.Address) error {
+	return r.db.WithContext(ctx).Save(address).Error
+}
+
+func (r *addressRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&domain.Address{}, id).Error
+}
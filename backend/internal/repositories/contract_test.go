@@ -0,0 +1,50 @@
+package repositories_test
+
+import (
+	"context"
+	"order-management-system/internal/migrations"
+	"order-management-system/internal/repositories"
+	"order-management-system/internal/repositories/repotest"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB abre una base SQLite en memoria con el esquema de las migraciones
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:?_pragma=foreign_keys(1)"), &gorm.Config{
+		Logger:         logger.Default.LogMode(logger.Silent),
+		TranslateError: true,
+	})
+	if err != nil {
+		t.Fatalf("Expected sqlite to open, got %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		t.Fatalf("Expected migrator, got %v", err)
+	}
+	if _, err := migrator.Up(context.Background(), 0); err != nil {
+		t.Fatalf("Expected migrations to apply, got %v", err)
+	}
+	return db
+}
+
+// Los repositorios de GORM corren la suite de contrato sobre una base SQLite en memoria
+// con el esquema de las migraciones
+func TestGormRepositories_Contract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repos {
+		db := openTestDB(t)
+		return repotest.Repos{
+			Users:    repositories.NewUserRepository(db),
+			Products: repositories.NewProductRepository(db),
+			Orders:   repositories.NewOrderRepository(db),
+		}
+	})
+}
@@ -1,24 +1,201 @@
 package repositories
 
-import "order-management-system/internal/domain"
+import (
+	"context"
+	"order-management-system/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrDuplicatedKey es el error de una clave única repetida; la base lo devuelve así porque
+// se abre con TranslateError
+var ErrDuplicatedKey = gorm.ErrDuplicatedKey
 
 type UserRepository interface {
-	GetByID(id uint) (*domain.User, error)
-	Create(user *domain.User) error
-	GetAll() ([]domain.User, error)
+	GetByID(ctx context.Context, id uint) (*domain.User, error)
+	Create(ctx context.Context, user *domain.User) error
+	GetAll(ctx context.Context) ([]domain.User, error)
 }
 
 type ProductRepository interface {
-	GetByID(id uint) (*domain.Product, error)
-	UpdateStock(id uint, quantity int) error
-	GetAll() ([]domain.Product, error)
-	Create(product *domain.Product) error
+	GetByID(ctx context.Context, id uint) (*domain.Product, error)
+	UpdateStock(ctx context.Context, id uint, quantity int) error
+	GetAll(ctx context.Context) ([]domain.Product, error)
+	Create(ctx context.Context, product *domain.Product) error
 }
 
 type OrderRepository interface {
-	Create(order *domain.Order) error
-	GetByID(id uint) (*domain.Order, error)
-	GetAll() ([]domain.Order, error)
-	GetByUserID(userID uint) ([]domain.Order, error)
-	Update(order *domain.Order) error
+	Create(ctx context.Context, order *domain.Order) error
+	GetByID(ctx context.Context, id uint) (*domain.Order, error)
+	GetByPublicID(ctx context.Context, publicID string) (*domain.Order, error)
+	GetByNumber(ctx context.Context, number string) (*domain.Order, error)
+	GetAll(ctx context.Context) ([]domain.Order, error)
+	GetByUserID(ctx context.Context, userID uint) ([]domain.Order, error)
+	Update(ctx context.Context, order *domain.Order) error
+	// UpdateIfStatus guarda las columnas del pedido, sin sus líneas, sólo si su estado sigue siendo
+	// status; devuelve false si otro proceso lo cambió
+	UpdateIfStatus(ctx context.Context, order *domain.Order, status domain.OrderStatus) (bool, error)
+	UpdateItems(ctx context.Context, order *domain.Order) error
+	MoveItems(ctx context.Context, itemIDs []uint, orderID uint) error
+	// GetPendingCreatedBefore devuelve, por ID, los pedidos PENDING con ID mayor a afterID creados antes de before
+	GetPendingCreatedBefore(ctx context.Context, before time.Time, afterID uint, limit int) ([]domain.Order, error)
+	// GetWithoutIdentifiers devuelve, por ID y sin relaciones, los pedidos con ID mayor a afterID
+	// a los que les falta el identificador público o el número de pedido
+	GetWithoutIdentifiers(ctx context.Context, afterID uint, limit int) ([]domain.Order, error)
+	// SetIdentifiers guarda sólo el identificador público y el número del pedido
+	SetIdentifiers(ctx context.Context, order *domain.Order) error
+}
+
+type PromotionRepository interface {
+	Create(ctx context.Context, promotion *domain.Promotion) error
+	GetByID(ctx context.Context, id uint) (*domain.Promotion, error)
+	GetByCode(ctx context.Context, code string) (*domain.Promotion, error)
+	GetAll(ctx context.Context) ([]domain.Promotion, error)
+	GetAutomatic(ctx context.Context) ([]domain.Promotion, error)
+	CountRedemptions(ctx context.Context, promotionID, userID uint) (int64, error)
+	CreateRedemption(ctx context.Context, redemption *domain.PromotionRedemption) error
+	GetRedemptionsByOrder(ctx context.Context, orderID uint) ([]domain.PromotionRedemption, error)
+	DeleteRedemptionsByOrder(ctx context.Context, orderID uint) error
+	// ClaimUsage suma un uso sólo si la promoción no llegó a MaxUses; devuelve false si ya llegó
+	ClaimUsage(ctx context.Context, id uint) (bool, error)
+	IncrementUsage(ctx context.Context, id uint, delta int) error
+	// ClaimUserUsage suma un uso del usuario sólo si no llegó a limit (0 es sin límite); devuelve false si ya llegó
+	ClaimUserUsage(ctx context.Context, promotionID, userID uint, limit int) (bool, error)
+	IncrementUserUsage(ctx context.Context, promotionID, userID uint, delta int) error
+}
+
+type TaxRuleRepository interface {
+	Create(ctx context.Context, rule *domain.TaxRule) error
+	GetAll(ctx context.Context) ([]domain.TaxRule, error)
+	GetByCountry(ctx context.Context, country string) ([]domain.TaxRule, error)
+}
+
+type AddressRepository interface {
+	Create(ctx context.Context, address *domain.Address) error
+	GetByID(ctx context.Context, id uint) (*domain.Address, error)
+	GetByUserID(ctx context.Context, userID uint) ([]domain.Address, error)
+	Update(ctx context.Context, address *domain.Address) error
+	Delete(ctx context.Context, id uint) error
+}
+
+type PaymentRepository interface {
+	Create(ctx context.Context, payment *domain.Payment) error
+	GetByID(ctx context.Context, id uint) (*domain.Payment, error)
+	GetByOrderID(ctx context.Context, orderID uint) ([]domain.Payment, error)
+	Update(ctx context.Context, payment *domain.Payment) error
+	// ClaimRefund suma amount a lo reembolsado de un pago cobrado sólo si no supera lo cobrado;
+	// devuelve false si no alcanza el saldo. El estado pasa a PARTIALLY_REFUNDED o REFUNDED.
+	ClaimRefund(ctx context.Context, id uint, amount float64) (bool, error)
+	// ReleaseRefund devuelve al saldo un monto reservado con ClaimRefund que no se reembolsó
+	ReleaseRefund(ctx context.Context, id uint, amount float64) error
+}
+
+type RefundRepository interface {
+	Create(ctx context.Context, refund *domain.Refund) error
+	GetByOrderID(ctx context.Context, orderID uint) ([]domain.Refund, error)
+}
+
+type ReturnRepository interface {
+	Create(ctx context.Context, rma *domain.ReturnAuthorization) error
+	GetByID(ctx context.Context, id uint) (*domain.ReturnAuthorization, error)
+	GetByOrderID(ctx context.Context, orderID uint) ([]domain.ReturnAuthorization, error)
+	Update(ctx context.Context, rma *domain.ReturnAuthorization) error
+	// UpdateIfStatus guarda la devolución, con sus ítems y transiciones nuevas, sólo si su estado
+	// sigue siendo status; devuelve false si otro proceso lo cambió
+	UpdateIfStatus(ctx context.Context, rma *domain.ReturnAuthorization, status domain.ReturnStatus) (bool, error)
+}
+
+type OrderChangeRepository interface {
+	Create(ctx context.Context, change *domain.OrderChange) error
+	GetByOrderID(ctx context.Context, orderID uint) ([]domain.OrderChange, error)
+}
+
+type OutboxRepository interface {
+	Create(ctx context.Context, event *domain.OutboxEvent) error
+	GetDue(ctx context.Context, now time.Time, limit int) ([]domain.OutboxEvent, error)
+	// OldestPending devuelve el evento pendiente más antiguo, o nil si no queda ninguno
+	OldestPending(ctx context.Context) (*domain.OutboxEvent, error)
+	Update(ctx context.Context, event *domain.OutboxEvent) error
+}
+
+// Repositories agrupa los repositorios que participan de una misma transacción.
+// Outbox puede ser nil cuando no se registran eventos.
+type Repositories struct {
+	Orders     OrderRepository
+	Products   ProductRepository
+	Outbox     OutboxRepository
+	Promotions PromotionRepository
+	Refunds    RefundRepository
+	Returns    ReturnRepository
+}
+
+// Transactor ejecuta fn dentro de una transacción; si fn devuelve error se revierte todo
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(tx Repositories) error) error
+}
+
+type WebhookRepository interface {
+	Create(ctx context.Context, subscription *domain.WebhookSubscription) error
+	GetByID(ctx context.Context, id uint) (*domain.WebhookSubscription, error)
+	GetAll(ctx context.Context) ([]domain.WebhookSubscription, error)
+	Update(ctx context.Context, subscription *domain.WebhookSubscription) error
+	Delete(ctx context.Context, id uint) error
+}
+
+type WebhookDeliveryRepository interface {
+	Create(ctx context.Context, delivery *domain.WebhookDelivery) error
+	GetByID(ctx context.Context, id uint) (*domain.WebhookDelivery, error)
+	GetBySubscription(ctx context.Context, subscriptionID uint, limit int) ([]domain.WebhookDelivery, error)
+	ExistsForEvent(ctx context.Context, subscriptionID, eventID uint) (bool, error)
+	GetDue(ctx context.Context, now time.Time, limit int) ([]domain.WebhookDelivery, error)
+	Update(ctx context.Context, delivery *domain.WebhookDelivery) error
+}
+
+type SimulatedAuthorizationRepository interface {
+	Create(ctx context.Context, auth *domain.SimulatedAuthorization) error
+	// GetByAuthorizationID devuelve nil sin error si la autorización no existe
+	GetByAuthorizationID(ctx context.Context, authorizationID string) (*domain.SimulatedAuthorization, error)
+	Update(ctx context.Context, auth *domain.SimulatedAuthorization) error
+}
+
+type ShippingLabelRepository interface {
+	Create(ctx context.Context, label *domain.ShippingLabel) error
+	// GetByTrackingNumber devuelve nil sin error si la etiqueta no existe
+	GetByTrackingNumber(ctx context.Context, trackingNumber string) (*domain.ShippingLabel, error)
+	Update(ctx context.Context, label *domain.ShippingLabel) error
+}
+
+type NotificationPreferenceRepository interface {
+	// GetByUserID devuelve nil sin error si el usuario no guardó preferencias
+	GetByUserID(ctx context.Context, userID uint) (*domain.NotificationPreference, error)
+	Save(ctx context.Context, preference *domain.NotificationPreference) error
+}
+
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *domain.Notification) error
+	GetByID(ctx context.Context, id uint) (*domain.Notification, error)
+	GetByUserID(ctx context.Context, userID uint, limit int) ([]domain.Notification, error)
+	ExistsForEvent(ctx context.Context, userID, eventID uint) (bool, error)
+	GetDue(ctx context.Context, now time.Time, limit int) ([]domain.Notification, error)
+	Update(ctx context.Context, notification *domain.Notification) error
+}
+
+type JobRepository interface {
+	// Create devuelve false sin error si ya existe un trabajo con la misma UniqueKey
+	Create(ctx context.Context, job *domain.Job) (bool, error)
+	GetByID(ctx context.Context, id uint) (*domain.Job, error)
+	List(ctx context.Context, filter domain.JobFilter) ([]domain.Job, error)
+	// Claim marca como RUNNING hasta limit trabajos vencidos que no tomó otro worker
+	Claim(ctx context.Context, now time.Time, limit int) ([]domain.Job, error)
+	// RequeueStale devuelve a PENDING los trabajos RUNNING tomados antes de before
+	RequeueStale(ctx context.Context, before time.Time) (int64, error)
+	Update(ctx context.Context, job *domain.Job) error
+	// DeleteFinished borra los trabajos con ese estado terminados antes de before
+	DeleteFinished(ctx context.Context, status domain.JobStatus, before time.Time) (int64, error)
+}
+
+type ReportRepository interface {
+	// SalesByStatus agrupa por estado los pedidos creados en [from, to)
+	SalesByStatus(ctx context.Context, from, to time.Time) ([]domain.StatusSales, error)
 }
@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"context"
+	"order-management-system/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type jobRepository struct {
+	db *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) JobRepository {
+	return &jobRepository{db: db}
+}
+
+func (r *jobRepository) Create(ctx context.Context, job *domain.Job) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "unique_key"}}, DoNothing: true}).Create(job)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *jobRepository) GetByID(ctx context.Context, id uint) (*domain.Job, error) {
+	var job domain.Job
+	if err := r.db.WithContext(ctx).First(&job, id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// List devuelve los trabajos más recientes primero
+func (r *jobRepository) List(ctx context.Context, filter domain.JobFilter) ([]domain.Job, error) {
+	query := r.db.WithContext(ctx).Order("id DESC")
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var jobs []domain.Job
+	if err := query.Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// Claim usa SKIP LOCKED para que varias instancias puedan tomar trabajos sin repetirlos
+func (r *jobRepository) Claim(ctx context.Context, now time.Time, limit int) ([]domain.Job, error) {
+	var jobs []domain.Job
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND run_at <= ?", domain.JobPending, now).
+			Order("run_at, id").Limit(limit).Find(&jobs).Error; err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(jobs))
+		for i := range jobs {
+			ids[i] = jobs[i].ID
+			jobs[i].Status = domain.JobRunning
+			jobs[i].LockedAt = &now
+		}
+		return tx.Model(&domain.Job{}).Where("id IN ?", ids).
+			Updates(map[string]interface{}{"status": domain.JobRunning, "locked_at": now}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (r *jobRepository) RequeueStale(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&domain.Job{}).
+		Where("status = ? AND locked_at < ?", domain.JobRunning, before).
+		Updates(map[string]interface{}{"status": domain.JobPending, "locked_at": nil})
+	return result.RowsAffected, result.Error
+}
+
+func (r *jobRepository) Update(ctx context.Context, job *domain.Job) error {
+	return r.db.WithContext(ctx).Save(job).Error
+}
+
+func (r *jobRepository) DeleteFinished(ctx context.Context, status domain.JobStatus, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("status = ? AND finished_at < ?", status, before).Delete(&domain.Job{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,57 @@
+// Package memory implementa los repositorios en memoria, con la misma semántica que los de
+// GORM: IDs y timestamps asignados al crear, copias independientes en cada lectura,
+// gorm.ErrRecordNotFound cuando no existe el registro y las mismas relaciones precargadas.
+// Son seguros para uso concurrente y sirven para tests y ejecuciones locales sin base.
+package memory
+
+import (
+	"fmt"
+	"order-management-system/internal/clock"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type options struct {
+	clock clock.Clock
+}
+
+// Option configura un repositorio en memoria
+type Option func(*options)
+
+// WithClock define de dónde toman la hora CreatedAt y UpdatedAt; por defecto el reloj del sistema
+func WithClock(clk clock.Clock) Option {
+	return func(o *options) {
+		o.clock = clk
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{clock: clock.System()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// duplicated es el error de una clave única repetida, como lo reporta GORM con TranslateError
+func duplicated(table, column string, value interface{}) error {
+	return fmt.Errorf("%w: %s.%s = %v", gorm.ErrDuplicatedKey, table, column, value)
+}
+
+func stamp(created, updated *time.Time, now time.Time) {
+	if created != nil && created.IsZero() {
+		*created = now
+	}
+	if updated != nil && updated.IsZero() {
+		*updated = now
+	}
+}
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
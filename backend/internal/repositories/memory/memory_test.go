@@ -0,0 +1,42 @@
+package memory_test
+
+import (
+	"context"
+	"order-management-system/internal/clock"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/repositories/memory"
+	"order-management-system/internal/repositories/repotest"
+	"testing"
+	"time"
+)
+
+func TestMemoryRepositories_Contract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repos {
+		users := memory.NewUserRepository()
+		products := memory.NewProductRepository()
+		return repotest.Repos{Users: users, Products: products, Orders: memory.NewOrderRepository(users, products)}
+	})
+}
+
+func TestOrderRepository_UsesClock(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFake(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+	orders := memory.NewOrderRepository(nil, nil, memory.WithClock(clk))
+
+	order := domain.Order{UserID: 1, Status: domain.StatusPending}
+	if err := orders.Create(ctx, &order); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	clk.Advance(time.Hour)
+	if err := orders.Update(ctx, &order); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	found, _ := orders.GetByID(ctx, order.ID)
+	if !found.CreatedAt.Equal(clk.Now().Add(-time.Hour)) || !found.UpdatedAt.Equal(clk.Now()) {
+		t.Errorf("Expected timestamps from the clock, got %v and %v", found.CreatedAt, found.UpdatedAt)
+	}
+	if found.User.ID != 0 || len(found.Items) != 0 {
+		t.Errorf("Expected empty relations without user and product repositories, got %+v", found)
+	}
+}
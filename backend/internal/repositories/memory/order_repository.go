@@ -0,0 +1,464 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/repositories"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// orderRepository guarda cada tabla por separado, como la base: el pedido sin relaciones,
+// sus líneas, descuentos, impuestos y pagos. Las lecturas arman copias nuevas y precargan
+// usuario y productos desde los repositorios recibidos.
+type orderRepository struct {
+	mu       sync.RWMutex
+	opts     options
+	users    repositories.UserRepository
+	products repositories.ProductRepository
+
+	orders    map[uint]domain.Order
+	items     map[uint]domain.OrderItem
+	discounts map[uint]domain.OrderItemDiscount
+	taxLines  map[uint]domain.OrderTaxLine
+	payments  map[uint]domain.Payment
+	lastID    map[string]uint
+}
+
+// NewOrderRepository crea el repositorio de pedidos; users y products se usan para precargar
+// User e Items.Product y pueden ser nil, en cuyo caso quedan vacíos
+func NewOrderRepository(users repositories.UserRepository, products repositories.ProductRepository, opts ...Option) repositories.OrderRepository {
+	return &orderRepository{
+		opts:      newOptions(opts),
+		users:     users,
+		products:  products,
+		orders:    make(map[uint]domain.Order),
+		items:     make(map[uint]domain.OrderItem),
+		discounts: make(map[uint]domain.OrderItemDiscount),
+		taxLines:  make(map[uint]domain.OrderTaxLine),
+		payments:  make(map[uint]domain.Payment),
+		lastID:    make(map[string]uint),
+	}
+}
+
+// preload indica qué relaciones completar al leer, como los Preload del repositorio de GORM
+type preload struct {
+	user, items, taxLines, payments, children bool
+}
+
+var (
+	preloadAll     = preload{user: true, items: true, taxLines: true, payments: true}
+	preloadDetails = preload{user: true, items: true, taxLines: true, payments: true, children: true}
+)
+
+func (r *orderRepository) Create(ctx context.Context, order *domain.Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.orders[order.ID]; ok {
+		return duplicated("orders", "id", order.ID)
+	}
+	if err := r.checkUnique(order); err != nil {
+		return err
+	}
+
+	now := r.opts.clock.Now()
+	order.ID = r.nextID("orders", order.ID)
+	stamp(&order.CreatedAt, &order.UpdatedAt, now)
+	r.orders[order.ID] = stripOrder(*order)
+	r.saveAssociations(order, now)
+	return nil
+}
+
+func (r *orderRepository) GetByID(ctx context.Context, id uint) (*domain.Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	loaded, err := r.load(ctx, order, preloadDetails)
+	if err != nil {
+		return nil, err
+	}
+	return &loaded, nil
+}
+
+// GetByPublicID devuelve sólo el pedido; se usa para resolver su ID interno
+func (r *orderRepository) GetByPublicID(ctx context.Context, publicID string) (*domain.Order, error) {
+	return r.find(ctx, func(o domain.Order) bool { return o.PublicID != nil && *o.PublicID == publicID })
+}
+
+// GetByNumber devuelve sólo el pedido con ese número de pedido canónico
+func (r *orderRepository) GetByNumber(ctx context.Context, number string) (*domain.Order, error) {
+	return r.find(ctx, func(o domain.Order) bool { return o.Number != nil && *o.Number == number })
+}
+
+func (r *orderRepository) GetAll(ctx context.Context) ([]domain.Order, error) {
+	return r.list(ctx, func(domain.Order) bool { return true }, preloadAll, 0)
+}
+
+func (r *orderRepository) GetPendingCreatedBefore(ctx context.Context, before time.Time, afterID uint, limit int) ([]domain.Order, error) {
+	return r.list(ctx, func(o domain.Order) bool {
+		return o.Status == domain.StatusPending && o.CreatedAt.Before(before) && o.ID > afterID
+	}, preload{payments: true}, limit)
+}
+
+func (r *orderRepository) GetWithoutIdentifiers(ctx context.Context, afterID uint, limit int) ([]domain.Order, error) {
+	return r.list(ctx, func(o domain.Order) bool {
+		return (o.PublicID == nil || o.Number == nil) && o.ID > afterID
+	}, preload{}, limit)
+}
+
+// SetIdentifiers no toca UpdatedAt, como UpdateColumns en GORM
+func (r *orderRepository) SetIdentifiers(ctx context.Context, order *domain.Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.orders[order.ID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	if err := r.checkUnique(order); err != nil {
+		return err
+	}
+	stored.PublicID = clonePtr(order.PublicID)
+	stored.Number = clonePtr(order.Number)
+	r.orders[order.ID] = stored
+	return nil
+}
+
+func (r *orderRepository) GetByUserID(ctx context.Context, userID uint) ([]domain.Order, error) {
+	return r.list(ctx, func(o domain.Order) bool { return o.UserID == userID }, preloadAll, 0)
+}
+
+// Update guarda el pedido como db.Save: todas sus columnas y, de las relaciones, inserta
+// las filas nuevas y sólo reasigna la clave foránea de las existentes
+func (r *orderRepository) Update(ctx context.Context, order *domain.Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.checkUnique(order); err != nil {
+		return err
+	}
+
+	now := r.opts.clock.Now()
+	order.ID = r.nextID("orders", order.ID)
+	stamp(&order.CreatedAt, nil, now)
+	order.UpdatedAt = now
+	r.orders[order.ID] = stripOrder(*order)
+	r.saveAssociations(order, now)
+	return nil
+}
+
+func (r *orderRepository) UpdateIfStatus(ctx context.Context, order *domain.Order, status domain.OrderStatus) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.orders[order.ID]
+	if !ok || stored.Status != status {
+		return false, nil
+	}
+	if err := r.checkUnique(order); err != nil {
+		return false, err
+	}
+
+	order.UpdatedAt = r.opts.clock.Now()
+	r.orders[order.ID] = stripOrder(*order)
+	return true, nil
+}
+
+// UpdateItems reemplaza las líneas, descuentos e impuestos de un pedido.
+// Las líneas con ID se actualizan, las nuevas se insertan y las ausentes se eliminan.
+func (r *orderRepository) UpdateItems(ctx context.Context, order *domain.Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.checkUnique(order); err != nil {
+		return err
+	}
+
+	kept := make(map[uint]bool)
+	for _, item := range order.Items {
+		if item.ID != 0 {
+			kept[item.ID] = true
+		}
+	}
+	for id, item := range r.items {
+		if item.OrderID != order.ID {
+			continue
+		}
+		for discountID, discount := range r.discounts {
+			if discount.OrderItemID == id {
+				delete(r.discounts, discountID)
+			}
+		}
+		if !kept[id] {
+			delete(r.items, id)
+		}
+	}
+	for id, line := range r.taxLines {
+		if line.OrderID == order.ID {
+			delete(r.taxLines, id)
+		}
+	}
+
+	for i := range order.Items {
+		item := &order.Items[i]
+		item.OrderID = order.ID
+		item.ID = r.nextID("order_items", item.ID)
+		r.items[item.ID] = stripItem(*item)
+		for j := range item.Discounts {
+			discount := &item.Discounts[j]
+			discount.OrderItemID = item.ID
+			discount.ID = r.nextID("order_item_discounts", 0)
+			r.discounts[discount.ID] = *discount
+		}
+	}
+	for i := range order.TaxLines {
+		line := &order.TaxLines[i]
+		line.OrderID = order.ID
+		line.ID = r.nextID("order_tax_lines", 0)
+		r.taxLines[line.ID] = *line
+	}
+
+	now := r.opts.clock.Now()
+	order.ID = r.nextID("orders", order.ID)
+	stamp(&order.CreatedAt, nil, now)
+	order.UpdatedAt = now
+	r.orders[order.ID] = stripOrder(*order)
+	return nil
+}
+
+// MoveItems reasigna líneas existentes (con sus descuentos) a otro pedido
+func (r *orderRepository) MoveItems(ctx context.Context, itemIDs []uint, orderID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range itemIDs {
+		if item, ok := r.items[id]; ok {
+			item.OrderID = orderID
+			r.items[id] = item
+		}
+	}
+	return nil
+}
+
+func (r *orderRepository) find(ctx context.Context, match func(domain.Order) bool) (*domain.Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, id := range sortedKeys(r.orders) {
+		if order := r.orders[id]; match(order) {
+			loaded, err := r.load(ctx, order, preload{})
+			if err != nil {
+				return nil, err
+			}
+			return &loaded, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// list devuelve por ID los pedidos que cumplen match, hasta limit si es mayor a cero
+func (r *orderRepository) list(ctx context.Context, match func(domain.Order) bool, with preload, limit int) ([]domain.Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var orders []domain.Order
+	for _, id := range sortedKeys(r.orders) {
+		if limit > 0 && len(orders) == limit {
+			break
+		}
+		if order := r.orders[id]; match(order) {
+			loaded, err := r.load(ctx, order, with)
+			if err != nil {
+				return nil, err
+			}
+			orders = append(orders, loaded)
+		}
+	}
+	return orders, nil
+}
+
+// load arma una copia del pedido con las relaciones pedidas; debe llamarse con el lock tomado
+func (r *orderRepository) load(ctx context.Context, order domain.Order, with preload) (domain.Order, error) {
+	order.PublicID = clonePtr(order.PublicID)
+	order.Number = clonePtr(order.Number)
+	order.ParentOrderID = clonePtr(order.ParentOrderID)
+	order.MergedIntoID = clonePtr(order.MergedIntoID)
+	order.CancelledAt = clonePtr(order.CancelledAt)
+
+	if with.user && r.users != nil {
+		user, err := r.users.GetByID(ctx, order.UserID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return order, err
+		}
+		if user != nil {
+			order.User = *user
+		}
+	}
+	if with.items {
+		order.Items = []domain.OrderItem{}
+		for _, id := range sortedKeys(r.items) {
+			item := r.items[id]
+			if item.OrderID != order.ID {
+				continue
+			}
+			if r.products != nil {
+				product, err := r.products.GetByID(ctx, item.ProductID)
+				if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+					return order, err
+				}
+				if product != nil {
+					item.Product = *product
+				}
+			}
+			item.Discounts = []domain.OrderItemDiscount{}
+			for _, discountID := range sortedKeys(r.discounts) {
+				if discount := r.discounts[discountID]; discount.OrderItemID == item.ID {
+					item.Discounts = append(item.Discounts, discount)
+				}
+			}
+			order.Items = append(order.Items, item)
+		}
+	}
+	if with.taxLines {
+		order.TaxLines = []domain.OrderTaxLine{}
+		for _, id := range sortedKeys(r.taxLines) {
+			if line := r.taxLines[id]; line.OrderID == order.ID {
+				order.TaxLines = append(order.TaxLines, line)
+			}
+		}
+	}
+	if with.payments {
+		order.Payments = []domain.Payment{}
+		for _, id := range sortedKeys(r.payments) {
+			if payment := r.payments[id]; payment.OrderID == order.ID {
+				order.Payments = append(order.Payments, payment)
+			}
+		}
+	}
+	if with.children {
+		order.ChildOrders = []domain.Order{}
+		order.MergedOrders = []domain.Order{}
+		for _, id := range sortedKeys(r.orders) {
+			other, _ := r.load(ctx, r.orders[id], preload{})
+			if other.ParentOrderID != nil && *other.ParentOrderID == order.ID {
+				order.ChildOrders = append(order.ChildOrders, other)
+			}
+			if other.MergedIntoID != nil && *other.MergedIntoID == order.ID {
+				order.MergedOrders = append(order.MergedOrders, other)
+			}
+		}
+	}
+	return order, nil
+}
+
+// saveAssociations guarda las relaciones del pedido como lo hace GORM al crear o guardar:
+// inserta las filas nuevas y de las existentes sólo actualiza la clave foránea
+func (r *orderRepository) saveAssociations(order *domain.Order, now time.Time) {
+	for i := range order.Items {
+		item := &order.Items[i]
+		item.OrderID = order.ID
+		if existing, ok := r.items[item.ID]; ok {
+			existing.OrderID = order.ID
+			r.items[item.ID] = existing
+		} else {
+			item.ID = r.nextID("order_items", item.ID)
+			r.items[item.ID] = stripItem(*item)
+		}
+
+		for j := range item.Discounts {
+			discount := &item.Discounts[j]
+			discount.OrderItemID = item.ID
+			if existing, ok := r.discounts[discount.ID]; ok {
+				existing.OrderItemID = item.ID
+				r.discounts[discount.ID] = existing
+			} else {
+				discount.ID = r.nextID("order_item_discounts", discount.ID)
+				r.discounts[discount.ID] = *discount
+			}
+		}
+	}
+
+	for i := range order.TaxLines {
+		line := &order.TaxLines[i]
+		line.OrderID = order.ID
+		if existing, ok := r.taxLines[line.ID]; ok {
+			existing.OrderID = order.ID
+			r.taxLines[line.ID] = existing
+		} else {
+			line.ID = r.nextID("order_tax_lines", line.ID)
+			r.taxLines[line.ID] = *line
+		}
+	}
+
+	for i := range order.Payments {
+		payment := &order.Payments[i]
+		payment.OrderID = order.ID
+		if existing, ok := r.payments[payment.ID]; ok {
+			existing.OrderID = order.ID
+			r.payments[payment.ID] = existing
+		} else {
+			payment.ID = r.nextID("payments", payment.ID)
+			stamp(&payment.CreatedAt, &payment.UpdatedAt, now)
+			r.payments[payment.ID] = *payment
+		}
+	}
+}
+
+// checkUnique rechaza un public_id o número ya usado por otro pedido
+func (r *orderRepository) checkUnique(order *domain.Order) error {
+	for id, existing := range r.orders {
+		if id == order.ID {
+			continue
+		}
+		if order.PublicID != nil && existing.PublicID != nil && *order.PublicID == *existing.PublicID {
+			return duplicated("orders", "public_id", *order.PublicID)
+		}
+		if order.Number != nil && existing.Number != nil && *order.Number == *existing.Number {
+			return duplicated("orders", "number", *order.Number)
+		}
+	}
+	return nil
+}
+
+// nextID devuelve id si no es cero o el siguiente de la tabla, como un autoincremental
+func (r *orderRepository) nextID(table string, id uint) uint {
+	if id == 0 {
+		r.lastID[table]++
+		return r.lastID[table]
+	}
+	if id > r.lastID[table] {
+		r.lastID[table] = id
+	}
+	return id
+}
+
+func sortedKeys[T any](rows map[uint]T) []uint {
+	keys := make([]uint, 0, len(rows))
+	for id := range rows {
+		keys = append(keys, id)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// stripOrder deja sólo las columnas propias del pedido
+func stripOrder(order domain.Order) domain.Order {
+	order.User = domain.User{}
+	order.Items = nil
+	order.TaxLines = nil
+	order.Payments = nil
+	order.ChildOrders = nil
+	order.MergedOrders = nil
+	order.PublicID = clonePtr(order.PublicID)
+	order.Number = clonePtr(order.Number)
+	order.ParentOrderID = clonePtr(order.ParentOrderID)
+	order.MergedIntoID = clonePtr(order.MergedIntoID)
+	order.CancelledAt = clonePtr(order.CancelledAt)
+	return order
+}
+
+func stripItem(item domain.OrderItem) domain.OrderItem {
+	item.Product = domain.Product{}
+	item.Discounts = nil
+	return item
+}
@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/repositories"
+	"sort"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+type productRepository struct {
+	mu       sync.RWMutex
+	opts     options
+	products map[uint]domain.Product
+	nextID   uint
+}
+
+func NewProductRepository(opts ...Option) repositories.ProductRepository {
+	return &productRepository{opts: newOptions(opts), products: make(map[uint]domain.Product)}
+}
+
+func (r *productRepository) GetByID(ctx context.Context, id uint) (*domain.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	product, ok := r.products[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &product, nil
+}
+
+// UpdateStock no falla si el producto no existe, igual que un UPDATE sin filas afectadas
+func (r *productRepository) UpdateStock(ctx context.Context, id uint, quantity int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if product, ok := r.products[id]; ok {
+		product.Stock = quantity
+		r.products[id] = product
+	}
+	return nil
+}
+
+func (r *productRepository) GetAll(ctx context.Context) ([]domain.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	products := make([]domain.Product, 0, len(r.products))
+	for _, product := range r.products {
+		products = append(products, product)
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+	return products, nil
+}
+
+func (r *productRepository) Create(ctx context.Context, product *domain.Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.products[product.ID]; ok {
+		return duplicated("products", "id", product.ID)
+	}
+
+	if product.ID == 0 {
+		r.nextID++
+		product.ID = r.nextID
+	} else if product.ID > r.nextID {
+		r.nextID = product.ID
+	}
+	// GORM completa los campos vacíos que tienen default en el modelo
+	if product.TaxClass == "" {
+		product.TaxClass = domain.TaxClassStandard
+	}
+	stamp(&product.CreatedAt, nil, r.opts.clock.Now())
+	r.products[product.ID] = *product
+	return nil
+}
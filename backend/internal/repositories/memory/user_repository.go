@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/repositories"
+	"sort"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+type userRepository struct {
+	mu     sync.RWMutex
+	opts   options
+	users  map[uint]domain.User
+	nextID uint
+}
+
+func NewUserRepository(opts ...Option) repositories.UserRepository {
+	return &userRepository{opts: newOptions(opts), users: make(map[uint]domain.User)}
+}
+
+func (r *userRepository) GetByID(ctx context.Context, id uint) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	user, ok := r.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &user, nil
+}
+
+func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[user.ID]; ok {
+		return duplicated("users", "id", user.ID)
+	}
+	for _, existing := range r.users {
+		if existing.Email == user.Email {
+			return duplicated("users", "email", user.Email)
+		}
+	}
+
+	if user.ID == 0 {
+		r.nextID++
+		user.ID = r.nextID
+	} else if user.ID > r.nextID {
+		r.nextID = user.ID
+	}
+	stamp(&user.CreatedAt, nil, r.opts.clock.Now())
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *userRepository) GetAll(ctx context.Context) ([]domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	users := make([]domain.User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
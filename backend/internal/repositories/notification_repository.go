@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"order-management-system/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type notificationPreferenceRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationPreferenceRepository(db *gorm.DB) NotificationPreferenceRepository {
+	return &notificationPreferenceRepository{db: db}
+}
+
+func (r *notificationPreferenceRepository) GetByUserID(ctx context.Context, userID uint) (*domain.NotificationPreference, error) {
+	var preference domain.NotificationPreference
+	err := r.db.WithContext(ctx).First(&preference, "user_id = ?", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &preference, nil
+}
+
+func (r *notificationPreferenceRepository) Save(ctx context.Context, preference *domain.NotificationPreference) error {
+	return r.db.WithContext(ctx).Save(preference).Error
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func (r *notificationRepository) Create(ctx context.Context, notification *domain.Notification) error {
+	return r.db.WithContext(ctx).Create(notification).Error
+}
+
+func (r *notificationRepository) GetByID(ctx context.Context, id uint) (*domain.Notification, error) {
+	var notification domain.Notification
+	if err := r.db.WithContext(ctx).First(&notification, id).Error; err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
+
+// GetByUserID devuelve las notificaciones más recientes primero
+func (r *notificationRepository) GetByUserID(ctx context.Context, userID uint, limit int) ([]domain.Notification, error) {
+	var notifications []domain.Notification
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id DESC").Limit(limit).Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (r *notificationRepository) ExistsForEvent(ctx context.Context, userID, eventID uint) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&domain.Notification{}).
+		Where("user_id = ? AND event_id = ?", userID, eventID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *notificationRepository) GetDue(ctx context.Context, now time.Time, limit int) ([]domain.Notification, error) {
+	var notifications []domain.Notification
+	if err := r.db.WithContext(ctx).Where("status = ? AND next_attempt_at <= ?", domain.NotificationPending, now).
+		Order("id").Limit(limit).Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (r *notificationRepository) Update(ctx context.Context, notification *domain.Notification) error {
+	return r.db.WithContext(ctx).Save(notification).Error
+}
@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"context"
+	"order-management-system/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type orderChangeRepository struct {
+	db *gorm.DB
+}
+
+func NewOrderChangeRepository(db *gorm.DB) OrderChangeRepository {
+	return &orderChangeRepository{db: db}
+}
+
+func (r *orderChangeRepository) Create(ctx context.Context, change *domain.OrderChange) error {
+	return r.db.WithContext(ctx).Create(change).Error
+}
+
+func (r *orderChangeRepository) GetByOrderID(ctx context.Context, orderID uint) ([]domain.OrderChange, error) {
+	var changes []domain.OrderChange
+	if err := r.db.WithContext(ctx).Where("order_id = ?", orderID).Order("id").Find(&changes).Error; err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
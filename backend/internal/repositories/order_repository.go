@@ -1,8 +1,12 @@
 package repositories
 
 import (
+	"context"
 	"order-management-system/internal/domain"
+	"time"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type orderRepository struct {
@@ -13,34 +17,144 @@ func NewOrderRepository(db *gorm.DB) OrderRepository {
 	return &orderRepository{db: db}
 }
 
-func (r *orderRepository) Create(order *domain.Order) error {
-	return r.db.Create(order).Error
+func (r *orderRepository) Create(ctx context.Context, order *domain.Order) error {
+	return r.db.WithContext(ctx).Create(order).Error
+}
+
+func (r *orderRepository) GetByID(ctx context.Context, id uint) (*domain.Order, error) {
+	var order domain.Order
+	if err := r.db.WithContext(ctx).Preload("User").Preload("TaxLines").Preload("Payments").Preload("Items.Product").Preload("Items.Discounts").
+		Preload("ChildOrders").Preload("MergedOrders").First(&order, id).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
 }
 
-func (r *orderRepository) GetByID(id uint) (*domain.Order, error) {
+// GetByPublicID devuelve sólo el pedido; se usa para resolver su ID interno
+func (r *orderRepository) GetByPublicID(ctx context.Context, publicID string) (*domain.Order, error) {
 	var order domain.Order
-	if err := r.db.Preload("User").Preload("Items.Product").First(&order, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("public_id = ?", publicID).First(&order).Error; err != nil {
 		return nil, err
 	}
 	return &order, nil
 }
 
-func (r *orderRepository) GetAll() ([]domain.Order, error) {
+// GetByNumber devuelve sólo el pedido con ese número de pedido canónico
+func (r *orderRepository) GetByNumber(ctx context.Context, number string) (*domain.Order, error) {
+	var order domain.Order
+	if err := r.db.WithContext(ctx).Where("number = ?", number).First(&order).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *orderRepository) GetAll(ctx context.Context) ([]domain.Order, error) {
+	var orders []domain.Order
+	if err := r.db.WithContext(ctx).Preload("User").Preload("TaxLines").Preload("Payments").Preload("Items.Product").Preload("Items.Discounts").Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (r *orderRepository) GetPendingCreatedBefore(ctx context.Context, before time.Time, afterID uint, limit int) ([]domain.Order, error) {
 	var orders []domain.Order
-	if err := r.db.Preload("User").Preload("Items.Product").Find(&orders).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("status = ? AND created_at < ? AND id > ?", domain.StatusPending, before, afterID).
+		Preload("Payments").Order("id").Limit(limit).Find(&orders).Error; err != nil {
 		return nil, err
 	}
 	return orders, nil
 }
 
-func (r *orderRepository) GetByUserID(userID uint) ([]domain.Order, error) {
+func (r *orderRepository) GetWithoutIdentifiers(ctx context.Context, afterID uint, limit int) ([]domain.Order, error) {
 	var orders []domain.Order
-	if err := r.db.Where("user_id = ?", userID).Preload("User").Preload("Items.Product").Find(&orders).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("(public_id IS NULL OR number IS NULL) AND id > ?", afterID).
+		Order("id").Limit(limit).Find(&orders).Error; err != nil {
 		return nil, err
 	}
 	return orders, nil
 }
 
-func (r *orderRepository) Update(order *domain.Order) error {
-	return r.db.Save(order).Error
+func (r *orderRepository) SetIdentifiers(ctx context.Context, order *domain.Order) error {
+	return r.db.WithContext(ctx).Model(&domain.Order{}).Where("id = ?", order.ID).
+		UpdateColumns(map[string]interface{}{"public_id": order.PublicID, "number": order.Number}).Error
+}
+
+func (r *orderRepository) GetByUserID(ctx context.Context, userID uint) ([]domain.Order, error) {
+	var orders []domain.Order
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Preload("User").Preload("TaxLines").Preload("Payments").Preload("Items.Product").Preload("Items.Discounts").Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (r *orderRepository) Update(ctx context.Context, order *domain.Order) error {
+	return r.db.WithContext(ctx).Save(order).Error
+}
+
+func (r *orderRepository) UpdateIfStatus(ctx context.Context, order *domain.Order, status domain.OrderStatus) (bool, error) {
+	result := r.db.WithContext(ctx).Model(order).Where("status = ?", status).
+		Select("*").Omit(clause.Associations).Updates(order)
+	return result.RowsAffected > 0, result.Error
+}
+
+// UpdateItems reemplaza las líneas, descuentos e impuestos de un pedido en una transacción.
+// Las líneas con ID se actualizan, las nuevas se insertan y las ausentes se eliminan.
+func (r *orderRepository) UpdateItems(ctx context.Context, order *domain.Order) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		itemIDs := tx.Model(&domain.OrderItem{}).Select("id").Where("order_id = ?", order.ID)
+		if err := tx.Where("order_item_id IN (?)", itemIDs).Delete(&domain.OrderItemDiscount{}).Error; err != nil {
+			return err
+		}
+
+		var kept []uint
+		for _, item := range order.Items {
+			if item.ID != 0 {
+				kept = append(kept, item.ID)
+			}
+		}
+		removed := tx.Where("order_id = ?", order.ID)
+		if len(kept) > 0 {
+			removed = removed.Where("id NOT IN ?", kept)
+		}
+		if err := removed.Delete(&domain.OrderItem{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("order_id = ?", order.ID).Delete(&domain.OrderTaxLine{}).Error; err != nil {
+			return err
+		}
+
+		for i := range order.Items {
+			item := &order.Items[i]
+			item.OrderID = order.ID
+			if err := tx.Omit(clause.Associations).Save(item).Error; err != nil {
+				return err
+			}
+			for j := range item.Discounts {
+				item.Discounts[j].ID = 0
+				item.Discounts[j].OrderItemID = item.ID
+			}
+			if len(item.Discounts) > 0 {
+				if err := tx.Create(&item.Discounts).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		for i := range order.TaxLines {
+			order.TaxLines[i].ID = 0
+			order.TaxLines[i].OrderID = order.ID
+		}
+		if len(order.TaxLines) > 0 {
+			if err := tx.Create(&order.TaxLines).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Omit(clause.Associations).Save(order).Error
+	})
+}
+
+// MoveItems reasigna líneas existentes (con sus descuentos) a otro pedido
+func (r *orderRepository) MoveItems(ctx context.Context, itemIDs []uint, orderID uint) error {
+	return r.db.WithContext(ctx).Model(&domain.OrderItem{}).Where("id IN ?", itemIDs).Update("order_id", orderID).Error
 }
@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+	"order-management-system/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+func (r *outboxRepository) Create(ctx context.Context, event *domain.OutboxEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+// GetDue devuelve los eventos pendientes cuyo próximo intento ya venció, en orden de creación
+func (r *outboxRepository) GetDue(ctx context.Context, now time.Time, limit int) ([]domain.OutboxEvent, error) {
+	var events []domain.OutboxEvent
+	if err := r.db.WithContext(ctx).Where("status = ? AND next_attempt_at <= ?", domain.OutboxPending, now).
+		Order("id").Limit(limit).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *outboxRepository) OldestPending(ctx context.Context) (*domain.OutboxEvent, error) {
+	var events []domain.OutboxEvent
+	if err := r.db.WithContext(ctx).Where("status = ?", domain.OutboxPending).Order("id").Limit(1).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, nil
+	}
+	return &events[0], nil
+}
+
+func (r *outboxRepository) Update(ctx context.Context, event *domain.OutboxEvent) error {
+	return r.db.WithContext(ctx).Save(event).Error
+}
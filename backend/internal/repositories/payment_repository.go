@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"context"
+	"order-management-system/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type paymentRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentRepository(db *gorm.DB) PaymentRepository {
+	return &paymentRepository{db: db}
+}
+
+func (r *paymentRepository) Create(ctx context.Context, payment *domain.Payment) error {
+	return r.db.WithContext(ctx).Create(payment).Error
+}
+
+func (r *paymentRepository) GetByID(ctx context.Context, id uint) (*domain.Payment, error) {
+	var payment domain.Payment
+	if err := r.db.WithContext(ctx).First(&payment, id).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *paymentRepository) GetByOrderID(ctx context.Context, orderID uint) ([]domain.Payment, error) {
+	var payments []domain.Payment
+	if err := r.db.WithContext(ctx).Where("order_id = ?", orderID).Order("id").Find(&payments).Error; err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+func (r *paymentRepository) Update(ctx context.Context, payment *domain.Payment) error {
+	return r.db.WithContext(ctx).Save(payment).Error
+}
+
+// ClaimRefund compara el saldo y suma lo reembolsado en la misma sentencia, así dos reembolsos
+// simultáneos no superan lo cobrado
+func (r *paymentRepository) ClaimRefund(ctx context.Context, id uint, amount float64) (bool, error) {
+	claimed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.Payment{}).
+			Where("id = ? AND status IN ? AND refunded_amount + ? <= captured_amount + ?", id,
+				[]domain.PaymentStatus{domain.PaymentCaptured, domain.PaymentPartiallyRefunded}, amount, moneyTolerance).
+			Update("refunded_amount", gorm.Expr("refunded_amount + ?", amount))
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		claimed = true
+		return syncRefundStatus(tx, id)
+	})
+	return claimed, err
+}
+
+func (r *paymentRepository) ReleaseRefund(ctx context.Context, id uint, amount float64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&domain.Payment{}).Where("id = ?", id).
+			Update("refunded_amount", gorm.Expr("refunded_amount - ?", amount)).Error
+		if err != nil {
+			return err
+		}
+		return syncRefundStatus(tx, id)
+	})
+}
+
+// moneyTolerance absorbe el redondeo de los importes en centavos guardados como float
+const moneyTolerance = 0.005
+
+// syncRefundStatus deriva el estado del pago de lo reembolsado. Va en una sentencia aparte
+// porque MySQL evalúa el SET con los valores ya actualizados y el resto de las bases no.
+func syncRefundStatus(tx *gorm.DB, id uint) error {
+	return tx.Model(&domain.Payment{}).Where("id = ?", id).
+		Update("status", gorm.Expr("CASE WHEN refunded_amount >= captured_amount - ? THEN ? WHEN refunded_amount > ? THEN ? ELSE ? END",
+			moneyTolerance, domain.PaymentRefunded, moneyTolerance, domain.PaymentPartiallyRefunded, domain.PaymentCaptured)).Error
+}
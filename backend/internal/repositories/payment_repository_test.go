@@ -0,0 +1,66 @@
+package repositories_test
+
+import (
+	"context"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/repositories"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPaymentRepository_ClaimRefundStopsAtCaptured(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	user := domain.User{Name: "Ana", Email: "ana@example.com"}
+	if err := repositories.NewUserRepository(db).Create(ctx, &user); err != nil {
+		t.Fatalf("Expected user to be created, got %v", err)
+	}
+	order := domain.Order{UserID: user.ID, Status: domain.StatusConfirmed, Total: 100}
+	if err := repositories.NewOrderRepository(db).Create(ctx, &order); err != nil {
+		t.Fatalf("Expected order to be created, got %v", err)
+	}
+	repo := repositories.NewPaymentRepository(db)
+	payment := domain.Payment{OrderID: order.ID, Gateway: "fake", Status: domain.PaymentCaptured, Amount: 100, CapturedAmount: 100}
+	if err := repo.Create(ctx, &payment); err != nil {
+		t.Fatalf("Expected payment to be created, got %v", err)
+	}
+
+	var claimed int32
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := repo.ClaimRefund(ctx, payment.ID, 30)
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if ok {
+				atomic.AddInt32(&claimed, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	saved, _ := repo.GetByID(ctx, payment.ID)
+	if claimed != 3 || saved.RefundedAmount != 90 || saved.Status != domain.PaymentPartiallyRefunded {
+		t.Errorf("Expected 3 claims of 30 (PARTIALLY_REFUNDED), got %d claims, %f %s", claimed, saved.RefundedAmount, saved.Status)
+	}
+
+	if ok, _ := repo.ClaimRefund(ctx, payment.ID, 10); !ok {
+		t.Fatal("Expected the exact balance to be claimable")
+	}
+	if saved, _ := repo.GetByID(ctx, payment.ID); saved.Status != domain.PaymentRefunded {
+		t.Errorf("Expected REFUNDED, got %s", saved.Status)
+	}
+
+	for _, amount := range []float64{10, 90} {
+		if err := repo.ReleaseRefund(ctx, payment.ID, amount); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if saved, _ := repo.GetByID(ctx, payment.ID); saved.RefundedAmount != 0 || saved.Status != domain.PaymentCaptured {
+		t.Errorf("Expected released claims to restore CAPTURED, got %f %s", saved.RefundedAmount, saved.Status)
+	}
+}
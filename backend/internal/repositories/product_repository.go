@@ -1,7 +1,9 @@
 package repositories
 
 import (
+	"context"
 	"order-management-system/internal/domain"
+
 	"gorm.io/gorm"
 )
 
@@ -13,26 +15,26 @@ func NewProductRepository(db *gorm.DB) ProductRepository {
 	return &productRepository{db: db}
 }
 
-func (r *productRepository) GetByID(id uint) (*domain.Product, error) {
+func (r *productRepository) GetByID(ctx context.Context, id uint) (*domain.Product, error) {
 	var product domain.Product
-	if err := r.db.First(&product, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).First(&product, id).Error; err != nil {
 		return nil, err
 	}
 	return &product, nil
 }
 
-func (r *productRepository) UpdateStock(id uint, quantity int) error {
-	return r.db.Model(&domain.Product{}).Where("id = ?", id).Update("stock", quantity).Error
+func (r *productRepository) UpdateStock(ctx context.Context, id uint, quantity int) error {
+	return r.db.WithContext(ctx).Model(&domain.Product{}).Where("id = ?", id).Update("stock", quantity).Error
 }
 
-func (r *productRepository) GetAll() ([]domain.Product, error) {
+func (r *productRepository) GetAll(ctx context.Context) ([]domain.Product, error) {
 	var products []domain.Product
-	if err := r.db.Find(&products).Error; err != nil {
+	if err := r.db.WithContext(ctx).Find(&products).Error; err != nil {
 		return nil, err
 	}
 	return products, nil
 }
 
-func (r *productRepository) Create(product *domain.Product) error {
-	return r.db.Create(product).Error
+func (r *productRepository) Create(ctx context.Context, product *domain.Product) error {
+	return r.db.WithContext(ctx).Create(product).Error
 }
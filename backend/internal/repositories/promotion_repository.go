@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"context"
+	"order-management-system/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type promotionRepository struct {
+	db *gorm.DB
+}
+
+func NewPromotionRepository(db *gorm.DB) PromotionRepository {
+	return &promotionRepository{db: db}
+}
+
+func (r *promotionRepository) Create(ctx context.Context, promotion *domain.Promotion) error {
+	return r.db.WithContext(ctx).Create(promotion).Error
+}
+
+func (r *promotionRepository) GetByID(ctx context.Context, id uint) (*domain.Promotion, error) {
+	var promotion domain.Promotion
+	if err := r.db.WithContext(ctx).First(&promotion, id).Error; err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+func (r *promotionRepository) GetByCode(ctx context.Context, code string) (*domain.Promotion, error) {
+	var promotion domain.Promotion
+	if err := r.db.WithContext(ctx).Where("code = ?", code).First(&promotion).Error; err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+func (r *promotionRepository) GetAll(ctx context.Context) ([]domain.Promotion, error) {
+	var promotions []domain.Promotion
+	if err := r.db.WithContext(ctx).Find(&promotions).Error; err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+func (r *promotionRepository) GetAutomatic(ctx context.Context) ([]domain.Promotion, error) {
+	var promotions []domain.Promotion
+	if err := r.db.WithContext(ctx).Where("code IS NULL AND disabled = ?", false).Find(&promotions).Error; err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+func (r *promotionRepository) CountRedemptions(ctx context.Context, promotionID, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.PromotionRedemption{}).
+		Where("promotion_id = ? AND user_id = ?", promotionID, userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *promotionRepository) CreateRedemption(ctx context.Context, redemption *domain.PromotionRedemption) error {
+	return r.db.WithContext(ctx).Create(redemption).Error
+}
+
+func (r *promotionRepository) GetRedemptionsByOrder(ctx context.Context, orderID uint) ([]domain.PromotionRedemption, error) {
+	var redemptions []domain.PromotionRedemption
+	if err := r.db.WithContext(ctx).Where("order_id = ?", orderID).Find(&redemptions).Error; err != nil {
+		return nil, err
+	}
+	return redemptions, nil
+}
+
+func (r *promotionRepository) DeleteRedemptionsByOrder(ctx context.Context, orderID uint) error {
+	return r.db.WithContext(ctx).Where("order_id = ?", orderID).Delete(&domain.PromotionRedemption{}).Error
+}
+
+// ClaimUsage compara y suma en la misma sentencia, así dos pedidos simultáneos no superan MaxUses
+func (r *promotionRepository) ClaimUsage(ctx context.Context, id uint) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&domain.Promotion{}).
+		Where("id = ? AND (max_uses = 0 OR used_count < max_uses)", id).
+		Update("used_count", gorm.Expr("used_count + 1"))
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *promotionRepository) IncrementUsage(ctx context.Context, id uint, delta int) error {
+	return r.db.WithContext(ctx).Model(&domain.Promotion{}).Where("id = ?", id).
+		Update("used_count", gorm.Expr("used_count + ?", delta)).Error
+}
+
+// ClaimUserUsage crea el contador del usuario si falta y lo compara y suma en la misma sentencia,
+// así dos pedidos simultáneos del mismo usuario no superan MaxUsesPerUser
+func (r *promotionRepository) ClaimUserUsage(ctx context.Context, promotionID, userID uint, limit int) (bool, error) {
+	usage := &domain.PromotionUserUsage{PromotionID: promotionID, UserID: userID}
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(usage).Error; err != nil {
+		return false, err
+	}
+	result := r.db.WithContext(ctx).Model(&domain.PromotionUserUsage{}).
+		Where("promotion_id = ? AND user_id = ? AND (? = 0 OR used_count < ?)", promotionID, userID, limit, limit).
+		Update("used_count", gorm.Expr("used_count + 1"))
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *promotionRepository) IncrementUserUsage(ctx context.Context, promotionID, userID uint, delta int) error {
+	return r.db.WithContext(ctx).Model(&domain.PromotionUserUsage{}).Where("promotion_id = ? AND user_id = ?", promotionID, userID).
+		Update("used_count", gorm.Expr("used_count + ?", delta)).Error
+}
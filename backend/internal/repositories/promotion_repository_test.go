@@ -0,0 +1,90 @@
+package repositories_test
+
+import (
+	"context"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/repositories"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPromotionRepository_ClaimUsageStopsAtMaxUses(t *testing.T) {
+	ctx := context.Background()
+	repo := repositories.NewPromotionRepository(openTestDB(t))
+
+	limited := domain.Promotion{Name: "Dos usos", Type: domain.PromotionPercentage, Value: 10, MaxUses: 2}
+	unlimited := domain.Promotion{Name: "Sin límite", Type: domain.PromotionPercentage, Value: 5}
+	for _, promotion := range []*domain.Promotion{&limited, &unlimited} {
+		if err := repo.Create(ctx, promotion); err != nil {
+			t.Fatalf("Expected promotion to be created, got %v", err)
+		}
+	}
+
+	var claimed int32
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := repo.ClaimUsage(ctx, limited.ID)
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if ok {
+				atomic.AddInt32(&claimed, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	saved, _ := repo.GetByID(ctx, limited.ID)
+	if claimed != 2 || saved.UsedCount != 2 {
+		t.Errorf("Expected exactly 2 claims, got %d claims and used_count %d", claimed, saved.UsedCount)
+	}
+
+	for i := 0; i < 3; i++ {
+		if ok, err := repo.ClaimUsage(ctx, unlimited.ID); !ok || err != nil {
+			t.Errorf("Expected a promotion without MaxUses to be claimable, got %t (%v)", ok, err)
+		}
+	}
+}
+
+func TestPromotionRepository_ClaimUserUsageStopsAtLimit(t *testing.T) {
+	ctx := context.Background()
+	repo := repositories.NewPromotionRepository(openTestDB(t))
+	promotion := domain.Promotion{Name: "Una vez", Type: domain.PromotionPercentage, Value: 10, MaxUsesPerUser: 1}
+	if err := repo.Create(ctx, &promotion); err != nil {
+		t.Fatalf("Expected promotion to be created, got %v", err)
+	}
+
+	var claimed int32
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := repo.ClaimUserUsage(ctx, promotion.ID, 1, promotion.MaxUsesPerUser)
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if ok {
+				atomic.AddInt32(&claimed, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if claimed != 1 {
+		t.Errorf("Expected exactly 1 claim, got %d", claimed)
+	}
+
+	if ok, err := repo.ClaimUserUsage(ctx, promotion.ID, 2, promotion.MaxUsesPerUser); !ok || err != nil {
+		t.Errorf("Expected another user to claim, got %t (%v)", ok, err)
+	}
+	if err := repo.IncrementUserUsage(ctx, promotion.ID, 1, -1); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if ok, err := repo.ClaimUserUsage(ctx, promotion.ID, 1, promotion.MaxUsesPerUser); !ok || err != nil {
+		t.Errorf("Expected a released use to be claimable again, got %t (%v)", ok, err)
+	}
+}
@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"context"
+	"order-management-system/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type refundRepository struct {
+	db *gorm.DB
+}
+
+func NewRefundRepository(db *gorm.DB) RefundRepository {
+	return &refundRepository{db: db}
+}
+
+func (r *refundRepository) Create(ctx context.Context, refund *domain.Refund) error {
+	return r.db.WithContext(ctx).Create(refund).Error
+}
+
+func (r *refundRepository) GetByOrderID(ctx context.Context, orderID uint) ([]domain.Refund, error) {
+	var refunds []domain.Refund
+	if err := r.db.WithContext(ctx).Where("order_id = ?", orderID).Preload("Lines").Order("id").Find(&refunds).Error; err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"context"
+	"order-management-system/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type reportRepository struct {
+	db *gorm.DB
+}
+
+func NewReportRepository(db *gorm.DB) ReportRepository {
+	return &reportRepository{db: db}
+}
+
+func (r *reportRepository) SalesByStatus(ctx context.Context, from, to time.Time) ([]domain.StatusSales, error) {
+	var sales []domain.StatusSales
+	if err := r.db.WithContext(ctx).Model(&domain.Order{}).
+		Select("status, COUNT(*) AS orders, COALESCE(SUM(total), 0) AS total").
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Group("status").Order("status").
+		Scan(&sales).Error; err != nil {
+		return nil, err
+	}
+	return sales, nil
+}
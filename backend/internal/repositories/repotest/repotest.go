@@ -0,0 +1,502 @@
+// Package repotest tiene la suite de contrato de los repositorios: el comportamiento que
+// tienen que respetar por igual la implementación de GORM y la de memoria.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/repositories"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Repos son los repositorios bajo prueba
+type Repos struct {
+	Users    repositories.UserRepository
+	Products repositories.ProductRepository
+	Orders   repositories.OrderRepository
+}
+
+// Run corre la suite completa; newRepos tiene que devolver repositorios vacíos e
+// independientes en cada llamada
+func Run(t *testing.T, newRepos func(t *testing.T) Repos) {
+	t.Run("Users", func(t *testing.T) { testUsers(t, newRepos(t)) })
+	t.Run("Products", func(t *testing.T) { testProducts(t, newRepos(t)) })
+	t.Run("OrdersCreateAndPreload", func(t *testing.T) { testOrderCreate(t, newRepos(t)) })
+	t.Run("OrdersLookups", func(t *testing.T) { testOrderLookups(t, newRepos(t)) })
+	t.Run("OrdersUpdate", func(t *testing.T) { testOrderUpdate(t, newRepos(t)) })
+	t.Run("OrdersUpdateIfStatus", func(t *testing.T) { testOrderUpdateIfStatus(t, newRepos(t)) })
+	t.Run("OrdersUpdateItems", func(t *testing.T) { testOrderUpdateItems(t, newRepos(t)) })
+	t.Run("OrdersMoveItems", func(t *testing.T) { testOrderMoveItems(t, newRepos(t)) })
+	t.Run("OrdersIdentifiers", func(t *testing.T) { testOrderIdentifiers(t, newRepos(t)) })
+	t.Run("OrdersPendingCreatedBefore", func(t *testing.T) { testOrderPending(t, newRepos(t)) })
+	t.Run("ConcurrentWrites", func(t *testing.T) { testConcurrentWrites(t, newRepos(t)) })
+}
+
+func strPtr(s string) *string { return &s }
+
+// seed crea un usuario y dos productos
+func seed(t *testing.T, repos Repos) (domain.User, domain.Product, domain.Product) {
+	t.Helper()
+	ctx := context.Background()
+	user := domain.User{Name: "Ana", Email: "ana@example.com"}
+	laptop := domain.Product{Name: "Laptop", Price: 1000, Stock: 5, Category: "computers"}
+	mouse := domain.Product{Name: "Mouse", Price: 20, Stock: 50, TaxClass: domain.TaxClassReduced}
+	if err := repos.Users.Create(ctx, &user); err != nil {
+		t.Fatalf("Expected user to be created, got %v", err)
+	}
+	for _, product := range []*domain.Product{&laptop, &mouse} {
+		if err := repos.Products.Create(ctx, product); err != nil {
+			t.Fatalf("Expected product to be created, got %v", err)
+		}
+	}
+	return user, laptop, mouse
+}
+
+func newOrder(user domain.User, products ...domain.Product) domain.Order {
+	order := domain.Order{UserID: user.ID, Status: domain.StatusPending}
+	for _, product := range products {
+		order.Items = append(order.Items, domain.OrderItem{ProductID: product.ID, Quantity: 1, Price: product.Price})
+		order.Total += product.Price
+	}
+	return order
+}
+
+func mustGetOrder(t *testing.T, repos Repos, id uint) *domain.Order {
+	t.Helper()
+	ctx := context.Background()
+	order, err := repos.Orders.GetByID(ctx, id)
+	if err != nil {
+		t.Fatalf("Expected order %d, got %v", id, err)
+	}
+	return order
+}
+
+func testUsers(t *testing.T, repos Repos) {
+	ctx := context.Background()
+	first := domain.User{Name: "Ana", Email: "ana@example.com"}
+	second := domain.User{Name: "Luis", Email: "luis@example.com"}
+	for _, user := range []*domain.User{&first, &second} {
+		if err := repos.Users.Create(ctx, user); err != nil {
+			t.Fatalf("Expected user to be created, got %v", err)
+		}
+	}
+	if first.ID == 0 || second.ID <= first.ID || first.CreatedAt.IsZero() {
+		t.Errorf("Expected increasing IDs and CreatedAt, got %+v and %+v", first, second)
+	}
+
+	found, err := repos.Users.GetByID(ctx, first.ID)
+	if err != nil || found.Email != "ana@example.com" || found.Name != "Ana" {
+		t.Fatalf("Expected to find Ana, got %+v (%v)", found, err)
+	}
+	found.Name = "changed"
+	if again, _ := repos.Users.GetByID(ctx, first.ID); again.Name != "Ana" {
+		t.Errorf("Expected stored user to be independent of returned copies, got %q", again.Name)
+	}
+
+	if _, err := repos.Users.GetByID(ctx, 999); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected gorm.ErrRecordNotFound, got %v", err)
+	}
+	if err := repos.Users.Create(ctx, &domain.User{Name: "Otra Ana", Email: "ana@example.com"}); err == nil {
+		t.Error("Expected duplicated email to be rejected")
+	}
+
+	all, err := repos.Users.GetAll(ctx)
+	if err != nil || len(all) != 2 || all[0].ID != first.ID || all[1].ID != second.ID {
+		t.Errorf("Expected both users ordered by ID, got %+v (%v)", all, err)
+	}
+}
+
+func testProducts(t *testing.T, repos Repos) {
+	ctx := context.Background()
+	_, laptop, mouse := seed(t, repos)
+	if laptop.TaxClass != domain.TaxClassStandard {
+		t.Errorf("Expected default tax class on create, got %q", laptop.TaxClass)
+	}
+
+	if err := repos.Products.UpdateStock(ctx, laptop.ID, 3); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	found, err := repos.Products.GetByID(ctx, laptop.ID)
+	if err != nil || found.Stock != 3 || found.Category != "computers" || found.TaxClass != domain.TaxClassStandard {
+		t.Errorf("Expected stock 3 with the other columns intact, got %+v (%v)", found, err)
+	}
+	if err := repos.Products.UpdateStock(ctx, 999, 1); err != nil {
+		t.Errorf("Expected updating a missing product to be a no-op, got %v", err)
+	}
+	if _, err := repos.Products.GetByID(ctx, 999); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected gorm.ErrRecordNotFound, got %v", err)
+	}
+
+	all, err := repos.Products.GetAll(ctx)
+	if err != nil || len(all) != 2 || all[0].ID != laptop.ID || all[1].ID != mouse.ID || all[1].TaxClass != domain.TaxClassReduced {
+		t.Errorf("Expected both products ordered by ID, got %+v (%v)", all, err)
+	}
+}
+
+func testOrderCreate(t *testing.T, repos Repos) {
+	ctx := context.Background()
+	user, laptop, mouse := seed(t, repos)
+	order := newOrder(user, laptop, mouse)
+	order.Items[0].Discounts = []domain.OrderItemDiscount{{PromotionID: 1, Code: "SAVE10", Type: domain.PromotionPercentage, Amount: 100}}
+	order.TaxLines = []domain.OrderTaxLine{{TaxClass: domain.TaxClassStandard, Name: "IVA", Rate: 0.21, Base: 900, Amount: 189}}
+	if err := repos.Orders.Create(ctx, &order); err != nil {
+		t.Fatalf("Expected order to be created, got %v", err)
+	}
+	if order.ID == 0 || order.CreatedAt.IsZero() || order.UpdatedAt.IsZero() {
+		t.Fatalf("Expected ID and timestamps to be set, got %+v", order)
+	}
+	for _, item := range order.Items {
+		if item.ID == 0 || item.OrderID != order.ID {
+			t.Errorf("Expected items to get an ID and the order ID, got %+v", item)
+		}
+	}
+	if d := order.Items[0].Discounts[0]; d.ID == 0 || d.OrderItemID != order.Items[0].ID {
+		t.Errorf("Expected discount to be linked to its item, got %+v", d)
+	}
+	if line := order.TaxLines[0]; line.ID == 0 || line.OrderID != order.ID {
+		t.Errorf("Expected tax line to be linked to the order, got %+v", line)
+	}
+
+	found := mustGetOrder(t, repos, order.ID)
+	if found.User.Email != user.Email || len(found.Items) != 2 || len(found.TaxLines) != 1 {
+		t.Fatalf("Expected user, items and tax lines preloaded, got %+v", found)
+	}
+	if found.Items[0].Product.Name != "Laptop" || found.Items[1].Product.Name != "Mouse" {
+		t.Errorf("Expected items ordered by ID with their products, got %+v", found.Items)
+	}
+	if len(found.Items[0].Discounts) != 1 || found.Items[0].Discounts[0].Code != "SAVE10" || len(found.Items[1].Discounts) != 0 {
+		t.Errorf("Expected item discounts preloaded, got %+v", found.Items)
+	}
+
+	found.Items[0].Quantity = 99
+	found.Items = found.Items[:1]
+	if again := mustGetOrder(t, repos, order.ID); len(again.Items) != 2 || again.Items[0].Quantity != 1 {
+		t.Errorf("Expected stored order to be independent of returned copies, got %+v", again.Items)
+	}
+	if _, err := repos.Orders.GetByID(ctx, 999); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected gorm.ErrRecordNotFound, got %v", err)
+	}
+}
+
+func testOrderLookups(t *testing.T, repos Repos) {
+	ctx := context.Background()
+	user, laptop, mouse := seed(t, repos)
+	other := domain.User{Name: "Luis", Email: "luis@example.com"}
+	if err := repos.Users.Create(ctx, &other); err != nil {
+		t.Fatalf("Expected user to be created, got %v", err)
+	}
+
+	first := newOrder(user, laptop)
+	first.PublicID = strPtr("01HZY3C7W8X9Y0Z1A2B3C4D5E6")
+	first.Number = strPtr("ORD-2024-01234567-8")
+	second := newOrder(other, mouse)
+	third := newOrder(user, mouse)
+	for _, order := range []*domain.Order{&first, &second, &third} {
+		if err := repos.Orders.Create(ctx, order); err != nil {
+			t.Fatalf("Expected order to be created, got %v", err)
+		}
+	}
+
+	byPublicID, err := repos.Orders.GetByPublicID(ctx, "01HZY3C7W8X9Y0Z1A2B3C4D5E6")
+	if err != nil || byPublicID.ID != first.ID || len(byPublicID.Items) != 0 || byPublicID.User.ID != 0 {
+		t.Errorf("Expected the bare order by public ID, got %+v (%v)", byPublicID, err)
+	}
+	byNumber, err := repos.Orders.GetByNumber(ctx, "ORD-2024-01234567-8")
+	if err != nil || byNumber.ID != first.ID || *byNumber.Number != "ORD-2024-01234567-8" {
+		t.Errorf("Expected the order by number, got %+v (%v)", byNumber, err)
+	}
+	if _, err := repos.Orders.GetByPublicID(ctx, "01HZY3C7W8X9Y0Z1A2B3C4D5E7"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected gorm.ErrRecordNotFound, got %v", err)
+	}
+	if _, err := repos.Orders.GetByNumber(ctx, "ORD-2024-00000000-0"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected gorm.ErrRecordNotFound, got %v", err)
+	}
+
+	clash := newOrder(user, laptop)
+	clash.Number = strPtr("ORD-2024-01234567-8")
+	if err := repos.Orders.Create(ctx, &clash); !errors.Is(err, repositories.ErrDuplicatedKey) {
+		t.Errorf("Expected ErrDuplicatedKey for a duplicated order number, got %v", err)
+	}
+
+	all, err := repos.Orders.GetAll(ctx)
+	if err != nil || len(all) != 3 || all[0].ID != first.ID || all[2].ID != third.ID {
+		t.Fatalf("Expected three orders ordered by ID, got %d (%v)", len(all), err)
+	}
+	if all[1].User.Email != other.Email || all[1].Items[0].Product.Name != "Mouse" {
+		t.Errorf("Expected GetAll to preload users and products, got %+v", all[1])
+	}
+
+	mine, err := repos.Orders.GetByUserID(ctx, user.ID)
+	if err != nil || len(mine) != 2 || mine[0].ID != first.ID || mine[1].ID != third.ID || mine[1].Items[0].Product.Name != "Mouse" {
+		t.Errorf("Expected only the user's orders with products, got %+v (%v)", mine, err)
+	}
+	if none, err := repos.Orders.GetByUserID(ctx, 999); err != nil || len(none) != 0 {
+		t.Errorf("Expected no orders, got %+v (%v)", none, err)
+	}
+}
+
+func testOrderUpdate(t *testing.T, repos Repos) {
+	ctx := context.Background()
+	user, laptop, mouse := seed(t, repos)
+	parent := newOrder(user, laptop)
+	if err := repos.Orders.Create(ctx, &parent); err != nil {
+		t.Fatalf("Expected order to be created, got %v", err)
+	}
+
+	order := mustGetOrder(t, repos, parent.ID)
+	order.Status = domain.StatusConfirmed
+	order.TrackingNumber = "TRACK-1"
+	order.Items[0].Quantity = 7
+	order.Items = append(order.Items, domain.OrderItem{ProductID: mouse.ID, Quantity: 2, Price: mouse.Price})
+	if err := repos.Orders.Update(ctx, order); err != nil {
+		t.Fatalf("Expected order to be updated, got %v", err)
+	}
+	if order.Items[1].ID == 0 {
+		t.Error("Expected new item to get an ID")
+	}
+
+	found := mustGetOrder(t, repos, parent.ID)
+	if found.Status != domain.StatusConfirmed || found.TrackingNumber != "TRACK-1" || len(found.Items) != 2 {
+		t.Fatalf("Expected columns saved and new item inserted, got %+v", found)
+	}
+	// Como db.Save, las líneas existentes sólo se reasignan; sus columnas no se pisan
+	if found.Items[0].Quantity != 1 || found.Items[1].Quantity != 2 {
+		t.Errorf("Expected existing item untouched and new one inserted, got %+v", found.Items)
+	}
+
+	split := newOrder(user, mouse)
+	split.ParentOrderID = &parent.ID
+	merged := newOrder(user, laptop)
+	if err := repos.Orders.Create(ctx, &split); err != nil {
+		t.Fatalf("Expected order to be created, got %v", err)
+	}
+	if err := repos.Orders.Create(ctx, &merged); err != nil {
+		t.Fatalf("Expected order to be created, got %v", err)
+	}
+	merged.Status = domain.StatusMerged
+	merged.MergedIntoID = &parent.ID
+	if err := repos.Orders.Update(ctx, &merged); err != nil {
+		t.Fatalf("Expected order to be updated, got %v", err)
+	}
+
+	found = mustGetOrder(t, repos, parent.ID)
+	if len(found.ChildOrders) != 1 || found.ChildOrders[0].ID != split.ID || len(found.MergedOrders) != 1 || found.MergedOrders[0].ID != merged.ID {
+		t.Errorf("Expected child and merged orders preloaded, got %+v and %+v", found.ChildOrders, found.MergedOrders)
+	}
+}
+
+func testOrderUpdateIfStatus(t *testing.T, repos Repos) {
+	ctx := context.Background()
+	user, laptop, _ := seed(t, repos)
+	order := newOrder(user, laptop)
+	if err := repos.Orders.Create(ctx, &order); err != nil {
+		t.Fatalf("Expected order to be created, got %v", err)
+	}
+
+	// Una lectura vieja que todavía ve el pedido CONFIRMED no puede pisar el estado actual
+	stale := mustGetOrder(t, repos, order.ID)
+	stale.Status = domain.StatusCancelled
+	stale.CancellationReason = "stale"
+	if updated, err := repos.Orders.UpdateIfStatus(ctx, stale, domain.StatusConfirmed); err != nil || updated {
+		t.Fatalf("Expected no update for a stale status, got %v (%v)", updated, err)
+	}
+	if found := mustGetOrder(t, repos, order.ID); found.Status != domain.StatusPending || found.CancellationReason != "" {
+		t.Fatalf("Expected order untouched, got %+v", found)
+	}
+
+	current := mustGetOrder(t, repos, order.ID)
+	current.Status = domain.StatusCancelled
+	current.CancellationReason = "changed my mind"
+	if updated, err := repos.Orders.UpdateIfStatus(ctx, current, domain.StatusPending); err != nil || !updated {
+		t.Fatalf("Expected the update to apply, got %v (%v)", updated, err)
+	}
+	found := mustGetOrder(t, repos, order.ID)
+	if found.Status != domain.StatusCancelled || found.CancellationReason != "changed my mind" || len(found.Items) != 1 {
+		t.Errorf("Expected cancelled order with its items, got %+v", found)
+	}
+}
+
+func testOrderIdentifiers(t *testing.T, repos Repos) {
+	ctx := context.Background()
+	user, laptop, mouse := seed(t, repos)
+	legacy := newOrder(user, laptop)
+	stamped := newOrder(user, mouse)
+	stamped.PublicID = strPtr("01HZY3C7W8X9Y0Z1A2B3C4D5E6")
+	stamped.Number = strPtr("ORD-2024-01234567-8")
+	for _, order := range []*domain.Order{&legacy, &stamped} {
+		if err := repos.Orders.Create(ctx, order); err != nil {
+			t.Fatalf("Expected order to be created, got %v", err)
+		}
+	}
+
+	missing, err := repos.Orders.GetWithoutIdentifiers(ctx, 0, 10)
+	if err != nil || len(missing) != 1 || missing[0].ID != legacy.ID {
+		t.Fatalf("Expected only the legacy order, got %d (%v)", len(missing), err)
+	}
+	if after, _ := repos.Orders.GetWithoutIdentifiers(ctx, legacy.ID, 10); len(after) != 0 {
+		t.Errorf("Expected no orders after the legacy one, got %d", len(after))
+	}
+
+	clash := missing[0]
+	clash.PublicID = strPtr("01HZY3C7W8X9Y0Z1A2B3C4D5E7")
+	clash.Number = stamped.Number
+	if err := repos.Orders.SetIdentifiers(ctx, &clash); !errors.Is(err, repositories.ErrDuplicatedKey) {
+		t.Fatalf("Expected ErrDuplicatedKey, got %v", err)
+	}
+
+	clash.Number = strPtr("ORD-2024-07654321-0")
+	if err := repos.Orders.SetIdentifiers(ctx, &clash); err != nil {
+		t.Fatalf("Expected identifiers to be set, got %v", err)
+	}
+	found := mustGetOrder(t, repos, legacy.ID)
+	if found.PublicID == nil || *found.PublicID != *clash.PublicID || found.Number == nil || *found.Number != *clash.Number {
+		t.Errorf("Expected the new identifiers, got %v %v", found.PublicID, found.Number)
+	}
+	if !found.UpdatedAt.Equal(legacy.UpdatedAt) || len(found.Items) != 1 {
+		t.Errorf("Expected only the identifiers to change, got %+v", found)
+	}
+	if missing, _ := repos.Orders.GetWithoutIdentifiers(ctx, 0, 10); len(missing) != 0 {
+		t.Errorf("Expected no orders without identifiers, got %d", len(missing))
+	}
+}
+
+func testOrderUpdateItems(t *testing.T, repos Repos) {
+	ctx := context.Background()
+	user, laptop, mouse := seed(t, repos)
+	order := newOrder(user, laptop, mouse)
+	order.Items[0].Discounts = []domain.OrderItemDiscount{{PromotionID: 1, Code: "OLD", Type: domain.PromotionFixed, Amount: 50}}
+	order.TaxLines = []domain.OrderTaxLine{{TaxClass: domain.TaxClassStandard, Name: "IVA", Rate: 0.21, Base: 1000, Amount: 210}}
+	if err := repos.Orders.Create(ctx, &order); err != nil {
+		t.Fatalf("Expected order to be created, got %v", err)
+	}
+	removedID := order.Items[1].ID
+
+	edited := mustGetOrder(t, repos, order.ID)
+	edited.Items = []domain.OrderItem{edited.Items[0], {ProductID: mouse.ID, Quantity: 3, Price: mouse.Price}}
+	edited.Items[0].Quantity = 2
+	edited.Items[0].Discounts = []domain.OrderItemDiscount{{PromotionID: 2, Code: "NEW", Type: domain.PromotionPercentage, Amount: 200}}
+	edited.TaxLines = []domain.OrderTaxLine{
+		{TaxClass: domain.TaxClassStandard, Name: "IVA", Rate: 0.21, Base: 1800, Amount: 378},
+		{TaxClass: domain.TaxClassReduced, Name: "IVA reducido", Rate: 0.105, Base: 60, Amount: 6.3},
+	}
+	edited.Total = 1860
+	if err := repos.Orders.UpdateItems(ctx, edited); err != nil {
+		t.Fatalf("Expected items to be replaced, got %v", err)
+	}
+
+	found := mustGetOrder(t, repos, order.ID)
+	if len(found.Items) != 2 || found.Items[0].ID != order.Items[0].ID || found.Items[0].Quantity != 2 || found.Total != 1860 {
+		t.Fatalf("Expected kept item updated and order saved, got %+v", found)
+	}
+	if found.Items[1].ID == removedID || found.Items[1].Quantity != 3 {
+		t.Errorf("Expected removed item deleted and new item inserted, got %+v", found.Items[1])
+	}
+	if len(found.Items[0].Discounts) != 1 || found.Items[0].Discounts[0].Code != "NEW" {
+		t.Errorf("Expected discounts replaced, got %+v", found.Items[0].Discounts)
+	}
+	if len(found.TaxLines) != 2 || found.TaxLines[1].Name != "IVA reducido" {
+		t.Errorf("Expected tax lines replaced, got %+v", found.TaxLines)
+	}
+}
+
+func testOrderMoveItems(t *testing.T, repos Repos) {
+	ctx := context.Background()
+	user, laptop, mouse := seed(t, repos)
+	source := newOrder(user, laptop, mouse)
+	target := newOrder(user, laptop)
+	source.Items[1].Discounts = []domain.OrderItemDiscount{{PromotionID: 1, Code: "MOVE", Type: domain.PromotionFixed, Amount: 5}}
+	for _, order := range []*domain.Order{&source, &target} {
+		if err := repos.Orders.Create(ctx, order); err != nil {
+			t.Fatalf("Expected order to be created, got %v", err)
+		}
+	}
+
+	if err := repos.Orders.MoveItems(ctx, []uint{source.Items[1].ID}, target.ID); err != nil {
+		t.Fatalf("Expected items to be moved, got %v", err)
+	}
+	from := mustGetOrder(t, repos, source.ID)
+	to := mustGetOrder(t, repos, target.ID)
+	// Las líneas se leen por ID, así que la movida queda antes que la propia del destino
+	if len(from.Items) != 1 || len(to.Items) != 2 || to.Items[0].ID != source.Items[1].ID || to.Items[0].OrderID != target.ID {
+		t.Fatalf("Expected item moved to the target order, got %+v and %+v", from.Items, to.Items)
+	}
+	if len(to.Items[0].Discounts) != 1 || to.Items[0].Discounts[0].Code != "MOVE" {
+		t.Errorf("Expected discounts to follow the item, got %+v", to.Items[0].Discounts)
+	}
+}
+
+func testOrderPending(t *testing.T, repos Repos) {
+	ctx := context.Background()
+	user, laptop, _ := seed(t, repos)
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	var ids []uint
+	for i, status := range []domain.OrderStatus{domain.StatusPending, domain.StatusConfirmed, domain.StatusPending, domain.StatusPending, domain.StatusPending} {
+		order := newOrder(user, laptop)
+		order.Status = status
+		order.CreatedAt = now.Add(-time.Duration(5-i) * time.Hour)
+		if i == 4 {
+			order.CreatedAt = now.Add(time.Hour)
+		}
+		if i == 0 {
+			order.Payments = []domain.Payment{{Gateway: "fake", Status: domain.PaymentAuthorized, Amount: laptop.Price}}
+		}
+		if err := repos.Orders.Create(ctx, &order); err != nil {
+			t.Fatalf("Expected order to be created, got %v", err)
+		}
+		ids = append(ids, order.ID)
+	}
+
+	page, err := repos.Orders.GetPendingCreatedBefore(ctx, now, 0, 2)
+	if err != nil || len(page) != 2 || page[0].ID != ids[0] || page[1].ID != ids[2] {
+		t.Fatalf("Expected the first two stale pending orders, got %+v (%v)", page, err)
+	}
+	if len(page[0].Payments) != 1 || page[0].Payments[0].Gateway != "fake" || page[0].Payments[0].CreatedAt.IsZero() {
+		t.Errorf("Expected payments preloaded, got %+v", page[0].Payments)
+	}
+
+	page, err = repos.Orders.GetPendingCreatedBefore(ctx, now, ids[2], 2)
+	if err != nil || len(page) != 1 || page[0].ID != ids[3] {
+		t.Errorf("Expected only the last stale pending order, got %+v (%v)", page, err)
+	}
+}
+
+func testConcurrentWrites(t *testing.T, repos Repos) {
+	ctx := context.Background()
+	_, laptop, _ := seed(t, repos)
+	const writers = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*2)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- repos.Users.Create(ctx, &domain.User{Name: "user", Email: fmt.Sprintf("user%d@example.com", i)})
+			errs <- repos.Products.UpdateStock(ctx, laptop.ID, i)
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Expected concurrent writes to succeed, got %v", err)
+		}
+	}
+
+	users, err := repos.Users.GetAll(ctx)
+	if err != nil || len(users) != writers+1 {
+		t.Errorf("Expected %d users, got %d (%v)", writers+1, len(users), err)
+	}
+	seen := make(map[uint]bool)
+	for _, user := range users {
+		if seen[user.ID] {
+			t.Errorf("Expected unique IDs, got %d twice", user.ID)
+		}
+		seen[user.ID] = true
+	}
+}
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"context"
+	"order-management-system/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type returnRepository struct {
+	db *gorm.DB
+}
+
+func NewReturnRepository(db *gorm.DB) ReturnRepository {
+	return &returnRepository{db: db}
+}
+
+func (r *returnRepository) Create(ctx context.Context, rma *domain.ReturnAuthorization) error {
+	return r.db.WithContext(ctx).Create(rma).Error
+}
+
+func (r *returnRepository) GetByID(ctx context.Context, id uint) (*domain.ReturnAuthorization, error) {
+	var rma domain.ReturnAuthorization
+	if err := r.preload(ctx).First(&rma, id).Error; err != nil {
+		return nil, err
+	}
+	return &rma, nil
+}
+
+func (r *returnRepository) GetByOrderID(ctx context.Context, orderID uint) ([]domain.ReturnAuthorization, error) {
+	var rmas []domain.ReturnAuthorization
+	if err := r.preload(ctx).Where("order_id = ?", orderID).Order("id").Find(&rmas).Error; err != nil {
+		return nil, err
+	}
+	return rmas, nil
+}
+
+// Update guarda la devolución junto con los destinos de sus ítems y las transiciones nuevas
+func (r *returnRepository) Update(ctx context.Context, rma *domain.ReturnAuthorization) error {
+	return r.db.WithContext(ctx).Session(&gorm.Session{FullSaveAssociations: true}).Save(rma).Error
+}
+
+// UpdateIfStatus compara el estado y guarda en la misma sentencia; las asociaciones se guardan
+// en la misma transacción sólo si el estado coincidía
+func (r *returnRepository) UpdateIfStatus(ctx context.Context, rma *domain.ReturnAuthorization, status domain.ReturnStatus) (bool, error) {
+	updated := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(rma).Where("status = ?", status).Select("*").Omit(clause.Associations).Updates(rma)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		updated = true
+		return tx.Session(&gorm.Session{FullSaveAssociations: true}).Save(rma).Error
+	})
+	return updated, err
+}
+
+func (r *returnRepository) preload(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Preload("Items").Preload("Transitions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	})
+}
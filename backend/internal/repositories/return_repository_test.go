@@ -0,0 +1,44 @@
+package repositories_test
+
+import (
+	"context"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/repositories"
+	"testing"
+)
+
+func TestReturnRepository_UpdateIfStatusChecksStoredStatus(t *testing.T) {
+	ctx := context.Background()
+	repo := repositories.NewReturnRepository(openTestDB(t))
+	rma := domain.ReturnAuthorization{
+		OrderID:     1,
+		Status:      domain.ReturnReceived,
+		Reason:      "damaged",
+		Items:       []domain.ReturnItem{{OrderItemID: 1, Quantity: 1}},
+		Transitions: []domain.ReturnTransition{{ToStatus: domain.ReturnReceived}},
+	}
+	if err := repo.Create(ctx, &rma); err != nil {
+		t.Fatalf("Expected return to be created, got %v", err)
+	}
+
+	inspected := rma
+	inspected.Status = domain.ReturnInspected
+	inspected.Items = []domain.ReturnItem{rma.Items[0]}
+	inspected.Items[0].Disposition = domain.DispositionRestock
+	inspected.Transitions = append(rma.Transitions, domain.ReturnTransition{ReturnID: rma.ID, FromStatus: domain.ReturnReceived, ToStatus: domain.ReturnInspected})
+
+	if updated, err := repo.UpdateIfStatus(ctx, &inspected, domain.ReturnApproved); updated || err != nil {
+		t.Fatalf("Expected no update for a stale status, got %t (%v)", updated, err)
+	}
+	if saved, _ := repo.GetByID(ctx, rma.ID); saved.Status != domain.ReturnReceived || len(saved.Transitions) != 1 || saved.Items[0].Disposition != "" {
+		t.Fatalf("Expected the return untouched, got %+v", saved)
+	}
+
+	if updated, err := repo.UpdateIfStatus(ctx, &inspected, domain.ReturnReceived); !updated || err != nil {
+		t.Fatalf("Expected update, got %t (%v)", updated, err)
+	}
+	saved, _ := repo.GetByID(ctx, rma.ID)
+	if saved.Status != domain.ReturnInspected || len(saved.Transitions) != 2 || saved.Items[0].Disposition != domain.DispositionRestock {
+		t.Errorf("Expected INSPECTED with its disposition and transition, got %+v", saved)
+	}
+}
@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"order-management-system/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type shippingLabelRepository struct {
+	db *gorm.DB
+}
+
+func NewShippingLabelRepository(db *gorm.DB) ShippingLabelRepository {
+	return &shippingLabelRepository{db: db}
+}
+
+func (r *shippingLabelRepository) Create(ctx context.Context, label *domain.ShippingLabel) error {
+	return r.db.WithContext(ctx).Create(label).Error
+}
+
+func (r *shippingLabelRepository) GetByTrackingNumber(ctx context.Context, trackingNumber string) (*domain.ShippingLabel, error) {
+	var label domain.ShippingLabel
+	err := r.db.WithContext(ctx).First(&label, "tracking_number = ?", trackingNumber).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &label, nil
+}
+
+func (r *shippingLabelRepository) Update(ctx context.Context, label *domain.ShippingLabel) error {
+	return r.db.WithContext(ctx).Save(label).Error
+}
@@ -0,0 +1,45 @@
+package repositories_test
+
+import (
+	"context"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/repositories"
+	"order-management-system/internal/shipping"
+	"testing"
+)
+
+// Un transportista local nuevo, como el de un servidor reiniciado, sigue las etiquetas
+// que emitió el anterior sobre la misma base
+func TestShippingLabelRepository_TrackingSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	request := shipping.LabelRequest{
+		Reference:   "order-1",
+		Method:      shipping.MethodStandard,
+		Destination: domain.AddressSnapshot{Country: "AR", Region: "Córdoba"},
+		Parcels:     []shipping.Parcel{{WeightGrams: 500}},
+	}
+
+	before := shipping.DefaultLocalCarrier(shipping.WithLabelStore(repositories.NewShippingLabelRepository(db)))
+	first, err := before.CreateLabel(ctx, request)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	second, _ := before.CreateLabel(ctx, request)
+
+	after := shipping.DefaultLocalCarrier(shipping.WithLabelStore(repositories.NewShippingLabelRepository(db)))
+	status, err := after.GetTrackingStatus(ctx, first.TrackingNumber)
+	if err != nil || status.Status != shipping.TrackingLabelCreated {
+		t.Fatalf("Expected LABEL_CREATED after restart, got %+v (%v)", status, err)
+	}
+
+	if err := after.VoidLabel(ctx, second.TrackingNumber); err != nil {
+		t.Fatalf("Expected void to succeed, got %v", err)
+	}
+	if status, _ := after.GetTrackingStatus(ctx, second.TrackingNumber); status == nil || status.Status != shipping.TrackingVoided {
+		t.Errorf("Expected VOIDED, got %+v", status)
+	}
+	if _, err := after.GetTrackingStatus(ctx, "LOC-missing"); err != shipping.ErrTrackingNotFound {
+		t.Errorf("Expected ErrTrackingNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"order-management-system/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type simulatedAuthorizationRepository struct {
+	db *gorm.DB
+}
+
+func NewSimulatedAuthorizationRepository(db *gorm.DB) SimulatedAuthorizationRepository {
+	return &simulatedAuthorizationRepository{db: db}
+}
+
+func (r *simulatedAuthorizationRepository) Create(ctx context.Context, auth *domain.SimulatedAuthorization) error {
+	return r.db.WithContext(ctx).Create(auth).Error
+}
+
+func (r *simulatedAuthorizationRepository) GetByAuthorizationID(ctx context.Context, authorizationID string) (*domain.SimulatedAuthorization, error) {
+	var auth domain.SimulatedAuthorization
+	err := r.db.WithContext(ctx).First(&auth, "authorization_id = ?", authorizationID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &auth, nil
+}
+
+func (r *simulatedAuthorizationRepository) Update(ctx context.Context, auth *domain.SimulatedAuthorization) error {
+	return r.db.WithContext(ctx).Save(auth).Error
+}
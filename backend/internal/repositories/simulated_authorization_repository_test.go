@@ -0,0 +1,35 @@
+package repositories_test
+
+import (
+	"context"
+	"order-management-system/internal/payments"
+	"order-management-system/internal/repositories"
+	"testing"
+)
+
+// Un simulador nuevo, como el de un servidor reiniciado, captura y reembolsa las
+// autorizaciones que aprobó el anterior sobre la misma base
+func TestSimulatedAuthorizationRepository_SurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+
+	before := payments.NewSimulator(payments.WithAuthorizationStore(repositories.NewSimulatedAuthorizationRepository(db)))
+	auth, err := before.Authorize(ctx, payments.AuthorizeRequest{Reference: "order-1", Amount: 100, Currency: "ARS", Card: payments.Card{Number: payments.CardApproved}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	after := payments.NewSimulator(payments.WithAuthorizationStore(repositories.NewSimulatedAuthorizationRepository(db)))
+	if err := after.Capture(ctx, auth.ID, 100); err != nil {
+		t.Fatalf("Expected capture after restart, got %v", err)
+	}
+	if _, err := after.Refund(ctx, auth.ID, 60); err != nil {
+		t.Fatalf("Expected refund after restart, got %v", err)
+	}
+	if _, err := after.Refund(ctx, auth.ID, 50); err != payments.ErrInvalidOperation {
+		t.Errorf("Expected refund above the captured amount to fail, got %v", err)
+	}
+	if err := after.Void(ctx, "sim_auth_missing"); err != payments.ErrAuthorizationNotFound {
+		t.Errorf("Expected ErrAuthorizationNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"context"
+	"order-management-system/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type taxRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewTaxRuleRepository(db *gorm.DB) TaxRuleRepository {
+	return &taxRuleRepository{db: db}
+}
+
+func (r *taxRuleRepository) Create(ctx context.Context, rule *domain.TaxRule) error {
+	return r.db.WithContext(ctx).Create(rule).Error
+}
+
+func (r *taxRuleRepository) GetAll(ctx context.Context) ([]domain.TaxRule, error) {
+	var rules []domain.TaxRule
+	if err := r.db.WithContext(ctx).Order("country, region, tax_class").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *taxRuleRepository) GetByCountry(ctx context.Context, country string) ([]domain.TaxRule, error) {
+	var rules []domain.TaxRule
+	if err := r.db.WithContext(ctx).Where("country = ?", country).Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
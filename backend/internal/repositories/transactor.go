@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type gormTransactor struct {
+	db *gorm.DB
+}
+
+func NewTransactor(db *gorm.DB) Transactor {
+	return &gormTransactor{db: db}
+}
+
+func (t *gormTransactor) WithinTransaction(ctx context.Context, fn func(tx Repositories) error) error {
+	return t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(Repositories{
+			Orders:     NewOrderRepository(tx),
+			Products:   NewProductRepository(tx),
+			Outbox:     NewOutboxRepository(tx),
+			Promotions: NewPromotionRepository(tx),
+			Refunds:    NewRefundRepository(tx),
+			Returns:    NewReturnRepository(tx),
+		})
+	})
+}
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/repositories"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrAddressNotFound        = errors.New("address not found")
+	ErrInvalidAddress         = errors.New("invalid address")
+	ErrMissingShippingAddress = errors.New("order has no shipping address")
+)
+
+// countryAddressRules son los campos obligatorios y el formato de código postal de cada país
+type countryAddressRules struct {
+	requireRegion bool
+	requirePostal bool
+	postalCode    *regexp.Regexp
+}
+
+var addressRules = map[string]countryAddressRules{
+	// Código postal de 4 dígitos o CPA (A1234ABC)
+	"AR": {requireRegion: true, requirePostal: true, postalCode: regexp.MustCompile(`^([A-Z]\d{4}[A-Z]{3}|\d{4})$`)},
+	"BR": {requireRegion: true, requirePostal: true, postalCode: regexp.MustCompile(`^\d{5}-?\d{3}$`)},
+	"CL": {requireRegion: true, postalCode: regexp.MustCompile(`^\d{7}$`)},
+	"UY": {requirePostal: true, postalCode: regexp.MustCompile(`^\d{5}$`)},
+	"US": {requireRegion: true, requirePostal: true, postalCode: regexp.MustCompile(`^\d{5}(-\d{4})?$`)},
+}
+
+type AddressService struct {
+	addressRepo repositories.AddressRepository
+	userRepo    repositories.UserRepository
+}
+
+func NewAddressService(addressRepo repositories.AddressRepository, userRepo repositories.UserRepository) *AddressService {
+	return &AddressService{
+		addressRepo: addressRepo,
+		userRepo:    userRepo,
+	}
+}
+
+func (s *AddressService) GetAddresses(ctx context.Context, userID uint) ([]domain.Address, error) {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, ErrUserNotFound
+	}
+	return s.addressRepo.GetByUserID(ctx, userID)
+}
+
+// CreateAddress valida y agrega una dirección; la primera dirección del usuario queda como predeterminada
+func (s *AddressService) CreateAddress(ctx context.Context, userID uint, address *domain.Address) error {
+	existing, err := s.GetAddresses(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	address.ID = 0
+	address.UserID = userID
+	address.AddressSnapshot = normalizeAddress(address.AddressSnapshot)
+	if err := ValidateAddress(address.AddressSnapshot); err != nil {
+		return err
+	}
+
+	if len(existing) == 0 {
+		address.IsDefaultShipping = true
+		address.IsDefaultBilling = true
+	}
+
+	if err := s.addressRepo.Create(ctx, address); err != nil {
+		return err
+	}
+	return s.clearOtherDefaults(ctx, address, existing)
+}
+
+// UpdateAddress reemplaza los datos de una dirección del usuario
+func (s *AddressService) UpdateAddress(ctx context.Context, userID, addressID uint, input *domain.Address) (*domain.Address, error) {
+	address, err := s.getOwned(ctx, userID, addressID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := normalizeAddress(input.AddressSnapshot)
+	if err := ValidateAddress(snapshot); err != nil {
+		return nil, err
+	}
+
+	address.Label = input.Label
+	address.AddressSnapshot = snapshot
+	address.IsDefaultShipping = input.IsDefaultShipping
+	address.IsDefaultBilling = input.IsDefaultBilling
+	if err := s.addressRepo.Update(ctx, address); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.addressRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.clearOtherDefaults(ctx, address, existing); err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// DeleteAddress elimina una dirección; si era predeterminada se promueve la más antigua restante
+func (s *AddressService) DeleteAddress(ctx context.Context, userID, addressID uint) error {
+	address, err := s.getOwned(ctx, userID, addressID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.addressRepo.Delete(ctx, address.ID); err != nil {
+		return err
+	}
+
+	if !address.IsDefaultShipping && !address.IsDefaultBilling {
+		return nil
+	}
+
+	remaining, err := s.addressRepo.GetByUserID(ctx, userID)
+	if err != nil || len(remaining) == 0 {
+		return err
+	}
+	next := remaining[0]
+	next.IsDefaultShipping = next.IsDefaultShipping || address.IsDefaultShipping
+	next.IsDefaultBilling = next.IsDefaultBilling || address.IsDefaultBilling
+	return s.addressRepo.Update(ctx, &next)
+}
+
+// ResolveOrderAddresses devuelve las direcciones a copiar en un pedido. Si no se indican
+// se usan las predeterminadas; la de facturación cae en la de envío si no hay otra.
+func (s *AddressService) ResolveOrderAddresses(ctx context.Context, userID uint, shippingID, billingID *uint) (domain.AddressSnapshot, domain.AddressSnapshot, error) {
+	var shipping, billing domain.AddressSnapshot
+
+	addresses, err := s.addressRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return shipping, billing, err
+	}
+
+	pick := func(id *uint, isDefault func(domain.Address) bool) (*domain.Address, error) {
+		for i := range addresses {
+			if id != nil && addresses[i].ID == *id {
+				return &addresses[i], nil
+			}
+			if id == nil && isDefault(addresses[i]) {
+				return &addresses[i], nil
+			}
+		}
+		if id != nil {
+			return nil, ErrAddressNotFound
+		}
+		return nil, nil
+	}
+
+	shippingAddress, err := pick(shippingID, func(a domain.Address) bool { return a.IsDefaultShipping })
+	if err != nil {
+		return shipping, billing, err
+	}
+	billingAddress, err := pick(billingID, func(a domain.Address) bool { return a.IsDefaultBilling })
+	if err != nil {
+		return shipping, billing, err
+	}
+
+	if shippingAddress != nil {
+		shipping = shippingAddress.AddressSnapshot
+	}
+	if billingAddress != nil {
+		billing = billingAddress.AddressSnapshot
+	} else {
+		billing = shipping
+	}
+	return shipping, billing, nil
+}
+
+// ValidateAddress verifica los campos obligatorios según el país
+func ValidateAddress(address domain.AddressSnapshot) error {
+	var missing []string
+	if address.RecipientName == "" {
+		missing = append(missing, "recipient_name")
+	}
+	if address.Line1 == "" {
+		missing = append(missing, "line1")
+	}
+	if address.City == "" {
+		missing = append(missing, "city")
+	}
+	if len(address.Country) != 2 {
+		return fmt.Errorf("%w: country must be an ISO 3166-1 alpha-2 code", ErrInvalidAddress)
+	}
+
+	rules := addressRules[address.Country]
+	if rules.requireRegion && address.Region == "" {
+		missing = append(missing, "region")
+	}
+	if rules.requirePostal && address.PostalCode == "" {
+		missing = append(missing, "postal_code")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: missing %s", ErrInvalidAddress, strings.Join(missing, ", "))
+	}
+
+	if rules.postalCode != nil && address.PostalCode != "" && !rules.postalCode.MatchString(address.PostalCode) {
+		return fmt.Errorf("%w: postal_code %q is not valid for %s", ErrInvalidAddress, address.PostalCode, address.Country)
+	}
+	return nil
+}
+
+func normalizeAddress(address domain.AddressSnapshot) domain.AddressSnapshot {
+	address.RecipientName = strings.TrimSpace(address.RecipientName)
+	address.Line1 = strings.TrimSpace(address.Line1)
+	address.Line2 = strings.TrimSpace(address.Line2)
+	address.City = strings.TrimSpace(address.City)
+	address.Region = strings.TrimSpace(address.Region)
+	address.PostalCode = strings.ToUpper(strings.TrimSpace(address.PostalCode))
+	address.Country = strings.ToUpper(strings.TrimSpace(address.Country))
+	address.Phone = strings.TrimSpace(address.Phone)
+	return address
+}
+
+func (s *AddressService) getOwned(ctx context.Context, userID, addressID uint) (*domain.Address, error) {
+	address, err := s.addressRepo.GetByID(ctx, addressID)
+	if err != nil || address.UserID != userID {
+		return nil, ErrAddressNotFound
+	}
+	return address, nil
+}
+
+// clearOtherDefaults garantiza una sola dirección predeterminada de envío y de facturación
+func (s *AddressService) clearOtherDefaults(ctx context.Context, address *domain.Address, addresses []domain.Address) error {
+	for _, other := range addresses {
+		if other.ID == address.ID {
+			continue
+		}
+		changed := false
+		if address.IsDefaultShipping && other.IsDefaultShipping {
+			other.IsDefaultShipping = false
+			changed = true
+		}
+		if address.IsDefaultBilling && other.IsDefaultBilling {
+			other.IsDefaultBilling = false
+			changed = true
+		}
+		if changed {
+			if err := s.addressRepo.Update(ctx, &other); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
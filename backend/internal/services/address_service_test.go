@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"order-management-system/internal/domain"
+	"testing"
+)
+
+type mockAddressRepository struct {
+	addresses map[uint]*domain.Address
+	nextID    uint
+}
+
+func (m *mockAddressRepository) Create(ctx context.Context, address *domain.Address) error {
+	m.nextID++
+	address.ID = m.nextID
+	stored := *address
+	m.addresses[address.ID] = &stored
+	return nil
+}
+
+func (m *mockAddressRepository) GetByID(ctx context.Context, id uint) (*domain.Address, error) {
+	if address, ok := m.addresses[id]; ok {
+		found := *address
+		return &found, nil
+	}
+	return nil, errors.New("address not found")
+}
+
+func (m *mockAddressRepository) GetByUserID(ctx context.Context, userID uint) ([]domain.Address, error) {
+	var addresses []domain.Address
+	for id := uint(1); id <= m.nextID; id++ {
+		if address, ok := m.addresses[id]; ok && address.UserID == userID {
+			addresses = append(addresses, *address)
+		}
+	}
+	return addresses, nil
+}
+
+func (m *mockAddressRepository) Update(ctx context.Context, address *domain.Address) error {
+	if _, ok := m.addresses[address.ID]; ok {
+		stored := *address
+		m.addresses[address.ID] = &stored
+		return nil
+	}
+	return errors.New("address not found")
+}
+
+func (m *mockAddressRepository) Delete(ctx context.Context, id uint) error {
+	delete(m.addresses, id)
+	return nil
+}
+
+func validAddress() *domain.Address {
+	return &domain.Address{
+		Label: "Casa",
+		AddressSnapshot: domain.AddressSnapshot{
+			RecipientName: "Test User",
+			Line1:         "Av. Colón 1234",
+			City:          "Córdoba",
+			Region:        "Córdoba",
+			PostalCode:    "x5000abc",
+			Country:       "ar",
+		},
+	}
+}
+
+// newTestAddressService crea el servicio con una dirección predeterminada para el usuario 1
+func newTestAddressService(userRepo *mockUserRepository) (*AddressService, *mockAddressRepository) {
+	ctx := context.Background()
+	addressRepo := &mockAddressRepository{addresses: make(map[uint]*domain.Address)}
+	service := NewAddressService(addressRepo, userRepo)
+	service.CreateAddress(ctx, 1, validAddress())
+	return service, addressRepo
+}
+
+func TestValidateAddress_CountryRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(a *domain.AddressSnapshot)
+		wantErr bool
+	}{
+		{"valid AR CPA", func(a *domain.AddressSnapshot) {}, false},
+		{"valid AR short postal code", func(a *domain.AddressSnapshot) { a.PostalCode = "5000" }, false},
+		{"invalid AR postal code", func(a *domain.AddressSnapshot) { a.PostalCode = "50000" }, true},
+		{"missing AR region", func(a *domain.AddressSnapshot) { a.Region = "" }, true},
+		{"missing line1", func(a *domain.AddressSnapshot) { a.Line1 = "" }, true},
+		{"invalid country", func(a *domain.AddressSnapshot) { a.Country = "ARG" }, true},
+		{"US requires zip", func(a *domain.AddressSnapshot) { a.Country = "US"; a.PostalCode = "" }, true},
+		{"unknown country only base fields", func(a *domain.AddressSnapshot) { a.Country = "DE"; a.Region = ""; a.PostalCode = "" }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address := normalizeAddress(validAddress().AddressSnapshot)
+			tt.modify(&address)
+			err := ValidateAddress(address)
+			if tt.wantErr && !errors.Is(err, ErrInvalidAddress) {
+				t.Errorf("Expected ErrInvalidAddress, got %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCreateAddress_SingleDefault(t *testing.T) {
+	ctx := context.Background()
+	_, userRepo, _, _ := setupService()
+	service, addressRepo := newTestAddressService(userRepo)
+
+	if !addressRepo.addresses[1].IsDefaultShipping || !addressRepo.addresses[1].IsDefaultBilling {
+		t.Fatalf("Expected first address to be default")
+	}
+
+	second := validAddress()
+	second.IsDefaultShipping = true
+	if err := service.CreateAddress(ctx, 1, second); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if addressRepo.addresses[1].IsDefaultShipping {
+		t.Errorf("Expected previous default shipping to be cleared")
+	}
+	if !addressRepo.addresses[1].IsDefaultBilling {
+		t.Errorf("Expected default billing to be kept")
+	}
+}
+
+func TestDeleteAddress_PromotesRemaining(t *testing.T) {
+	ctx := context.Background()
+	_, userRepo, _, _ := setupService()
+	service, addressRepo := newTestAddressService(userRepo)
+	service.CreateAddress(ctx, 1, validAddress())
+
+	if err := service.DeleteAddress(ctx, 1, 1); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !addressRepo.addresses[2].IsDefaultShipping || !addressRepo.addresses[2].IsDefaultBilling {
+		t.Errorf("Expected remaining address to become default")
+	}
+
+	if err := service.DeleteAddress(ctx, 2, 2); err != ErrAddressNotFound {
+		t.Errorf("Expected ErrAddressNotFound for another user's address, got %v", err)
+	}
+}
+
+func TestCreateOrder_SnapshotsAddress(t *testing.T) {
+	ctx := context.Background()
+	service, _, _, _ := setupService()
+	addressService := service.addresses
+
+	order, err := service.CreateOrder(ctx, domain.CreateOrderRequest{
+		UserID: 1,
+		Items:  []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if order.ShippingAddress.City != "Córdoba" || order.BillingAddress.City != "Córdoba" {
+		t.Errorf("Expected default address snapshot, got %+v", order.ShippingAddress)
+	}
+
+	// Editar la libreta no modifica el pedido
+	edited := validAddress()
+	edited.City = "Villa María"
+	if _, err := addressService.UpdateAddress(ctx, 1, 1, edited); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if order.ShippingAddress.City != "Córdoba" {
+		t.Errorf("Expected snapshot to remain unchanged, got %s", order.ShippingAddress.City)
+	}
+
+	missing := uint(99)
+	_, err = service.CreateOrder(ctx, domain.CreateOrderRequest{
+		UserID:            1,
+		Items:             []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}},
+		ShippingAddressID: &missing,
+	})
+	if err != ErrAddressNotFound {
+		t.Errorf("Expected ErrAddressNotFound, got %v", err)
+	}
+}
+
+func TestShipOrder_RequiresShippingAddress(t *testing.T) {
+	ctx := context.Background()
+	_, userRepo, productRepo, orderRepo := setupService()
+	service := NewOrderService(orderRepo, productRepo, userRepo)
+
+	order, _ := service.CreateOrder(ctx, domain.CreateOrderRequest{
+		UserID: 1,
+		Items:  []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}},
+	})
+	service.ConfirmOrder(ctx, order.ID)
+
+	if _, err := service.ShipOrder(ctx, order.ID); err != ErrMissingShippingAddress {
+		t.Errorf("Expected ErrMissingShippingAddress, got %v", err)
+	}
+}
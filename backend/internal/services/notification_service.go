@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"order-management-system/internal/backoff"
+	"order-management-system/internal/clock"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/notifications"
+	"order-management-system/internal/repositories"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrNotificationSent     = errors.New("notification already sent")
+	ErrInvalidPreference    = errors.New("invalid notification preference")
+)
+
+const (
+	notificationBatchSize = 50
+	notificationLogLimit  = 100
+	notificationChannel   = "email"
+)
+
+// NotificationService envía a los clientes los emails de los eventos de sus pedidos según
+// sus preferencias y guarda cada envío, reintentando los que fallan.
+type NotificationService struct {
+	preferenceRepo   repositories.NotificationPreferenceRepository
+	notificationRepo repositories.NotificationRepository
+	userRepo         repositories.UserRepository
+	orderRepo        repositories.OrderRepository
+	renderer         *notifications.Renderer
+	mailer           notifications.Mailer
+
+	maxAttempts int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+	now         func() time.Time
+
+	sending sync.Mutex
+}
+
+// NotificationServiceOption configura parámetros opcionales del NotificationService
+type NotificationServiceOption func(*NotificationService)
+
+// WithNotificationRetry define los intentos por email y el backoff exponencial entre ellos
+func WithNotificationRetry(maxAttempts int, baseBackoff, maxBackoff time.Duration) NotificationServiceOption {
+	return func(s *NotificationService) {
+		s.maxAttempts = maxAttempts
+		s.baseBackoff = baseBackoff
+		s.maxBackoff = maxBackoff
+	}
+}
+
+// WithNotificationClock reemplaza el reloj usado para programar reintentos
+func WithNotificationClock(clk clock.Clock) NotificationServiceOption {
+	return func(s *NotificationService) {
+		s.now = clk.Now
+	}
+}
+
+func NewNotificationService(
+	preferenceRepo repositories.NotificationPreferenceRepository,
+	notificationRepo repositories.NotificationRepository,
+	userRepo repositories.UserRepository,
+	orderRepo repositories.OrderRepository,
+	renderer *notifications.Renderer,
+	mailer notifications.Mailer,
+	opts ...NotificationServiceOption,
+) *NotificationService {
+	s := &NotificationService{
+		preferenceRepo:   preferenceRepo,
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		orderRepo:        orderRepo,
+		renderer:         renderer,
+		mailer:           mailer,
+		maxAttempts:      5,
+		baseBackoff:      time.Minute,
+		maxBackoff:       time.Hour,
+		now:              clock.System().Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// GetPreferences devuelve las preferencias del usuario o las de por defecto si no guardó ninguna
+func (s *NotificationService) GetPreferences(ctx context.Context, userID uint) (*domain.NotificationPreference, error) {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, ErrUserNotFound
+	}
+	return s.preferences(ctx, userID)
+}
+
+func (s *NotificationService) UpdatePreferences(ctx context.Context, userID uint, req domain.UpdateNotificationPreferenceRequest) (*domain.NotificationPreference, error) {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, ErrUserNotFound
+	}
+	for _, eventType := range req.MutedEvents {
+		if !s.renderer.Supports(eventType) {
+			return nil, fmt.Errorf("%w: no email for event %q", ErrInvalidPreference, eventType)
+		}
+	}
+
+	preference := &domain.NotificationPreference{
+		UserID:       userID,
+		Locale:       req.Locale,
+		EmailEnabled: req.EmailEnabled,
+		MutedEvents:  domain.EventTypeList(req.MutedEvents),
+	}
+	if err := s.preferenceRepo.Save(ctx, preference); err != nil {
+		return nil, err
+	}
+	return preference, nil
+}
+
+// GetNotifications devuelve los últimos emails del usuario, los más recientes primero
+func (s *NotificationService) GetNotifications(ctx context.Context, userID uint) ([]domain.Notification, error) {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, ErrUserNotFound
+	}
+	return s.notificationRepo.GetByUserID(ctx, userID, notificationLogLimit)
+}
+
+// HandleEvent es el suscriptor del outbox: arma el email del evento y lo envía en el momento.
+// Si el envío falla queda pendiente para reintentar; como el outbox puede repetir eventos,
+// no se crea otro email para un evento ya registrado.
+func (s *NotificationService) HandleEvent(ctx context.Context, event domain.OutboxEvent) error {
+	if !s.renderer.Supports(event.EventType) {
+		return nil
+	}
+	var payload domain.OrderEvent
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+
+	preference, err := s.preferences(ctx, payload.UserID)
+	if err != nil {
+		return err
+	}
+	if !preference.Wants(event.EventType) {
+		return nil
+	}
+	exists, err := s.notificationRepo.ExistsForEvent(ctx, payload.UserID, event.ID)
+	if err != nil || exists {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, payload.UserID)
+	if err != nil {
+		return fmt.Errorf("loading user %d: %w", payload.UserID, err)
+	}
+	order, err := s.orderRepo.GetByID(ctx, payload.OrderID)
+	if err != nil {
+		return fmt.Errorf("loading order %d: %w", payload.OrderID, err)
+	}
+	// El evento refleja el estado al momento del cambio, aunque el pedido haya seguido avanzando
+	order.Status = payload.Status
+	order.Total = payload.Total
+
+	subject, text, html, err := s.renderer.Render(event.EventType, preference.Locale, notifications.TemplateData{
+		CustomerName: user.Name,
+		Order:        order,
+	})
+	if err != nil {
+		return err
+	}
+
+	notification := &domain.Notification{
+		UserID:        payload.UserID,
+		OrderID:       payload.OrderID,
+		EventID:       event.ID,
+		EventType:     event.EventType,
+		Channel:       notificationChannel,
+		Locale:        preference.Locale,
+		Recipient:     user.Email,
+		Subject:       subject,
+		TextBody:      text,
+		HTMLBody:      html,
+		Status:        domain.NotificationPending,
+		NextAttemptAt: s.now(),
+	}
+	if err := s.notificationRepo.Create(ctx, notification); err != nil {
+		return err
+	}
+	return s.attempt(ctx, notification)
+}
+
+// Retry reenvía en el momento un email que no se pudo enviar
+func (s *NotificationService) Retry(ctx context.Context, id uint) (*domain.Notification, error) {
+	notification, err := s.notificationRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrNotificationNotFound
+	}
+	if notification.Status == domain.NotificationSent {
+		return nil, ErrNotificationSent
+	}
+
+	notification.Status = domain.NotificationPending
+	if err := s.attempt(ctx, notification); err != nil {
+		return nil, err
+	}
+	return notification, nil
+}
+
+// SendDue reintenta los emails pendientes cuyo próximo intento ya venció
+func (s *NotificationService) SendDue(ctx context.Context) error {
+	s.sending.Lock()
+	defer s.sending.Unlock()
+
+	due, err := s.notificationRepo.GetDue(ctx, s.now(), notificationBatchSize)
+	if err != nil {
+		return err
+	}
+	for i := range due {
+		if err := s.attempt(ctx, &due[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attempt envía el email y registra el resultado; sólo devuelve errores al guardarlo
+func (s *NotificationService) attempt(ctx context.Context, notification *domain.Notification) error {
+	now := s.now()
+	err := s.mailer.Send(notifications.Email{
+		To:      notification.Recipient,
+		Subject: notification.Subject,
+		Text:    notification.TextBody,
+		HTML:    notification.HTMLBody,
+	})
+
+	notification.Attempts++
+	if err == nil {
+		notification.Status = domain.NotificationSent
+		notification.SentAt = &now
+		notification.LastError = ""
+	} else {
+		notification.LastError = err.Error()
+		if notification.Attempts >= s.maxAttempts {
+			notification.Status = domain.NotificationFailed
+		} else {
+			notification.NextAttemptAt = now.Add(backoff.Exponential(s.baseBackoff, s.maxBackoff, notification.Attempts))
+		}
+	}
+	return s.notificationRepo.Update(ctx, notification)
+}
+
+func (s *NotificationService) preferences(ctx context.Context, userID uint) (*domain.NotificationPreference, error) {
+	preference, err := s.preferenceRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if preference == nil {
+		preference = &domain.NotificationPreference{UserID: userID, Locale: domain.LocaleES, EmailEnabled: true}
+	}
+	return preference, nil
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"order-management-system/internal/clock"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/notifications"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockNotificationPreferenceRepository struct {
+	preferences map[uint]domain.NotificationPreference
+}
+
+func (m *mockNotificationPreferenceRepository) GetByUserID(ctx context.Context, userID uint) (*domain.NotificationPreference, error) {
+	if preference, ok := m.preferences[userID]; ok {
+		return &preference, nil
+	}
+	return nil, nil
+}
+
+func (m *mockNotificationPreferenceRepository) Save(ctx context.Context, preference *domain.NotificationPreference) error {
+	m.preferences[preference.UserID] = *preference
+	return nil
+}
+
+type mockNotificationRepository struct {
+	notifications []domain.Notification
+}
+
+func (m *mockNotificationRepository) Create(ctx context.Context, notification *domain.Notification) error {
+	notification.ID = uint(len(m.notifications) + 1)
+	m.notifications = append(m.notifications, *notification)
+	return nil
+}
+
+func (m *mockNotificationRepository) GetByID(ctx context.Context, id uint) (*domain.Notification, error) {
+	if id == 0 || int(id) > len(m.notifications) {
+		return nil, errors.New("notification not found")
+	}
+	found := m.notifications[id-1]
+	return &found, nil
+}
+
+func (m *mockNotificationRepository) GetByUserID(ctx context.Context, userID uint, limit int) ([]domain.Notification, error) {
+	var found []domain.Notification
+	for i := len(m.notifications) - 1; i >= 0 && len(found) < limit; i-- {
+		if m.notifications[i].UserID == userID {
+			found = append(found, m.notifications[i])
+		}
+	}
+	return found, nil
+}
+
+func (m *mockNotificationRepository) ExistsForEvent(ctx context.Context, userID, eventID uint) (bool, error) {
+	for _, n := range m.notifications {
+		if n.UserID == userID && n.EventID == eventID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockNotificationRepository) GetDue(ctx context.Context, now time.Time, limit int) ([]domain.Notification, error) {
+	var due []domain.Notification
+	for _, n := range m.notifications {
+		if n.Status == domain.NotificationPending && !n.NextAttemptAt.After(now) && len(due) < limit {
+			due = append(due, n)
+		}
+	}
+	return due, nil
+}
+
+func (m *mockNotificationRepository) Update(ctx context.Context, notification *domain.Notification) error {
+	m.notifications[notification.ID-1] = *notification
+	return nil
+}
+
+// flakyMailer falla mientras failing sea true y entrega al mailbox en memoria el resto del tiempo
+type flakyMailer struct {
+	mailbox *notifications.Mailbox
+	failing bool
+}
+
+func (m *flakyMailer) Send(email notifications.Email) error {
+	if m.failing {
+		return errors.New("smtp: connection refused")
+	}
+	return m.mailbox.Send(email)
+}
+
+type notificationFixture struct {
+	service       *NotificationService
+	orders        *OrderService
+	notifications *mockNotificationRepository
+	mailer        *flakyMailer
+	clock         *clock.Fake
+}
+
+func setupNotificationService(t *testing.T) notificationFixture {
+	t.Helper()
+	orders, userRepo, _, orderRepo := setupService()
+	renderer, err := notifications.NewRenderer()
+	if err != nil {
+		t.Fatalf("Expected templates to parse, got %v", err)
+	}
+	mailbox, _ := notifications.NewMailbox("", "tienda@example.com")
+
+	f := notificationFixture{
+		orders:        orders,
+		notifications: &mockNotificationRepository{},
+		mailer:        &flakyMailer{mailbox: mailbox},
+	}
+	f.clock = clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	f.service = NewNotificationService(
+		&mockNotificationPreferenceRepository{preferences: make(map[uint]domain.NotificationPreference)},
+		f.notifications, userRepo, orderRepo, renderer, f.mailer,
+		WithNotificationRetry(3, time.Minute, time.Hour),
+		WithNotificationClock(f.clock),
+	)
+	return f
+}
+
+func orderOutboxEvent(t *testing.T, id uint, eventType domain.EventType, order *domain.Order) domain.OutboxEvent {
+	t.Helper()
+	event, err := domain.NewOutboxEvent(eventType, "order", order.ID, orderEvent(order))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	event.ID = id
+	return *event
+}
+
+func TestNotifications_SendsLocalizedEmailOncePerEvent(t *testing.T) {
+	ctx := context.Background()
+	f := setupNotificationService(t)
+	order, _ := f.orders.CreateOrder(ctx, domain.CreateOrderRequest{UserID: 1, Items: []domain.OrderItemRequest{{ProductID: 1, Quantity: 2}}})
+
+	// order.created no tiene email
+	f.service.HandleEvent(ctx, orderOutboxEvent(t, 1, domain.EventOrderCreated, order))
+
+	confirmed := orderOutboxEvent(t, 2, domain.EventOrderConfirmed, order)
+	if err := f.service.HandleEvent(ctx, confirmed); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	f.service.HandleEvent(ctx, confirmed)
+
+	messages := f.mailer.mailbox.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("Expected 1 email, got %d", len(messages))
+	}
+	if messages[0].To != "test@test.com" || messages[0].Subject != "Tu pedido #1 está confirmado" || !strings.Contains(messages[0].Text, "x2: $ 100,00") {
+		t.Errorf("Unexpected email %+v", messages[0])
+	}
+
+	// Con preferencias en inglés y el envío silenciado no llega el aviso de envío
+	if _, err := f.service.UpdatePreferences(ctx, 1, domain.UpdateNotificationPreferenceRequest{
+		Locale: domain.LocaleEN, EmailEnabled: true, MutedEvents: []domain.EventType{domain.EventOrderShipped},
+	}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	f.service.HandleEvent(ctx, orderOutboxEvent(t, 3, domain.EventOrderShipped, order))
+	f.service.HandleEvent(ctx, orderOutboxEvent(t, 4, domain.EventOrderCancelled, order))
+
+	messages = f.mailer.mailbox.Messages()
+	if len(messages) != 2 || messages[1].Subject != "Your order #1 was cancelled" {
+		t.Errorf("Expected only the english cancellation email, got %+v", messages)
+	}
+
+	sent, _ := f.service.GetNotifications(ctx, 1)
+	if len(sent) != 2 || sent[0].EventType != domain.EventOrderCancelled || sent[0].Status != domain.NotificationSent || sent[0].Locale != domain.LocaleEN {
+		t.Errorf("Unexpected notification log %+v", sent)
+	}
+}
+
+func TestNotifications_RetriesFailedSends(t *testing.T) {
+	ctx := context.Background()
+	f := setupNotificationService(t)
+	order, _ := f.orders.CreateOrder(ctx, domain.CreateOrderRequest{UserID: 1, Items: []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}}})
+	f.mailer.failing = true
+
+	// El fallo de envío no se propaga al outbox: queda registrado para reintentar
+	if err := f.service.HandleEvent(ctx, orderOutboxEvent(t, 1, domain.EventOrderConfirmed, order)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	notification := f.notifications.notifications[0]
+	if notification.Status != domain.NotificationPending || notification.Attempts != 1 || !notification.NextAttemptAt.Equal(f.clock.Now().Add(time.Minute)) {
+		t.Fatalf("Expected retry in 1m, got %+v", notification)
+	}
+
+	f.service.SendDue(ctx)
+	if f.notifications.notifications[0].Attempts != 1 {
+		t.Error("Expected no retry before backoff")
+	}
+
+	f.clock.Advance(time.Minute)
+	f.service.SendDue(ctx)
+	f.clock.Advance(2 * time.Minute)
+	f.service.SendDue(ctx)
+	if notification = f.notifications.notifications[0]; notification.Status != domain.NotificationFailed || notification.Attempts != 3 || notification.LastError == "" {
+		t.Fatalf("Expected FAILED after 3 attempts, got %+v", notification)
+	}
+
+	f.mailer.failing = false
+	retried, err := f.service.Retry(ctx, notification.ID)
+	if err != nil || retried.Status != domain.NotificationSent || len(f.mailer.mailbox.Messages()) != 1 {
+		t.Errorf("Expected manual retry to send, got %+v (%v)", retried, err)
+	}
+	if _, err := f.service.Retry(ctx, notification.ID); err != ErrNotificationSent {
+		t.Errorf("Expected ErrNotificationSent, got %v", err)
+	}
+}
+
+func TestNotificationPreferences(t *testing.T) {
+	ctx := context.Background()
+	f := setupNotificationService(t)
+
+	preference, err := f.service.GetPreferences(ctx, 1)
+	if err != nil || !preference.EmailEnabled || preference.Locale != domain.LocaleES {
+		t.Errorf("Expected default preferences, got %+v (%v)", preference, err)
+	}
+	if _, err := f.service.GetPreferences(ctx, 99); err != ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+	if _, err := f.service.UpdatePreferences(ctx, 1, domain.UpdateNotificationPreferenceRequest{
+		Locale: domain.LocaleES, MutedEvents: []domain.EventType{domain.EventStockChanged},
+	}); !errors.Is(err, ErrInvalidPreference) {
+		t.Errorf("Expected ErrInvalidPreference, got %v", err)
+	}
+
+	f.service.UpdatePreferences(ctx, 1, domain.UpdateNotificationPreferenceRequest{Locale: domain.LocaleES, EmailEnabled: false})
+	order, _ := f.orders.CreateOrder(ctx, domain.CreateOrderRequest{UserID: 1, Items: []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}}})
+	f.service.HandleEvent(ctx, orderOutboxEvent(t, 1, domain.EventOrderConfirmed, order))
+	if len(f.notifications.notifications) != 0 {
+		t.Errorf("Expected no email with notifications disabled, got %d", len(f.notifications.notifications))
+	}
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/repositories"
+)
+
+var (
+	ErrOrderNotEditable   = errors.New("only pending orders can be amended")
+	ErrInvalidOrderChange = errors.New("invalid order change")
+)
+
+// WithChangeLog registra en el historial cada modificación de líneas de un pedido
+func WithChangeLog(changeRepo repositories.OrderChangeRepository) OrderServiceOption {
+	return func(s *OrderService) {
+		s.changeRepo = changeRepo
+	}
+}
+
+// AmendOrder agrega, quita o cambia cantidades de las líneas de un pedido PENDING.
+// Stock, precios, promociones, impuestos y envío se recalculan con las mismas reglas que CreateOrder.
+func (s *OrderService) AmendOrder(ctx context.Context, orderID uint, req domain.AmendOrderRequest) (*domain.Order, error) {
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, ErrOrderNotFound
+	}
+
+	if order.Status != domain.StatusPending {
+		return nil, ErrOrderNotEditable
+	}
+
+	// Los cupones ingresados por el cliente se vuelven a evaluar sobre las líneas nuevas
+	var codes []string
+	seenCodes := make(map[string]bool)
+	for _, item := range order.Items {
+		for _, discount := range item.Discounts {
+			if discount.Code != "" && !seenCodes[discount.Code] {
+				seenCodes[discount.Code] = true
+				codes = append(codes, discount.Code)
+			}
+		}
+	}
+
+	items := make([]domain.OrderItem, len(order.Items))
+	for i, item := range order.Items {
+		items[i] = domain.OrderItem{ID: item.ID, OrderID: order.ID, ProductID: item.ProductID, Quantity: item.Quantity}
+	}
+
+	var changes []domain.OrderChange
+	for _, change := range req.Changes {
+		logged, err := applyItemChange(&items, change)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, logged)
+	}
+
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: an order must keep at least one item", ErrInvalidOrderChange)
+	}
+
+	lines, err := s.priceItems(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+
+	var discounts *DiscountResult
+	if s.promotions != nil {
+		discounts, err = s.promotions.ReapplyPromotions(ctx, order.ID, order.UserID, lines, codes, s.clock.Now())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	previousTotal := order.Total
+	order.Items = items
+	order.TaxLines = nil
+	if err := s.computeTotals(ctx, order, lines, discounts, order.ShippingMethod); err != nil {
+		return nil, err
+	}
+
+	err = s.withinTransaction(ctx, func(tx repositories.Repositories) error {
+		if err := tx.Orders.UpdateItems(ctx, order); err != nil {
+			return err
+		}
+		if discounts != nil {
+			return s.promotions.ReplaceRedemptions(ctx, tx, order.ID, order.UserID, discounts.Promotions)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Una autorización menor al nuevo total ya no alcanza: se anula para volver a autorizar
+	if s.payments != nil {
+		authorization, err := s.payments.ActiveAuthorization(ctx, order.ID)
+		if err != nil {
+			return nil, err
+		}
+		if authorization != nil && roundMoney(authorization.Amount) < order.Total {
+			if err := s.payments.Release(ctx, order.ID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if s.changeRepo != nil {
+		for i := range changes {
+			changes[i].OrderID = order.ID
+			changes[i].PreviousTotal = previousTotal
+			changes[i].NewTotal = order.Total
+			if err := s.changeRepo.Create(ctx, &changes[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return s.orderRepo.GetByID(ctx, order.ID)
+}
+
+// GetOrderChanges devuelve el historial de modificaciones de un pedido
+func (s *OrderService) GetOrderChanges(ctx context.Context, orderID uint) ([]domain.OrderChange, error) {
+	if _, err := s.orderRepo.GetByID(ctx, orderID); err != nil {
+		return nil, ErrOrderNotFound
+	}
+	if s.changeRepo == nil {
+		return []domain.OrderChange{}, nil
+	}
+	return s.changeRepo.GetByOrderID(ctx, orderID)
+}
+
+// applyItemChange aplica un cambio sobre las líneas en memoria y devuelve su registro para el historial
+func applyItemChange(items *[]domain.OrderItem, change domain.OrderItemChange) (domain.OrderChange, error) {
+	logged := domain.OrderChange{Action: change.Action}
+
+	if change.Action == domain.OrderChangeAddItem {
+		if change.ProductID == 0 || change.Quantity == 0 {
+			return logged, fmt.Errorf("%w: %s requires product_id and quantity", ErrInvalidOrderChange, change.Action)
+		}
+		logged.ProductID = change.ProductID
+
+		// Si el producto ya está en el pedido se suma a esa línea
+		for i := range *items {
+			if (*items)[i].ProductID == change.ProductID {
+				logged.OldQuantity = (*items)[i].Quantity
+				(*items)[i].Quantity += change.Quantity
+				logged.NewQuantity = (*items)[i].Quantity
+				return logged, nil
+			}
+		}
+		*items = append(*items, domain.OrderItem{ProductID: change.ProductID, Quantity: change.Quantity})
+		logged.NewQuantity = change.Quantity
+		return logged, nil
+	}
+
+	index := -1
+	for i := range *items {
+		if change.OrderItemID != 0 && (*items)[i].ID == change.OrderItemID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return logged, fmt.Errorf("%w: order item %d does not belong to the order", ErrInvalidOrderChange, change.OrderItemID)
+	}
+
+	item := &(*items)[index]
+	logged.ProductID = item.ProductID
+	logged.OldQuantity = item.Quantity
+
+	switch change.Action {
+	case domain.OrderChangeRemoveItem:
+		*items = append((*items)[:index], (*items)[index+1:]...)
+	case domain.OrderChangeChangeQuantity:
+		if change.Quantity == 0 {
+			return logged, fmt.Errorf("%w: %s requires quantity", ErrInvalidOrderChange, change.Action)
+		}
+		item.Quantity = change.Quantity
+		logged.NewQuantity = change.Quantity
+	default:
+		return logged, fmt.Errorf("%w: unknown action %q", ErrInvalidOrderChange, change.Action)
+	}
+	return logged, nil
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"order-management-system/internal/domain"
+	"testing"
+)
+
+type mockOrderChangeRepository struct {
+	changes []domain.OrderChange
+}
+
+func (m *mockOrderChangeRepository) Create(ctx context.Context, change *domain.OrderChange) error {
+	change.ID = uint(len(m.changes) + 1)
+	m.changes = append(m.changes, *change)
+	return nil
+}
+
+func (m *mockOrderChangeRepository) GetByOrderID(ctx context.Context, orderID uint) ([]domain.OrderChange, error) {
+	var changes []domain.OrderChange
+	for _, c := range m.changes {
+		if c.OrderID == orderID {
+			changes = append(changes, c)
+		}
+	}
+	return changes, nil
+}
+
+func TestAmendOrder_AddRemoveAndChangeQuantity(t *testing.T) {
+	ctx := context.Background()
+	_, userRepo, productRepo, orderRepo := setupService()
+	productRepo.products[3] = &domain.Product{ID: 3, Name: "Product 3", Price: 20.0, Stock: 2}
+	changeRepo := &mockOrderChangeRepository{}
+	addressService, _ := newTestAddressService(userRepo)
+	service := NewOrderService(orderRepo, productRepo, userRepo, WithAddresses(addressService), WithChangeLog(changeRepo))
+
+	order, _ := service.CreateOrder(ctx, domain.CreateOrderRequest{
+		UserID: 1,
+		Items:  []domain.OrderItemRequest{{ProductID: 1, Quantity: 2}, {ProductID: 2, Quantity: 1}},
+	})
+	firstID, secondID := order.Items[0].ID, order.Items[1].ID
+
+	amended, err := service.AmendOrder(ctx, order.ID, domain.AmendOrderRequest{Changes: []domain.OrderItemChange{
+		{Action: domain.OrderChangeChangeQuantity, OrderItemID: firstID, Quantity: 1},
+		{Action: domain.OrderChangeRemoveItem, OrderItemID: secondID},
+		{Action: domain.OrderChangeAddItem, ProductID: 3, Quantity: 2},
+	}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if amended.ID != order.ID || len(amended.Items) != 2 {
+		t.Fatalf("Expected same order with 2 items, got %+v", amended)
+	}
+	if amended.Items[0].ID != firstID || amended.Items[0].Quantity != 1 {
+		t.Errorf("Expected first line kept with quantity 1, got %+v", amended.Items[0])
+	}
+	if amended.Total != 140 {
+		t.Errorf("Expected total 140 (100 + 2 x 20), got %f", amended.Total)
+	}
+
+	changes, _ := service.GetOrderChanges(ctx, order.ID)
+	if len(changes) != 3 {
+		t.Fatalf("Expected 3 changes logged, got %d", len(changes))
+	}
+	if changes[0].OldQuantity != 2 || changes[0].NewQuantity != 1 || changes[0].PreviousTotal != 250 || changes[0].NewTotal != 140 {
+		t.Errorf("Unexpected change %+v", changes[0])
+	}
+	if changes[1].Action != domain.OrderChangeRemoveItem || changes[1].ProductID != 2 {
+		t.Errorf("Unexpected change %+v", changes[1])
+	}
+}
+
+func TestAmendOrder_Validation(t *testing.T) {
+	ctx := context.Background()
+	service, _, _, _ := setupService()
+	order, _ := service.CreateOrder(ctx, domain.CreateOrderRequest{
+		UserID: 1,
+		Items:  []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}},
+	})
+	itemID := order.Items[0].ID
+
+	tests := []struct {
+		name     string
+		change   domain.OrderItemChange
+		expected error
+	}{
+		{"insufficient stock", domain.OrderItemChange{Action: domain.OrderChangeChangeQuantity, OrderItemID: itemID, Quantity: 11}, ErrInsufficientStock},
+		{"unknown product", domain.OrderItemChange{Action: domain.OrderChangeAddItem, ProductID: 99, Quantity: 1}, ErrProductNotFound},
+		{"unknown line", domain.OrderItemChange{Action: domain.OrderChangeRemoveItem, OrderItemID: 99}, ErrInvalidOrderChange},
+		{"empty order", domain.OrderItemChange{Action: domain.OrderChangeRemoveItem, OrderItemID: itemID}, ErrInvalidOrderChange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.AmendOrder(ctx, order.ID, domain.AmendOrderRequest{Changes: []domain.OrderItemChange{tt.change}})
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	service.ConfirmOrder(ctx, order.ID)
+	_, err := service.AmendOrder(ctx, order.ID, domain.AmendOrderRequest{Changes: []domain.OrderItemChange{
+		{Action: domain.OrderChangeAddItem, ProductID: 2, Quantity: 1},
+	}})
+	if err != ErrOrderNotEditable {
+		t.Errorf("Expected ErrOrderNotEditable, got %v", err)
+	}
+}
+
+func TestAmendOrder_VoidsInsufficientAuthorization(t *testing.T) {
+	ctx := context.Background()
+	service, paymentService, paymentRepo, _ := setupPaymentService()
+	order := createPendingOrder(t, service)
+	payment, _ := paymentService.AuthorizeOrder(ctx, order.ID, approvedCard())
+
+	if _, err := service.AmendOrder(ctx, order.ID, domain.AmendOrderRequest{Changes: []domain.OrderItemChange{
+		{Action: domain.OrderChangeAddItem, ProductID: 1, Quantity: 1},
+	}}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if paymentRepo.payments[payment.ID].Status != domain.PaymentVoided {
+		t.Errorf("Expected authorization to be voided, got %s", paymentRepo.payments[payment.ID].Status)
+	}
+	if _, err := service.ConfirmOrder(ctx, order.ID); err != ErrPaymentRequired {
+		t.Errorf("Expected ErrPaymentRequired, got %v", err)
+	}
+}
+
+func TestAmendOrder_KeepsCouponAtUsageLimit(t *testing.T) {
+	ctx := context.Background()
+	service, promotionRepo, _ := setupPromotionService()
+	promotionRepo.Create(ctx, &domain.Promotion{Code: code("ONCE"), Name: "once", Type: domain.PromotionPercentage, Value: 10, MaxUses: 1, MaxUsesPerUser: 1})
+
+	order, err := service.CreateOrder(ctx, domain.CreateOrderRequest{
+		UserID:      1,
+		Items:       []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}},
+		CouponCodes: []string{"ONCE"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// El único uso del cupón es del propio pedido, así que sigue aplicando
+	amended, err := service.AmendOrder(ctx, order.ID, domain.AmendOrderRequest{Changes: []domain.OrderItemChange{
+		{Action: domain.OrderChangeChangeQuantity, OrderItemID: order.Items[0].ID, Quantity: 2},
+	}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if amended.Items[0].Discount != 20 {
+		t.Errorf("Expected 10%% off 200, got %f", amended.Items[0].Discount)
+	}
+	if promotionRepo.promotions[1].UsedCount != 1 || len(promotionRepo.redemptions) != 1 {
+		t.Errorf("Expected a single redemption, got used count %d and %d redemptions",
+			promotionRepo.promotions[1].UsedCount, len(promotionRepo.redemptions))
+	}
+}
+
+type failingItemsRepository struct {
+	*mockOrderRepository
+}
+
+func (f *failingItemsRepository) UpdateItems(ctx context.Context, order *domain.Order) error {
+	return errors.New("database unavailable")
+}
+
+func TestAmendOrder_KeepsRedemptionsWhenUpdateFails(t *testing.T) {
+	ctx := context.Background()
+	_, userRepo, productRepo, orderRepo := setupService()
+	promotionRepo := &mockPromotionRepository{promotions: make(map[uint]*domain.Promotion)}
+	promotions := NewPromotionService(promotionRepo)
+	promotionRepo.Create(ctx, &domain.Promotion{Name: "big orders", Type: domain.PromotionPercentage, Value: 10, MinSubtotal: 150})
+
+	service := NewOrderService(orderRepo, productRepo, userRepo, WithPromotions(promotions))
+	order, err := service.CreateOrder(ctx, domain.CreateOrderRequest{
+		UserID: 1,
+		Items:  []domain.OrderItemRequest{{ProductID: 1, Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Con una sola unidad la promoción deja de aplicar, pero el cambio no se guarda
+	failing := NewOrderService(orderRepo, productRepo, userRepo,
+		WithPromotions(promotions),
+		WithOutbox(&mockTransactor{orders: &failingItemsRepository{orderRepo}, products: productRepo, outbox: &mockOutboxRepository{}}),
+	)
+	if _, err := failing.AmendOrder(ctx, order.ID, domain.AmendOrderRequest{Changes: []domain.OrderItemChange{
+		{Action: domain.OrderChangeChangeQuantity, OrderItemID: order.Items[0].ID, Quantity: 1},
+	}}); err == nil {
+		t.Fatal("Expected error from failing repository")
+	}
+	if promotionRepo.promotions[1].UsedCount != 1 || len(promotionRepo.redemptions) != 1 {
+		t.Errorf("Expected the redemption to survive, got used count %d and %d redemptions",
+			promotionRepo.promotions[1].UsedCount, len(promotionRepo.redemptions))
+	}
+}
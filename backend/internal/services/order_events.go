@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/repositories"
+)
+
+// WithOutbox guarda los eventos de dominio del pedido en la misma transacción que el cambio de estado
+func WithOutbox(transactor repositories.Transactor) OrderServiceOption {
+	return func(s *OrderService) {
+		s.transactor = transactor
+	}
+}
+
+// OrderPublisher recibe los cambios de estado de los pedidos una vez guardados
+type OrderPublisher interface {
+	PublishOrder(eventType domain.EventType, order domain.OrderEvent)
+}
+
+// WithPublisher notifica los cambios de estado de los pedidos, por ejemplo a los clientes en tiempo real
+func WithPublisher(publisher OrderPublisher) OrderServiceOption {
+	return func(s *OrderService) {
+		s.publisher = publisher
+	}
+}
+
+// withinTransaction ejecuta fn con los repositorios de una transacción. Sin outbox
+// configurado usa los repositorios del servicio y los eventos se descartan.
+func (s *OrderService) withinTransaction(ctx context.Context, fn func(tx repositories.Repositories) error) error {
+	if s.transactor == nil {
+		return fn(repositories.Repositories{Orders: s.orderRepo, Products: s.productRepo})
+	}
+	return s.transactor.WithinTransaction(ctx, fn)
+}
+
+// raiseOrderEvent registra un evento del ciclo de vida del pedido
+func raiseOrderEvent(ctx context.Context, tx repositories.Repositories, eventType domain.EventType, order *domain.Order) error {
+	return raise(ctx, tx, eventType, "order", order.ID, orderEvent(order))
+}
+
+// publish avisa al publisher, si hay, de un cambio ya confirmado en la base
+func (s *OrderService) publish(eventType domain.EventType, order *domain.Order) {
+	if s.publisher != nil {
+		s.publisher.PublishOrder(eventType, orderEvent(order))
+	}
+}
+
+func orderEvent(order *domain.Order) domain.OrderEvent {
+	event := domain.OrderEvent{
+		OrderID:        order.ID,
+		UserID:         order.UserID,
+		Status:         order.Status,
+		Total:          order.Total,
+		TrackingNumber: order.TrackingNumber,
+	}
+	if order.PublicID != nil {
+		event.PublicID = *order.PublicID
+	}
+	if order.Number != nil {
+		event.Number = *order.Number
+	}
+	return event
+}
+
+// updateStock cambia el stock de un producto y registra el evento StockChanged
+func updateStock(ctx context.Context, tx repositories.Repositories, productID uint, order *domain.Order, previous, stock int) error {
+	if err := tx.Products.UpdateStock(ctx, productID, stock); err != nil {
+		return err
+	}
+	event := domain.StockChangedEvent{
+		ProductID:     productID,
+		OrderID:       order.ID,
+		PreviousStock: previous,
+		Stock:         stock,
+	}
+	if order.PublicID != nil {
+		event.OrderPublicID = *order.PublicID
+	}
+	if order.Number != nil {
+		event.OrderNumber = *order.Number
+	}
+	return raise(ctx, tx, domain.EventStockChanged, "product", productID, event)
+}
+
+func raise(ctx context.Context, tx repositories.Repositories, eventType domain.EventType, aggregateType string, aggregateID uint, payload interface{}) error {
+	if tx.Outbox == nil {
+		return nil
+	}
+	event, err := domain.NewOutboxEvent(eventType, aggregateType, aggregateID, payload)
+	if err != nil {
+		return err
+	}
+	return tx.Outbox.Create(ctx, event)
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/repositories"
+	"testing"
+	"time"
+)
+
+type mockOutboxRepository struct {
+	events []domain.OutboxEvent
+}
+
+func (m *mockOutboxRepository) Create(ctx context.Context, event *domain.OutboxEvent) error {
+	event.ID = uint(len(m.events) + 1)
+	m.events = append(m.events, *event)
+	return nil
+}
+
+func (m *mockOutboxRepository) GetDue(ctx context.Context, now time.Time, limit int) ([]domain.OutboxEvent, error) {
+	return m.events, nil
+}
+
+func (m *mockOutboxRepository) OldestPending(ctx context.Context) (*domain.OutboxEvent, error) {
+	return nil, nil
+}
+
+func (m *mockOutboxRepository) Update(ctx context.Context, event *domain.OutboxEvent) error {
+	m.events[event.ID-1] = *event
+	return nil
+}
+
+// mockTransactor descarta los eventos registrados si fn falla, simulando el rollback del outbox
+type mockTransactor struct {
+	orders   repositories.OrderRepository
+	products repositories.ProductRepository
+	outbox   *mockOutboxRepository
+}
+
+func (m *mockTransactor) WithinTransaction(ctx context.Context, fn func(tx repositories.Repositories) error) error {
+	pending := &mockOutboxRepository{}
+	if err := fn(repositories.Repositories{Orders: m.orders, Products: m.products, Outbox: pending}); err != nil {
+		return err
+	}
+	for _, event := range pending.events {
+		m.outbox.Create(ctx, &event)
+	}
+	return nil
+}
+
+func eventTypes(events []domain.OutboxEvent) []domain.EventType {
+	types := make([]domain.EventType, len(events))
+	for i, e := range events {
+		types[i] = e.EventType
+	}
+	return types
+}
+
+func TestOrderLifecycle_RaisesEvents(t *testing.T) {
+	ctx := context.Background()
+	_, userRepo, productRepo, orderRepo := setupService()
+	outbox := &mockOutboxRepository{}
+	addressService, _ := newTestAddressService(userRepo)
+	service := NewOrderService(orderRepo, productRepo, userRepo,
+		WithAddresses(addressService),
+		WithOutbox(&mockTransactor{orders: orderRepo, products: productRepo, outbox: outbox}),
+	)
+
+	order, _ := service.CreateOrder(ctx, domain.CreateOrderRequest{
+		UserID: 1,
+		Items:  []domain.OrderItemRequest{{ProductID: 1, Quantity: 2}},
+	})
+	service.ConfirmOrder(ctx, order.ID)
+	service.CancelOrder(ctx, order.ID)
+
+	expected := []domain.EventType{
+		domain.EventOrderCreated,
+		domain.EventStockChanged, domain.EventOrderConfirmed,
+		domain.EventStockChanged, domain.EventOrderCancelled,
+	}
+	got := eventTypes(outbox.events)
+	if len(got) != len(expected) {
+		t.Fatalf("Expected events %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Expected event %d to be %s, got %s", i, expected[i], got[i])
+		}
+	}
+
+	var stock domain.StockChangedEvent
+	if err := outbox.events[1].Decode(&stock); err != nil || stock.PreviousStock != 10 || stock.Stock != 8 || stock.OrderID != order.ID {
+		t.Errorf("Unexpected stock event %+v (%v)", stock, err)
+	}
+	var cancelled domain.OrderEvent
+	outbox.events[4].Decode(&cancelled)
+	if cancelled.Status != domain.StatusCancelled || cancelled.Total != 200 {
+		t.Errorf("Unexpected cancel event %+v", cancelled)
+	}
+}
+
+type failingOrderRepository struct {
+	*mockOrderRepository
+}
+
+func (f *failingOrderRepository) Update(ctx context.Context, order *domain.Order) error {
+	return errors.New("database unavailable")
+}
+
+func (f *failingOrderRepository) UpdateIfStatus(ctx context.Context, order *domain.Order, status domain.OrderStatus) (bool, error) {
+	return false, errors.New("database unavailable")
+}
+
+func TestConfirmOrder_NoEventWhenUpdateFails(t *testing.T) {
+	ctx := context.Background()
+	_, userRepo, productRepo, orderRepo := setupService()
+	outbox := &mockOutboxRepository{}
+	failing := &failingOrderRepository{orderRepo}
+	service := NewOrderService(orderRepo, productRepo, userRepo,
+		WithOutbox(&mockTransactor{orders: failing, products: productRepo, outbox: outbox}),
+	)
+
+	order, _ := service.CreateOrder(ctx, domain.CreateOrderRequest{UserID: 1, Items: []domain.OrderItemRequest{{ProductID: 2, Quantity: 1}}})
+	if _, err := service.ConfirmOrder(ctx, order.ID); err == nil {
+		t.Fatal("Expected error from failing repository")
+	}
+	if got := eventTypes(outbox.events); len(got) != 1 || got[0] != domain.EventOrderCreated {
+		t.Errorf("Expected only order.created, got %v", got)
+	}
+}
+
+type recordingPublisher struct {
+	statuses []domain.OrderStatus
+}
+
+func (p *recordingPublisher) PublishOrder(eventType domain.EventType, order domain.OrderEvent) {
+	p.statuses = append(p.statuses, order.Status)
+}
+
+func TestOrderLifecycle_PublishesCommittedTransitions(t *testing.T) {
+	ctx := context.Background()
+	_, userRepo, productRepo, orderRepo := setupService()
+	publisher := &recordingPublisher{}
+	failing := &failingOrderRepository{orderRepo}
+	service := NewOrderService(orderRepo, productRepo, userRepo,
+		WithOutbox(&mockTransactor{orders: failing, products: productRepo, outbox: &mockOutboxRepository{}}),
+		WithPublisher(publisher),
+	)
+
+	order, _ := service.CreateOrder(ctx, domain.CreateOrderRequest{UserID: 1, Items: []domain.OrderItemRequest{{ProductID: 2, Quantity: 1}}})
+	service.ConfirmOrder(ctx, order.ID)
+
+	// La confirmación falló al guardar, así que sólo se publica la creación
+	if len(publisher.statuses) != 1 || publisher.statuses[0] != domain.StatusPending {
+		t.Errorf("Expected only PENDING published, got %v", publisher.statuses)
+	}
+}
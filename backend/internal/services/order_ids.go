@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"order-management-system/internal/clock"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/ids"
+	"order-management-system/internal/repositories"
+	"strconv"
+)
+
+// identifierAttempts es cuántas veces se generan identificadores nuevos cuando la base
+// rechaza uno por repetido
+const identifierAttempts = 5
+
+// identifierBatch es cuántos pedidos sin identificadores se completan por consulta
+const identifierBatch = 100
+
+// WithClock reemplaza el reloj usado para fechar pedidos, cancelaciones y promociones
+func WithClock(clk clock.Clock) OrderServiceOption {
+	return func(s *OrderService) {
+		s.clock = clk
+	}
+}
+
+// WithIDGenerator asigna un identificador público a cada pedido nuevo
+func WithIDGenerator(generator ids.Generator) OrderServiceOption {
+	return func(s *OrderService) {
+		s.ids = generator
+	}
+}
+
+// WithOrderNumbers asigna a cada pedido nuevo un número legible para clientes y soporte,
+// por ejemplo con ids.OrderNumberGenerator
+func WithOrderNumbers(generator ids.Generator) OrderServiceOption {
+	return func(s *OrderService) {
+		s.numbers = generator
+	}
+}
+
+// WithNumericOrderIDs hace que ResolveOrderID acepte también los IDs numéricos, para
+// clientes que todavía no usan el identificador público ni el número de pedido. Sin esta
+// opción se rechazan, para no exponer claves secuenciales en la API.
+func WithNumericOrderIDs() OrderServiceOption {
+	return func(s *OrderService) {
+		s.numericIDs = true
+	}
+}
+
+// ResolveOrderID convierte la referencia a un pedido recibida en la API (identificador
+// público, número de pedido o, con WithNumericOrderIDs, ID numérico) en su ID interno. Un
+// número de pedido con el verificador incorrecto se rechaza sin consultar la base.
+func (s *OrderService) ResolveOrderID(ctx context.Context, ref string) (uint, error) {
+	if id, err := strconv.ParseUint(ref, 10, 32); err == nil {
+		if !s.numericIDs {
+			return 0, ErrOrderNotFound
+		}
+		return uint(id), nil
+	}
+
+	var order *domain.Order
+	var err error
+	switch number, ok := ids.ParseOrderNumber(ref); {
+	case ok && s.numbers != nil:
+		order, err = s.orderRepo.GetByNumber(ctx, number)
+	case ids.IsULID(ref) && s.ids != nil:
+		order, err = s.orderRepo.GetByPublicID(ctx, ref)
+	default:
+		return 0, ErrOrderNotFound
+	}
+	if err != nil {
+		return 0, ErrOrderNotFound
+	}
+	return order.ID, nil
+}
+
+// createWithIdentifiers fecha el pedido, le asigna identificador público y número, y
+// ejecuta create. Si la base rechaza un identificador por repetido, create se deshizo
+// entero: se generan otros y se reintenta.
+func (s *OrderService) createWithIdentifiers(order *domain.Order, create func() error) error {
+	now := s.clock.Now()
+	order.CreatedAt = now
+	order.UpdatedAt = now
+
+	var err error
+	for attempt := 0; attempt < identifierAttempts; attempt++ {
+		order.ID = 0
+		order.PublicID, order.Number = nil, nil
+		s.stampIdentifiers(order)
+		if err = create(); !errors.Is(err, repositories.ErrDuplicatedKey) {
+			return err
+		}
+	}
+	return err
+}
+
+// stampIdentifiers asigna el identificador público y el número de pedido que falten
+func (s *OrderService) stampIdentifiers(order *domain.Order) {
+	if s.ids != nil && order.PublicID == nil {
+		publicID := s.ids.NewID()
+		order.PublicID = &publicID
+	}
+	if s.numbers != nil && order.Number == nil {
+		number := s.numbers.NewID()
+		order.Number = &number
+	}
+}
+
+// AssignMissingIdentifiers completa el identificador público y el número de los pedidos
+// creados antes de que existieran, para que sigan siendo accesibles sin el ID numérico.
+// Devuelve cuántos pedidos completó.
+func (s *OrderService) AssignMissingIdentifiers(ctx context.Context) (int, error) {
+	if s.ids == nil && s.numbers == nil {
+		return 0, nil
+	}
+	assigned := 0
+	var afterID uint
+	for {
+		orders, err := s.orderRepo.GetWithoutIdentifiers(ctx, afterID, identifierBatch)
+		if err != nil {
+			return assigned, err
+		}
+		if len(orders) == 0 {
+			return assigned, nil
+		}
+		for i := range orders {
+			order := &orders[i]
+			afterID = order.ID
+			publicID, number := order.PublicID, order.Number
+			for attempt := 0; attempt < identifierAttempts; attempt++ {
+				order.PublicID, order.Number = publicID, number
+				s.stampIdentifiers(order)
+				if err = s.orderRepo.SetIdentifiers(ctx, order); !errors.Is(err, repositories.ErrDuplicatedKey) {
+					break
+				}
+			}
+			if err != nil {
+				return assigned, err
+			}
+			assigned++
+		}
+	}
+}
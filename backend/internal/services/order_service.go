@@ -1,86 +1,273 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"order-management-system/internal/clock"
 	"order-management-system/internal/domain"
+	"order-management-system/internal/ids"
+	"order-management-system/internal/logging"
 	"order-management-system/internal/repositories"
+	"order-management-system/internal/shipping"
 )
 
 var (
-	ErrUserNotFound       = errors.New("user not found")
-	ErrProductNotFound    = errors.New("product not found")
-	ErrInsufficientStock  = errors.New("insufficient stock")
-	ErrOrderNotFound      = errors.New("order not found")
-	ErrInvalidStatus      = errors.New("invalid order status transition")
+	ErrUserNotFound        = errors.New("user not found")
+	ErrProductNotFound     = errors.New("product not found")
+	ErrInsufficientStock   = errors.New("insufficient stock")
+	ErrOrderNotFound       = errors.New("order not found")
+	ErrInvalidStatus       = errors.New("invalid order status transition")
 	ErrCannotCancelShipped = errors.New("cannot cancel shipped order")
+	ErrNoTrackingNumber    = errors.New("order has no tracking number")
 )
 
 type OrderService struct {
 	orderRepo   repositories.OrderRepository
 	productRepo repositories.ProductRepository
 	userRepo    repositories.UserRepository
+	promotions  *PromotionService
+	taxes       TaxCalculator
+	addresses   *AddressService
+	carrier     shipping.Carrier
+	payments    *PaymentService
+	refunds     *RefundService
+	changeRepo  repositories.OrderChangeRepository
+	transactor  repositories.Transactor
+	publisher   OrderPublisher
+	clock       clock.Clock
+	ids         ids.Generator
+	numbers     ids.Generator
+	// numericIDs acepta los IDs numéricos en ResolveOrderID, por compatibilidad
+	numericIDs bool
+}
+
+// OrderServiceOption configura dependencias opcionales del OrderService
+type OrderServiceOption func(*OrderService)
+
+// WithPromotions habilita el cálculo de cupones y promociones en CreateOrder
+func WithPromotions(promotions *PromotionService) OrderServiceOption {
+	return func(s *OrderService) {
+		s.promotions = promotions
+	}
+}
+
+// WithTaxCalculator habilita el cálculo de impuestos en CreateOrder
+func WithTaxCalculator(taxes TaxCalculator) OrderServiceOption {
+	return func(s *OrderService) {
+		s.taxes = taxes
+	}
+}
+
+// WithAddresses habilita la selección de direcciones de envío y facturación en CreateOrder
+func WithAddresses(addresses *AddressService) OrderServiceOption {
+	return func(s *OrderService) {
+		s.addresses = addresses
+	}
+}
+
+// WithCarrier habilita la cotización de envíos y la generación de etiquetas
+func WithCarrier(carrier shipping.Carrier) OrderServiceOption {
+	return func(s *OrderService) {
+		s.carrier = carrier
+	}
+}
+
+// WithPayments exige un pago autorizado para confirmar y lo libera al cancelar
+func WithPayments(payments *PaymentService) OrderServiceOption {
+	return func(s *OrderService) {
+		s.payments = payments
+	}
+}
+
+// WithRefunds registra como reembolso el saldo cobrado de los pedidos cancelados
+func WithRefunds(refunds *RefundService) OrderServiceOption {
+	return func(s *OrderService) {
+		s.refunds = refunds
+	}
 }
 
 func NewOrderService(
 	orderRepo repositories.OrderRepository,
 	productRepo repositories.ProductRepository,
 	userRepo repositories.UserRepository,
+	opts ...OrderServiceOption,
 ) *OrderService {
-	return &OrderService{
+	s := &OrderService{
 		orderRepo:   orderRepo,
 		productRepo: productRepo,
 		userRepo:    userRepo,
+		clock:       clock.System(),
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-// CreateOrder valida stock, existencia de usuario, calcula total y crea pedido con estado PENDING
-func (s *OrderService) CreateOrder(req domain.CreateOrderRequest) (*domain.Order, error) {
+// CreateOrder valida stock, existencia de usuario, aplica promociones e impuestos, calcula total y crea pedido con estado PENDING
+func (s *OrderService) CreateOrder(ctx context.Context, req domain.CreateOrderRequest) (*domain.Order, error) {
 	// Validar existencia del usuario
-	user, err := s.userRepo.GetByID(req.UserID)
+	user, err := s.userRepo.GetByID(ctx, req.UserID)
 	if err != nil {
 		return nil, ErrUserNotFound
 	}
 
-	var total float64
-	var orderItems []domain.OrderItem
+	// Copiar las direcciones de la libreta al pedido
+	var shippingAddress, billingAddress domain.AddressSnapshot
+	if s.addresses != nil {
+		shippingAddress, billingAddress, err = s.addresses.ResolveOrderAddresses(ctx, user.ID, req.ShippingAddressID, req.BillingAddressID)
+		if err != nil {
+			return nil, err
+		}
+	} else if req.ShippingAddressID != nil || req.BillingAddressID != nil {
+		return nil, ErrAddressNotFound
+	}
+
+	orderItems := make([]domain.OrderItem, len(req.Items))
+	for i, item := range req.Items {
+		orderItems[i] = domain.OrderItem{ProductID: item.ProductID, Quantity: item.Quantity}
+	}
 
-	// Validar stock y calcular total
-	for _, item := range req.Items {
-		product, err := s.productRepo.GetByID(item.ProductID)
+	// Validar stock y tomar precios actuales
+	lines, err := s.priceItems(ctx, orderItems)
+	if err != nil {
+		return nil, err
+	}
+
+	// Aplicar descuentos línea por línea
+	var applied []domain.Promotion
+	var discounts *DiscountResult
+	if s.promotions != nil {
+		discounts, err = s.promotions.ApplyPromotions(ctx, user.ID, lines, req.CouponCodes, s.clock.Now())
+		if err != nil {
+			return nil, err
+		}
+		applied = discounts.Promotions
+	} else if len(req.CouponCodes) > 0 {
+		return nil, ErrCouponNotFound
+	}
+
+	order := &domain.Order{
+		UserID:          user.ID,
+		ShippingAddress: shippingAddress,
+		BillingAddress:  billingAddress,
+		Status:          domain.StatusPending,
+		Items:           orderItems,
+	}
+
+	if err := s.computeTotals(ctx, order, lines, discounts, req.ShippingMethod); err != nil {
+		return nil, err
+	}
+
+	err = s.createWithIdentifiers(order, func() error {
+		return s.withinTransaction(ctx, func(tx repositories.Repositories) error {
+			if err := tx.Orders.Create(ctx, order); err != nil {
+				return err
+			}
+			// Los usos de cupones se consumen con el pedido: si se agotaron, no se crea
+			if s.promotions != nil {
+				if err := s.promotions.RecordRedemptions(ctx, tx, order.ID, user.ID, applied); err != nil {
+					return err
+				}
+			}
+			return raiseOrderEvent(ctx, tx, domain.EventOrderCreated, order)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.publish(domain.EventOrderCreated, order)
+
+	return s.orderRepo.GetByID(ctx, order.ID)
+}
+
+// priceItems valida producto y stock de cada línea y fija el precio vigente
+func (s *OrderService) priceItems(ctx context.Context, items []domain.OrderItem) ([]PricedLine, error) {
+	lines := make([]PricedLine, len(items))
+	for i := range items {
+		product, err := s.productRepo.GetByID(ctx, items[i].ProductID)
 		if err != nil {
 			return nil, ErrProductNotFound
 		}
 
-		if product.Stock < item.Quantity {
+		if product.Stock < items[i].Quantity {
 			return nil, ErrInsufficientStock
 		}
 
-		orderItem := domain.OrderItem{
-			ProductID: item.ProductID,
-			Quantity:  item.Quantity,
-			Price:     product.Price,
+		items[i].Price = product.Price
+		items[i].TaxClass = product.TaxClass
+		lines[i] = PricedLine{Product: product, Quantity: items[i].Quantity, UnitPrice: product.Price}
+	}
+	return lines, nil
+}
+
+// computeTotals aplica descuentos, impuestos y envío a las líneas ya valorizadas y recalcula el total
+func (s *OrderService) computeTotals(ctx context.Context, order *domain.Order, lines []PricedLine, discounts *DiscountResult, shippingMethod string) error {
+	order.Subtotal = 0
+	order.DiscountTotal = 0
+	for i := range order.Items {
+		item := &order.Items[i]
+		order.Subtotal += item.Price * float64(item.Quantity)
+		item.Discount = 0
+		item.Discounts = nil
+		if discounts != nil {
+			item.Discounts = discounts.Lines[i]
+			for _, discount := range discounts.Lines[i] {
+				item.Discount = roundMoney(item.Discount + discount.Amount)
+			}
 		}
-		orderItems = append(orderItems, orderItem)
-		total += product.Price * float64(item.Quantity)
+	}
+	order.Subtotal = roundMoney(order.Subtotal)
+	if discounts != nil {
+		order.DiscountTotal = discounts.Total
 	}
 
-	order := &domain.Order{
-		UserID: user.ID,
-		Total:  total,
-		Status: domain.StatusPending,
-		Items:  orderItems,
+	// Calcular impuestos sobre el neto de cada línea
+	if s.taxes != nil {
+		taxable := make([]TaxableLine, len(order.Items))
+		for i, item := range order.Items {
+			taxable[i] = TaxableLine{
+				TaxClass: item.TaxClass,
+				Amount:   roundMoney(item.Price*float64(item.Quantity) - item.Discount),
+			}
+		}
+		jurisdiction := domain.Jurisdiction{Country: order.ShippingAddress.Country, Region: order.ShippingAddress.Region}
+		result, err := s.taxes.Calculate(ctx, jurisdiction, taxable)
+		if err != nil {
+			return err
+		}
+		for i := range order.Items {
+			order.Items[i].TaxClass = result.Lines[i].TaxClass
+			order.Items[i].TaxRate = result.Lines[i].Rate
+			order.Items[i].TaxAmount = result.Lines[i].Amount
+		}
+		order.TaxLines = result.TaxLines
+		order.TaxTotal = result.Total
+		order.TaxCountry = result.Jurisdiction.Country
+		order.TaxRegion = result.Jurisdiction.Region
 	}
 
-	if err := s.orderRepo.Create(order); err != nil {
-		return nil, err
+	// Cotizar el envío con el método elegido
+	if s.carrier != nil && !order.ShippingAddress.IsEmpty() {
+		rate, err := s.quoteRate(ctx, order.ShippingAddress, parcelsFromLines(lines), shippingMethod)
+		if err != nil {
+			return err
+		}
+		order.ShippingCarrier = rate.Carrier
+		order.ShippingMethod = rate.Method
+		order.ShippingCost = rate.Amount
+	} else if shippingMethod != "" {
+		return shipping.ErrMethodUnavailable
 	}
 
-	return s.orderRepo.GetByID(order.ID)
+	order.Total = roundMoney(order.Subtotal - order.DiscountTotal + order.TaxTotal + order.ShippingCost)
+	return nil
 }
 
 // ConfirmOrder reduce el stock real y cambia el estado a CONFIRMED
-func (s *OrderService) ConfirmOrder(orderID uint) (*domain.Order, error) {
-	order, err := s.orderRepo.GetByID(orderID)
+func (s *OrderService) ConfirmOrder(ctx context.Context, orderID uint) (*domain.Order, error) {
+	order, err := s.orderRepo.GetByID(ctx, orderID)
 	if err != nil {
 		return nil, ErrOrderNotFound
 	}
@@ -89,34 +276,73 @@ func (s *OrderService) ConfirmOrder(orderID uint) (*domain.Order, error) {
 		return nil, ErrInvalidStatus
 	}
 
-	// Reducir stock de cada producto
-	for _, item := range order.Items {
-		product, err := s.productRepo.GetByID(item.ProductID)
+	// Verificar que exista un pago autorizado por el total
+	var payment *domain.Payment
+	if s.payments != nil {
+		payment, err = s.payments.RequireAuthorization(ctx, order)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Validar stock de todos los productos antes de cobrar
+	previousStock := make([]int, len(order.Items))
+	newStock := make([]int, len(order.Items))
+	for i, item := range order.Items {
+		product, err := s.productRepo.GetByID(ctx, item.ProductID)
 		if err != nil {
 			return nil, ErrProductNotFound
 		}
 
-		newStock := product.Stock - item.Quantity
-		if newStock < 0 {
+		previousStock[i] = product.Stock
+		newStock[i] = product.Stock - item.Quantity
+		if newStock[i] < 0 {
 			return nil, ErrInsufficientStock
 		}
+	}
 
-		if err := s.productRepo.UpdateStock(item.ProductID, newStock); err != nil {
+	// Capturar el pago autorizado; si la transacción falla se reembolsa
+	if payment != nil {
+		if err := s.payments.Capture(ctx, payment, order.Total); err != nil {
 			return nil, err
 		}
 	}
 
+	// Reducir stock de cada producto y confirmar en una misma transacción
 	order.Status = domain.StatusConfirmed
-	if err := s.orderRepo.Update(order); err != nil {
+	err = s.withinTransaction(ctx, func(tx repositories.Repositories) error {
+		// Si se canceló mientras se cobraba no se confirma: el error devuelve lo cobrado
+		updated, err := tx.Orders.UpdateIfStatus(ctx, order, domain.StatusPending)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return ErrInvalidStatus
+		}
+		for i, item := range order.Items {
+			if err := updateStock(ctx, tx, item.ProductID, order, previousStock[i], newStock[i]); err != nil {
+				return err
+			}
+		}
+		return raiseOrderEvent(ctx, tx, domain.EventOrderConfirmed, order)
+	})
+	if err != nil {
+		// El pedido no quedó confirmado: devolver lo cobrado para no retener el dinero
+		if payment != nil {
+			if _, refundErr := s.payments.RefundPayment(ctx, payment, payment.CapturedAmount); refundErr != nil {
+				logging.For(ctx, "orders").Error("could not refund capture of unconfirmed order", "order_id", order.ID, "payment_id", payment.ID, "error", refundErr)
+			}
+		}
 		return nil, err
 	}
+	s.publish(domain.EventOrderConfirmed, order)
 
-	return s.orderRepo.GetByID(order.ID)
+	return s.orderRepo.GetByID(ctx, order.ID)
 }
 
-// ShipOrder cambia el estado a SHIPPED solo si está CONFIRMED
-func (s *OrderService) ShipOrder(orderID uint) (*domain.Order, error) {
-	order, err := s.orderRepo.GetByID(orderID)
+// ShipOrder cambia el estado a SHIPPED solo si está CONFIRMED y tiene dirección de envío
+func (s *OrderService) ShipOrder(ctx context.Context, orderID uint) (*domain.Order, error) {
+	order, err := s.orderRepo.GetByID(ctx, orderID)
 	if err != nil {
 		return nil, ErrOrderNotFound
 	}
@@ -125,56 +351,280 @@ func (s *OrderService) ShipOrder(orderID uint) (*domain.Order, error) {
 		return nil, ErrInvalidStatus
 	}
 
+	if order.ShippingAddress.IsEmpty() {
+		return nil, ErrMissingShippingAddress
+	}
+
+	// Solicitar la etiqueta al transportista
+	if s.carrier != nil {
+		method := order.ShippingMethod
+		if method == "" {
+			method = shipping.MethodStandard
+		}
+		label, err := s.carrier.CreateLabel(ctx, shipping.LabelRequest{
+			Reference:   orderReference(order.ID),
+			Method:      method,
+			Destination: order.ShippingAddress,
+			Parcels:     parcelsFromItems(order.Items),
+		})
+		if err != nil {
+			return nil, err
+		}
+		order.ShippingCarrier = s.carrier.Name()
+		order.ShippingMethod = method
+		order.TrackingNumber = label.TrackingNumber
+		order.LabelURL = label.LabelURL
+	}
+
 	order.Status = domain.StatusShipped
-	if err := s.orderRepo.Update(order); err != nil {
+	err = s.withinTransaction(ctx, func(tx repositories.Repositories) error {
+		// Si se canceló mientras se pedía la etiqueta no se envía: el error la anula
+		updated, err := tx.Orders.UpdateIfStatus(ctx, order, domain.StatusConfirmed)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return ErrInvalidStatus
+		}
+		return raiseOrderEvent(ctx, tx, domain.EventOrderShipped, order)
+	})
+	if err != nil {
+		// El pedido no quedó enviado: anular la etiqueta para no dejarla huérfana
+		if order.TrackingNumber != "" {
+			if voidErr := s.carrier.VoidLabel(ctx, order.TrackingNumber); voidErr != nil {
+				logging.For(ctx, "orders").Error("could not void shipping label", "order_id", order.ID, "tracking_number", order.TrackingNumber, "error", voidErr)
+			}
+		}
 		return nil, err
 	}
+	s.publish(domain.EventOrderShipped, order)
 
-	return s.orderRepo.GetByID(order.ID)
+	return s.orderRepo.GetByID(ctx, order.ID)
+}
+
+type cancellation struct {
+	actor    string
+	reason   string
+	expected domain.OrderStatus
+}
+
+// CancelOption describe quién cancela un pedido y bajo qué condición
+type CancelOption func(*cancellation)
+
+// CancelledBy registra el actor y el motivo de la cancelación; por defecto es el usuario sin motivo
+func CancelledBy(actor, reason string) CancelOption {
+	return func(c *cancellation) {
+		c.actor = actor
+		c.reason = reason
+	}
+}
+
+// IfStatus cancela sólo si el pedido sigue en ese estado; si cambió devuelve ErrInvalidStatus
+func IfStatus(status domain.OrderStatus) CancelOption {
+	return func(c *cancellation) {
+		c.expected = status
+	}
 }
 
 // CancelOrder devuelve el stock si no fue enviado y cambia estado a CANCELLED
-func (s *OrderService) CancelOrder(orderID uint) (*domain.Order, error) {
-	order, err := s.orderRepo.GetByID(orderID)
+func (s *OrderService) CancelOrder(ctx context.Context, orderID uint, opts ...CancelOption) (*domain.Order, error) {
+	cancel := cancellation{actor: domain.ActorUser}
+	for _, opt := range opts {
+		opt(&cancel)
+	}
+
+	order, err := s.orderRepo.GetByID(ctx, orderID)
 	if err != nil {
 		return nil, ErrOrderNotFound
 	}
 
+	if cancel.expected != "" && order.Status != cancel.expected {
+		return nil, ErrInvalidStatus
+	}
+
 	if order.Status == domain.StatusShipped {
 		return nil, ErrCannotCancelShipped
 	}
 
-	// Si el pedido estaba confirmado, devolver stock
-	if order.Status == domain.StatusConfirmed {
-		for _, item := range order.Items {
-			product, err := s.productRepo.GetByID(item.ProductID)
-			if err != nil {
-				return nil, ErrProductNotFound
+	if order.Status == domain.StatusCancelled || order.Status == domain.StatusMerged {
+		return nil, ErrInvalidStatus
+	}
+
+	// Unidades que ya volvieron al stock por reembolsos parciales
+	restocked := map[uint]int{}
+	if s.refunds != nil {
+		if restocked, err = s.refunds.RestockedQuantities(ctx, order.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	previous := order.Status
+	cancelledAt := s.clock.Now()
+	order.Status = domain.StatusCancelled
+	order.CancelledBy = cancel.actor
+	order.CancellationReason = cancel.reason
+	order.CancelledAt = &cancelledAt
+	err = s.withinTransaction(ctx, func(tx repositories.Repositories) error {
+		// El estado leído puede haber cambiado desde entonces: sólo se cancela si sigue igual
+		updated, err := tx.Orders.UpdateIfStatus(ctx, order, previous)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return ErrInvalidStatus
+		}
+
+		// Si el pedido estaba confirmado, devolver stock
+		if previous == domain.StatusConfirmed {
+			for _, item := range order.Items {
+				quantity := item.Quantity - restocked[item.ID]
+				if quantity <= 0 {
+					continue
+				}
+
+				product, err := tx.Products.GetByID(ctx, item.ProductID)
+				if err != nil {
+					return ErrProductNotFound
+				}
+
+				if err := updateStock(ctx, tx, item.ProductID, order, product.Stock, product.Stock+quantity); err != nil {
+					return err
+				}
 			}
+		}
 
-			newStock := product.Stock + item.Quantity
-			if err := s.productRepo.UpdateStock(item.ProductID, newStock); err != nil {
-				return nil, err
+		// Liberar los usos de cupones consumidos por el pedido
+		if s.promotions != nil {
+			if err := s.promotions.ReleaseRedemptions(ctx, tx, order.ID); err != nil {
+				return err
 			}
 		}
+		return raiseOrderEvent(ctx, tx, domain.EventOrderCancelled, order)
+	})
+	if err != nil {
+		return nil, err
 	}
+	s.releasePayments(ctx, order)
+	s.publish(domain.EventOrderCancelled, order)
 
-	order.Status = domain.StatusCancelled
-	if err := s.orderRepo.Update(order); err != nil {
+	return s.orderRepo.GetByID(ctx, order.ID)
+}
+
+// releasePayments reembolsa el saldo cobrado y anula las autorizaciones de un pedido ya cancelado.
+// Corre después del commit para no mover dinero si la cancelación no se guarda; los fallos quedan
+// registrados y se pueden reintentar porque ambos pasos sólo actúan sobre el saldo pendiente.
+func (s *OrderService) releasePayments(ctx context.Context, order *domain.Order) {
+	if s.refunds != nil {
+		if _, err := s.refunds.RefundBalance(ctx, order, "order cancelled"); err != nil {
+			logging.For(ctx, "orders").Error("could not refund cancelled order", "order_id", order.ID, "error", err)
+			return
+		}
+	}
+	if s.payments != nil {
+		if err := s.payments.Release(ctx, order.ID); err != nil {
+			logging.For(ctx, "orders").Error("could not release payments of cancelled order", "order_id", order.ID, "error", err)
+		}
+	}
+}
+
+// QuoteShipping cotiza las tarifas de envío disponibles para un pedido antes de crearlo
+func (s *OrderService) QuoteShipping(ctx context.Context, req domain.CreateOrderRequest) ([]shipping.Rate, error) {
+	if s.carrier == nil || s.addresses == nil {
+		return nil, shipping.ErrNoRates
+	}
+
+	if _, err := s.userRepo.GetByID(ctx, req.UserID); err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	destination, _, err := s.addresses.ResolveOrderAddresses(ctx, req.UserID, req.ShippingAddressID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if destination.IsEmpty() {
+		return nil, ErrMissingShippingAddress
+	}
+
+	var lines []PricedLine
+	for _, item := range req.Items {
+		product, err := s.productRepo.GetByID(ctx, item.ProductID)
+		if err != nil {
+			return nil, ErrProductNotFound
+		}
+		lines = append(lines, PricedLine{Product: product, Quantity: item.Quantity, UnitPrice: product.Price})
+	}
+
+	return s.carrier.QuoteRates(ctx, destination, parcelsFromLines(lines))
+}
+
+// GetTracking consulta al transportista el estado del envío de un pedido
+func (s *OrderService) GetTracking(ctx context.Context, orderID uint) (*shipping.TrackingStatus, error) {
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, ErrOrderNotFound
+	}
+	if order.TrackingNumber == "" || s.carrier == nil {
+		return nil, ErrNoTrackingNumber
+	}
+	return s.carrier.GetTrackingStatus(ctx, order.TrackingNumber)
+}
+
+// quoteRate elige la tarifa del método pedido o la más barata si no se indica
+func (s *OrderService) quoteRate(ctx context.Context, destination domain.AddressSnapshot, parcels []shipping.Parcel, method string) (*shipping.Rate, error) {
+	rates, err := s.carrier.QuoteRates(ctx, destination, parcels)
+	if err != nil {
 		return nil, err
 	}
+	if method == "" {
+		cheapest := rates[0]
+		for _, rate := range rates[1:] {
+			if rate.Amount < cheapest.Amount {
+				cheapest = rate
+			}
+		}
+		return &cheapest, nil
+	}
+	return shipping.FindRate(rates, method)
+}
+
+func orderReference(orderID uint) string {
+	return fmt.Sprintf("order-%d", orderID)
+}
+
+func parcelsFromLines(lines []PricedLine) []shipping.Parcel {
+	parcels := make([]shipping.Parcel, 0, len(lines))
+	for _, line := range lines {
+		parcels = append(parcels, productParcel(line.Product, line.Quantity))
+	}
+	return parcels
+}
 
-	return s.orderRepo.GetByID(order.ID)
+func parcelsFromItems(items []domain.OrderItem) []shipping.Parcel {
+	parcels := make([]shipping.Parcel, 0, len(items))
+	for _, item := range items {
+		parcels = append(parcels, productParcel(&item.Product, item.Quantity))
+	}
+	return parcels
+}
+
+// productParcel apila las unidades de una línea en un único bulto
+func productParcel(product *domain.Product, quantity int) shipping.Parcel {
+	return shipping.Parcel{
+		WeightGrams: product.WeightGrams * quantity,
+		LengthCm:    product.LengthCm,
+		WidthCm:     product.WidthCm,
+		HeightCm:    product.HeightCm * float64(quantity),
+	}
 }
 
-func (s *OrderService) GetOrder(orderID uint) (*domain.Order, error) {
-	return s.orderRepo.GetByID(orderID)
+func (s *OrderService) GetOrder(ctx context.Context, orderID uint) (*domain.Order, error) {
+	return s.orderRepo.GetByID(ctx, orderID)
 }
 
-func (s *OrderService) GetAllOrders() ([]domain.Order, error) {
-	return s.orderRepo.GetAll()
+func (s *OrderService) GetAllOrders(ctx context.Context) ([]domain.Order, error) {
+	return s.orderRepo.GetAll(ctx)
 }
 
-func (s *OrderService) GetOrdersByUser(userID uint) ([]domain.Order, error) {
-	return s.orderRepo.GetByUserID(userID)
+func (s *OrderService) GetOrdersByUser(ctx context.Context, userID uint) ([]domain.Order, error) {
+	return s.orderRepo.GetByUserID(ctx, userID)
 }
@@ -1,9 +1,17 @@
 package services
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"order-management-system/internal/clock"
 	"order-management-system/internal/domain"
+	"order-management-system/internal/ids"
+	"order-management-system/internal/repositories"
+	"strings"
 	"testing"
+	"time"
 )
 
 // Mock Repositories
@@ -11,19 +19,19 @@ type mockUserRepository struct {
 	users map[uint]*domain.User
 }
 
-func (m *mockUserRepository) GetByID(id uint) (*domain.User, error) {
+func (m *mockUserRepository) GetByID(ctx context.Context, id uint) (*domain.User, error) {
 	if user, ok := m.users[id]; ok {
 		return user, nil
 	}
 	return nil, errors.New("user not found")
 }
 
-func (m *mockUserRepository) Create(user *domain.User) error {
+func (m *mockUserRepository) Create(ctx context.Context, user *domain.User) error {
 	m.users[user.ID] = user
 	return nil
 }
 
-func (m *mockUserRepository) GetAll() ([]domain.User, error) {
+func (m *mockUserRepository) GetAll(ctx context.Context) ([]domain.User, error) {
 	var users []domain.User
 	for _, u := range m.users {
 		users = append(users, *u)
@@ -35,14 +43,14 @@ type mockProductRepository struct {
 	products map[uint]*domain.Product
 }
 
-func (m *mockProductRepository) GetByID(id uint) (*domain.Product, error) {
+func (m *mockProductRepository) GetByID(ctx context.Context, id uint) (*domain.Product, error) {
 	if product, ok := m.products[id]; ok {
 		return product, nil
 	}
 	return nil, errors.New("product not found")
 }
 
-func (m *mockProductRepository) UpdateStock(id uint, quantity int) error {
+func (m *mockProductRepository) UpdateStock(ctx context.Context, id uint, quantity int) error {
 	if product, ok := m.products[id]; ok {
 		product.Stock = quantity
 		return nil
@@ -50,7 +58,7 @@ func (m *mockProductRepository) UpdateStock(id uint, quantity int) error {
 	return errors.New("product not found")
 }
 
-func (m *mockProductRepository) GetAll() ([]domain.Product, error) {
+func (m *mockProductRepository) GetAll(ctx context.Context) ([]domain.Product, error) {
 	var products []domain.Product
 	for _, p := range m.products {
 		products = append(products, *p)
@@ -58,31 +66,55 @@ func (m *mockProductRepository) GetAll() ([]domain.Product, error) {
 	return products, nil
 }
 
-func (m *mockProductRepository) Create(product *domain.Product) error {
+func (m *mockProductRepository) Create(ctx context.Context, product *domain.Product) error {
 	m.products[product.ID] = product
 	return nil
 }
 
 type mockOrderRepository struct {
-	orders map[uint]*domain.Order
-	nextID uint
+	orders     map[uint]*domain.Order
+	nextID     uint
+	nextItemID uint
 }
 
-func (m *mockOrderRepository) Create(order *domain.Order) error {
+func (m *mockOrderRepository) Create(ctx context.Context, order *domain.Order) error {
 	m.nextID++
 	order.ID = m.nextID
+	for i := range order.Items {
+		m.nextItemID++
+		order.Items[i].ID = m.nextItemID
+		order.Items[i].OrderID = order.ID
+	}
 	m.orders[order.ID] = order
 	return nil
 }
 
-func (m *mockOrderRepository) GetByID(id uint) (*domain.Order, error) {
+func (m *mockOrderRepository) GetByID(ctx context.Context, id uint) (*domain.Order, error) {
 	if order, ok := m.orders[id]; ok {
 		return order, nil
 	}
 	return nil, errors.New("order not found")
 }
 
-func (m *mockOrderRepository) GetAll() ([]domain.Order, error) {
+func (m *mockOrderRepository) GetByPublicID(ctx context.Context, publicID string) (*domain.Order, error) {
+	for _, o := range m.orders {
+		if o.PublicID != nil && *o.PublicID == publicID {
+			return o, nil
+		}
+	}
+	return nil, errors.New("order not found")
+}
+
+func (m *mockOrderRepository) GetByNumber(ctx context.Context, number string) (*domain.Order, error) {
+	for _, o := range m.orders {
+		if o.Number != nil && *o.Number == number {
+			return o, nil
+		}
+	}
+	return nil, errors.New("order not found")
+}
+
+func (m *mockOrderRepository) GetAll(ctx context.Context) ([]domain.Order, error) {
 	var orders []domain.Order
 	for _, o := range m.orders {
 		orders = append(orders, *o)
@@ -90,7 +122,7 @@ func (m *mockOrderRepository) GetAll() ([]domain.Order, error) {
 	return orders, nil
 }
 
-func (m *mockOrderRepository) GetByUserID(userID uint) ([]domain.Order, error) {
+func (m *mockOrderRepository) GetByUserID(ctx context.Context, userID uint) ([]domain.Order, error) {
 	var orders []domain.Order
 	for _, o := range m.orders {
 		if o.UserID == userID {
@@ -100,7 +132,7 @@ func (m *mockOrderRepository) GetByUserID(userID uint) ([]domain.Order, error) {
 	return orders, nil
 }
 
-func (m *mockOrderRepository) Update(order *domain.Order) error {
+func (m *mockOrderRepository) Update(ctx context.Context, order *domain.Order) error {
 	if _, ok := m.orders[order.ID]; ok {
 		m.orders[order.ID] = order
 		return nil
@@ -108,6 +140,82 @@ func (m *mockOrderRepository) Update(order *domain.Order) error {
 	return errors.New("order not found")
 }
 
+// UpdateIfStatus compara con el estado guardado. Como el mock devuelve los mismos punteros que
+// guarda, si recibe el pedido guardado no puede ver su estado anterior y lo acepta.
+func (m *mockOrderRepository) UpdateIfStatus(ctx context.Context, order *domain.Order, status domain.OrderStatus) (bool, error) {
+	stored, ok := m.orders[order.ID]
+	if !ok {
+		return false, errors.New("order not found")
+	}
+	if stored != order && stored.Status != status {
+		return false, nil
+	}
+	m.orders[order.ID] = order
+	return true, nil
+}
+
+func (m *mockOrderRepository) MoveItems(ctx context.Context, itemIDs []uint, orderID uint) error {
+	moving := make(map[uint]bool)
+	for _, id := range itemIDs {
+		moving[id] = true
+	}
+	var moved []domain.OrderItem
+	for _, order := range m.orders {
+		var kept []domain.OrderItem
+		for _, item := range order.Items {
+			if moving[item.ID] {
+				item.OrderID = orderID
+				moved = append(moved, item)
+			} else {
+				kept = append(kept, item)
+			}
+		}
+		order.Items = kept
+	}
+	m.orders[orderID].Items = append(m.orders[orderID].Items, moved...)
+	return nil
+}
+
+func (m *mockOrderRepository) GetPendingCreatedBefore(ctx context.Context, before time.Time, afterID uint, limit int) ([]domain.Order, error) {
+	var orders []domain.Order
+	for id := afterID + 1; id <= m.nextID && len(orders) < limit; id++ {
+		if o, ok := m.orders[id]; ok && o.Status == domain.StatusPending && o.CreatedAt.Before(before) {
+			orders = append(orders, *o)
+		}
+	}
+	return orders, nil
+}
+
+func (m *mockOrderRepository) GetWithoutIdentifiers(ctx context.Context, afterID uint, limit int) ([]domain.Order, error) {
+	var orders []domain.Order
+	for id := afterID + 1; id <= m.nextID && len(orders) < limit; id++ {
+		if o, ok := m.orders[id]; ok && (o.PublicID == nil || o.Number == nil) {
+			orders = append(orders, *o)
+		}
+	}
+	return orders, nil
+}
+
+func (m *mockOrderRepository) SetIdentifiers(ctx context.Context, order *domain.Order) error {
+	stored, ok := m.orders[order.ID]
+	if !ok {
+		return errors.New("order not found")
+	}
+	stored.PublicID = order.PublicID
+	stored.Number = order.Number
+	return nil
+}
+
+func (m *mockOrderRepository) UpdateItems(ctx context.Context, order *domain.Order) error {
+	for i := range order.Items {
+		if order.Items[i].ID == 0 {
+			m.nextItemID++
+			order.Items[i].ID = m.nextItemID
+		}
+	}
+	return m.Update(ctx, order)
+}
+
 // Test Functions
 func setupService() (*OrderService, *mockUserRepository, *mockProductRepository, *mockOrderRepository) {
 	userRepo := &mockUserRepository{users: make(map[uint]*domain.User)}
@@ -119,11 +227,13 @@ func setupService() (*OrderService, *mockUserRepository, *mockProductRepository,
 	productRepo.products[1] = &domain.Product{ID: 1, Name: "Product 1", Price: 100.0, Stock: 10}
 	productRepo.products[2] = &domain.Product{ID: 2, Name: "Product 2", Price: 50.0, Stock: 5}
 
-	service := NewOrderService(orderRepo, productRepo, userRepo)
+	addressService, _ := newTestAddressService(userRepo)
+	service := NewOrderService(orderRepo, productRepo, userRepo, WithAddresses(addressService))
 	return service, userRepo, productRepo, orderRepo
 }
 
 func TestCreateOrder_Success(t *testing.T) {
+	ctx := context.Background()
 	service, _, _, _ := setupService()
 
 	req := domain.CreateOrderRequest{
@@ -133,7 +243,7 @@ func TestCreateOrder_Success(t *testing.T) {
 		},
 	}
 
-	order, err := service.CreateOrder(req)
+	order, err := service.CreateOrder(ctx, req)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -149,6 +259,7 @@ func TestCreateOrder_Success(t *testing.T) {
 }
 
 func TestCreateOrder_InsufficientStock(t *testing.T) {
+	ctx := context.Background()
 	service, _, _, _ := setupService()
 
 	req := domain.CreateOrderRequest{
@@ -158,13 +269,14 @@ func TestCreateOrder_InsufficientStock(t *testing.T) {
 		},
 	}
 
-	_, err := service.CreateOrder(req)
+	_, err := service.CreateOrder(ctx, req)
 	if err != ErrInsufficientStock {
 		t.Errorf("Expected ErrInsufficientStock, got %v", err)
 	}
 }
 
 func TestCreateOrder_UserNotFound(t *testing.T) {
+	ctx := context.Background()
 	service, _, _, _ := setupService()
 
 	req := domain.CreateOrderRequest{
@@ -174,13 +286,14 @@ func TestCreateOrder_UserNotFound(t *testing.T) {
 		},
 	}
 
-	_, err := service.CreateOrder(req)
+	_, err := service.CreateOrder(ctx, req)
 	if err != ErrUserNotFound {
 		t.Errorf("Expected ErrUserNotFound, got %v", err)
 	}
 }
 
 func TestConfirmOrder_Success(t *testing.T) {
+	ctx := context.Background()
 	service, _, productRepo, _ := setupService()
 
 	// Create order first
@@ -190,12 +303,12 @@ func TestConfirmOrder_Success(t *testing.T) {
 			{ProductID: 1, Quantity: 3},
 		},
 	}
-	order, _ := service.CreateOrder(req)
+	order, _ := service.CreateOrder(ctx, req)
 
 	initialStock := productRepo.products[1].Stock
 
 	// Confirm order
-	confirmedOrder, err := service.ConfirmOrder(order.ID)
+	confirmedOrder, err := service.ConfirmOrder(ctx, order.ID)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -212,6 +325,7 @@ func TestConfirmOrder_Success(t *testing.T) {
 }
 
 func TestConfirmOrder_InvalidStatus(t *testing.T) {
+	ctx := context.Background()
 	service, _, _, _ := setupService()
 
 	// Create and confirm order
@@ -221,17 +335,18 @@ func TestConfirmOrder_InvalidStatus(t *testing.T) {
 			{ProductID: 1, Quantity: 2},
 		},
 	}
-	order, _ := service.CreateOrder(req)
-	service.ConfirmOrder(order.ID)
+	order, _ := service.CreateOrder(ctx, req)
+	service.ConfirmOrder(ctx, order.ID)
 
 	// Try to confirm again
-	_, err := service.ConfirmOrder(order.ID)
+	_, err := service.ConfirmOrder(ctx, order.ID)
 	if err != ErrInvalidStatus {
 		t.Errorf("Expected ErrInvalidStatus, got %v", err)
 	}
 }
 
 func TestShipOrder_Success(t *testing.T) {
+	ctx := context.Background()
 	service, _, _, _ := setupService()
 
 	req := domain.CreateOrderRequest{
@@ -240,10 +355,10 @@ func TestShipOrder_Success(t *testing.T) {
 			{ProductID: 1, Quantity: 2},
 		},
 	}
-	order, _ := service.CreateOrder(req)
-	service.ConfirmOrder(order.ID)
+	order, _ := service.CreateOrder(ctx, req)
+	service.ConfirmOrder(ctx, order.ID)
 
-	shippedOrder, err := service.ShipOrder(order.ID)
+	shippedOrder, err := service.ShipOrder(ctx, order.ID)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -254,6 +369,7 @@ func TestShipOrder_Success(t *testing.T) {
 }
 
 func TestCancelOrder_ReturnStock(t *testing.T) {
+	ctx := context.Background()
 	service, _, productRepo, _ := setupService()
 
 	req := domain.CreateOrderRequest{
@@ -262,13 +378,13 @@ func TestCancelOrder_ReturnStock(t *testing.T) {
 			{ProductID: 1, Quantity: 3},
 		},
 	}
-	order, _ := service.CreateOrder(req)
-	service.ConfirmOrder(order.ID)
+	order, _ := service.CreateOrder(ctx, req)
+	service.ConfirmOrder(ctx, order.ID)
 
 	stockAfterConfirm := productRepo.products[1].Stock
 
 	// Cancel order
-	cancelledOrder, err := service.CancelOrder(order.ID)
+	cancelledOrder, err := service.CancelOrder(ctx, order.ID)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -285,6 +401,7 @@ func TestCancelOrder_ReturnStock(t *testing.T) {
 }
 
 func TestCancelOrder_CannotCancelShipped(t *testing.T) {
+	ctx := context.Background()
 	service, _, _, _ := setupService()
 
 	req := domain.CreateOrderRequest{
@@ -293,12 +410,233 @@ func TestCancelOrder_CannotCancelShipped(t *testing.T) {
 			{ProductID: 1, Quantity: 2},
 		},
 	}
-	order, _ := service.CreateOrder(req)
-	service.ConfirmOrder(order.ID)
-	service.ShipOrder(order.ID)
+	order, _ := service.CreateOrder(ctx, req)
+	service.ConfirmOrder(ctx, order.ID)
+	service.ShipOrder(ctx, order.ID)
 
-	_, err := service.CancelOrder(order.ID)
+	_, err := service.CancelOrder(ctx, order.ID)
 	if err != ErrCannotCancelShipped {
 		t.Errorf("Expected ErrCannotCancelShipped, got %v", err)
 	}
 }
+
+func TestCreateOrder_UsesInjectedClockAndPublicIDs(t *testing.T) {
+	ctx := context.Background()
+	_, userRepo, productRepo, orderRepo := setupService()
+	frozen := clock.NewFake(time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC))
+	service := NewOrderService(orderRepo, productRepo, userRepo,
+		WithClock(frozen),
+		WithIDGenerator(ids.NewULIDGenerator(frozen)),
+	)
+
+	order, err := service.CreateOrder(ctx, domain.CreateOrderRequest{UserID: 1, Items: []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !order.CreatedAt.Equal(frozen.Now()) || order.PublicID == nil || !ids.IsULID(*order.PublicID) {
+		t.Fatalf("Expected frozen timestamp and public ID, got %v / %v", order.CreatedAt, order.PublicID)
+	}
+
+	frozen.Advance(time.Hour)
+	cancelled, _ := service.CancelOrder(ctx, order.ID)
+	if !cancelled.CancelledAt.Equal(time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)) {
+		t.Errorf("Expected cancellation at the frozen time, got %v", cancelled.CancelledAt)
+	}
+
+	if id, err := service.ResolveOrderID(ctx, *order.PublicID); err != nil || id != order.ID {
+		t.Errorf("Expected the public ID to resolve to %d, got %d (%v)", order.ID, id, err)
+	}
+	if _, err := service.ResolveOrderID(ctx, "01ARYZ6S41TSV4RRFFQ69G5FAV"); err != ErrOrderNotFound {
+		t.Errorf("Expected ErrOrderNotFound, got %v", err)
+	}
+	if _, err := service.ResolveOrderID(ctx, "1"); err != ErrOrderNotFound {
+		t.Errorf("Expected numeric IDs to be rejected by default, got %v", err)
+	}
+
+	compatible := NewOrderService(orderRepo, productRepo, userRepo, WithIDGenerator(ids.NewULIDGenerator(frozen)), WithNumericOrderIDs())
+	if id, err := compatible.ResolveOrderID(ctx, "1"); err != nil || id != order.ID {
+		t.Errorf("Expected the numeric ID to resolve to %d with WithNumericOrderIDs, got %d (%v)", order.ID, id, err)
+	}
+}
+
+func TestOrderJSON_HidesNumericIDs(t *testing.T) {
+	parentID := uint(7)
+	publicID := "01HZY3C7W8X9Y0Z1A2B3C4D5E6"
+	data, err := json.Marshal(domain.Order{ID: 8, PublicID: &publicID, ParentOrderID: &parentID, MergedIntoID: &parentID})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var fields map[string]interface{}
+	json.Unmarshal(data, &fields)
+	for _, key := range []string{"id", "parent_order_id", "merged_into_id"} {
+		if _, ok := fields[key]; ok {
+			t.Errorf("Expected %q to be hidden, got %s", key, data)
+		}
+	}
+	if fields["public_id"] != publicID {
+		t.Errorf("Expected the public ID, got %s", data)
+	}
+}
+
+func TestPublicJSON_OmitsNumericOrderID(t *testing.T) {
+	publicID := "01HZY3C7W8X9Y0Z1A2B3C4D5E6"
+	order := domain.Order{
+		ID:       8,
+		PublicID: &publicID,
+		Items:    []domain.OrderItem{{ID: 1, OrderID: 8, ProductID: 3, Quantity: 1}},
+		Payments: []domain.Payment{{ID: 2, OrderID: 8}},
+		TaxLines: []domain.OrderTaxLine{{OrderID: 8}},
+	}
+	payloads := map[string]interface{}{
+		"order":  order,
+		"refund": domain.Refund{ID: 3, OrderID: 8, Lines: []domain.RefundLine{{OrderItemID: 1, Quantity: 1}}},
+		"return": domain.ReturnAuthorization{ID: 4, OrderID: 8, Items: []domain.ReturnItem{{OrderItemID: 1, Quantity: 1}}},
+		"change": domain.OrderChange{OrderID: 8},
+		"stale":  domain.StaleOrderReport{Cancelled: []domain.StaleOrder{{OrderID: 8, PublicID: publicID}}},
+	}
+	for name, payload := range payloads {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		var decoded interface{}
+		json.Unmarshal(data, &decoded)
+		if hasKey(decoded, "order_id") {
+			t.Errorf("Expected no order_id in the %s payload, got %s", name, data)
+		}
+	}
+}
+
+// hasKey busca la clave en todos los niveles del JSON decodificado
+func hasKey(value interface{}, key string) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if k == key || hasKey(child, key) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			if hasKey(child, key) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sequenceGenerator devuelve los identificadores en orden, para forzar repetidos
+type sequenceGenerator struct {
+	values []string
+}
+
+func (g *sequenceGenerator) NewID() string {
+	value := g.values[0]
+	g.values = g.values[1:]
+	return value
+}
+
+// uniqueNumberRepository rechaza números de pedido repetidos, como el índice único de la base
+type uniqueNumberRepository struct {
+	*mockOrderRepository
+}
+
+func (r *uniqueNumberRepository) Create(ctx context.Context, order *domain.Order) error {
+	for _, existing := range r.orders {
+		if existing.Number != nil && order.Number != nil && *existing.Number == *order.Number {
+			return fmt.Errorf("%w: orders.number = %s", repositories.ErrDuplicatedKey, *order.Number)
+		}
+	}
+	return r.mockOrderRepository.Create(ctx, order)
+}
+
+func TestCreateOrder_RetriesDuplicatedNumber(t *testing.T) {
+	ctx := context.Background()
+	_, userRepo, productRepo, orderRepo := setupService()
+	repo := &uniqueNumberRepository{orderRepo}
+	numbers := &sequenceGenerator{values: []string{"ORD-1", "ORD-1", "ORD-1", "ORD-2"}}
+	service := NewOrderService(repo, productRepo, userRepo,
+		WithOrderNumbers(numbers),
+		WithOutbox(&mockTransactor{orders: repo, products: productRepo, outbox: &mockOutboxRepository{}}),
+	)
+	req := domain.CreateOrderRequest{UserID: 1, Items: []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}}}
+
+	first, err := service.CreateOrder(ctx, req)
+	if err != nil || *first.Number != "ORD-1" {
+		t.Fatalf("Expected ORD-1, got %v (%v)", first, err)
+	}
+	second, err := service.CreateOrder(ctx, req)
+	if err != nil {
+		t.Fatalf("Expected the collision to be retried, got %v", err)
+	}
+	if *second.Number != "ORD-2" || second.ID == first.ID {
+		t.Errorf("Expected a second order numbered ORD-2, got %v (ID %d)", *second.Number, second.ID)
+	}
+}
+
+func TestAssignMissingIdentifiers_BackfillsLegacyOrders(t *testing.T) {
+	ctx := context.Background()
+	_, userRepo, productRepo, orderRepo := setupService()
+	legacy := NewOrderService(orderRepo, productRepo, userRepo)
+	req := domain.CreateOrderRequest{UserID: 1, Items: []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}}}
+	for i := 0; i < 2; i++ {
+		if _, err := legacy.CreateOrder(ctx, req); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	frozen := clock.NewFake(time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC))
+	numbers, _ := ids.NewOrderNumberGenerator(frozen, "ORD")
+	service := NewOrderService(orderRepo, productRepo, userRepo,
+		WithIDGenerator(ids.NewULIDGenerator(frozen)),
+		WithOrderNumbers(numbers),
+	)
+	assigned, err := service.AssignMissingIdentifiers(ctx)
+	if err != nil || assigned != 2 {
+		t.Fatalf("Expected two orders to be completed, got %d (%v)", assigned, err)
+	}
+	for id := uint(1); id <= 2; id++ {
+		order := orderRepo.orders[id]
+		if order.PublicID == nil || order.Number == nil {
+			t.Fatalf("Expected identifiers on order %d, got %+v", id, order)
+		}
+		if resolved, err := service.ResolveOrderID(ctx, *order.Number); err != nil || resolved != id {
+			t.Errorf("Expected the new number to resolve to %d, got %d (%v)", id, resolved, err)
+		}
+	}
+	if assigned, _ := service.AssignMissingIdentifiers(ctx); assigned != 0 {
+		t.Errorf("Expected nothing left to assign, got %d", assigned)
+	}
+}
+
+func TestResolveOrderID_AcceptsOrderNumbers(t *testing.T) {
+	ctx := context.Background()
+	_, userRepo, productRepo, orderRepo := setupService()
+	frozen := clock.NewFake(time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC))
+	numbers, _ := ids.NewOrderNumberGenerator(frozen, "ORD")
+	service := NewOrderService(orderRepo, productRepo, userRepo, WithOrderNumbers(numbers))
+
+	order, _ := service.CreateOrder(ctx, domain.CreateOrderRequest{UserID: 1, Items: []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}}})
+	if order.Number == nil || (*order.Number)[:9] != "ORD-2024-" {
+		t.Fatalf("Expected an order number, got %v", order.Number)
+	}
+	if event := orderEvent(order); event.Number != *order.Number {
+		t.Errorf("Expected the number in the order event, got %+v", event)
+	}
+
+	if id, err := service.ResolveOrderID(ctx, strings.ToLower(*order.Number)); err != nil || id != order.ID {
+		t.Errorf("Expected the number to resolve to %d, got %d (%v)", order.ID, id, err)
+	}
+	// Un verificador incorrecto no llega a consultar la base
+	number := *order.Number
+	typo := number[:len(number)-1] + "0"
+	if number[len(number)-1] == '0' {
+		typo = number[:len(number)-1] + "1"
+	}
+	for _, ref := range []string{typo, "1", "ORD-2024-00000000-0"} {
+		if _, err := service.ResolveOrderID(ctx, ref); err != ErrOrderNotFound {
+			t.Errorf("Expected %q to be rejected, got %v", ref, err)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/logging"
+	"order-management-system/internal/repositories"
+	"sort"
+)
+
+var (
+	ErrInvalidSplit = errors.New("invalid order split")
+	ErrInvalidMerge = errors.New("invalid order merge")
+)
+
+// SplitOrder separa las líneas indicadas en un nuevo pedido vinculado al original.
+// Las líneas conservan precio, descuentos e impuestos, por lo que la suma de ambos
+// totales es igual al total original; el envío queda en el pedido original.
+// El stock de un pedido CONFIRMED ya descontado viaja con sus líneas. Un pedido con un pago
+// cobrado no se divide porque el cobro no se puede repartir entre ambos pedidos.
+func (s *OrderService) SplitOrder(ctx context.Context, orderID uint, req domain.SplitOrderRequest) (*domain.Order, *domain.Order, error) {
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, nil, ErrOrderNotFound
+	}
+
+	if order.Status != domain.StatusPending && order.Status != domain.StatusConfirmed {
+		return nil, nil, ErrInvalidStatus
+	}
+
+	selected := make(map[uint]bool)
+	for _, id := range req.OrderItemIDs {
+		if findOrderItem(order, id) == nil {
+			return nil, nil, fmt.Errorf("%w: order item %d does not belong to the order", ErrInvalidSplit, id)
+		}
+		selected[id] = true
+	}
+
+	var kept, moved []domain.OrderItem
+	var movedIDs []uint
+	for _, item := range order.Items {
+		if selected[item.ID] {
+			moved = append(moved, item)
+			movedIDs = append(movedIDs, item.ID)
+		} else {
+			kept = append(kept, item)
+		}
+	}
+	if len(kept) == 0 {
+		return nil, nil, fmt.Errorf("%w: the original order must keep at least one item", ErrInvalidSplit)
+	}
+
+	if s.payments != nil {
+		captured, err := s.payments.CapturedPayment(ctx, order.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if captured != nil {
+			return nil, nil, fmt.Errorf("%w: orders with a captured payment cannot be split", ErrInvalidSplit)
+		}
+	}
+
+	taxNames := taxLineNames(order.TaxLines)
+	parentID := order.ID
+	child := &domain.Order{
+		UserID:          order.UserID,
+		TaxCountry:      order.TaxCountry,
+		TaxRegion:       order.TaxRegion,
+		ShippingAddress: order.ShippingAddress,
+		BillingAddress:  order.BillingAddress,
+		ShippingCarrier: order.ShippingCarrier,
+		ShippingMethod:  order.ShippingMethod,
+		Status:          order.Status,
+		ParentOrderID:   &parentID,
+		Items:           moved,
+	}
+	summarizeOrder(child, taxNames)
+
+	order.Items = kept
+	summarizeOrder(order, taxNames)
+
+	// El pedido nuevo se crea sin líneas y luego se le reasignan las existentes
+	err = s.createWithIdentifiers(child, func() error {
+		return s.withinTransaction(ctx, func(tx repositories.Repositories) error {
+			child.Items = nil
+			if err := tx.Orders.Create(ctx, child); err != nil {
+				return err
+			}
+			if err := tx.Orders.MoveItems(ctx, movedIDs, child.ID); err != nil {
+				return err
+			}
+			child.Items = moved
+			if err := tx.Orders.UpdateItems(ctx, child); err != nil {
+				return err
+			}
+			if err := tx.Orders.UpdateItems(ctx, order); err != nil {
+				return err
+			}
+			return raiseOrderEvent(ctx, tx, domain.EventOrderCreated, child)
+		})
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	s.publish(domain.EventOrderCreated, child)
+
+	// La autorización de un pedido pendiente ya no corresponde a ningún total: se anula.
+	// La división ya está guardada, así que un fallo sólo se registra
+	if order.Status == domain.StatusPending && s.payments != nil {
+		if err := s.payments.Release(ctx, order.ID); err != nil {
+			logging.For(ctx, "orders").Error("could not release payments of split order", "order_id", order.ID, "error", err)
+		}
+	}
+
+	parent, err := s.orderRepo.GetByID(ctx, order.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	created, err := s.orderRepo.GetByID(ctx, child.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return parent, created, nil
+}
+
+// MergeOrders fusiona las líneas de un pedido PENDING en otro del mismo usuario y dirección de envío.
+// El pedido fusionado queda en estado MERGED apuntando al destino y sus usos de cupones pasan al destino.
+// Ambos pedidos se actualizan sólo si siguen PENDING al guardar y emiten order.merged.
+func (s *OrderService) MergeOrders(ctx context.Context, targetID, sourceID uint) (*domain.Order, error) {
+	if targetID == sourceID {
+		return nil, fmt.Errorf("%w: an order cannot be merged into itself", ErrInvalidMerge)
+	}
+
+	target, err := s.orderRepo.GetByID(ctx, targetID)
+	if err != nil {
+		return nil, ErrOrderNotFound
+	}
+	source, err := s.orderRepo.GetByID(ctx, sourceID)
+	if err != nil {
+		return nil, ErrOrderNotFound
+	}
+
+	if target.Status != domain.StatusPending || source.Status != domain.StatusPending {
+		return nil, ErrInvalidStatus
+	}
+	if target.UserID != source.UserID {
+		return nil, fmt.Errorf("%w: orders belong to different users", ErrInvalidMerge)
+	}
+	if target.ShippingAddress != source.ShippingAddress {
+		return nil, fmt.Errorf("%w: orders ship to different addresses", ErrInvalidMerge)
+	}
+
+	taxNames := taxLineNames(append(target.TaxLines, source.TaxLines...))
+
+	movedIDs := make([]uint, len(source.Items))
+	for i, item := range source.Items {
+		movedIDs[i] = item.ID
+	}
+	target.Items = append(target.Items, source.Items...)
+	target.ShippingCost = roundMoney(target.ShippingCost + source.ShippingCost)
+	summarizeOrder(target, taxNames)
+
+	source.Items = nil
+	source.ShippingCost = 0
+	summarizeOrder(source, taxNames)
+	source.Status = domain.StatusMerged
+	source.MergedIntoID = &target.ID
+
+	err = s.withinTransaction(ctx, func(tx repositories.Repositories) error {
+		// Otra operación pudo confirmar o cancelar alguno después de leerlo
+		for _, order := range []*domain.Order{target, source} {
+			updated, err := tx.Orders.UpdateIfStatus(ctx, order, domain.StatusPending)
+			if err != nil {
+				return err
+			}
+			if !updated {
+				return ErrInvalidStatus
+			}
+		}
+		if err := tx.Orders.MoveItems(ctx, movedIDs, target.ID); err != nil {
+			return err
+		}
+		if err := tx.Orders.UpdateItems(ctx, target); err != nil {
+			return err
+		}
+		if err := tx.Orders.UpdateItems(ctx, source); err != nil {
+			return err
+		}
+		// Los descuentos viajan con las líneas, así que los usos de cupones también
+		if s.promotions != nil {
+			if err := s.promotions.TransferRedemptions(ctx, tx, source.ID, target.ID); err != nil {
+				return err
+			}
+		}
+		if err := raiseOrderEvent(ctx, tx, domain.EventOrderMerged, source); err != nil {
+			return err
+		}
+		return raiseOrderEvent(ctx, tx, domain.EventOrderMerged, target)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.publish(domain.EventOrderMerged, source)
+	s.publish(domain.EventOrderMerged, target)
+
+	// Los montos autorizados ya no corresponden a los pedidos: se anulan ambos.
+	// La fusión ya está guardada, así que un fallo sólo se registra
+	if s.payments != nil {
+		for _, id := range []uint{source.ID, target.ID} {
+			if err := s.payments.Release(ctx, id); err != nil {
+				logging.For(ctx, "orders").Error("could not release payments of merged order", "order_id", id, "error", err)
+			}
+		}
+	}
+
+	return s.orderRepo.GetByID(ctx, target.ID)
+}
+
+// summarizeOrder recalcula los totales de un pedido a partir de los importes ya calculados de sus líneas
+func summarizeOrder(order *domain.Order, taxNames map[string]string) {
+	order.Subtotal, order.DiscountTotal, order.TaxTotal = 0, 0, 0
+	groups := make(map[string]*domain.OrderTaxLine)
+	for _, item := range order.Items {
+		base := roundMoney(item.Price*float64(item.Quantity) - item.Discount)
+		order.Subtotal = roundMoney(order.Subtotal + item.Price*float64(item.Quantity))
+		order.DiscountTotal = roundMoney(order.DiscountTotal + item.Discount)
+		order.TaxTotal = roundMoney(order.TaxTotal + item.TaxAmount)
+
+		// Las líneas sin regla impositiva no forman parte del resumen
+		key := taxLineKey(item.TaxClass, item.TaxRate)
+		name, named := taxNames[key]
+		if !named && item.TaxRate == 0 {
+			continue
+		}
+		group, ok := groups[key]
+		if !ok {
+			group = &domain.OrderTaxLine{TaxClass: item.TaxClass, Name: name, Rate: item.TaxRate}
+			groups[key] = group
+		}
+		group.Base = roundMoney(group.Base + base)
+		group.Amount = roundMoney(group.Amount + item.TaxAmount)
+	}
+
+	order.TaxLines = nil
+	for _, group := range groups {
+		order.TaxLines = append(order.TaxLines, *group)
+	}
+	sort.Slice(order.TaxLines, func(i, j int) bool {
+		return order.TaxLines[i].Rate > order.TaxLines[j].Rate
+	})
+
+	order.Total = roundMoney(order.Subtotal - order.DiscountTotal + order.TaxTotal + order.ShippingCost)
+}
+
+func taxLineNames(lines []domain.OrderTaxLine) map[string]string {
+	names := make(map[string]string)
+	for _, line := range lines {
+		names[taxLineKey(line.TaxClass, line.Rate)] = line.Name
+	}
+	return names
+}
+
+func taxLineKey(taxClass string, rate float64) string {
+	return fmt.Sprintf("%s|%f", taxClass, rate)
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/payments"
+	"testing"
+)
+
+func TestSplitOrder_PreservesTotalsAndStock(t *testing.T) {
+	ctx := context.Background()
+	_, userRepo, productRepo, orderRepo := setupService()
+	addressService, _ := newTestAddressService(userRepo)
+	service := NewOrderService(orderRepo, productRepo, userRepo,
+		WithAddresses(addressService),
+		WithTaxCalculator(newTestTaxCalculator()),
+	)
+
+	order, _ := service.CreateOrder(ctx, domain.CreateOrderRequest{
+		UserID: 1,
+		Items:  []domain.OrderItemRequest{{ProductID: 1, Quantity: 2}, {ProductID: 2, Quantity: 1}},
+	})
+	originalTotal := order.Total
+	service.ConfirmOrder(ctx, order.ID)
+	movedID := order.Items[1].ID
+
+	parent, child, err := service.SplitOrder(ctx, order.ID, domain.SplitOrderRequest{OrderItemIDs: []uint{movedID}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if child.ParentOrderID == nil || *child.ParentOrderID != parent.ID || child.Status != domain.StatusConfirmed {
+		t.Errorf("Expected confirmed child linked to parent, got %+v", child)
+	}
+	if len(child.Items) != 1 || child.Items[0].ID != movedID {
+		t.Errorf("Expected moved item in child, got %+v", child.Items)
+	}
+	if parent.Total+child.Total != originalTotal {
+		t.Errorf("Expected totals to add up to %f, got %f + %f", originalTotal, parent.Total, child.Total)
+	}
+	if child.Total != 60.5 || len(child.TaxLines) != 1 || child.TaxLines[0].Name != "IVA 21%" {
+		t.Errorf("Expected child total 60.5 with IVA 21%%, got %f %+v", child.Total, child.TaxLines)
+	}
+	if productRepo.products[1].Stock != 8 || productRepo.products[2].Stock != 4 {
+		t.Errorf("Expected stock reservations untouched, got %d and %d", productRepo.products[1].Stock, productRepo.products[2].Stock)
+	}
+
+	if _, _, err := service.SplitOrder(ctx, parent.ID, domain.SplitOrderRequest{OrderItemIDs: []uint{parent.Items[0].ID}}); !errors.Is(err, ErrInvalidSplit) {
+		t.Errorf("Expected ErrInvalidSplit when no items remain, got %v", err)
+	}
+}
+
+func TestMergeOrders(t *testing.T) {
+	ctx := context.Background()
+	service, _, _, _ := setupService()
+
+	target, _ := service.CreateOrder(ctx, domain.CreateOrderRequest{UserID: 1, Items: []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}}})
+	source, _ := service.CreateOrder(ctx, domain.CreateOrderRequest{UserID: 1, Items: []domain.OrderItemRequest{{ProductID: 2, Quantity: 2}}})
+
+	merged, err := service.MergeOrders(ctx, target.ID, source.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(merged.Items) != 2 || merged.Total != 200 {
+		t.Errorf("Expected 2 items totalling 200, got %d items and %f", len(merged.Items), merged.Total)
+	}
+
+	stored, _ := service.GetOrder(ctx, source.ID)
+	if stored.Status != domain.StatusMerged || stored.MergedIntoID == nil || *stored.MergedIntoID != target.ID || stored.Total != 0 {
+		t.Errorf("Expected source MERGED into target, got %+v", stored)
+	}
+
+	if _, err := service.CancelOrder(ctx, source.ID); err != ErrInvalidStatus {
+		t.Errorf("Expected merged order not cancellable, got %v", err)
+	}
+	if _, err := service.MergeOrders(ctx, target.ID, target.ID); !errors.Is(err, ErrInvalidMerge) {
+		t.Errorf("Expected ErrInvalidMerge, got %v", err)
+	}
+
+	service.ConfirmOrder(ctx, target.ID)
+	other, _ := service.CreateOrder(ctx, domain.CreateOrderRequest{UserID: 1, Items: []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}}})
+	if _, err := service.MergeOrders(ctx, target.ID, other.ID); err != ErrInvalidStatus {
+		t.Errorf("Expected ErrInvalidStatus for confirmed target, got %v", err)
+	}
+}
+
+func TestSplitOrder_RejectsCapturedPayment(t *testing.T) {
+	ctx := context.Background()
+	service, paymentService, _, _ := setupPaymentService()
+	order, _ := service.CreateOrder(ctx, domain.CreateOrderRequest{
+		UserID: 1,
+		Items:  []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}, {ProductID: 2, Quantity: 1}},
+	})
+	paymentService.AuthorizeOrder(ctx, order.ID, approvedCard())
+	if _, err := service.ConfirmOrder(ctx, order.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	_, _, err := service.SplitOrder(ctx, order.ID, domain.SplitOrderRequest{OrderItemIDs: []uint{order.Items[1].ID}})
+	if !errors.Is(err, ErrInvalidSplit) {
+		t.Errorf("Expected ErrInvalidSplit for a captured order, got %v", err)
+	}
+}
+
+func TestSplitOrder_RaisesCreatedForChild(t *testing.T) {
+	ctx := context.Background()
+	_, userRepo, productRepo, orderRepo := setupService()
+	outbox := &mockOutboxRepository{}
+	service := NewOrderService(orderRepo, productRepo, userRepo,
+		WithOutbox(&mockTransactor{orders: orderRepo, products: productRepo, outbox: outbox}),
+	)
+	order, _ := service.CreateOrder(ctx, domain.CreateOrderRequest{
+		UserID: 1,
+		Items:  []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}, {ProductID: 2, Quantity: 1}},
+	})
+
+	_, child, err := service.SplitOrder(ctx, order.ID, domain.SplitOrderRequest{OrderItemIDs: []uint{order.Items[1].ID}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	last := outbox.events[len(outbox.events)-1]
+	if last.EventType != domain.EventOrderCreated || last.AggregateID != child.ID {
+		t.Errorf("Expected order.created for the child, got %s for %d", last.EventType, last.AggregateID)
+	}
+}
+
+func TestMergeOrders_MovesRedemptions(t *testing.T) {
+	ctx := context.Background()
+	service, promotionRepo, _ := setupPromotionService()
+	promotionRepo.Create(ctx, &domain.Promotion{Code: code("ONCE"), Name: "once", Type: domain.PromotionPercentage, Value: 10, MaxUsesPerUser: 1})
+
+	target, _ := service.CreateOrder(ctx, domain.CreateOrderRequest{UserID: 1, Items: []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}}})
+	source, err := service.CreateOrder(ctx, domain.CreateOrderRequest{
+		UserID:      1,
+		Items:       []domain.OrderItemRequest{{ProductID: 2, Quantity: 1}},
+		CouponCodes: []string{"ONCE"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := service.MergeOrders(ctx, target.ID, source.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(promotionRepo.redemptions) != 1 || promotionRepo.redemptions[0].OrderID != target.ID {
+		t.Fatalf("Expected the redemption to move to the target, got %+v", promotionRepo.redemptions)
+	}
+
+	if _, err := service.CancelOrder(ctx, target.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if promotionRepo.promotions[1].UsedCount != 0 || len(promotionRepo.redemptions) != 0 {
+		t.Errorf("Expected cancelling the target to release the coupon, got used count %d", promotionRepo.promotions[1].UsedCount)
+	}
+}
+
+func TestMergeOrders_RejectsConcurrentStatusChange(t *testing.T) {
+	ctx := context.Background()
+	service, userRepo, productRepo, orderRepo := setupService()
+	target, _ := service.CreateOrder(ctx, domain.CreateOrderRequest{UserID: 1, Items: []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}}})
+	source, _ := service.CreateOrder(ctx, domain.CreateOrderRequest{UserID: 1, Items: []domain.OrderItemRequest{{ProductID: 2, Quantity: 2}}})
+
+	// El destino se confirmó después de que MergeOrders lo leyera PENDING
+	orderRepo.orders[target.ID].Status = domain.StatusConfirmed
+	merging := NewOrderService(&staleReadRepository{orderRepo, domain.StatusPending}, productRepo, userRepo)
+	if _, err := merging.MergeOrders(ctx, target.ID, source.ID); err != ErrInvalidStatus {
+		t.Fatalf("Expected ErrInvalidStatus, got %v", err)
+	}
+	stored := orderRepo.orders[source.ID]
+	if stored.Status != domain.StatusPending || stored.MergedIntoID != nil || len(stored.Items) != 1 {
+		t.Errorf("Expected source untouched, got %+v", stored)
+	}
+}
+
+func TestMergeOrders_RaisesMergedForBoth(t *testing.T) {
+	ctx := context.Background()
+	_, userRepo, productRepo, orderRepo := setupService()
+	outbox := &mockOutboxRepository{}
+	service := NewOrderService(orderRepo, productRepo, userRepo,
+		WithOutbox(&mockTransactor{orders: orderRepo, products: productRepo, outbox: outbox}),
+	)
+	target, _ := service.CreateOrder(ctx, domain.CreateOrderRequest{UserID: 1, Items: []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}}})
+	source, _ := service.CreateOrder(ctx, domain.CreateOrderRequest{UserID: 1, Items: []domain.OrderItemRequest{{ProductID: 2, Quantity: 2}}})
+
+	if _, err := service.MergeOrders(ctx, target.ID, source.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	merged := make(map[uint]bool)
+	for _, event := range outbox.events {
+		if event.EventType == domain.EventOrderMerged {
+			merged[event.AggregateID] = true
+		}
+	}
+	if !merged[source.ID] || !merged[target.ID] {
+		t.Errorf("Expected order.merged for source and target, got %+v", merged)
+	}
+}
+
+func TestMergeOrders_ReleaseFailureKeepsMerge(t *testing.T) {
+	ctx := context.Background()
+	_, userRepo, productRepo, orderRepo := setupService()
+	paymentRepo := &mockPaymentRepository{payments: make(map[uint]*domain.Payment)}
+	gateway := payments.NewSimulator()
+	paymentService := NewPaymentService(paymentRepo, orderRepo, gateway)
+	service := NewOrderService(orderRepo, productRepo, userRepo, WithPayments(paymentService))
+	target, _ := service.CreateOrder(ctx, domain.CreateOrderRequest{UserID: 1, Items: []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}}})
+	source, _ := service.CreateOrder(ctx, domain.CreateOrderRequest{UserID: 1, Items: []domain.OrderItemRequest{{ProductID: 2, Quantity: 2}}})
+	payment, _ := paymentService.AuthorizeOrder(ctx, source.ID, approvedCard())
+
+	// La pasarela ya no acepta anular la autorización
+	gateway.Void(ctx, payment.AuthorizationID)
+	merged, err := service.MergeOrders(ctx, target.ID, source.ID)
+	if err != nil {
+		t.Fatalf("Expected the merge to succeed, got %v", err)
+	}
+	if len(merged.Items) != 2 || orderRepo.orders[source.ID].Status != domain.StatusMerged {
+		t.Errorf("Expected the merge to be kept, got %+v", merged)
+	}
+}
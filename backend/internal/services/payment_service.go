@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/logging"
+	"order-management-system/internal/payments"
+	"order-management-system/internal/repositories"
+)
+
+var (
+	ErrPaymentRequired          = errors.New("order requires an authorized payment")
+	ErrPaymentAlreadyAuthorized = errors.New("order already has an authorized payment")
+	ErrPaymentNotAllowed        = errors.New("payments are only accepted for pending orders")
+)
+
+type PaymentService struct {
+	paymentRepo repositories.PaymentRepository
+	orderRepo   repositories.OrderRepository
+	gateway     payments.Gateway
+}
+
+func NewPaymentService(
+	paymentRepo repositories.PaymentRepository,
+	orderRepo repositories.OrderRepository,
+	gateway payments.Gateway,
+) *PaymentService {
+	return &PaymentService{
+		paymentRepo: paymentRepo,
+		orderRepo:   orderRepo,
+		gateway:     gateway,
+	}
+}
+
+// AuthorizeOrder solicita a la pasarela la retención del total de un pedido PENDING.
+// Los rechazos también quedan registrados como pagos FAILED.
+func (s *PaymentService) AuthorizeOrder(ctx context.Context, orderID uint, card payments.Card) (*domain.Payment, error) {
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, ErrOrderNotFound
+	}
+
+	if order.Status != domain.StatusPending {
+		return nil, ErrPaymentNotAllowed
+	}
+
+	existing, err := s.ActiveAuthorization(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrPaymentAlreadyAuthorized
+	}
+
+	payment := &domain.Payment{
+		OrderID:   order.ID,
+		Gateway:   s.gateway.Name(),
+		Amount:    order.Total,
+		CardLast4: card.Last4(),
+	}
+
+	auth, authErr := s.gateway.Authorize(ctx, payments.AuthorizeRequest{
+		Reference: orderReference(order.ID),
+		Amount:    order.Total,
+		Currency:  "ARS",
+		Card:      card,
+	})
+
+	var decline *payments.DeclineError
+	switch {
+	case authErr == nil:
+		payment.Status = domain.PaymentAuthorized
+		payment.AuthorizationID = auth.ID
+	case errors.As(authErr, &decline):
+		payment.Status = domain.PaymentFailed
+		payment.FailureCode = decline.Code
+		payment.FailureMessage = decline.Message
+	default:
+		return nil, authErr
+	}
+
+	if err := s.paymentRepo.Create(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	if authErr != nil {
+		return payment, authErr
+	}
+	return payment, nil
+}
+
+func (s *PaymentService) GetPayments(ctx context.Context, orderID uint) ([]domain.Payment, error) {
+	if _, err := s.orderRepo.GetByID(ctx, orderID); err != nil {
+		return nil, ErrOrderNotFound
+	}
+	return s.paymentRepo.GetByOrderID(ctx, orderID)
+}
+
+// RequireAuthorization devuelve la autorización vigente que cubre el total del pedido
+func (s *PaymentService) RequireAuthorization(ctx context.Context, order *domain.Order) (*domain.Payment, error) {
+	payment, err := s.ActiveAuthorization(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	if payment == nil || roundMoney(payment.Amount) < roundMoney(order.Total) {
+		return nil, ErrPaymentRequired
+	}
+	return payment, nil
+}
+
+// Capture cobra el total del pedido sobre la autorización
+func (s *PaymentService) Capture(ctx context.Context, payment *domain.Payment, amount float64) error {
+	if err := s.gateway.Capture(ctx, payment.AuthorizationID, amount); err != nil {
+		return err
+	}
+	payment.Status = domain.PaymentCaptured
+	payment.CapturedAmount = amount
+	return s.paymentRepo.Update(ctx, payment)
+}
+
+// Release anula las autorizaciones pendientes y reembolsa lo cobrado de un pedido cancelado
+func (s *PaymentService) Release(ctx context.Context, orderID uint) error {
+	records, err := s.paymentRepo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	for i := range records {
+		payment := &records[i]
+		switch payment.Status {
+		case domain.PaymentAuthorized:
+			if err := s.gateway.Void(ctx, payment.AuthorizationID); err != nil {
+				return err
+			}
+			payment.Status = domain.PaymentVoided
+			if err := s.paymentRepo.Update(ctx, payment); err != nil {
+				return err
+			}
+		case domain.PaymentCaptured, domain.PaymentPartiallyRefunded:
+			remaining := roundMoney(payment.CapturedAmount - payment.RefundedAmount)
+			if remaining <= 0 {
+				continue
+			}
+			if _, err := s.RefundPayment(ctx, payment, remaining); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CapturedPayment devuelve el pago cobrado del pedido que aún tiene saldo reembolsable
+func (s *PaymentService) CapturedPayment(ctx context.Context, orderID uint) (*domain.Payment, error) {
+	records, err := s.paymentRepo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range records {
+		switch records[i].Status {
+		case domain.PaymentCaptured, domain.PaymentPartiallyRefunded:
+			return &records[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// RefundPayment devuelve un monto del pago capturado y actualiza su saldo. El monto se reserva
+// sobre el saldo antes de ir a la pasarela, así dos reembolsos simultáneos no superan lo cobrado;
+// si la pasarela falla la reserva se libera.
+func (s *PaymentService) RefundPayment(ctx context.Context, payment *domain.Payment, amount float64) (string, error) {
+	claimed, err := s.paymentRepo.ClaimRefund(ctx, payment.ID, amount)
+	if err != nil {
+		return "", err
+	}
+	if !claimed {
+		return "", ErrRefundExceedsPaid
+	}
+
+	refundID, err := s.gateway.Refund(ctx, payment.AuthorizationID, amount)
+	if err != nil {
+		if releaseErr := s.paymentRepo.ReleaseRefund(ctx, payment.ID, amount); releaseErr != nil {
+			logging.For(ctx, "payments").Error("could not release refund claim", "payment_id", payment.ID, "amount", amount, "error", releaseErr)
+		}
+		return "", err
+	}
+
+	stored, err := s.paymentRepo.GetByID(ctx, payment.ID)
+	if err != nil {
+		return "", err
+	}
+	*payment = *stored
+	return refundID, nil
+}
+
+// ActiveAuthorization devuelve la autorización vigente del pedido, o nil si no tiene
+func (s *PaymentService) ActiveAuthorization(ctx context.Context, orderID uint) (*domain.Payment, error) {
+	records, err := s.paymentRepo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range records {
+		if records[i].Status == domain.PaymentAuthorized {
+			return &records[i], nil
+		}
+	}
+	return nil, nil
+}
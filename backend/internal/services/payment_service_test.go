@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/payments"
+	"testing"
+)
+
+type mockPaymentRepository struct {
+	payments map[uint]*domain.Payment
+	nextID   uint
+}
+
+func (m *mockPaymentRepository) Create(ctx context.Context, payment *domain.Payment) error {
+	m.nextID++
+	payment.ID = m.nextID
+	stored := *payment
+	m.payments[payment.ID] = &stored
+	return nil
+}
+
+func (m *mockPaymentRepository) GetByID(ctx context.Context, id uint) (*domain.Payment, error) {
+	if payment, ok := m.payments[id]; ok {
+		found := *payment
+		return &found, nil
+	}
+	return nil, errors.New("payment not found")
+}
+
+func (m *mockPaymentRepository) GetByOrderID(ctx context.Context, orderID uint) ([]domain.Payment, error) {
+	var records []domain.Payment
+	for id := uint(1); id <= m.nextID; id++ {
+		if payment, ok := m.payments[id]; ok && payment.OrderID == orderID {
+			records = append(records, *payment)
+		}
+	}
+	return records, nil
+}
+
+func (m *mockPaymentRepository) Update(ctx context.Context, payment *domain.Payment) error {
+	stored := *payment
+	m.payments[payment.ID] = &stored
+	return nil
+}
+
+func (m *mockPaymentRepository) ClaimRefund(ctx context.Context, id uint, amount float64) (bool, error) {
+	payment, ok := m.payments[id]
+	if !ok {
+		return false, errors.New("payment not found")
+	}
+	if payment.Status != domain.PaymentCaptured && payment.Status != domain.PaymentPartiallyRefunded {
+		return false, nil
+	}
+	if roundMoney(payment.RefundedAmount+amount) > payment.CapturedAmount {
+		return false, nil
+	}
+	m.addRefunded(payment, amount)
+	return true, nil
+}
+
+func (m *mockPaymentRepository) ReleaseRefund(ctx context.Context, id uint, amount float64) error {
+	payment, ok := m.payments[id]
+	if !ok {
+		return errors.New("payment not found")
+	}
+	m.addRefunded(payment, -amount)
+	return nil
+}
+
+func (m *mockPaymentRepository) addRefunded(payment *domain.Payment, amount float64) {
+	payment.RefundedAmount = roundMoney(payment.RefundedAmount + amount)
+	switch {
+	case payment.RefundedAmount >= payment.CapturedAmount:
+		payment.Status = domain.PaymentRefunded
+	case payment.RefundedAmount > 0:
+		payment.Status = domain.PaymentPartiallyRefunded
+	default:
+		payment.Status = domain.PaymentCaptured
+	}
+}
+
+func setupPaymentService() (*OrderService, *PaymentService, *mockPaymentRepository, *mockProductRepository) {
+	_, userRepo, productRepo, orderRepo := setupService()
+	paymentRepo := &mockPaymentRepository{payments: make(map[uint]*domain.Payment)}
+	paymentService := NewPaymentService(paymentRepo, orderRepo, payments.NewSimulator())
+	addressService, _ := newTestAddressService(userRepo)
+	service := NewOrderService(orderRepo, productRepo, userRepo,
+		WithAddresses(addressService),
+		WithPayments(paymentService),
+	)
+	return service, paymentService, paymentRepo, productRepo
+}
+
+func createPendingOrder(t *testing.T, service *OrderService) *domain.Order {
+	t.Helper()
+	ctx := context.Background()
+	order, err := service.CreateOrder(ctx, domain.CreateOrderRequest{
+		UserID: 1,
+		Items:  []domain.OrderItemRequest{{ProductID: 1, Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return order
+}
+
+func approvedCard() payments.Card {
+	return payments.Card{Number: payments.CardApproved, ExpMonth: 12, ExpYear: 2030, CVC: "123"}
+}
+
+func TestConfirmOrder_RequiresAuthorization(t *testing.T) {
+	ctx := context.Background()
+	service, paymentService, paymentRepo, productRepo := setupPaymentService()
+	order := createPendingOrder(t, service)
+
+	if _, err := service.ConfirmOrder(ctx, order.ID); err != ErrPaymentRequired {
+		t.Fatalf("Expected ErrPaymentRequired, got %v", err)
+	}
+	if productRepo.products[1].Stock != 10 {
+		t.Errorf("Expected stock untouched, got %d", productRepo.products[1].Stock)
+	}
+
+	payment, err := paymentService.AuthorizeOrder(ctx, order.ID, approvedCard())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if payment.Status != domain.PaymentAuthorized || payment.Amount != 200 || payment.CardLast4 != "4242" {
+		t.Errorf("Unexpected payment %+v", payment)
+	}
+
+	if _, err := service.ConfirmOrder(ctx, order.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stored := paymentRepo.payments[payment.ID]; stored.Status != domain.PaymentCaptured || stored.CapturedAmount != 200 {
+		t.Errorf("Expected captured payment, got %+v", stored)
+	}
+}
+
+func TestConfirmOrder_RefundsCaptureWhenUpdateFails(t *testing.T) {
+	ctx := context.Background()
+	_, userRepo, productRepo, orderRepo := setupService()
+	paymentRepo := &mockPaymentRepository{payments: make(map[uint]*domain.Payment)}
+	paymentService := NewPaymentService(paymentRepo, orderRepo, payments.NewSimulator())
+	service := NewOrderService(orderRepo, productRepo, userRepo,
+		WithPayments(paymentService),
+		WithOutbox(&mockTransactor{orders: &failingOrderRepository{orderRepo}, products: productRepo, outbox: &mockOutboxRepository{}}),
+	)
+	order := createPendingOrder(t, service)
+	payment, _ := paymentService.AuthorizeOrder(ctx, order.ID, approvedCard())
+
+	if _, err := service.ConfirmOrder(ctx, order.ID); err == nil {
+		t.Fatal("Expected error from failing repository")
+	}
+	if stored := paymentRepo.payments[payment.ID]; stored.Status != domain.PaymentRefunded || stored.RefundedAmount != 200 {
+		t.Errorf("Expected the capture to be refunded, got %+v", stored)
+	}
+}
+
+func TestConfirmOrder_RefundsCaptureWhenCancelledMeanwhile(t *testing.T) {
+	ctx := context.Background()
+	_, userRepo, productRepo, orderRepo := setupService()
+	paymentRepo := &mockPaymentRepository{payments: make(map[uint]*domain.Payment)}
+	paymentService := NewPaymentService(paymentRepo, orderRepo, payments.NewSimulator())
+	service := NewOrderService(orderRepo, productRepo, userRepo, WithPayments(paymentService))
+	order := createPendingOrder(t, service)
+	payment, _ := paymentService.AuthorizeOrder(ctx, order.ID, approvedCard())
+
+	// El barrido canceló el pedido después de que ConfirmOrder lo leyera PENDING
+	orderRepo.orders[order.ID].Status = domain.StatusCancelled
+	confirming := NewOrderService(&staleReadRepository{orderRepo, domain.StatusPending}, productRepo, userRepo, WithPayments(paymentService))
+	if _, err := confirming.ConfirmOrder(ctx, order.ID); err != ErrInvalidStatus {
+		t.Fatalf("Expected ErrInvalidStatus, got %v", err)
+	}
+	if stored := orderRepo.orders[order.ID]; stored.Status != domain.StatusCancelled {
+		t.Errorf("Expected order to stay CANCELLED, got %s", stored.Status)
+	}
+	if stored := paymentRepo.payments[payment.ID]; stored.Status != domain.PaymentRefunded {
+		t.Errorf("Expected the capture to be refunded, got %s", stored.Status)
+	}
+	if productRepo.products[1].Stock != 10 {
+		t.Errorf("Expected stock untouched, got %d", productRepo.products[1].Stock)
+	}
+}
+
+func TestCancelOrder_KeepsPaymentWhenUpdateFails(t *testing.T) {
+	ctx := context.Background()
+	_, userRepo, productRepo, orderRepo := setupService()
+	paymentRepo := &mockPaymentRepository{payments: make(map[uint]*domain.Payment)}
+	paymentService := NewPaymentService(paymentRepo, orderRepo, payments.NewSimulator())
+	service := NewOrderService(orderRepo, productRepo, userRepo,
+		WithPayments(paymentService),
+		WithOutbox(&mockTransactor{orders: &failingOrderRepository{orderRepo}, products: productRepo, outbox: &mockOutboxRepository{}}),
+	)
+	order := createPendingOrder(t, service)
+	payment, _ := paymentService.AuthorizeOrder(ctx, order.ID, approvedCard())
+
+	if _, err := service.CancelOrder(ctx, order.ID); err == nil {
+		t.Fatal("Expected error from failing repository")
+	}
+	if stored := paymentRepo.payments[payment.ID]; stored.Status != domain.PaymentAuthorized {
+		t.Errorf("Expected the authorization to survive a failed cancellation, got %s", stored.Status)
+	}
+}
+
+func TestAuthorizeOrder_DeclinedIsRecorded(t *testing.T) {
+	ctx := context.Background()
+	service, paymentService, _, _ := setupPaymentService()
+	order := createPendingOrder(t, service)
+
+	card := approvedCard()
+	card.Number = payments.CardInsufficientFunds
+	payment, err := paymentService.AuthorizeOrder(ctx, order.ID, card)
+	if !errors.Is(err, payments.ErrCardDeclined) {
+		t.Fatalf("Expected ErrCardDeclined, got %v", err)
+	}
+	if payment.Status != domain.PaymentFailed || payment.FailureCode != "insufficient_funds" {
+		t.Errorf("Expected failed payment record, got %+v", payment)
+	}
+
+	if _, err := service.ConfirmOrder(ctx, order.ID); err != ErrPaymentRequired {
+		t.Errorf("Expected ErrPaymentRequired after decline, got %v", err)
+	}
+
+	if _, err := paymentService.AuthorizeOrder(ctx, order.ID, approvedCard()); err != nil {
+		t.Fatalf("Expected retry to succeed, got %v", err)
+	}
+	if _, err := paymentService.AuthorizeOrder(ctx, order.ID, approvedCard()); err != ErrPaymentAlreadyAuthorized {
+		t.Errorf("Expected ErrPaymentAlreadyAuthorized, got %v", err)
+	}
+}
+
+func TestCancelOrder_VoidsOrRefunds(t *testing.T) {
+	ctx := context.Background()
+	service, paymentService, paymentRepo, _ := setupPaymentService()
+
+	pending := createPendingOrder(t, service)
+	voided, _ := paymentService.AuthorizeOrder(ctx, pending.ID, approvedCard())
+	if _, err := service.CancelOrder(ctx, pending.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if paymentRepo.payments[voided.ID].Status != domain.PaymentVoided {
+		t.Errorf("Expected VOIDED, got %s", paymentRepo.payments[voided.ID].Status)
+	}
+
+	confirmed := createPendingOrder(t, service)
+	refunded, _ := paymentService.AuthorizeOrder(ctx, confirmed.ID, approvedCard())
+	service.ConfirmOrder(ctx, confirmed.ID)
+	if _, err := service.CancelOrder(ctx, confirmed.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	stored := paymentRepo.payments[refunded.ID]
+	if stored.Status != domain.PaymentRefunded || stored.RefundedAmount != 200 {
+		t.Errorf("Expected REFUNDED 200, got %+v", stored)
+	}
+}
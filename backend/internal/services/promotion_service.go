@@ -0,0 +1,458 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/repositories"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidPromotion    = errors.New("invalid promotion")
+	ErrPromotionNotFound   = errors.New("promotion not found")
+	ErrCouponNotFound      = errors.New("coupon not found")
+	ErrCouponExpired       = errors.New("coupon is not valid at this time")
+	ErrCouponUsageExceeded = errors.New("coupon usage limit reached")
+	ErrCouponNotApplicable = errors.New("coupon does not apply to this order")
+	ErrCouponNotStackable  = errors.New("coupon cannot be combined with other promotions")
+)
+
+// PricedLine es una línea de pedido ya valorizada sobre la que se calculan descuentos.
+type PricedLine struct {
+	Product   *domain.Product
+	Quantity  int
+	UnitPrice float64
+}
+
+func (l PricedLine) amount() float64 {
+	return l.UnitPrice * float64(l.Quantity)
+}
+
+// DiscountResult contiene los descuentos asignados a cada línea (mismo índice que
+// las líneas de entrada) y las promociones que efectivamente se aplicaron.
+type DiscountResult struct {
+	Lines      [][]domain.OrderItemDiscount
+	Promotions []domain.Promotion
+	Total      float64
+}
+
+type PromotionService struct {
+	promotionRepo repositories.PromotionRepository
+}
+
+func NewPromotionService(promotionRepo repositories.PromotionRepository) *PromotionService {
+	return &PromotionService{promotionRepo: promotionRepo}
+}
+
+// CreatePromotion valida las reglas de la promoción y la persiste
+func (s *PromotionService) CreatePromotion(ctx context.Context, promotion *domain.Promotion) error {
+	if promotion.Code != nil {
+		code := strings.ToUpper(strings.TrimSpace(*promotion.Code))
+		if code == "" {
+			promotion.Code = nil
+		} else {
+			promotion.Code = &code
+		}
+	}
+
+	if err := validatePromotion(promotion); err != nil {
+		return err
+	}
+
+	return s.promotionRepo.Create(ctx, promotion)
+}
+
+func (s *PromotionService) GetPromotion(ctx context.Context, id uint) (*domain.Promotion, error) {
+	promotion, err := s.promotionRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrPromotionNotFound
+	}
+	return promotion, nil
+}
+
+func (s *PromotionService) GetAllPromotions(ctx context.Context) ([]domain.Promotion, error) {
+	return s.promotionRepo.GetAll(ctx)
+}
+
+// ApplyPromotions calcula los descuentos para las líneas de un pedido.
+// Los cupones ingresados por el cliente deben poder aplicarse, de lo contrario se
+// devuelve error; las promociones automáticas que no aplican simplemente se ignoran.
+// Las promociones no acumulables se evalúan solas y se elige la opción de mayor descuento.
+func (s *PromotionService) ApplyPromotions(ctx context.Context, userID uint, lines []PricedLine, codes []string, now time.Time) (*DiscountResult, error) {
+	return s.apply(ctx, userID, lines, codes, now, nil)
+}
+
+// apply evalúa las promociones sin contar, para los límites de uso, los usos indicados en own
+func (s *PromotionService) apply(ctx context.Context, userID uint, lines []PricedLine, codes []string, now time.Time, own map[uint]int) (*DiscountResult, error) {
+	var subtotal float64
+	for _, line := range lines {
+		subtotal += line.amount()
+	}
+
+	automatic, err := s.promotionRepo.GetAutomatic(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []domain.Promotion
+	for _, promotion := range automatic {
+		if s.checkEligibility(ctx, &promotion, userID, subtotal, now, own[promotion.ID]) == nil {
+			candidates = append(candidates, promotion)
+		}
+	}
+
+	coupons := make(map[uint]bool)
+	for _, code := range codes {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if code == "" {
+			continue
+		}
+		promotion, err := s.promotionRepo.GetByCode(ctx, code)
+		if err != nil {
+			return nil, ErrCouponNotFound
+		}
+		if coupons[promotion.ID] {
+			continue
+		}
+		if err := s.checkEligibility(ctx, promotion, userID, subtotal, now, own[promotion.ID]); err != nil {
+			return nil, err
+		}
+		if allocate(promotion, lines, lineAmounts(lines)).total == 0 {
+			return nil, ErrCouponNotApplicable
+		}
+		coupons[promotion.ID] = true
+		candidates = append(candidates, *promotion)
+	}
+
+	// Armar opciones: todas las acumulables juntas, o cada no acumulable sola
+	var stackable []domain.Promotion
+	var options [][]domain.Promotion
+	for _, promotion := range candidates {
+		if promotion.Stackable {
+			stackable = append(stackable, promotion)
+		} else {
+			options = append(options, []domain.Promotion{promotion})
+		}
+	}
+	options = append([][]domain.Promotion{stackable}, options...)
+
+	var best *DiscountResult
+	for _, option := range options {
+		if !containsCoupons(option, coupons) {
+			continue
+		}
+		result := evaluate(option, lines)
+		if best == nil || result.Total > best.Total {
+			best = result
+		}
+	}
+
+	if best == nil {
+		return nil, ErrCouponNotStackable
+	}
+	return best, nil
+}
+
+// RecordRedemptions registra el uso de las promociones aplicadas a un pedido dentro de la
+// transacción tx. Si otro pedido consumió el último uso, global o del usuario, desde ApplyPromotions devuelve
+// ErrCouponUsageExceeded y la transacción se revierte.
+func (s *PromotionService) RecordRedemptions(ctx context.Context, tx repositories.Repositories, orderID, userID uint, promotions []domain.Promotion) error {
+	repo := s.repo(tx)
+	for _, promotion := range promotions {
+		claimed, err := repo.ClaimUsage(ctx, promotion.ID)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return ErrCouponUsageExceeded
+		}
+		// El límite por usuario se reserva igual que el global; CountRedemptions sólo adelanta el rechazo
+		claimed, err = repo.ClaimUserUsage(ctx, promotion.ID, userID, promotion.MaxUsesPerUser)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return ErrCouponUsageExceeded
+		}
+		redemption := &domain.PromotionRedemption{
+			PromotionID: promotion.ID,
+			UserID:      userID,
+			OrderID:     orderID,
+		}
+		if err := repo.CreateRedemption(ctx, redemption); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReapplyPromotions recalcula los descuentos de un pedido existente. Los usos que ya consumió
+// el pedido no cuentan para los límites; no registra nada, ReplaceRedemptions actualiza los usos
+// dentro de la transacción que guarda el pedido.
+func (s *PromotionService) ReapplyPromotions(ctx context.Context, orderID, userID uint, lines []PricedLine, codes []string, now time.Time) (*DiscountResult, error) {
+	previous, err := s.promotionRepo.GetRedemptionsByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	own := make(map[uint]int)
+	for _, redemption := range previous {
+		own[redemption.PromotionID]++
+	}
+	return s.apply(ctx, userID, lines, codes, now, own)
+}
+
+// ReplaceRedemptions libera los usos del pedido y registra los de las promociones que aplican ahora,
+// dentro de la transacción tx
+func (s *PromotionService) ReplaceRedemptions(ctx context.Context, tx repositories.Repositories, orderID, userID uint, promotions []domain.Promotion) error {
+	if err := s.ReleaseRedemptions(ctx, tx, orderID); err != nil {
+		return err
+	}
+	return s.RecordRedemptions(ctx, tx, orderID, userID, promotions)
+}
+
+// ReleaseRedemptions libera, dentro de la transacción tx, los usos consumidos por un pedido cancelado
+func (s *PromotionService) ReleaseRedemptions(ctx context.Context, tx repositories.Repositories, orderID uint) error {
+	repo := s.repo(tx)
+	redemptions, err := repo.GetRedemptionsByOrder(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	for _, redemption := range redemptions {
+		if err := repo.IncrementUsage(ctx, redemption.PromotionID, -1); err != nil {
+			return err
+		}
+		if err := repo.IncrementUserUsage(ctx, redemption.PromotionID, redemption.UserID, -1); err != nil {
+			return err
+		}
+	}
+	return repo.DeleteRedemptionsByOrder(ctx, orderID)
+}
+
+// TransferRedemptions pasa, dentro de la transacción tx, los usos de un pedido a otro sin cambiar
+// la cantidad de usos de cada promoción
+func (s *PromotionService) TransferRedemptions(ctx context.Context, tx repositories.Repositories, fromOrderID, toOrderID uint) error {
+	repo := s.repo(tx)
+	redemptions, err := repo.GetRedemptionsByOrder(ctx, fromOrderID)
+	if err != nil {
+		return err
+	}
+	if err := repo.DeleteRedemptionsByOrder(ctx, fromOrderID); err != nil {
+		return err
+	}
+	for _, redemption := range redemptions {
+		moved := &domain.PromotionRedemption{
+			PromotionID: redemption.PromotionID,
+			UserID:      redemption.UserID,
+			OrderID:     toOrderID,
+		}
+		if err := repo.CreateRedemption(ctx, moved); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// repo devuelve el repositorio de promociones de la transacción, o el propio fuera de una
+func (s *PromotionService) repo(tx repositories.Repositories) repositories.PromotionRepository {
+	if tx.Promotions != nil {
+		return tx.Promotions
+	}
+	return s.promotionRepo
+}
+
+// checkEligibility valida vigencia, límites de uso y subtotal mínimo; own son los usos del pedido
+// que se está recalculando, que no cuentan contra los límites
+func (s *PromotionService) checkEligibility(ctx context.Context, promotion *domain.Promotion, userID uint, subtotal float64, now time.Time, own int) error {
+	if promotion.Disabled {
+		return ErrCouponExpired
+	}
+	if promotion.StartsAt != nil && now.Before(*promotion.StartsAt) {
+		return ErrCouponExpired
+	}
+	if promotion.EndsAt != nil && !now.Before(*promotion.EndsAt) {
+		return ErrCouponExpired
+	}
+	if promotion.MaxUses > 0 && promotion.UsedCount-own >= promotion.MaxUses {
+		return ErrCouponUsageExceeded
+	}
+	if promotion.MaxUsesPerUser > 0 {
+		used, err := s.promotionRepo.CountRedemptions(ctx, promotion.ID, userID)
+		if err != nil {
+			return err
+		}
+		if used-int64(own) >= int64(promotion.MaxUsesPerUser) {
+			return ErrCouponUsageExceeded
+		}
+	}
+	if subtotal < promotion.MinSubtotal {
+		return ErrCouponNotApplicable
+	}
+	return nil
+}
+
+func validatePromotion(promotion *domain.Promotion) error {
+	if strings.TrimSpace(promotion.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidPromotion)
+	}
+	switch promotion.Type {
+	case domain.PromotionPercentage:
+		if promotion.Value <= 0 || promotion.Value > 100 {
+			return fmt.Errorf("%w: percentage must be between 0 and 100", ErrInvalidPromotion)
+		}
+	case domain.PromotionFixed:
+		if promotion.Value <= 0 {
+			return fmt.Errorf("%w: fixed amount must be positive", ErrInvalidPromotion)
+		}
+	case domain.PromotionBuyXGetY:
+		if promotion.BuyQuantity <= 0 || promotion.GetQuantity <= 0 {
+			return fmt.Errorf("%w: buy and get quantities must be positive", ErrInvalidPromotion)
+		}
+		if promotion.Value < 0 || promotion.Value > 100 {
+			return fmt.Errorf("%w: percentage must be between 0 and 100", ErrInvalidPromotion)
+		}
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidPromotion, promotion.Type)
+	}
+	if promotion.StartsAt != nil && promotion.EndsAt != nil && !promotion.EndsAt.After(*promotion.StartsAt) {
+		return fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidPromotion)
+	}
+	if promotion.MaxUses < 0 || promotion.MaxUsesPerUser < 0 || promotion.MinSubtotal < 0 {
+		return fmt.Errorf("%w: limits cannot be negative", ErrInvalidPromotion)
+	}
+	return nil
+}
+
+func containsCoupons(option []domain.Promotion, coupons map[uint]bool) bool {
+	found := 0
+	for _, promotion := range option {
+		if coupons[promotion.ID] {
+			found++
+		}
+	}
+	return found == len(coupons)
+}
+
+// evaluate aplica las promociones en orden de prioridad sobre el importe remanente
+// de cada línea, de modo que el descuento de una línea nunca supere su importe.
+func evaluate(promotions []domain.Promotion, lines []PricedLine) *DiscountResult {
+	ordered := make([]domain.Promotion, len(promotions))
+	copy(ordered, promotions)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Priority > ordered[j].Priority
+	})
+
+	result := &DiscountResult{Lines: make([][]domain.OrderItemDiscount, len(lines))}
+	remaining := lineAmounts(lines)
+
+	for _, promotion := range ordered {
+		alloc := allocate(&promotion, lines, remaining)
+		if alloc.total == 0 {
+			continue
+		}
+		code := ""
+		if promotion.Code != nil {
+			code = *promotion.Code
+		}
+		for i, amount := range alloc.amounts {
+			if amount == 0 {
+				continue
+			}
+			remaining[i] = roundMoney(remaining[i] - amount)
+			result.Lines[i] = append(result.Lines[i], domain.OrderItemDiscount{
+				PromotionID: promotion.ID,
+				Code:        code,
+				Description: promotion.Name,
+				Type:        promotion.Type,
+				Amount:      amount,
+			})
+		}
+		result.Total = roundMoney(result.Total + alloc.total)
+		result.Promotions = append(result.Promotions, promotion)
+	}
+
+	return result
+}
+
+type allocation struct {
+	amounts []float64
+	total   float64
+}
+
+func allocate(promotion *domain.Promotion, lines []PricedLine, remaining []float64) allocation {
+	alloc := allocation{amounts: make([]float64, len(lines))}
+
+	var eligible []int
+	var eligibleTotal float64
+	for i, line := range lines {
+		if appliesTo(promotion, line.Product) && remaining[i] > 0 {
+			eligible = append(eligible, i)
+			eligibleTotal += remaining[i]
+		}
+	}
+	if len(eligible) == 0 {
+		return alloc
+	}
+
+	switch promotion.Type {
+	case domain.PromotionPercentage:
+		for _, i := range eligible {
+			alloc.amounts[i] = roundMoney(remaining[i] * promotion.Value / 100)
+		}
+	case domain.PromotionFixed:
+		// Se prorratea el monto fijo entre las líneas elegibles
+		amount := roundMoney(math.Min(promotion.Value, eligibleTotal))
+		left := amount
+		for n, i := range eligible {
+			share := roundMoney(amount * remaining[i] / eligibleTotal)
+			if n == len(eligible)-1 {
+				share = math.Min(roundMoney(left), remaining[i])
+			}
+			alloc.amounts[i] = share
+			left -= share
+		}
+	case domain.PromotionBuyXGetY:
+		percent := promotion.Value
+		if percent == 0 {
+			percent = 100
+		}
+		group := promotion.BuyQuantity + promotion.GetQuantity
+		for _, i := range eligible {
+			free := (lines[i].Quantity / group) * promotion.GetQuantity
+			amount := float64(free) * lines[i].UnitPrice * percent / 100
+			alloc.amounts[i] = roundMoney(math.Min(amount, remaining[i]))
+		}
+	}
+
+	for _, amount := range alloc.amounts {
+		alloc.total += amount
+	}
+	alloc.total = roundMoney(alloc.total)
+	return alloc
+}
+
+func appliesTo(promotion *domain.Promotion, product *domain.Product) bool {
+	if promotion.ProductID != nil && (product == nil || product.ID != *promotion.ProductID) {
+		return false
+	}
+	if promotion.Category != "" && (product == nil || !strings.EqualFold(product.Category, promotion.Category)) {
+		return false
+	}
+	return true
+}
+
+func lineAmounts(lines []PricedLine) []float64 {
+	amounts := make([]float64, len(lines))
+	for i, line := range lines {
+		amounts[i] = roundMoney(line.amount())
+	}
+	return amounts
+}
+
+func roundMoney(value float64) float64 {
+	return math.Round(value*100) / 100
+}
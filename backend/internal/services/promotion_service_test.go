@@ -0,0 +1,395 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"order-management-system/internal/domain"
+	"testing"
+	"time"
+)
+
+type mockPromotionRepository struct {
+	promotions  map[uint]*domain.Promotion
+	redemptions []domain.PromotionRedemption
+	userUsages  map[[2]uint]int
+	nextID      uint
+}
+
+func (m *mockPromotionRepository) Create(ctx context.Context, promotion *domain.Promotion) error {
+	m.nextID++
+	promotion.ID = m.nextID
+	m.promotions[promotion.ID] = promotion
+	return nil
+}
+
+func (m *mockPromotionRepository) GetByID(ctx context.Context, id uint) (*domain.Promotion, error) {
+	if promotion, ok := m.promotions[id]; ok {
+		return promotion, nil
+	}
+	return nil, errors.New("promotion not found")
+}
+
+func (m *mockPromotionRepository) GetByCode(ctx context.Context, code string) (*domain.Promotion, error) {
+	for _, p := range m.promotions {
+		if p.Code != nil && *p.Code == code {
+			return p, nil
+		}
+	}
+	return nil, errors.New("promotion not found")
+}
+
+func (m *mockPromotionRepository) GetAll(ctx context.Context) ([]domain.Promotion, error) {
+	var promotions []domain.Promotion
+	for _, p := range m.promotions {
+		promotions = append(promotions, *p)
+	}
+	return promotions, nil
+}
+
+func (m *mockPromotionRepository) GetAutomatic(ctx context.Context) ([]domain.Promotion, error) {
+	var promotions []domain.Promotion
+	for _, p := range m.promotions {
+		if p.Code == nil && !p.Disabled {
+			promotions = append(promotions, *p)
+		}
+	}
+	return promotions, nil
+}
+
+func (m *mockPromotionRepository) CountRedemptions(ctx context.Context, promotionID, userID uint) (int64, error) {
+	var count int64
+	for _, r := range m.redemptions {
+		if r.PromotionID == promotionID && r.UserID == userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *mockPromotionRepository) CreateRedemption(ctx context.Context, redemption *domain.PromotionRedemption) error {
+	m.redemptions = append(m.redemptions, *redemption)
+	return nil
+}
+
+func (m *mockPromotionRepository) GetRedemptionsByOrder(ctx context.Context, orderID uint) ([]domain.PromotionRedemption, error) {
+	var redemptions []domain.PromotionRedemption
+	for _, r := range m.redemptions {
+		if r.OrderID == orderID {
+			redemptions = append(redemptions, r)
+		}
+	}
+	return redemptions, nil
+}
+
+func (m *mockPromotionRepository) DeleteRedemptionsByOrder(ctx context.Context, orderID uint) error {
+	var kept []domain.PromotionRedemption
+	for _, r := range m.redemptions {
+		if r.OrderID != orderID {
+			kept = append(kept, r)
+		}
+	}
+	m.redemptions = kept
+	return nil
+}
+
+func (m *mockPromotionRepository) ClaimUsage(ctx context.Context, id uint) (bool, error) {
+	promotion, ok := m.promotions[id]
+	if !ok {
+		return false, errors.New("promotion not found")
+	}
+	if promotion.MaxUses > 0 && promotion.UsedCount >= promotion.MaxUses {
+		return false, nil
+	}
+	promotion.UsedCount++
+	return true, nil
+}
+
+func (m *mockPromotionRepository) IncrementUsage(ctx context.Context, id uint, delta int) error {
+	if promotion, ok := m.promotions[id]; ok {
+		promotion.UsedCount += delta
+		return nil
+	}
+	return errors.New("promotion not found")
+}
+
+func (m *mockPromotionRepository) ClaimUserUsage(ctx context.Context, promotionID, userID uint, limit int) (bool, error) {
+	if m.userUsages == nil {
+		m.userUsages = make(map[[2]uint]int)
+	}
+	key := [2]uint{promotionID, userID}
+	if limit > 0 && m.userUsages[key] >= limit {
+		return false, nil
+	}
+	m.userUsages[key]++
+	return true, nil
+}
+
+func (m *mockPromotionRepository) IncrementUserUsage(ctx context.Context, promotionID, userID uint, delta int) error {
+	if m.userUsages == nil {
+		m.userUsages = make(map[[2]uint]int)
+	}
+	m.userUsages[[2]uint{promotionID, userID}] += delta
+	return nil
+}
+
+func setupPromotionService() (*OrderService, *mockPromotionRepository, *mockProductRepository) {
+	_, userRepo, productRepo, orderRepo := setupService()
+	productRepo.products[1].Category = "computers"
+	productRepo.products[2].Category = "accessories"
+
+	promotionRepo := &mockPromotionRepository{promotions: make(map[uint]*domain.Promotion)}
+	service := NewOrderService(orderRepo, productRepo, userRepo,
+		WithPromotions(NewPromotionService(promotionRepo)),
+	)
+	return service, promotionRepo, productRepo
+}
+
+func code(c string) *string {
+	return &c
+}
+
+func TestCreateOrder_PercentageCoupon(t *testing.T) {
+	ctx := context.Background()
+	service, promotionRepo, _ := setupPromotionService()
+	promotionRepo.Create(ctx, &domain.Promotion{Code: code("OFF10"), Name: "10%", Type: domain.PromotionPercentage, Value: 10})
+
+	order, err := service.CreateOrder(ctx, domain.CreateOrderRequest{
+		UserID:      1,
+		Items:       []domain.OrderItemRequest{{ProductID: 1, Quantity: 2}, {ProductID: 2, Quantity: 1}},
+		CouponCodes: []string{"off10"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if order.Subtotal != 250.0 || order.DiscountTotal != 25.0 || order.Total != 225.0 {
+		t.Errorf("Expected 250/25/225, got %f/%f/%f", order.Subtotal, order.DiscountTotal, order.Total)
+	}
+	if order.Items[0].Discount != 20.0 || order.Items[1].Discount != 5.0 {
+		t.Errorf("Expected line discounts 20 and 5, got %f and %f", order.Items[0].Discount, order.Items[1].Discount)
+	}
+	if len(order.Items[0].Discounts) != 1 || order.Items[0].Discounts[0].Code != "OFF10" {
+		t.Errorf("Expected discount line with code OFF10, got %+v", order.Items[0].Discounts)
+	}
+	if promotionRepo.promotions[1].UsedCount != 1 {
+		t.Errorf("Expected used count 1, got %d", promotionRepo.promotions[1].UsedCount)
+	}
+}
+
+func TestCreateOrder_FixedCouponProratedAcrossLines(t *testing.T) {
+	ctx := context.Background()
+	service, promotionRepo, _ := setupPromotionService()
+	promotionRepo.Create(ctx, &domain.Promotion{Code: code("MENOS30"), Name: "$30", Type: domain.PromotionFixed, Value: 30})
+
+	order, err := service.CreateOrder(ctx, domain.CreateOrderRequest{
+		UserID:      1,
+		Items:       []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}, {ProductID: 2, Quantity: 1}},
+		CouponCodes: []string{"MENOS30"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if order.Items[0].Discount != 20.0 || order.Items[1].Discount != 10.0 {
+		t.Errorf("Expected prorated discounts 20 and 10, got %f and %f", order.Items[0].Discount, order.Items[1].Discount)
+	}
+	if order.Total != 120.0 {
+		t.Errorf("Expected total 120, got %f", order.Total)
+	}
+}
+
+func TestCreateOrder_BuyXGetYByCategory(t *testing.T) {
+	ctx := context.Background()
+	service, promotionRepo, _ := setupPromotionService()
+	promotionRepo.Create(ctx, &domain.Promotion{Name: "3x2", Type: domain.PromotionBuyXGetY, BuyQuantity: 2, GetQuantity: 1, Category: "accessories", Stackable: true})
+
+	order, err := service.CreateOrder(ctx, domain.CreateOrderRequest{
+		UserID: 1,
+		Items:  []domain.OrderItemRequest{{ProductID: 1, Quantity: 3}, {ProductID: 2, Quantity: 3}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if order.Items[0].Discount != 0 {
+		t.Errorf("Expected no discount on other category, got %f", order.Items[0].Discount)
+	}
+	if order.Items[1].Discount != 50.0 {
+		t.Errorf("Expected one free unit (50), got %f", order.Items[1].Discount)
+	}
+}
+
+func TestCreateOrder_BestOptionBetweenStackableAndExclusive(t *testing.T) {
+	ctx := context.Background()
+	service, promotionRepo, _ := setupPromotionService()
+	promotionRepo.Create(ctx, &domain.Promotion{Name: "5%", Type: domain.PromotionPercentage, Value: 5, Stackable: true})
+	promotionRepo.Create(ctx, &domain.Promotion{Name: "$10", Type: domain.PromotionFixed, Value: 10, Stackable: true})
+	promotionRepo.Create(ctx, &domain.Promotion{Name: "20% exclusivo", Type: domain.PromotionPercentage, Value: 20})
+
+	order, err := service.CreateOrder(ctx, domain.CreateOrderRequest{
+		UserID: 1,
+		Items:  []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if order.DiscountTotal != 20.0 {
+		t.Errorf("Expected exclusive 20%% to win, got discount %f", order.DiscountTotal)
+	}
+}
+
+func TestCreateOrder_ExclusiveCouponsCannotBeCombined(t *testing.T) {
+	ctx := context.Background()
+	service, promotionRepo, _ := setupPromotionService()
+	promotionRepo.Create(ctx, &domain.Promotion{Code: code("A"), Name: "A", Type: domain.PromotionPercentage, Value: 5})
+	promotionRepo.Create(ctx, &domain.Promotion{Code: code("B"), Name: "B", Type: domain.PromotionPercentage, Value: 5})
+
+	_, err := service.CreateOrder(ctx, domain.CreateOrderRequest{
+		UserID:      1,
+		Items:       []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}},
+		CouponCodes: []string{"A", "B"},
+	})
+	if err != ErrCouponNotStackable {
+		t.Errorf("Expected ErrCouponNotStackable, got %v", err)
+	}
+}
+
+func TestCreateOrder_CouponRules(t *testing.T) {
+	ctx := context.Background()
+	past := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name      string
+		promotion domain.Promotion
+		expected  error
+	}{
+		{"unknown code", domain.Promotion{Code: code("OTHER"), Name: "x", Type: domain.PromotionPercentage, Value: 5}, ErrCouponNotFound},
+		{"expired", domain.Promotion{Code: code("X"), Name: "x", Type: domain.PromotionPercentage, Value: 5, EndsAt: &past}, ErrCouponExpired},
+		{"disabled", domain.Promotion{Code: code("X"), Name: "x", Type: domain.PromotionPercentage, Value: 5, Disabled: true}, ErrCouponExpired},
+		{"min spend", domain.Promotion{Code: code("X"), Name: "x", Type: domain.PromotionPercentage, Value: 5, MinSubtotal: 500}, ErrCouponNotApplicable},
+		{"global limit", domain.Promotion{Code: code("X"), Name: "x", Type: domain.PromotionPercentage, Value: 5, MaxUses: 3, UsedCount: 3}, ErrCouponUsageExceeded},
+		{"other category", domain.Promotion{Code: code("X"), Name: "x", Type: domain.PromotionPercentage, Value: 5, Category: "accessories"}, ErrCouponNotApplicable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, promotionRepo, _ := setupPromotionService()
+			promotion := tt.promotion
+			promotionRepo.Create(ctx, &promotion)
+
+			_, err := service.CreateOrder(ctx, domain.CreateOrderRequest{
+				UserID:      1,
+				Items:       []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}},
+				CouponCodes: []string{"X"},
+			})
+			if err != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+// stalePromotionRepository devuelve los cupones como se leyeron antes de que otro pedido
+// consumiera su último uso
+type stalePromotionRepository struct {
+	*mockPromotionRepository
+}
+
+func (r stalePromotionRepository) GetByCode(ctx context.Context, code string) (*domain.Promotion, error) {
+	promotion, err := r.mockPromotionRepository.GetByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	stale := *promotion
+	stale.UsedCount = 0
+	return &stale, nil
+}
+
+func TestCreateOrder_CouponExhaustedConcurrently(t *testing.T) {
+	ctx := context.Background()
+	_, userRepo, productRepo, orderRepo := setupService()
+	promotionRepo := &mockPromotionRepository{promotions: make(map[uint]*domain.Promotion)}
+	promotionRepo.Create(ctx, &domain.Promotion{Code: code("LAST"), Name: "last", Type: domain.PromotionPercentage, Value: 10, MaxUses: 1, UsedCount: 1})
+	service := NewOrderService(orderRepo, productRepo, userRepo,
+		WithPromotions(NewPromotionService(stalePromotionRepository{promotionRepo})),
+	)
+
+	_, err := service.CreateOrder(ctx, domain.CreateOrderRequest{
+		UserID:      1,
+		Items:       []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}},
+		CouponCodes: []string{"LAST"},
+	})
+	if !errors.Is(err, ErrCouponUsageExceeded) {
+		t.Fatalf("Expected ErrCouponUsageExceeded, got %v", err)
+	}
+	if promotionRepo.promotions[1].UsedCount != 1 || len(promotionRepo.redemptions) != 0 {
+		t.Errorf("Expected the limit to hold, got used count %d and %d redemptions",
+			promotionRepo.promotions[1].UsedCount, len(promotionRepo.redemptions))
+	}
+}
+
+// staleRedemptionCountRepository no ve los usos que otro pedido del usuario registró después de la validación
+type staleRedemptionCountRepository struct {
+	*mockPromotionRepository
+}
+
+func (r staleRedemptionCountRepository) CountRedemptions(ctx context.Context, promotionID, userID uint) (int64, error) {
+	return 0, nil
+}
+
+func TestCreateOrder_PerUserLimitExhaustedConcurrently(t *testing.T) {
+	ctx := context.Background()
+	_, userRepo, productRepo, orderRepo := setupService()
+	promotionRepo := &mockPromotionRepository{promotions: make(map[uint]*domain.Promotion)}
+	promotionRepo.Create(ctx, &domain.Promotion{Code: code("ONCE"), Name: "once", Type: domain.PromotionPercentage, Value: 10, MaxUsesPerUser: 1})
+	req := domain.CreateOrderRequest{
+		UserID:      1,
+		Items:       []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}},
+		CouponCodes: []string{"ONCE"},
+	}
+	first := NewOrderService(orderRepo, productRepo, userRepo, WithPromotions(NewPromotionService(promotionRepo)))
+	if _, err := first.CreateOrder(ctx, req); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	second := NewOrderService(orderRepo, productRepo, userRepo,
+		WithPromotions(NewPromotionService(staleRedemptionCountRepository{promotionRepo})),
+	)
+	if _, err := second.CreateOrder(ctx, req); !errors.Is(err, ErrCouponUsageExceeded) {
+		t.Fatalf("Expected ErrCouponUsageExceeded, got %v", err)
+	}
+	if promotionRepo.userUsages[[2]uint{1, 1}] != 1 {
+		t.Errorf("Expected the per-user limit to hold, got %d uses", promotionRepo.userUsages[[2]uint{1, 1}])
+	}
+}
+
+func TestCreateOrder_PerUserLimitReleasedOnCancel(t *testing.T) {
+	ctx := context.Background()
+	service, promotionRepo, _ := setupPromotionService()
+	promotionRepo.Create(ctx, &domain.Promotion{Code: code("ONCE"), Name: "once", Type: domain.PromotionPercentage, Value: 10, MaxUsesPerUser: 1})
+
+	req := domain.CreateOrderRequest{
+		UserID:      1,
+		Items:       []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}},
+		CouponCodes: []string{"ONCE"},
+	}
+	order, err := service.CreateOrder(ctx, req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := service.CreateOrder(ctx, req); err != ErrCouponUsageExceeded {
+		t.Fatalf("Expected ErrCouponUsageExceeded, got %v", err)
+	}
+
+	if _, err := service.CancelOrder(ctx, order.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if promotionRepo.promotions[1].UsedCount != 0 {
+		t.Errorf("Expected used count 0 after cancel, got %d", promotionRepo.promotions[1].UsedCount)
+	}
+
+	if _, err := service.CreateOrder(ctx, req); err != nil {
+		t.Errorf("Expected coupon to be usable again, got %v", err)
+	}
+}
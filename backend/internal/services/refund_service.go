@@ -0,0 +1,305 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/logging"
+	"order-management-system/internal/repositories"
+)
+
+var (
+	ErrInvalidRefund     = errors.New("invalid refund")
+	ErrNothingToRefund   = errors.New("order has no captured payment to refund")
+	ErrRefundExceedsPaid = errors.New("refund exceeds the amount paid")
+)
+
+type RefundService struct {
+	refundRepo  repositories.RefundRepository
+	orderRepo   repositories.OrderRepository
+	productRepo repositories.ProductRepository
+	payments    *PaymentService
+	transactor  repositories.Transactor
+}
+
+// RefundServiceOption configura parámetros opcionales del RefundService
+type RefundServiceOption func(*RefundService)
+
+// WithRefundTransactor guarda el reembolso y la reposición de stock en una misma transacción
+func WithRefundTransactor(transactor repositories.Transactor) RefundServiceOption {
+	return func(s *RefundService) {
+		s.transactor = transactor
+	}
+}
+
+func NewRefundService(
+	refundRepo repositories.RefundRepository,
+	orderRepo repositories.OrderRepository,
+	productRepo repositories.ProductRepository,
+	payments *PaymentService,
+	opts ...RefundServiceOption,
+) *RefundService {
+	s := &RefundService{
+		refundRepo:  refundRepo,
+		orderRepo:   orderRepo,
+		productRepo: productRepo,
+		payments:    payments,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// refundedItem acumula lo ya reembolsado de una línea del pedido
+type refundedItem struct {
+	quantity  int
+	amount    float64
+	restocked int
+}
+
+func (s *RefundService) GetRefunds(ctx context.Context, orderID uint) ([]domain.Refund, error) {
+	if _, err := s.orderRepo.GetByID(ctx, orderID); err != nil {
+		return nil, ErrOrderNotFound
+	}
+	return s.refundRepo.GetByOrderID(ctx, orderID)
+}
+
+// CreateRefund devuelve dinero sobre el pago capturado de un pedido CONFIRMED o SHIPPED.
+// El total nunca puede superar el saldo cobrado y aún no reembolsado.
+func (s *RefundService) CreateRefund(ctx context.Context, orderID uint, req domain.CreateRefundRequest) (*domain.Refund, error) {
+	return s.createRefund(ctx, orderID, req, nil)
+}
+
+// createRefund es CreateRefund con un paso extra, within, que corre en la transacción que guarda el reembolso
+func (s *RefundService) createRefund(ctx context.Context, orderID uint, req domain.CreateRefundRequest, within func(tx repositories.Repositories, refund *domain.Refund) error) (*domain.Refund, error) {
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, ErrOrderNotFound
+	}
+
+	modes := 0
+	for _, set := range []bool{req.Full, len(req.Items) > 0, req.Amount > 0} {
+		if set {
+			modes++
+		}
+	}
+	if modes != 1 {
+		return nil, fmt.Errorf("%w: specify exactly one of full, items or amount", ErrInvalidRefund)
+	}
+
+	if order.Status != domain.StatusConfirmed && order.Status != domain.StatusShipped {
+		return nil, ErrNothingToRefund
+	}
+
+	payment, err := s.payments.CapturedPayment(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	if payment == nil {
+		return nil, ErrNothingToRefund
+	}
+	balance := roundMoney(payment.CapturedAmount - payment.RefundedAmount)
+
+	refunded, err := s.refundedItems(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	refund := &domain.Refund{OrderID: order.ID, PaymentID: payment.ID, Reason: req.Reason}
+
+	switch {
+	case req.Full:
+		if balance <= 0 {
+			return nil, ErrNothingToRefund
+		}
+		for _, item := range order.Items {
+			done := refunded[item.ID]
+			if quantity := item.Quantity - done.quantity; quantity > 0 {
+				refund.Lines = append(refund.Lines, domain.RefundLine{
+					OrderItemID: item.ID,
+					Quantity:    quantity,
+					Amount:      roundMoney(itemTotal(item) - done.amount),
+					Restocked:   req.Restock,
+				})
+			}
+		}
+		refund.Amount = balance
+
+	case len(req.Items) > 0:
+		seen := make(map[uint]bool)
+		for _, requested := range req.Items {
+			if seen[requested.OrderItemID] {
+				return nil, fmt.Errorf("%w: order item %d appears more than once", ErrInvalidRefund, requested.OrderItemID)
+			}
+			seen[requested.OrderItemID] = true
+
+			item := findOrderItem(order, requested.OrderItemID)
+			if item == nil {
+				return nil, fmt.Errorf("%w: order item %d does not belong to the order", ErrInvalidRefund, requested.OrderItemID)
+			}
+
+			done := refunded[item.ID]
+			remaining := item.Quantity - done.quantity
+			quantity := requested.Quantity
+			if quantity == 0 {
+				quantity = remaining
+			}
+			if quantity <= 0 || quantity > remaining {
+				return nil, fmt.Errorf("%w: only %d unit(s) of order item %d can be refunded", ErrInvalidRefund, remaining, item.ID)
+			}
+
+			// Precio efectivo por unidad: neto de descuentos e impuestos incluidos
+			amount := roundMoney(itemTotal(*item) / float64(item.Quantity) * float64(quantity))
+			if quantity == remaining {
+				amount = roundMoney(itemTotal(*item) - done.amount)
+			}
+
+			refund.Lines = append(refund.Lines, domain.RefundLine{
+				OrderItemID: item.ID,
+				Quantity:    quantity,
+				Amount:      amount,
+				Restocked:   requested.Restock || req.Restock,
+			})
+			refund.Amount = roundMoney(refund.Amount + amount)
+		}
+
+	default:
+		if req.Reason == "" {
+			return nil, fmt.Errorf("%w: reason is required for an amount refund", ErrInvalidRefund)
+		}
+		refund.Amount = roundMoney(req.Amount)
+	}
+
+	if refund.Amount <= 0 {
+		return nil, ErrNothingToRefund
+	}
+	if refund.Amount > balance {
+		return nil, ErrRefundExceedsPaid
+	}
+
+	return s.execute(ctx, payment, refund, within)
+}
+
+// RefundBalance reembolsa todo el saldo cobrado de un pedido que se cancela.
+// Devuelve nil si no hay nada cobrado. La reposición de stock queda a cargo de la cancelación.
+func (s *RefundService) RefundBalance(ctx context.Context, order *domain.Order, reason string) (*domain.Refund, error) {
+	payment, err := s.payments.CapturedPayment(ctx, order.ID)
+	if err != nil || payment == nil {
+		return nil, err
+	}
+	balance := roundMoney(payment.CapturedAmount - payment.RefundedAmount)
+	if balance <= 0 {
+		return nil, nil
+	}
+	return s.execute(ctx, payment, &domain.Refund{
+		OrderID:   order.ID,
+		PaymentID: payment.ID,
+		Amount:    balance,
+		Reason:    reason,
+	}, nil)
+}
+
+// RestockedQuantities devuelve las unidades por línea que ya volvieron al stock por reembolsos
+func (s *RefundService) RestockedQuantities(ctx context.Context, orderID uint) (map[uint]int, error) {
+	refunded, err := s.refundedItems(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	restocked := make(map[uint]int)
+	for itemID, done := range refunded {
+		if done.restocked > 0 {
+			restocked[itemID] = done.restocked
+		}
+	}
+	return restocked, nil
+}
+
+// execute devuelve el dinero en la pasarela y después registra el reembolso y repone el stock de
+// las líneas marcadas en una sola transacción. El saldo del pago se reserva antes de la pasarela,
+// así que un reembolso simultáneo que ya no entra falla con ErrRefundExceedsPaid.
+func (s *RefundService) execute(ctx context.Context, payment *domain.Payment, refund *domain.Refund, within func(tx repositories.Repositories, refund *domain.Refund) error) (*domain.Refund, error) {
+	order, err := s.orderRepo.GetByID(ctx, refund.OrderID)
+	if err != nil {
+		return nil, ErrOrderNotFound
+	}
+
+	gatewayRefundID, err := s.payments.RefundPayment(ctx, payment, refund.Amount)
+	if err != nil {
+		return nil, err
+	}
+	refund.GatewayRefundID = gatewayRefundID
+
+	err = s.withinTransaction(ctx, func(tx repositories.Repositories) error {
+		if err := tx.Refunds.Create(ctx, refund); err != nil {
+			return err
+		}
+		for _, line := range refund.Lines {
+			if !line.Restocked {
+				continue
+			}
+			item := findOrderItem(order, line.OrderItemID)
+			product, err := tx.Products.GetByID(ctx, item.ProductID)
+			if err != nil {
+				return ErrProductNotFound
+			}
+			if err := tx.Products.UpdateStock(ctx, product.ID, product.Stock+line.Quantity); err != nil {
+				return err
+			}
+		}
+		if within != nil {
+			return within(tx, refund)
+		}
+		return nil
+	})
+	if err != nil {
+		// El dinero ya salió y el saldo del pago lo refleja: queda en el log para conciliarlo
+		logging.For(ctx, "refunds").Error("could not record gateway refund", "order_id", refund.OrderID, "payment_id", payment.ID,
+			"gateway_refund_id", gatewayRefundID, "amount", refund.Amount, "error", err)
+		return nil, err
+	}
+	return refund, nil
+}
+
+// withinTransaction ejecuta fn con los repositorios de una transacción, o con los propios sin transactor
+func (s *RefundService) withinTransaction(ctx context.Context, fn func(tx repositories.Repositories) error) error {
+	if s.transactor == nil {
+		return fn(repositories.Repositories{Orders: s.orderRepo, Products: s.productRepo, Refunds: s.refundRepo})
+	}
+	return s.transactor.WithinTransaction(ctx, fn)
+}
+
+func (s *RefundService) refundedItems(ctx context.Context, orderID uint) (map[uint]refundedItem, error) {
+	refunds, err := s.refundRepo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	refunded := make(map[uint]refundedItem)
+	for _, refund := range refunds {
+		for _, line := range refund.Lines {
+			done := refunded[line.OrderItemID]
+			done.quantity += line.Quantity
+			done.amount = roundMoney(done.amount + line.Amount)
+			if line.Restocked {
+				done.restocked += line.Quantity
+			}
+			refunded[line.OrderItemID] = done
+		}
+	}
+	return refunded, nil
+}
+
+// itemTotal es lo cobrado por una línea: precio por cantidad, menos descuentos, más impuestos
+func itemTotal(item domain.OrderItem) float64 {
+	return roundMoney(item.Price*float64(item.Quantity) - item.Discount + item.TaxAmount)
+}
+
+func findOrderItem(order *domain.Order, itemID uint) *domain.OrderItem {
+	for i := range order.Items {
+		if order.Items[i].ID == itemID {
+			return &order.Items[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/payments"
+	"testing"
+)
+
+type mockRefundRepository struct {
+	refunds []domain.Refund
+}
+
+func (m *mockRefundRepository) Create(ctx context.Context, refund *domain.Refund) error {
+	refund.ID = uint(len(m.refunds) + 1)
+	for i := range refund.Lines {
+		refund.Lines[i].RefundID = refund.ID
+	}
+	m.refunds = append(m.refunds, *refund)
+	return nil
+}
+
+func (m *mockRefundRepository) GetByOrderID(ctx context.Context, orderID uint) ([]domain.Refund, error) {
+	var refunds []domain.Refund
+	for _, r := range m.refunds {
+		if r.OrderID == orderID {
+			refunds = append(refunds, r)
+		}
+	}
+	return refunds, nil
+}
+
+func setupRefundService() (*OrderService, *RefundService, *PaymentService, *mockPaymentRepository, *mockProductRepository) {
+	_, userRepo, productRepo, orderRepo := setupService()
+	paymentRepo := &mockPaymentRepository{payments: make(map[uint]*domain.Payment)}
+	paymentService := NewPaymentService(paymentRepo, orderRepo, payments.NewSimulator())
+	refundService := NewRefundService(&mockRefundRepository{}, orderRepo, productRepo, paymentService)
+	addressService, _ := newTestAddressService(userRepo)
+	service := NewOrderService(orderRepo, productRepo, userRepo,
+		WithAddresses(addressService),
+		WithPayments(paymentService),
+		WithRefunds(refundService),
+	)
+	return service, refundService, paymentService, paymentRepo, productRepo
+}
+
+// createConfirmedOrder crea un pedido de 2 x Product 1 y 1 x Product 2 (250) y lo cobra
+func createConfirmedOrder(t *testing.T, service *OrderService, paymentService *PaymentService) *domain.Order {
+	t.Helper()
+	ctx := context.Background()
+	order, err := service.CreateOrder(ctx, domain.CreateOrderRequest{
+		UserID: 1,
+		Items:  []domain.OrderItemRequest{{ProductID: 1, Quantity: 2}, {ProductID: 2, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := paymentService.AuthorizeOrder(ctx, order.ID, approvedCard()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := service.ConfirmOrder(ctx, order.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return order
+}
+
+func TestCreateRefund_PartialItems(t *testing.T) {
+	ctx := context.Background()
+	service, refundService, paymentService, paymentRepo, productRepo := setupRefundService()
+	order := createConfirmedOrder(t, service, paymentService)
+	itemID := order.Items[0].ID
+
+	refund, err := refundService.CreateRefund(ctx, order.ID, domain.CreateRefundRequest{
+		Items: []domain.RefundItemRequest{{OrderItemID: itemID, Quantity: 1, Restock: true}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if refund.Amount != 100 || len(refund.Lines) != 1 || !refund.Lines[0].Restocked {
+		t.Errorf("Unexpected refund %+v", refund)
+	}
+	if productRepo.products[1].Stock != 9 {
+		t.Errorf("Expected stock 9 after restock, got %d", productRepo.products[1].Stock)
+	}
+	if payment := paymentRepo.payments[1]; payment.Status != domain.PaymentPartiallyRefunded || payment.RefundedAmount != 100 {
+		t.Errorf("Expected partially refunded payment, got %+v", payment)
+	}
+
+	// Solo queda una unidad de la línea
+	_, err = refundService.CreateRefund(ctx, order.ID, domain.CreateRefundRequest{
+		Items: []domain.RefundItemRequest{{OrderItemID: itemID, Quantity: 2}},
+	})
+	if !errors.Is(err, ErrInvalidRefund) {
+		t.Errorf("Expected ErrInvalidRefund, got %v", err)
+	}
+
+	// Sin cantidad se devuelven las unidades restantes
+	refund, err = refundService.CreateRefund(ctx, order.ID, domain.CreateRefundRequest{
+		Items: []domain.RefundItemRequest{{OrderItemID: itemID}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if refund.Amount != 100 || refund.Lines[0].Quantity != 1 || refund.Lines[0].Restocked {
+		t.Errorf("Unexpected refund %+v", refund)
+	}
+}
+
+func TestCreateRefund_Validation(t *testing.T) {
+	ctx := context.Background()
+	service, refundService, paymentService, _, _ := setupRefundService()
+
+	pending, _ := service.CreateOrder(ctx, domain.CreateOrderRequest{
+		UserID: 1,
+		Items:  []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}},
+	})
+	if _, err := refundService.CreateRefund(ctx, pending.ID, domain.CreateRefundRequest{Full: true}); err != ErrNothingToRefund {
+		t.Errorf("Expected ErrNothingToRefund for pending order, got %v", err)
+	}
+
+	order := createConfirmedOrder(t, service, paymentService)
+
+	tests := []struct {
+		name     string
+		req      domain.CreateRefundRequest
+		expected error
+	}{
+		{"no mode", domain.CreateRefundRequest{Reason: "x"}, ErrInvalidRefund},
+		{"two modes", domain.CreateRefundRequest{Full: true, Amount: 10}, ErrInvalidRefund},
+		{"amount without reason", domain.CreateRefundRequest{Amount: 10}, ErrInvalidRefund},
+		{"unknown item", domain.CreateRefundRequest{Items: []domain.RefundItemRequest{{OrderItemID: 999}}}, ErrInvalidRefund},
+		{"exceeds paid", domain.CreateRefundRequest{Amount: 250.01, Reason: "goodwill"}, ErrRefundExceedsPaid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := refundService.CreateRefund(ctx, order.ID, tt.req); !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestCreateRefund_AmountThenFull(t *testing.T) {
+	ctx := context.Background()
+	service, refundService, paymentService, paymentRepo, productRepo := setupRefundService()
+	order := createConfirmedOrder(t, service, paymentService)
+
+	if _, err := refundService.CreateRefund(ctx, order.ID, domain.CreateRefundRequest{Amount: 30, Reason: "late delivery"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	refund, err := refundService.CreateRefund(ctx, order.ID, domain.CreateRefundRequest{Full: true, Restock: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if refund.Amount != 220 || len(refund.Lines) != 2 {
+		t.Errorf("Expected remaining balance 220 over 2 lines, got %+v", refund)
+	}
+	if productRepo.products[1].Stock != 10 || productRepo.products[2].Stock != 5 {
+		t.Errorf("Expected stock restored, got %d and %d", productRepo.products[1].Stock, productRepo.products[2].Stock)
+	}
+	if paymentRepo.payments[1].Status != domain.PaymentRefunded {
+		t.Errorf("Expected REFUNDED, got %s", paymentRepo.payments[1].Status)
+	}
+
+	if _, err := refundService.CreateRefund(ctx, order.ID, domain.CreateRefundRequest{Full: true}); err != ErrNothingToRefund {
+		t.Errorf("Expected ErrNothingToRefund, got %v", err)
+	}
+}
+
+func TestCancelOrder_RefundsBalanceWithoutDoubleRestock(t *testing.T) {
+	ctx := context.Background()
+	service, refundService, paymentService, paymentRepo, productRepo := setupRefundService()
+	order := createConfirmedOrder(t, service, paymentService)
+
+	if _, err := refundService.CreateRefund(ctx, order.ID, domain.CreateRefundRequest{
+		Items: []domain.RefundItemRequest{{OrderItemID: order.Items[0].ID, Quantity: 1, Restock: true}},
+	}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := service.CancelOrder(ctx, order.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if productRepo.products[1].Stock != 10 || productRepo.products[2].Stock != 5 {
+		t.Errorf("Expected stock 10 and 5, got %d and %d", productRepo.products[1].Stock, productRepo.products[2].Stock)
+	}
+
+	refunds, _ := refundService.GetRefunds(ctx, order.ID)
+	if len(refunds) != 2 || refunds[1].Amount != 150 || refunds[1].Reason != "order cancelled" {
+		t.Errorf("Expected cancellation refund of 150, got %+v", refunds)
+	}
+	if payment := paymentRepo.payments[1]; payment.Status != domain.PaymentRefunded || payment.RefundedAmount != 250 {
+		t.Errorf("Expected fully refunded payment, got %+v", payment)
+	}
+}
+
+func TestCreateRefund_StalePaymentCannotExceedPaid(t *testing.T) {
+	ctx := context.Background()
+	service, refundService, paymentService, paymentRepo, _ := setupRefundService()
+	order := createConfirmedOrder(t, service, paymentService)
+
+	// Otro reembolso leyó el pago antes de que éste devolviera todo el saldo
+	stale, _ := paymentService.CapturedPayment(ctx, order.ID)
+	if _, err := refundService.CreateRefund(ctx, order.ID, domain.CreateRefundRequest{Full: true}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := refundService.execute(ctx, stale, &domain.Refund{OrderID: order.ID, PaymentID: stale.ID, Amount: 50}, nil); err != ErrRefundExceedsPaid {
+		t.Fatalf("Expected ErrRefundExceedsPaid, got %v", err)
+	}
+	if payment := paymentRepo.payments[stale.ID]; payment.RefundedAmount != 250 {
+		t.Errorf("Expected refunded amount to stay 250, got %f", payment.RefundedAmount)
+	}
+	if refunds, _ := refundService.GetRefunds(ctx, order.ID); len(refunds) != 1 {
+		t.Errorf("Expected a single refund recorded, got %d", len(refunds))
+	}
+}
+
+func TestCreateRefund_GatewayFailureReleasesBalance(t *testing.T) {
+	ctx := context.Background()
+	_, userRepo, productRepo, orderRepo := setupService()
+	paymentRepo := &mockPaymentRepository{payments: make(map[uint]*domain.Payment)}
+	gateway := payments.NewSimulator()
+	paymentService := NewPaymentService(paymentRepo, orderRepo, gateway)
+	refundService := NewRefundService(&mockRefundRepository{}, orderRepo, productRepo, paymentService)
+	service := NewOrderService(orderRepo, productRepo, userRepo, WithPayments(paymentService), WithRefunds(refundService))
+	order := createConfirmedOrder(t, service, paymentService)
+	payment, _ := paymentService.CapturedPayment(ctx, order.ID)
+
+	// La pasarela ya no tiene saldo para devolver
+	gateway.Refund(ctx, payment.AuthorizationID, payment.CapturedAmount)
+	if _, err := refundService.CreateRefund(ctx, order.ID, domain.CreateRefundRequest{Amount: 50, Reason: "goodwill"}); err == nil {
+		t.Fatal("Expected the gateway error")
+	}
+	if stored := paymentRepo.payments[payment.ID]; stored.RefundedAmount != 0 || stored.Status != domain.PaymentCaptured {
+		t.Errorf("Expected the claim to be released, got %f %s", stored.RefundedAmount, stored.Status)
+	}
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/jobs"
+	"order-management-system/internal/logging"
+	"order-management-system/internal/repositories"
+	"time"
+)
+
+// ReportService arma los reportes de ventas que se generan como trabajos programados
+type ReportService struct {
+	reportRepo repositories.ReportRepository
+	now        func() time.Time
+}
+
+func NewReportService(reportRepo repositories.ReportRepository) *ReportService {
+	return &ReportService{reportRepo: reportRepo, now: time.Now}
+}
+
+// SalesReport resume los pedidos creados en [from, to). Los pedidos fusionados no cuentan
+// porque sus ítems pasaron al pedido destino, y los cancelados no suman a la facturación.
+func (s *ReportService) SalesReport(ctx context.Context, from, to time.Time) (*domain.SalesReport, error) {
+	sales, err := s.reportRepo.SalesByStatus(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.SalesReport{From: from, To: to, ByStatus: sales}
+	var billed int64
+	for _, status := range sales {
+		if status.Status == domain.StatusMerged {
+			continue
+		}
+		report.Orders += status.Orders
+		if status.Status != domain.StatusCancelled {
+			billed += status.Orders
+			report.Revenue += status.Total
+		}
+	}
+	report.Revenue = roundMoney(report.Revenue)
+	if billed > 0 {
+		report.AverageOrder = roundMoney(report.Revenue / float64(billed))
+	}
+	return report, nil
+}
+
+// DailySalesPayload indica el día del reporte (AAAA-MM-DD); vacío es el día anterior
+type DailySalesPayload struct {
+	Date string `json:"date,omitempty"`
+}
+
+// RunDailySales es el handler del trabajo programado del reporte diario de ventas
+func (s *ReportService) RunDailySales(ctx context.Context, job domain.Job) error {
+	var payload DailySalesPayload
+	if err := job.Decode(&payload); err != nil {
+		return jobs.Permanent(err)
+	}
+
+	now := s.now()
+	day := time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, now.Location())
+	if payload.Date != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", payload.Date, now.Location())
+		if err != nil {
+			return jobs.Permanent(fmt.Errorf("invalid report date %q: %w", payload.Date, err))
+		}
+		day = parsed
+	}
+
+	report, err := s.SalesReport(ctx, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+	logging.For(ctx, "reports").Info("daily sales",
+		"date", day.Format("2006-01-02"), "orders", report.Orders, "revenue", report.Revenue, "average", report.AverageOrder)
+	return nil
+}
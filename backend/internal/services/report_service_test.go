@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"order-management-system/internal/domain"
+	"testing"
+	"time"
+)
+
+type mockReportRepository struct {
+	sales    []domain.StatusSales
+	from, to time.Time
+}
+
+func (m *mockReportRepository) SalesByStatus(ctx context.Context, from, to time.Time) ([]domain.StatusSales, error) {
+	m.from, m.to = from, to
+	return m.sales, nil
+}
+
+func TestReportService_DailySales(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockReportRepository{sales: []domain.StatusSales{
+		{Status: domain.StatusCancelled, Orders: 1, Total: 50},
+		{Status: domain.StatusConfirmed, Orders: 2, Total: 300},
+		{Status: domain.StatusMerged, Orders: 1, Total: 80},
+		{Status: domain.StatusShipped, Orders: 1, Total: 100.01},
+	}}
+	service := NewReportService(repo)
+	service.now = func() time.Time { return time.Date(2024, 3, 1, 0, 5, 0, 0, time.UTC) }
+
+	report, err := service.SalesReport(ctx, time.Time{}, time.Now())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Orders != 4 || report.Revenue != 400.01 || report.AverageOrder != 133.34 {
+		t.Errorf("Unexpected report %+v", report)
+	}
+
+	// Sin fecha el trabajo reporta el día anterior
+	job, _ := domain.NewJob("reports.daily_sales", DailySalesPayload{}, time.Now())
+	if err := service.RunDailySales(context.Background(), *job); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !repo.from.Equal(time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)) || !repo.to.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected February 29th, got %s - %s", repo.from, repo.to)
+	}
+
+	job, _ = domain.NewJob("reports.daily_sales", DailySalesPayload{Date: "yesterday"}, time.Now())
+	if err := service.RunDailySales(context.Background(), *job); err == nil {
+		t.Error("Expected invalid date to fail")
+	}
+}
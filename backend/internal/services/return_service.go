@@ -0,0 +1,377 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/logging"
+	"order-management-system/internal/repositories"
+)
+
+var (
+	ErrReturnNotFound          = errors.New("return not found")
+	ErrInvalidReturn           = errors.New("invalid return")
+	ErrReturnNotAllowed        = errors.New("returns are only accepted for shipped orders")
+	ErrInvalidReturnTransition = errors.New("invalid return status transition")
+)
+
+// returnTransitions son los cambios de estado permitidos de una devolución
+var returnTransitions = map[domain.ReturnStatus][]domain.ReturnStatus{
+	domain.ReturnRequested: {domain.ReturnApproved, domain.ReturnRejected},
+	domain.ReturnApproved:  {domain.ReturnReceived},
+	domain.ReturnReceived:  {domain.ReturnInspected},
+}
+
+type ReturnService struct {
+	returnRepo  repositories.ReturnRepository
+	orderRepo   repositories.OrderRepository
+	productRepo repositories.ProductRepository
+	refunds     *RefundService
+	transactor  repositories.Transactor
+}
+
+// ReturnServiceOption configura parámetros opcionales del ReturnService
+type ReturnServiceOption func(*ReturnService)
+
+// WithReturnTransactor guarda la inspección y la reposición de stock en una misma transacción
+func WithReturnTransactor(transactor repositories.Transactor) ReturnServiceOption {
+	return func(s *ReturnService) {
+		s.transactor = transactor
+	}
+}
+
+func NewReturnService(
+	returnRepo repositories.ReturnRepository,
+	orderRepo repositories.OrderRepository,
+	productRepo repositories.ProductRepository,
+	refunds *RefundService,
+	opts ...ReturnServiceOption,
+) *ReturnService {
+	s := &ReturnService{
+		returnRepo:  returnRepo,
+		orderRepo:   orderRepo,
+		productRepo: productRepo,
+		refunds:     refunds,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// RequestReturn registra la solicitud de devolución de ítems de un pedido SHIPPED.
+// No se puede pedir más unidades que las enviadas menos las de otras devoluciones no rechazadas
+// y las ya reembolsadas fuera de una devolución.
+func (s *ReturnService) RequestReturn(ctx context.Context, orderID uint, req domain.CreateReturnRequest) (*domain.ReturnAuthorization, error) {
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, ErrOrderNotFound
+	}
+
+	if order.Status != domain.StatusShipped {
+		return nil, ErrReturnNotAllowed
+	}
+
+	existing, err := s.returnRepo.GetByOrderID(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	returned := make(map[uint]int)
+	rmaRefunds := make(map[uint]bool)
+	for _, rma := range existing {
+		if rma.RefundID != nil {
+			rmaRefunds[*rma.RefundID] = true
+		}
+		if rma.Status == domain.ReturnRejected {
+			continue
+		}
+		for _, item := range rma.Items {
+			returned[item.OrderItemID] += item.Quantity
+		}
+	}
+	refunded, err := s.refundedQuantities(ctx, order.ID, rmaRefunds)
+	if err != nil {
+		return nil, err
+	}
+	for itemID, quantity := range refunded {
+		returned[itemID] += quantity
+	}
+
+	rma := &domain.ReturnAuthorization{
+		OrderID: order.ID,
+		Status:  domain.ReturnRequested,
+		Reason:  req.Reason,
+	}
+
+	seen := make(map[uint]bool)
+	for _, requested := range req.Items {
+		if seen[requested.OrderItemID] {
+			return nil, fmt.Errorf("%w: order item %d appears more than once", ErrInvalidReturn, requested.OrderItemID)
+		}
+		seen[requested.OrderItemID] = true
+
+		item := findOrderItem(order, requested.OrderItemID)
+		if item == nil {
+			return nil, fmt.Errorf("%w: order item %d does not belong to the order", ErrInvalidReturn, requested.OrderItemID)
+		}
+		if remaining := item.Quantity - returned[item.ID]; requested.Quantity > remaining {
+			if remaining < 0 {
+				remaining = 0
+			}
+			return nil, fmt.Errorf("%w: only %d unit(s) of order item %d can be returned", ErrInvalidReturn, remaining, item.ID)
+		}
+
+		rma.Items = append(rma.Items, domain.ReturnItem{
+			OrderItemID: item.ID,
+			Quantity:    requested.Quantity,
+			Reason:      requested.Reason,
+		})
+	}
+
+	rma.Transitions = []domain.ReturnTransition{{ToStatus: domain.ReturnRequested, Note: req.Reason}}
+	if err := s.returnRepo.Create(ctx, rma); err != nil {
+		return nil, err
+	}
+	return rma, nil
+}
+
+func (s *ReturnService) GetReturn(ctx context.Context, id uint) (*domain.ReturnAuthorization, error) {
+	rma, err := s.returnRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrReturnNotFound
+	}
+	return rma, nil
+}
+
+func (s *ReturnService) GetReturns(ctx context.Context, orderID uint) ([]domain.ReturnAuthorization, error) {
+	if _, err := s.orderRepo.GetByID(ctx, orderID); err != nil {
+		return nil, ErrOrderNotFound
+	}
+	return s.returnRepo.GetByOrderID(ctx, orderID)
+}
+
+// Approve aprueba la devolución y reembolsa las unidades devueltas que todavía no se reembolsaron.
+// Si el pedido no tiene un pago cobrado se aprueba sin reembolso. El stock se repone recién en la inspección.
+// La aprobación se guarda antes del reembolso y sólo si la devolución sigue REQUESTED, así una
+// aprobación simultánea no reembolsa dos veces; si el reembolso falla vuelve a REQUESTED.
+func (s *ReturnService) Approve(ctx context.Context, id uint, note string) (*domain.ReturnAuthorization, error) {
+	rma, err := s.GetReturn(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !canTransition(rma.Status, domain.ReturnApproved) {
+		return nil, ErrInvalidReturnTransition
+	}
+
+	order, err := s.orderRepo.GetByID(ctx, rma.OrderID)
+	if err != nil {
+		return nil, ErrOrderNotFound
+	}
+	refunded, err := s.refundedQuantities(ctx, order.ID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req := domain.CreateRefundRequest{Reason: fmt.Sprintf("RMA #%d: %s", rma.ID, rma.Reason)}
+	for _, item := range rma.Items {
+		quantity := item.Quantity
+		if orderItem := findOrderItem(order, item.OrderItemID); orderItem != nil {
+			if unrefunded := orderItem.Quantity - refunded[item.OrderItemID]; unrefunded < quantity {
+				quantity = unrefunded
+			}
+		}
+		if quantity > 0 {
+			req.Items = append(req.Items, domain.RefundItemRequest{OrderItemID: item.OrderItemID, Quantity: quantity})
+		}
+	}
+
+	if err := s.transition(ctx, s.returnRepo, rma, domain.ReturnApproved, note); err != nil {
+		return nil, err
+	}
+	if len(req.Items) == 0 {
+		return rma, nil
+	}
+
+	// El reembolso queda vinculado a la devolución en la misma transacción que lo registra
+	_, err = s.refunds.createRefund(ctx, rma.OrderID, req, func(tx repositories.Repositories, refund *domain.Refund) error {
+		rma.RefundID = &refund.ID
+		return s.returns(tx).Update(ctx, rma)
+	})
+	if err != nil && !errors.Is(err, ErrNothingToRefund) {
+		rma.RefundID = nil
+		s.revertApproval(ctx, rma, err)
+		return nil, err
+	}
+	return rma, nil
+}
+
+// revertApproval devuelve a REQUESTED una aprobación cuyo reembolso falló, dejando el motivo en el historial
+func (s *ReturnService) revertApproval(ctx context.Context, rma *domain.ReturnAuthorization, cause error) {
+	rma.Transitions = append(rma.Transitions, domain.ReturnTransition{
+		ReturnID:   rma.ID,
+		FromStatus: domain.ReturnApproved,
+		ToStatus:   domain.ReturnRequested,
+		Note:       "refund failed: " + cause.Error(),
+	})
+	rma.Status = domain.ReturnRequested
+	if _, err := s.returnRepo.UpdateIfStatus(ctx, rma, domain.ReturnApproved); err != nil {
+		logging.For(ctx, "returns").Error("could not revert approval of return", "return_id", rma.ID, "error", err)
+	}
+}
+
+// Reject rechaza la devolución; el motivo es obligatorio
+func (s *ReturnService) Reject(ctx context.Context, id uint, note string) (*domain.ReturnAuthorization, error) {
+	if note == "" {
+		return nil, fmt.Errorf("%w: a note is required to reject a return", ErrInvalidReturn)
+	}
+	rma, err := s.GetReturn(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.transition(ctx, s.returnRepo, rma, domain.ReturnRejected, note); err != nil {
+		return nil, err
+	}
+	return rma, nil
+}
+
+// Receive registra la llegada de la mercadería devuelta
+func (s *ReturnService) Receive(ctx context.Context, id uint, note string) (*domain.ReturnAuthorization, error) {
+	rma, err := s.GetReturn(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.transition(ctx, s.returnRepo, rma, domain.ReturnReceived, note); err != nil {
+		return nil, err
+	}
+	return rma, nil
+}
+
+// Inspect asigna el destino de cada ítem recibido: RESTOCK lo devuelve al stock, WRITE_OFF lo da de baja
+func (s *ReturnService) Inspect(ctx context.Context, id uint, req domain.InspectReturnRequest) (*domain.ReturnAuthorization, error) {
+	rma, err := s.GetReturn(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !canTransition(rma.Status, domain.ReturnInspected) {
+		return nil, ErrInvalidReturnTransition
+	}
+
+	dispositions := make(map[uint]domain.ReturnDisposition)
+	for _, inspected := range req.Items {
+		if _, ok := dispositions[inspected.ReturnItemID]; ok {
+			return nil, fmt.Errorf("%w: return item %d appears more than once", ErrInvalidReturn, inspected.ReturnItemID)
+		}
+		dispositions[inspected.ReturnItemID] = inspected.Disposition
+	}
+	for _, item := range rma.Items {
+		if _, ok := dispositions[item.ID]; !ok {
+			return nil, fmt.Errorf("%w: missing disposition for return item %d", ErrInvalidReturn, item.ID)
+		}
+	}
+	if len(dispositions) != len(rma.Items) {
+		return nil, fmt.Errorf("%w: disposition given for an item outside the return", ErrInvalidReturn)
+	}
+
+	order, err := s.orderRepo.GetByID(ctx, rma.OrderID)
+	if err != nil {
+		return nil, ErrOrderNotFound
+	}
+
+	for i := range rma.Items {
+		rma.Items[i].Disposition = dispositions[rma.Items[i].ID]
+	}
+
+	// El stock se repone sólo si esta inspección es la que pasa la devolución a INSPECTED
+	err = s.withinTransaction(ctx, func(tx repositories.Repositories) error {
+		if err := s.transition(ctx, s.returns(tx), rma, domain.ReturnInspected, req.Note); err != nil {
+			return err
+		}
+		for _, item := range rma.Items {
+			if item.Disposition != domain.DispositionRestock {
+				continue
+			}
+			orderItem := findOrderItem(order, item.OrderItemID)
+			product, err := tx.Products.GetByID(ctx, orderItem.ProductID)
+			if err != nil {
+				return ErrProductNotFound
+			}
+			if err := tx.Products.UpdateStock(ctx, product.ID, product.Stock+item.Quantity); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rma, nil
+}
+
+// transition valida el cambio de estado, lo registra en el historial y guarda la devolución sólo si
+// sigue en el estado leído; si otro proceso la cambió devuelve ErrInvalidReturnTransition
+func (s *ReturnService) transition(ctx context.Context, repo repositories.ReturnRepository, rma *domain.ReturnAuthorization, to domain.ReturnStatus, note string) error {
+	from := rma.Status
+	if !canTransition(from, to) {
+		return ErrInvalidReturnTransition
+	}
+
+	rma.Transitions = append(rma.Transitions, domain.ReturnTransition{
+		ReturnID:   rma.ID,
+		FromStatus: from,
+		ToStatus:   to,
+		Note:       note,
+	})
+	rma.Status = to
+	updated, err := repo.UpdateIfStatus(ctx, rma, from)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrInvalidReturnTransition
+	}
+	return nil
+}
+
+// withinTransaction ejecuta fn con los repositorios de una transacción, o con los propios sin transactor
+func (s *ReturnService) withinTransaction(ctx context.Context, fn func(tx repositories.Repositories) error) error {
+	if s.transactor == nil {
+		return fn(repositories.Repositories{Orders: s.orderRepo, Products: s.productRepo, Returns: s.returnRepo})
+	}
+	return s.transactor.WithinTransaction(ctx, fn)
+}
+
+// returns devuelve el repositorio de devoluciones de la transacción, o el propio fuera de una
+func (s *ReturnService) returns(tx repositories.Repositories) repositories.ReturnRepository {
+	if tx.Returns != nil {
+		return tx.Returns
+	}
+	return s.returnRepo
+}
+
+// refundedQuantities suma las unidades reembolsadas por línea del pedido, salvo los reembolsos en skip
+func (s *ReturnService) refundedQuantities(ctx context.Context, orderID uint, skip map[uint]bool) (map[uint]int, error) {
+	refunds, err := s.refunds.GetRefunds(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	refunded := make(map[uint]int)
+	for _, refund := range refunds {
+		if skip[refund.ID] {
+			continue
+		}
+		for _, line := range refund.Lines {
+			refunded[line.OrderItemID] += line.Quantity
+		}
+	}
+	return refunded, nil
+}
+
+func canTransition(from, to domain.ReturnStatus) bool {
+	for _, allowed := range returnTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
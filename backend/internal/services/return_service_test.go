@@ -0,0 +1,341 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/payments"
+	"testing"
+)
+
+type mockReturnRepository struct {
+	returns map[uint]*domain.ReturnAuthorization
+	nextID  uint
+}
+
+func (m *mockReturnRepository) Create(ctx context.Context, rma *domain.ReturnAuthorization) error {
+	m.nextID++
+	rma.ID = m.nextID
+	for i := range rma.Items {
+		rma.Items[i].ID = uint(i + 1)
+		rma.Items[i].ReturnID = rma.ID
+	}
+	for i := range rma.Transitions {
+		rma.Transitions[i].ReturnID = rma.ID
+	}
+	m.returns[rma.ID] = rma
+	return nil
+}
+
+func (m *mockReturnRepository) GetByID(ctx context.Context, id uint) (*domain.ReturnAuthorization, error) {
+	if rma, ok := m.returns[id]; ok {
+		return rma, nil
+	}
+	return nil, errors.New("return not found")
+}
+
+func (m *mockReturnRepository) GetByOrderID(ctx context.Context, orderID uint) ([]domain.ReturnAuthorization, error) {
+	var rmas []domain.ReturnAuthorization
+	for id := uint(1); id <= m.nextID; id++ {
+		if rma, ok := m.returns[id]; ok && rma.OrderID == orderID {
+			rmas = append(rmas, *rma)
+		}
+	}
+	return rmas, nil
+}
+
+func (m *mockReturnRepository) Update(ctx context.Context, rma *domain.ReturnAuthorization) error {
+	m.returns[rma.ID] = rma
+	return nil
+}
+
+func (m *mockReturnRepository) UpdateIfStatus(ctx context.Context, rma *domain.ReturnAuthorization, status domain.ReturnStatus) (bool, error) {
+	stored, ok := m.returns[rma.ID]
+	if !ok {
+		return false, errors.New("return not found")
+	}
+	if stored != rma && stored.Status != status {
+		return false, nil
+	}
+	m.returns[rma.ID] = rma
+	return true, nil
+}
+
+// staleReturnRepository devuelve una copia de la devolución con el estado que tenía antes de un cambio concurrente
+type staleReturnRepository struct {
+	*mockReturnRepository
+	status domain.ReturnStatus
+}
+
+func (r *staleReturnRepository) GetByID(ctx context.Context, id uint) (*domain.ReturnAuthorization, error) {
+	rma, err := r.mockReturnRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	stale := *rma
+	stale.Status = r.status
+	stale.Items = append([]domain.ReturnItem(nil), rma.Items...)
+	stale.Transitions = append([]domain.ReturnTransition(nil), rma.Transitions...)
+	return &stale, nil
+}
+
+func setupReturnService(t *testing.T) (*ReturnService, *domain.Order, *mockPaymentRepository, *mockProductRepository) {
+	ctx := context.Background()
+	service, refundService, paymentService, paymentRepo, productRepo := setupRefundService()
+	order := createConfirmedOrder(t, service, paymentService)
+	if _, err := service.ShipOrder(ctx, order.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	returnService := NewReturnService(&mockReturnRepository{returns: make(map[uint]*domain.ReturnAuthorization)},
+		service.orderRepo, productRepo, refundService)
+	return returnService, order, paymentRepo, productRepo
+}
+
+func TestReturn_FullWorkflow(t *testing.T) {
+	ctx := context.Background()
+	returnService, order, paymentRepo, productRepo := setupReturnService(t)
+
+	rma, err := returnService.RequestReturn(ctx, order.ID, domain.CreateReturnRequest{
+		Reason: "damaged in transit",
+		Items: []domain.ReturnItemRequest{
+			{OrderItemID: order.Items[0].ID, Quantity: 2},
+			{OrderItemID: order.Items[1].ID, Quantity: 1},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if rma.Status != domain.ReturnRequested {
+		t.Errorf("Expected REQUESTED, got %s", rma.Status)
+	}
+
+	if _, err := returnService.Receive(ctx, rma.ID, ""); err != ErrInvalidReturnTransition {
+		t.Errorf("Expected ErrInvalidReturnTransition before approval, got %v", err)
+	}
+
+	rma, err = returnService.Approve(ctx, rma.ID, "ok")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if rma.RefundID == nil || paymentRepo.payments[1].RefundedAmount != 250 {
+		t.Errorf("Expected approval to refund 250, got %+v", paymentRepo.payments[1])
+	}
+	if productRepo.products[1].Stock != 8 {
+		t.Errorf("Expected no restock before inspection, got %d", productRepo.products[1].Stock)
+	}
+
+	if _, err := returnService.Receive(ctx, rma.ID, "arrived"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	rma, err = returnService.Inspect(ctx, rma.ID, domain.InspectReturnRequest{Items: []domain.ReturnInspectionItem{
+		{ReturnItemID: rma.Items[0].ID, Disposition: domain.DispositionRestock},
+		{ReturnItemID: rma.Items[1].ID, Disposition: domain.DispositionWriteOff},
+	}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if productRepo.products[1].Stock != 10 || productRepo.products[2].Stock != 4 {
+		t.Errorf("Expected stock 10 and 4, got %d and %d", productRepo.products[1].Stock, productRepo.products[2].Stock)
+	}
+
+	history, _ := returnService.GetReturns(ctx, order.ID)
+	if len(history) != 1 || len(history[0].Transitions) != 4 || history[0].Status != domain.ReturnInspected {
+		t.Errorf("Expected 4 transitions ending in INSPECTED, got %+v", history)
+	}
+}
+
+func TestRequestReturn_Validation(t *testing.T) {
+	ctx := context.Background()
+	returnService, order, _, _ := setupReturnService(t)
+	itemID := order.Items[0].ID
+
+	if _, err := returnService.RequestReturn(ctx, order.ID, domain.CreateReturnRequest{
+		Reason: "wrong size",
+		Items:  []domain.ReturnItemRequest{{OrderItemID: itemID, Quantity: 3}},
+	}); !errors.Is(err, ErrInvalidReturn) {
+		t.Errorf("Expected ErrInvalidReturn for excess quantity, got %v", err)
+	}
+
+	first, err := returnService.RequestReturn(ctx, order.ID, domain.CreateReturnRequest{
+		Reason: "wrong size",
+		Items:  []domain.ReturnItemRequest{{OrderItemID: itemID, Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	second := domain.CreateReturnRequest{Reason: "again", Items: []domain.ReturnItemRequest{{OrderItemID: itemID, Quantity: 1}}}
+	if _, err := returnService.RequestReturn(ctx, order.ID, second); !errors.Is(err, ErrInvalidReturn) {
+		t.Errorf("Expected ErrInvalidReturn while units are under return, got %v", err)
+	}
+
+	if _, err := returnService.Reject(ctx, first.ID, ""); !errors.Is(err, ErrInvalidReturn) {
+		t.Errorf("Expected note to be required, got %v", err)
+	}
+	if _, err := returnService.Reject(ctx, first.ID, "outside return window"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := returnService.RequestReturn(ctx, order.ID, second); err != nil {
+		t.Errorf("Expected units of a rejected return to be returnable, got %v", err)
+	}
+}
+
+func TestRequestReturn_SubtractsRefundedUnits(t *testing.T) {
+	ctx := context.Background()
+	returnService, order, _, _ := setupReturnService(t)
+	itemID := order.Items[0].ID
+
+	if _, err := returnService.refunds.CreateRefund(ctx, order.ID, domain.CreateRefundRequest{
+		Items: []domain.RefundItemRequest{{OrderItemID: itemID, Quantity: 1}},
+	}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := returnService.RequestReturn(ctx, order.ID, domain.CreateReturnRequest{
+		Reason: "wrong size",
+		Items:  []domain.ReturnItemRequest{{OrderItemID: itemID, Quantity: 2}},
+	}); !errors.Is(err, ErrInvalidReturn) {
+		t.Errorf("Expected ErrInvalidReturn for an already refunded unit, got %v", err)
+	}
+
+	rma, err := returnService.RequestReturn(ctx, order.ID, domain.CreateReturnRequest{
+		Reason: "wrong size",
+		Items:  []domain.ReturnItemRequest{{OrderItemID: itemID, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := returnService.Approve(ctx, rma.ID, ""); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	refunds, _ := returnService.refunds.GetRefunds(ctx, order.ID)
+	if len(refunds) != 2 || refunds[1].Lines[0].Quantity != 1 {
+		t.Errorf("Expected the return to refund the remaining unit, got %+v", refunds)
+	}
+}
+
+func TestApproveReturn_WithoutCapturedPayment(t *testing.T) {
+	ctx := context.Background()
+	_, userRepo, productRepo, orderRepo := setupService()
+	paymentRepo := &mockPaymentRepository{payments: make(map[uint]*domain.Payment)}
+	paymentService := NewPaymentService(paymentRepo, orderRepo, payments.NewSimulator())
+	refundService := NewRefundService(&mockRefundRepository{}, orderRepo, productRepo, paymentService)
+	addressService, _ := newTestAddressService(userRepo)
+	service := NewOrderService(orderRepo, productRepo, userRepo, WithAddresses(addressService))
+
+	order := createPendingOrder(t, service)
+	service.ConfirmOrder(ctx, order.ID)
+	if _, err := service.ShipOrder(ctx, order.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	returnService := NewReturnService(&mockReturnRepository{returns: make(map[uint]*domain.ReturnAuthorization)},
+		orderRepo, productRepo, refundService)
+	rma, err := returnService.RequestReturn(ctx, order.ID, domain.CreateReturnRequest{
+		Reason: "damaged",
+		Items:  []domain.ReturnItemRequest{{OrderItemID: order.Items[0].ID, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	approved, err := returnService.Approve(ctx, rma.ID, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if approved.Status != domain.ReturnApproved || approved.RefundID != nil {
+		t.Errorf("Expected APPROVED without refund, got %+v", approved)
+	}
+}
+
+func TestRequestReturn_RequiresShippedOrder(t *testing.T) {
+	ctx := context.Background()
+	service, refundService, paymentService, _, productRepo := setupRefundService()
+	order := createConfirmedOrder(t, service, paymentService)
+	returnService := NewReturnService(&mockReturnRepository{returns: make(map[uint]*domain.ReturnAuthorization)},
+		service.orderRepo, productRepo, refundService)
+
+	_, err := returnService.RequestReturn(ctx, order.ID, domain.CreateReturnRequest{
+		Reason: "changed my mind",
+		Items:  []domain.ReturnItemRequest{{OrderItemID: order.Items[0].ID, Quantity: 1}},
+	})
+	if err != ErrReturnNotAllowed {
+		t.Errorf("Expected ErrReturnNotAllowed, got %v", err)
+	}
+}
+
+func TestApproveReturn_ConcurrentApprovalRefundsOnce(t *testing.T) {
+	ctx := context.Background()
+	returnService, order, paymentRepo, _ := setupReturnService(t)
+	rma, _ := returnService.RequestReturn(ctx, order.ID, domain.CreateReturnRequest{
+		Reason: "wrong size",
+		Items:  []domain.ReturnItemRequest{{OrderItemID: order.Items[0].ID, Quantity: 1}},
+	})
+	if _, err := returnService.Approve(ctx, rma.ID, "ok"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Otra aprobación leyó la devolución REQUESTED antes de que la primera la guardara
+	returnRepo := returnService.returnRepo.(*mockReturnRepository)
+	approving := NewReturnService(&staleReturnRepository{returnRepo, domain.ReturnRequested},
+		returnService.orderRepo, returnService.productRepo, returnService.refunds)
+	if _, err := approving.Approve(ctx, rma.ID, "ok"); err != ErrInvalidReturnTransition {
+		t.Fatalf("Expected ErrInvalidReturnTransition, got %v", err)
+	}
+	if payment := paymentRepo.payments[1]; payment.RefundedAmount != 100 {
+		t.Errorf("Expected a single refund of 100, got %f", payment.RefundedAmount)
+	}
+	if stored := returnRepo.returns[rma.ID]; len(stored.Transitions) != 2 {
+		t.Errorf("Expected a single approval in the history, got %+v", stored.Transitions)
+	}
+}
+
+func TestApproveReturn_RefundFailureRevertsApproval(t *testing.T) {
+	ctx := context.Background()
+	returnService, order, paymentRepo, _ := setupReturnService(t)
+	rma, _ := returnService.RequestReturn(ctx, order.ID, domain.CreateReturnRequest{
+		Reason: "wrong size",
+		Items:  []domain.ReturnItemRequest{{OrderItemID: order.Items[0].ID, Quantity: 1}},
+	})
+
+	// La pasarela no reconoce la autorización del pago
+	paymentRepo.payments[1].AuthorizationID = "unknown"
+	if _, err := returnService.Approve(ctx, rma.ID, "ok"); err == nil {
+		t.Fatal("Expected the refund error")
+	}
+	stored, _ := returnService.GetReturn(ctx, rma.ID)
+	if stored.Status != domain.ReturnRequested || stored.RefundID != nil {
+		t.Errorf("Expected the return back to REQUESTED without refund, got %+v", stored)
+	}
+	if paymentRepo.payments[1].RefundedAmount != 0 {
+		t.Errorf("Expected nothing refunded, got %f", paymentRepo.payments[1].RefundedAmount)
+	}
+}
+
+func TestInspectReturn_RetryDoesNotRestockTwice(t *testing.T) {
+	ctx := context.Background()
+	returnService, order, _, productRepo := setupReturnService(t)
+	rma, _ := returnService.RequestReturn(ctx, order.ID, domain.CreateReturnRequest{
+		Reason: "wrong size",
+		Items:  []domain.ReturnItemRequest{{OrderItemID: order.Items[0].ID, Quantity: 2}},
+	})
+	returnService.Approve(ctx, rma.ID, "")
+	returnService.Receive(ctx, rma.ID, "")
+	inspection := domain.InspectReturnRequest{Items: []domain.ReturnInspectionItem{
+		{ReturnItemID: rma.Items[0].ID, Disposition: domain.DispositionRestock},
+	}}
+	if _, err := returnService.Inspect(ctx, rma.ID, inspection); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Un reintento que leyó la devolución RECEIVED antes de que se guardara la inspección
+	retrying := NewReturnService(&staleReturnRepository{returnService.returnRepo.(*mockReturnRepository), domain.ReturnReceived},
+		returnService.orderRepo, returnService.productRepo, returnService.refunds)
+	if _, err := retrying.Inspect(ctx, rma.ID, inspection); err != ErrInvalidReturnTransition {
+		t.Fatalf("Expected ErrInvalidReturnTransition, got %v", err)
+	}
+	if productRepo.products[1].Stock != 10 {
+		t.Errorf("Expected a single restock to 10, got %d", productRepo.products[1].Stock)
+	}
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/shipping"
+	"order-management-system/internal/shipping/shippingtest"
+	"testing"
+)
+
+func setupShippingService(t *testing.T) (*OrderService, *shippingtest.Server) {
+	server := shippingtest.NewServer("")
+	t.Cleanup(server.Close)
+
+	_, userRepo, productRepo, orderRepo := setupService()
+	productRepo.products[1].WeightGrams = 1500
+	productRepo.products[2].WeightGrams = 200
+
+	addressService, _ := newTestAddressService(userRepo)
+	carrier := shipping.NewHTTPCarrier("fake", server.URL, "", server.Client())
+	service := NewOrderService(orderRepo, productRepo, userRepo,
+		WithAddresses(addressService),
+		WithCarrier(carrier),
+	)
+	return service, server
+}
+
+func TestCreateOrder_AddsShippingCost(t *testing.T) {
+	ctx := context.Background()
+	service, _ := setupShippingService(t)
+
+	order, err := service.CreateOrder(ctx, domain.CreateOrderRequest{
+		UserID:         1,
+		Items:          []domain.OrderItemRequest{{ProductID: 1, Quantity: 2}},
+		ShippingMethod: shipping.MethodExpress,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// 3 kg a Córdoba (zona 2), tramo hasta 5 kg
+	if order.ShippingMethod != shipping.MethodExpress || order.ShippingCost != 21600 {
+		t.Errorf("Expected EXPRESS at 21600, got %s at %f", order.ShippingMethod, order.ShippingCost)
+	}
+	if order.Total != 200+21600 {
+		t.Errorf("Expected total to include shipping, got %f", order.Total)
+	}
+
+	_, err = service.CreateOrder(ctx, domain.CreateOrderRequest{
+		UserID:         1,
+		Items:          []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}},
+		ShippingMethod: "DRONE",
+	})
+	if err != shipping.ErrMethodUnavailable {
+		t.Errorf("Expected ErrMethodUnavailable, got %v", err)
+	}
+}
+
+func TestCreateOrder_DefaultsToCheapestRate(t *testing.T) {
+	ctx := context.Background()
+	service, _ := setupShippingService(t)
+
+	rates, err := service.QuoteShipping(ctx, domain.CreateOrderRequest{
+		UserID: 1,
+		Items:  []domain.OrderItemRequest{{ProductID: 2, Quantity: 1}},
+	})
+	if err != nil || len(rates) != 2 {
+		t.Fatalf("Expected 2 rates, got %+v (%v)", rates, err)
+	}
+
+	order, err := service.CreateOrder(ctx, domain.CreateOrderRequest{
+		UserID: 1,
+		Items:  []domain.OrderItemRequest{{ProductID: 2, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if order.ShippingMethod != shipping.MethodStandard {
+		t.Errorf("Expected cheapest STANDARD method, got %s", order.ShippingMethod)
+	}
+}
+
+func TestShipOrder_RequestsLabel(t *testing.T) {
+	ctx := context.Background()
+	service, server := setupShippingService(t)
+
+	order, _ := service.CreateOrder(ctx, domain.CreateOrderRequest{
+		UserID: 1,
+		Items:  []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}},
+	})
+	service.ConfirmOrder(ctx, order.ID)
+
+	shipped, err := service.ShipOrder(ctx, order.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if shipped.TrackingNumber == "" || shipped.LabelURL == "" {
+		t.Errorf("Expected tracking number and label, got %+v", shipped)
+	}
+
+	labels := server.Labels()
+	if len(labels) != 1 || labels[0].Destination.City != "Córdoba" {
+		t.Errorf("Expected one label to Córdoba, got %+v", labels)
+	}
+
+	status, err := service.GetTracking(ctx, order.ID)
+	if err != nil || status.TrackingNumber != shipped.TrackingNumber {
+		t.Errorf("Expected tracking status, got %+v (%v)", status, err)
+	}
+}
+
+func TestShipOrder_CarrierFailureKeepsConfirmed(t *testing.T) {
+	ctx := context.Background()
+	service, server := setupShippingService(t)
+
+	order, _ := service.CreateOrder(ctx, domain.CreateOrderRequest{
+		UserID: 1,
+		Items:  []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}},
+	})
+	service.ConfirmOrder(ctx, order.ID)
+
+	server.SetFailing(true)
+	if _, err := service.ShipOrder(ctx, order.ID); err == nil {
+		t.Fatalf("Expected carrier error")
+	}
+
+	current, _ := service.GetOrder(ctx, order.ID)
+	if current.Status != domain.StatusConfirmed {
+		t.Errorf("Expected order to remain CONFIRMED, got %s", current.Status)
+	}
+}
+
+func TestShipOrder_VoidsLabelWhenUpdateFails(t *testing.T) {
+	ctx := context.Background()
+	server := shippingtest.NewServer("")
+	t.Cleanup(server.Close)
+
+	_, userRepo, productRepo, orderRepo := setupService()
+	addressService, _ := newTestAddressService(userRepo)
+	service := NewOrderService(orderRepo, productRepo, userRepo,
+		WithAddresses(addressService),
+		WithCarrier(shipping.NewHTTPCarrier("fake", server.URL, "", server.Client())),
+	)
+	order, _ := service.CreateOrder(ctx, domain.CreateOrderRequest{UserID: 1, Items: []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}}})
+	service.ConfirmOrder(ctx, order.ID)
+
+	// El pedido se guarda dentro de la transacción, que falla después de emitir la etiqueta
+	failing := NewOrderService(orderRepo, productRepo, userRepo,
+		WithAddresses(addressService),
+		WithCarrier(shipping.NewHTTPCarrier("fake", server.URL, "", server.Client())),
+		WithOutbox(&mockTransactor{orders: &failingOrderRepository{orderRepo}, products: productRepo, outbox: &mockOutboxRepository{}}),
+	)
+	if _, err := failing.ShipOrder(ctx, order.ID); err == nil {
+		t.Fatal("Expected error from failing repository")
+	}
+
+	labels, voided := server.Labels(), server.Voided()
+	if len(labels) != 1 || len(voided) != 1 {
+		t.Fatalf("Expected the only label to be voided, got %d labels and %v voided", len(labels), voided)
+	}
+	if status, err := shipping.NewHTTPCarrier("fake", server.URL, "", server.Client()).GetTrackingStatus(ctx, voided[0]); err != nil || status.Status != shipping.TrackingVoided {
+		t.Errorf("Expected voided label, got %+v (%v)", status, err)
+	}
+}
+
+func TestShipOrder_VoidsLabelWhenCancelledMeanwhile(t *testing.T) {
+	ctx := context.Background()
+	server := shippingtest.NewServer("")
+	t.Cleanup(server.Close)
+
+	_, userRepo, productRepo, orderRepo := setupService()
+	addressService, _ := newTestAddressService(userRepo)
+	service := NewOrderService(orderRepo, productRepo, userRepo, WithAddresses(addressService))
+	order, _ := service.CreateOrder(ctx, domain.CreateOrderRequest{UserID: 1, Items: []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}}})
+	service.ConfirmOrder(ctx, order.ID)
+
+	// Se canceló después de que ShipOrder lo leyera CONFIRMED
+	orderRepo.orders[order.ID].Status = domain.StatusCancelled
+	shipper := NewOrderService(&staleReadRepository{orderRepo, domain.StatusConfirmed}, productRepo, userRepo,
+		WithAddresses(addressService),
+		WithCarrier(shipping.NewHTTPCarrier("fake", server.URL, "", server.Client())),
+	)
+	if _, err := shipper.ShipOrder(ctx, order.ID); err != ErrInvalidStatus {
+		t.Fatalf("Expected ErrInvalidStatus, got %v", err)
+	}
+	if stored := orderRepo.orders[order.ID]; stored.Status != domain.StatusCancelled {
+		t.Errorf("Expected order to stay CANCELLED, got %s", stored.Status)
+	}
+	if labels, voided := server.Labels(), server.Voided(); len(labels) != 1 || len(voided) != 1 {
+		t.Errorf("Expected the label to be voided, got %d labels and %v voided", len(labels), voided)
+	}
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"order-management-system/internal/clock"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/jobs"
+	"order-management-system/internal/logging"
+	"order-management-system/internal/repositories"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StaleOrderPolicy define cuánto puede quedar PENDING un pedido antes de cancelarlo.
+// Timeout aplica a todos los pedidos y ByPaymentMethod lo reemplaza según la pasarela del
+// último intento de pago (PaymentMethodNone si no hubo intentos). Un timeout 0 no cancela.
+type StaleOrderPolicy struct {
+	Timeout         time.Duration
+	ByPaymentMethod map[string]time.Duration
+}
+
+// TimeoutFor devuelve el timeout que corresponde a un medio de pago
+func (p StaleOrderPolicy) TimeoutFor(paymentMethod string) time.Duration {
+	if timeout, ok := p.ByPaymentMethod[paymentMethod]; ok {
+		return timeout
+	}
+	return p.Timeout
+}
+
+// shortest devuelve el menor timeout activo, o 0 si la política no cancela nada
+func (p StaleOrderPolicy) shortest() time.Duration {
+	shortest := p.Timeout
+	for _, timeout := range p.ByPaymentMethod {
+		if timeout > 0 && (shortest <= 0 || timeout < shortest) {
+			shortest = timeout
+		}
+	}
+	return shortest
+}
+
+// ParsePaymentMethodTimeouts interpreta timeouts por medio de pago como "simulator=72h,none=2h"
+func ParsePaymentMethodTimeouts(spec string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration)
+	for _, pair := range strings.Split(spec, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		method, raw, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid payment method timeout %q", pair)
+		}
+		timeout, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil || timeout < 0 {
+			return nil, fmt.Errorf("invalid timeout for %s: %q", method, raw)
+		}
+		timeouts[strings.TrimSpace(method)] = timeout
+	}
+	return timeouts, nil
+}
+
+const defaultSweepBatchSize = 100
+
+// StaleOrderSweeper cancela los pedidos PENDING abandonados según la StaleOrderPolicy
+type StaleOrderSweeper struct {
+	orders    *OrderService
+	orderRepo repositories.OrderRepository
+	policy    StaleOrderPolicy
+	batchSize int
+	now       func() time.Time
+}
+
+// StaleOrderSweeperOption configura parámetros opcionales del StaleOrderSweeper
+type StaleOrderSweeperOption func(*StaleOrderSweeper)
+
+// WithSweeperClock reemplaza el reloj usado para calcular la antigüedad de los pedidos
+func WithSweeperClock(clk clock.Clock) StaleOrderSweeperOption {
+	return func(s *StaleOrderSweeper) {
+		s.now = clk.Now
+	}
+}
+
+// WithSweepBatchSize define de a cuántos pedidos se leen los candidatos
+func WithSweepBatchSize(size int) StaleOrderSweeperOption {
+	return func(s *StaleOrderSweeper) {
+		s.batchSize = size
+	}
+}
+
+func NewStaleOrderSweeper(
+	orders *OrderService,
+	orderRepo repositories.OrderRepository,
+	policy StaleOrderPolicy,
+	opts ...StaleOrderSweeperOption,
+) *StaleOrderSweeper {
+	s := &StaleOrderSweeper{
+		orders:    orders,
+		orderRepo: orderRepo,
+		policy:    policy,
+		batchSize: defaultSweepBatchSize,
+		now:       clock.System().Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Sweep cancela, como actor system, los pedidos PENDING que superaron su timeout y devuelve
+// el reporte. Con dryRun sólo informa cuáles cancelaría. Un pedido que cambió de estado
+// durante el barrido se omite; los que fallan quedan en Failed y se reintentan en el próximo.
+func (s *StaleOrderSweeper) Sweep(ctx context.Context, dryRun bool) (*domain.StaleOrderReport, error) {
+	now := s.now()
+	report := &domain.StaleOrderReport{DryRun: dryRun, RanAt: now, Cancelled: []domain.StaleOrder{}, Failed: []domain.StaleOrder{}}
+	shortest := s.policy.shortest()
+	if shortest <= 0 {
+		return report, nil
+	}
+
+	var afterID uint
+	for {
+		candidates, err := s.orderRepo.GetPendingCreatedBefore(ctx, now.Add(-shortest), afterID, s.batchSize)
+		if err != nil {
+			return nil, fmt.Errorf("fetching pending orders: %w", err)
+		}
+		for i := range candidates {
+			s.sweepOrder(ctx, &candidates[i], now, report)
+			afterID = candidates[i].ID
+		}
+		if len(candidates) < s.batchSize {
+			break
+		}
+	}
+
+	logging.For(ctx, "stale_orders").Info("sweep finished", "cancelled", len(report.Cancelled), "failed", len(report.Failed), "dry_run", dryRun)
+	return report, nil
+}
+
+func (s *StaleOrderSweeper) sweepOrder(ctx context.Context, order *domain.Order, now time.Time, report *domain.StaleOrderReport) {
+	method := paymentMethod(order)
+	timeout := s.policy.TimeoutFor(method)
+	if timeout <= 0 || now.Sub(order.CreatedAt) < timeout {
+		return
+	}
+
+	stale := domain.StaleOrder{
+		OrderID:       order.ID,
+		UserID:        order.UserID,
+		PaymentMethod: method,
+		Total:         order.Total,
+		CreatedAt:     order.CreatedAt,
+		Timeout:       timeout.String(),
+	}
+	if order.PublicID != nil {
+		stale.PublicID = *order.PublicID
+	}
+	if order.Number != nil {
+		stale.Number = *order.Number
+	}
+	if report.DryRun {
+		report.Cancelled = append(report.Cancelled, stale)
+		return
+	}
+
+	reason := fmt.Sprintf("pending for more than %s", timeout)
+	_, err := s.orders.CancelOrder(ctx, order.ID, CancelledBy(domain.ActorSystem, reason), IfStatus(domain.StatusPending))
+	switch {
+	case err == nil:
+		report.Cancelled = append(report.Cancelled, stale)
+		logging.For(ctx, "stale_orders").Info("cancelled order", "order_id", order.ID, "payment_method", method, "reason", reason)
+	case errors.Is(err, ErrInvalidStatus):
+		// Se confirmó o canceló mientras tanto
+	default:
+		stale.Error = err.Error()
+		report.Failed = append(report.Failed, stale)
+		logging.For(ctx, "stale_orders").Error("cancelling order", "order_id", order.ID, "error", err)
+	}
+}
+
+// paymentMethod devuelve la pasarela del último intento de pago del pedido
+func paymentMethod(order *domain.Order) string {
+	if len(order.Payments) == 0 {
+		return domain.PaymentMethodNone
+	}
+	payments := append([]domain.Payment(nil), order.Payments...)
+	sort.Slice(payments, func(i, j int) bool { return payments[i].ID < payments[j].ID })
+	return payments[len(payments)-1].Gateway
+}
+
+// StaleSweepPayload es el payload del trabajo programado del barrido
+type StaleSweepPayload struct {
+	DryRun bool `json:"dry_run"`
+}
+
+// RunSweep es el handler del trabajo programado que cancela los pedidos vencidos. Como no
+// tiene a quién devolver el reporte, lo deja completo en el log.
+func (s *StaleOrderSweeper) RunSweep(ctx context.Context, job domain.Job) error {
+	var payload StaleSweepPayload
+	if err := job.Decode(&payload); err != nil {
+		return jobs.Permanent(err)
+	}
+	report, err := s.Sweep(ctx, payload.DryRun)
+	if err != nil {
+		return err
+	}
+	logging.For(ctx, "stale_orders").Info("sweep report",
+		"dry_run", report.DryRun, "ran_at", report.RanAt, "cancelled", report.Cancelled, "failed", report.Failed)
+	return nil
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"order-management-system/internal/clock"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/logging"
+	"testing"
+	"time"
+)
+
+func TestStaleOrderSweeper_CancelsByPaymentMethodTimeout(t *testing.T) {
+	ctx := context.Background()
+	service, _, _, orderRepo := setupService()
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	create := func(age time.Duration, gateway string) *domain.Order {
+		order, err := service.CreateOrder(ctx, domain.CreateOrderRequest{UserID: 1, Items: []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}}})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		order.CreatedAt = now.Add(-age)
+		if gateway != "" {
+			order.Payments = []domain.Payment{{ID: order.ID, Gateway: gateway, Status: domain.PaymentFailed}}
+		}
+		return order
+	}
+	unpaid := create(3*time.Hour, "")
+	recent := create(time.Hour, "")
+	card := create(30*time.Hour, "simulator")
+	cardRecent := create(3*time.Hour, "simulator")
+	confirmed := create(72*time.Hour, "")
+	confirmed.Status = domain.StatusConfirmed
+
+	sweeper := NewStaleOrderSweeper(service, orderRepo,
+		StaleOrderPolicy{Timeout: 2 * time.Hour, ByPaymentMethod: map[string]time.Duration{"simulator": 24 * time.Hour}},
+		WithSweeperClock(clock.NewFake(now)),
+		WithSweepBatchSize(2),
+	)
+
+	// En dry-run se informa sin cancelar
+	report, err := sweeper.Sweep(ctx, true)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.Cancelled) != 2 || !report.DryRun || unpaid.Status != domain.StatusPending {
+		t.Fatalf("Expected 2 candidates without changes, got %+v", report)
+	}
+
+	report, _ = sweeper.Sweep(ctx, false)
+	if len(report.Cancelled) != 2 || report.Cancelled[0].OrderID != unpaid.ID || report.Cancelled[1].OrderID != card.ID ||
+		report.Cancelled[0].PaymentMethod != domain.PaymentMethodNone || report.Cancelled[1].Timeout != "24h0m0s" {
+		t.Fatalf("Unexpected report %+v", report)
+	}
+
+	cancelled, _ := service.GetOrder(ctx, card.ID)
+	if cancelled.Status != domain.StatusCancelled || cancelled.CancelledBy != domain.ActorSystem ||
+		cancelled.CancellationReason != "pending for more than 24h0m0s" || cancelled.CancelledAt == nil {
+		t.Errorf("Expected system cancellation, got %+v", cancelled)
+	}
+	for _, order := range []*domain.Order{recent, cardRecent, confirmed} {
+		if order.Status == domain.StatusCancelled {
+			t.Errorf("Expected order %d untouched", order.ID)
+		}
+	}
+
+	if report, _ = sweeper.Sweep(ctx, false); len(report.Cancelled) != 0 {
+		t.Errorf("Expected nothing left to cancel, got %+v", report.Cancelled)
+	}
+}
+
+// staleReadRepository devuelve una copia del pedido con el estado que tenía antes de un cambio concurrente
+type staleReadRepository struct {
+	*mockOrderRepository
+	status domain.OrderStatus
+}
+
+func (r *staleReadRepository) GetByID(ctx context.Context, id uint) (*domain.Order, error) {
+	order, err := r.mockOrderRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	stale := *order
+	stale.Status = r.status
+	return &stale, nil
+}
+
+func TestStaleOrderSweeper_ScheduledRunLogsReport(t *testing.T) {
+	service, _, _, orderRepo := setupService()
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	order, _ := service.CreateOrder(context.Background(), domain.CreateOrderRequest{UserID: 1, Items: []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}}})
+	order.CreatedAt = now.Add(-3 * time.Hour)
+
+	var buf bytes.Buffer
+	logger, _ := logging.New(&buf, "json", logging.Levels{})
+	ctx := logging.WithLogger(context.Background(), logger)
+	sweeper := NewStaleOrderSweeper(service, orderRepo, StaleOrderPolicy{Timeout: 2 * time.Hour}, WithSweeperClock(clock.NewFake(now)))
+	job, _ := domain.NewJob("orders.cancel_stale", StaleSweepPayload{}, now)
+	if err := sweeper.RunSweep(ctx, *job); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var report struct {
+		Msg       string              `json:"msg"`
+		Cancelled []domain.StaleOrder `json:"cancelled"`
+	}
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		if json.Unmarshal(line, &report); report.Msg == "sweep report" {
+			break
+		}
+	}
+	if report.Msg != "sweep report" || len(report.Cancelled) != 1 || report.Cancelled[0].Total != order.Total {
+		t.Errorf("Expected the report in the log, got %s", buf.String())
+	}
+}
+
+func TestCancelOrder_RejectsStatusChangedAfterRead(t *testing.T) {
+	ctx := context.Background()
+	service, userRepo, productRepo, orderRepo := setupService()
+	order, _ := service.CreateOrder(ctx, domain.CreateOrderRequest{UserID: 1, Items: []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}}})
+	if _, err := service.ConfirmOrder(ctx, order.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// El barrido leyó el pedido PENDING justo antes de que se confirmara
+	sweeping := NewOrderService(&staleReadRepository{orderRepo, domain.StatusPending}, productRepo, userRepo)
+	if _, err := sweeping.CancelOrder(ctx, order.ID, IfStatus(domain.StatusPending)); err != ErrInvalidStatus {
+		t.Fatalf("Expected ErrInvalidStatus, got %v", err)
+	}
+	if stored := orderRepo.orders[order.ID]; stored.Status != domain.StatusConfirmed {
+		t.Errorf("Expected order to stay CONFIRMED, got %s", stored.Status)
+	}
+}
+
+func TestCancelOrder_IfStatusGuardsConcurrentChanges(t *testing.T) {
+	ctx := context.Background()
+	service, _, _, _ := setupService()
+	order, _ := service.CreateOrder(ctx, domain.CreateOrderRequest{UserID: 1, Items: []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}}})
+	order.Status = domain.StatusConfirmed
+
+	if _, err := service.CancelOrder(ctx, order.ID, IfStatus(domain.StatusPending)); err != ErrInvalidStatus {
+		t.Errorf("Expected ErrInvalidStatus, got %v", err)
+	}
+
+	cancelled, err := service.CancelOrder(ctx, order.ID, CancelledBy(domain.ActorUser, "changed my mind"))
+	if err != nil || cancelled.CancelledBy != domain.ActorUser || cancelled.CancellationReason != "changed my mind" {
+		t.Errorf("Expected user cancellation, got %+v (%v)", cancelled, err)
+	}
+}
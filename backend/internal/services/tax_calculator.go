@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/repositories"
+	"sort"
+	"strings"
+)
+
+// ErrNoTaxRule indica que no hay regla para la jurisdicción o la clase impositiva de una línea.
+// Las clases exentas deben tener su propia regla con alícuota cero.
+var ErrNoTaxRule = errors.New("no tax rule for jurisdiction and tax class")
+
+// TaxableLine es el importe neto (después de descuentos) de una línea y su clase impositiva.
+type TaxableLine struct {
+	TaxClass string
+	Amount   float64
+}
+
+// LineTax es el impuesto calculado para una línea.
+type LineTax struct {
+	TaxClass string
+	Rate     float64
+	Amount   float64
+}
+
+// TaxResult contiene la jurisdicción aplicada, el impuesto por línea (mismo índice
+// que la entrada) y el resumen agrupado por alícuota que se persiste en el pedido.
+type TaxResult struct {
+	Jurisdiction domain.Jurisdiction
+	Lines        []LineTax
+	TaxLines     []domain.OrderTaxLine
+	Total        float64
+}
+
+// TaxCalculator calcula los impuestos de un pedido para una jurisdicción.
+type TaxCalculator interface {
+	Calculate(ctx context.Context, jurisdiction domain.Jurisdiction, lines []TaxableLine) (*TaxResult, error)
+}
+
+// RuleTaxCalculator calcula impuestos a partir de reglas por jurisdicción y clase impositiva.
+// Los precios se consideran netos de impuestos.
+type RuleTaxCalculator struct {
+	taxRuleRepo         repositories.TaxRuleRepository
+	defaultJurisdiction domain.Jurisdiction
+}
+
+func NewRuleTaxCalculator(taxRuleRepo repositories.TaxRuleRepository, defaultJurisdiction domain.Jurisdiction) *RuleTaxCalculator {
+	return &RuleTaxCalculator{
+		taxRuleRepo:         taxRuleRepo,
+		defaultJurisdiction: defaultJurisdiction,
+	}
+}
+
+// resolve normaliza la jurisdicción y aplica la jurisdicción por defecto si no se informa país
+func (c *RuleTaxCalculator) resolve(jurisdiction domain.Jurisdiction) domain.Jurisdiction {
+	jurisdiction.Country = strings.ToUpper(strings.TrimSpace(jurisdiction.Country))
+	jurisdiction.Region = strings.TrimSpace(jurisdiction.Region)
+	if jurisdiction.Country == "" {
+		return c.defaultJurisdiction
+	}
+	return jurisdiction
+}
+
+func (c *RuleTaxCalculator) Calculate(ctx context.Context, jurisdiction domain.Jurisdiction, lines []TaxableLine) (*TaxResult, error) {
+	jurisdiction = c.resolve(jurisdiction)
+
+	rules, err := c.taxRuleRepo.GetByCountry(ctx, jurisdiction.Country)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TaxResult{Jurisdiction: jurisdiction, Lines: make([]LineTax, len(lines))}
+	groups := make(map[string]*domain.OrderTaxLine)
+
+	for i, line := range lines {
+		taxClass := line.TaxClass
+		if taxClass == "" {
+			taxClass = domain.TaxClassStandard
+		}
+
+		rule := matchTaxRule(rules, jurisdiction, taxClass)
+		if rule == nil {
+			return nil, fmt.Errorf("%w: %s %s", ErrNoTaxRule, jurisdiction.Country, taxClass)
+		}
+
+		amount := roundMoney(line.Amount * rule.Rate)
+		result.Lines[i] = LineTax{TaxClass: taxClass, Rate: rule.Rate, Amount: amount}
+
+		key := fmt.Sprintf("%s|%s|%f", taxClass, rule.Name, rule.Rate)
+		group, ok := groups[key]
+		if !ok {
+			group = &domain.OrderTaxLine{TaxClass: taxClass, Name: rule.Name, Rate: rule.Rate}
+			groups[key] = group
+		}
+		group.Base = roundMoney(group.Base + line.Amount)
+		group.Amount = roundMoney(group.Amount + amount)
+		result.Total = roundMoney(result.Total + amount)
+	}
+
+	for _, group := range groups {
+		result.TaxLines = append(result.TaxLines, *group)
+	}
+	sort.Slice(result.TaxLines, func(i, j int) bool {
+		return result.TaxLines[i].Rate > result.TaxLines[j].Rate
+	})
+
+	return result, nil
+}
+
+// matchTaxRule elige la regla de la región si existe, si no la del país
+func matchTaxRule(rules []domain.TaxRule, jurisdiction domain.Jurisdiction, taxClass string) *domain.TaxRule {
+	var countryRule *domain.TaxRule
+	for i := range rules {
+		rule := &rules[i]
+		if !strings.EqualFold(rule.TaxClass, taxClass) {
+			continue
+		}
+		if rule.Region == "" {
+			countryRule = rule
+		} else if strings.EqualFold(rule.Region, jurisdiction.Region) {
+			return rule
+		}
+	}
+	return countryRule
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"order-management-system/internal/domain"
+	"testing"
+)
+
+type mockTaxRuleRepository struct {
+	rules []domain.TaxRule
+}
+
+func (m *mockTaxRuleRepository) Create(ctx context.Context, rule *domain.TaxRule) error {
+	rule.ID = uint(len(m.rules) + 1)
+	m.rules = append(m.rules, *rule)
+	return nil
+}
+
+func (m *mockTaxRuleRepository) GetAll(ctx context.Context) ([]domain.TaxRule, error) {
+	return m.rules, nil
+}
+
+func (m *mockTaxRuleRepository) GetByCountry(ctx context.Context, country string) ([]domain.TaxRule, error) {
+	var rules []domain.TaxRule
+	for _, r := range m.rules {
+		if r.Country == country {
+			rules = append(rules, r)
+		}
+	}
+	return rules, nil
+}
+
+func newTestTaxCalculator() *RuleTaxCalculator {
+	repo := &mockTaxRuleRepository{rules: []domain.TaxRule{
+		{Country: "AR", TaxClass: domain.TaxClassStandard, Name: "IVA 21%", Rate: 0.21},
+		{Country: "AR", TaxClass: domain.TaxClassReduced, Name: "IVA 10.5%", Rate: 0.105},
+		{Country: "AR", TaxClass: domain.TaxClassExempt, Name: "IVA Exento", Rate: 0},
+		{Country: "AR", Region: "Tierra del Fuego", TaxClass: domain.TaxClassStandard, Name: "Exento TDF", Rate: 0},
+		{Country: "UY", TaxClass: domain.TaxClassStandard, Name: "IVA 22%", Rate: 0.22},
+	}}
+	return NewRuleTaxCalculator(repo, domain.Jurisdiction{Country: "AR"})
+}
+
+func TestTaxCalculator_GroupsByRate(t *testing.T) {
+	ctx := context.Background()
+	calculator := newTestTaxCalculator()
+
+	result, err := calculator.Calculate(ctx, domain.Jurisdiction{Country: "ar"}, []TaxableLine{
+		{TaxClass: domain.TaxClassStandard, Amount: 100},
+		{TaxClass: "", Amount: 50},
+		{TaxClass: domain.TaxClassReduced, Amount: 200},
+		{TaxClass: domain.TaxClassExempt, Amount: 30},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.Total != 52.5 {
+		t.Errorf("Expected tax total 52.5, got %f", result.Total)
+	}
+	if result.Lines[1].TaxClass != domain.TaxClassStandard || result.Lines[1].Amount != 10.5 {
+		t.Errorf("Expected empty class to default to STANDARD (10.5), got %+v", result.Lines[1])
+	}
+	if len(result.TaxLines) != 3 {
+		t.Fatalf("Expected 3 tax lines, got %d", len(result.TaxLines))
+	}
+	if result.TaxLines[0].Name != "IVA 21%" || result.TaxLines[0].Base != 150 || result.TaxLines[0].Amount != 31.5 {
+		t.Errorf("Unexpected IVA 21%% line: %+v", result.TaxLines[0])
+	}
+}
+
+func TestTaxCalculator_Jurisdictions(t *testing.T) {
+	ctx := context.Background()
+	calculator := newTestTaxCalculator()
+	lines := []TaxableLine{{TaxClass: domain.TaxClassStandard, Amount: 100}}
+
+	tests := []struct {
+		name         string
+		jurisdiction domain.Jurisdiction
+		expected     float64
+		country      string
+	}{
+		{"default jurisdiction", domain.Jurisdiction{}, 21, "AR"},
+		{"region override", domain.Jurisdiction{Country: "AR", Region: "tierra del fuego"}, 0, "AR"},
+		{"other country", domain.Jurisdiction{Country: "UY"}, 22, "UY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := calculator.Calculate(ctx, tt.jurisdiction, lines)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if result.Total != tt.expected {
+				t.Errorf("Expected tax %f, got %f", tt.expected, result.Total)
+			}
+			if result.Jurisdiction.Country != tt.country {
+				t.Errorf("Expected country %s, got %s", tt.country, result.Jurisdiction.Country)
+			}
+		})
+	}
+}
+
+func TestTaxCalculator_RejectsMissingRule(t *testing.T) {
+	ctx := context.Background()
+	calculator := newTestTaxCalculator()
+
+	tests := []struct {
+		name         string
+		jurisdiction domain.Jurisdiction
+		taxClass     string
+	}{
+		{"unknown country", domain.Jurisdiction{Country: "CL"}, domain.TaxClassStandard},
+		{"unknown class", domain.Jurisdiction{Country: "UY"}, domain.TaxClassReduced},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := calculator.Calculate(ctx, tt.jurisdiction, []TaxableLine{{TaxClass: tt.taxClass, Amount: 100}})
+			if !errors.Is(err, ErrNoTaxRule) {
+				t.Errorf("Expected ErrNoTaxRule, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCreateOrder_TaxOnDiscountedLines(t *testing.T) {
+	ctx := context.Background()
+	_, userRepo, productRepo, orderRepo := setupService()
+	productRepo.products[2].TaxClass = domain.TaxClassReduced
+
+	promotionRepo := &mockPromotionRepository{promotions: make(map[uint]*domain.Promotion)}
+	promotionRepo.Create(ctx, &domain.Promotion{Code: code("OFF10"), Name: "10%", Type: domain.PromotionPercentage, Value: 10})
+
+	service := NewOrderService(orderRepo, productRepo, userRepo,
+		WithPromotions(NewPromotionService(promotionRepo)),
+		WithTaxCalculator(newTestTaxCalculator()),
+	)
+
+	order, err := service.CreateOrder(ctx, domain.CreateOrderRequest{
+		UserID:      1,
+		Items:       []domain.OrderItemRequest{{ProductID: 1, Quantity: 1}, {ProductID: 2, Quantity: 2}},
+		CouponCodes: []string{"OFF10"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Netos: 90 al 21% y 90 al 10.5%
+	if order.TaxTotal != 28.35 {
+		t.Errorf("Expected tax total 28.35, got %f", order.TaxTotal)
+	}
+	if order.Total != 208.35 {
+		t.Errorf("Expected total 208.35 (200 - 20 + 28.35), got %f", order.Total)
+	}
+	if order.Items[1].TaxRate != 0.105 || order.Items[1].TaxAmount != 9.45 {
+		t.Errorf("Expected reduced rate on line 2, got %+v", order.Items[1])
+	}
+	if len(order.TaxLines) != 2 || order.TaxCountry != "AR" {
+		t.Errorf("Expected 2 tax lines for AR, got %d for %s", len(order.TaxLines), order.TaxCountry)
+	}
+}
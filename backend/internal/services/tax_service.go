@@ -0,0 +1,40 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/repositories"
+	"strings"
+)
+
+var ErrInvalidTaxRule = errors.New("invalid tax rule")
+
+type TaxService struct {
+	taxRuleRepo repositories.TaxRuleRepository
+}
+
+func NewTaxService(taxRuleRepo repositories.TaxRuleRepository) *TaxService {
+	return &TaxService{taxRuleRepo: taxRuleRepo}
+}
+
+// CreateRule valida y persiste una regla impositiva
+func (s *TaxService) CreateRule(ctx context.Context, rule *domain.TaxRule) error {
+	rule.Country = strings.ToUpper(strings.TrimSpace(rule.Country))
+	rule.TaxClass = strings.ToUpper(strings.TrimSpace(rule.TaxClass))
+	if len(rule.Country) != 2 {
+		return fmt.Errorf("%w: country must be an ISO 3166-1 alpha-2 code", ErrInvalidTaxRule)
+	}
+	if rule.TaxClass == "" || strings.TrimSpace(rule.Name) == "" {
+		return fmt.Errorf("%w: tax_class and name are required", ErrInvalidTaxRule)
+	}
+	if rule.Rate < 0 || rule.Rate >= 1 {
+		return fmt.Errorf("%w: rate must be a fraction between 0 and 1", ErrInvalidTaxRule)
+	}
+	return s.taxRuleRepo.Create(ctx, rule)
+}
+
+func (s *TaxService) GetAllRules(ctx context.Context) ([]domain.TaxRule, error) {
+	return s.taxRuleRepo.GetAll(ctx)
+}
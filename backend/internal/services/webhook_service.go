@@ -0,0 +1,380 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"order-management-system/internal/backoff"
+	"order-management-system/internal/clock"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/logging"
+	"order-management-system/internal/repositories"
+	"order-management-system/internal/webhooks"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook subscription not found")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrInvalidWebhook   = errors.New("invalid webhook subscription")
+)
+
+// webhookEventTypes son los eventos a los que se puede suscribir un partner
+var webhookEventTypes = map[domain.EventType]bool{
+	domain.EventOrderCreated:   true,
+	domain.EventOrderConfirmed: true,
+	domain.EventOrderShipped:   true,
+	domain.EventOrderCancelled: true,
+	domain.EventOrderMerged:    true,
+	domain.EventStockChanged:   true,
+}
+
+const (
+	webhookBatchSize     = 50
+	webhookDeliveryLimit = 100
+)
+
+// WebhookService administra las suscripciones y entrega los eventos del outbox a cada una,
+// con reintentos y backoff exponencial propios por suscripción.
+type WebhookService struct {
+	subscriptionRepo repositories.WebhookRepository
+	deliveryRepo     repositories.WebhookDeliveryRepository
+	sender           webhooks.Sender
+
+	maxAttempts      int
+	baseBackoff      time.Duration
+	maxBackoff       time.Duration
+	disableThreshold int
+	now              func() time.Time
+	resolver         webhooks.Resolver
+	allowPrivate     bool
+
+	delivering sync.Mutex
+}
+
+// WebhookServiceOption configura parámetros opcionales del WebhookService
+type WebhookServiceOption func(*WebhookService)
+
+// WithWebhookRetry define los intentos por entrega y el backoff exponencial entre ellos
+func WithWebhookRetry(maxAttempts int, baseBackoff, maxBackoff time.Duration) WebhookServiceOption {
+	return func(s *WebhookService) {
+		s.maxAttempts = maxAttempts
+		s.baseBackoff = baseBackoff
+		s.maxBackoff = maxBackoff
+	}
+}
+
+// WithDisableThreshold define tras cuántos intentos fallidos consecutivos se deshabilita una suscripción
+func WithDisableThreshold(failures int) WebhookServiceOption {
+	return func(s *WebhookService) {
+		s.disableThreshold = failures
+	}
+}
+
+// WithWebhookClock reemplaza el reloj usado para programar reintentos
+func WithWebhookClock(clk clock.Clock) WebhookServiceOption {
+	return func(s *WebhookService) {
+		s.now = clk.Now
+	}
+}
+
+// WithWebhookResolver reemplaza el resolver DNS con el que se validan las URLs de las suscripciones
+func WithWebhookResolver(resolver webhooks.Resolver) WebhookServiceOption {
+	return func(s *WebhookService) {
+		s.resolver = resolver
+	}
+}
+
+// WithPrivateWebhookTargets acepta suscripciones a direcciones internas; sólo para desarrollo local
+func WithPrivateWebhookTargets() WebhookServiceOption {
+	return func(s *WebhookService) {
+		s.allowPrivate = true
+	}
+}
+
+func NewWebhookService(
+	subscriptionRepo repositories.WebhookRepository,
+	deliveryRepo repositories.WebhookDeliveryRepository,
+	sender webhooks.Sender,
+	opts ...WebhookServiceOption,
+) *WebhookService {
+	s := &WebhookService{
+		subscriptionRepo: subscriptionRepo,
+		deliveryRepo:     deliveryRepo,
+		sender:           sender,
+		maxAttempts:      8,
+		baseBackoff:      30 * time.Second,
+		maxBackoff:       time.Hour,
+		disableThreshold: 20,
+		now:              clock.System().Now,
+		resolver:         net.DefaultResolver,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// CreateSubscription registra un endpoint; si no se indica secreto se genera uno.
+// El secreto sólo se devuelve en la respuesta de alta.
+func (s *WebhookService) CreateSubscription(ctx context.Context, req domain.CreateWebhookRequest) (*domain.WebhookSubscription, error) {
+	if err := s.validateWebhook(ctx, req.URL, req.EventTypes); err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		buf := make([]byte, 24)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		secret = "whsec_" + hex.EncodeToString(buf)
+	}
+
+	subscription := &domain.WebhookSubscription{
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		Secret:     secret,
+	}
+	if err := s.subscriptionRepo.Create(ctx, subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (s *WebhookService) GetSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	subscriptions, err := s.subscriptionRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+	return subscriptions, nil
+}
+
+func (s *WebhookService) GetSubscription(ctx context.Context, id uint) (*domain.WebhookSubscription, error) {
+	subscription, err := s.subscriptionRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrWebhookNotFound
+	}
+	subscription.Secret = ""
+	return subscription, nil
+}
+
+// UpdateSubscription cambia URL y eventos; volver a habilitarla reinicia el contador de fallas
+func (s *WebhookService) UpdateSubscription(ctx context.Context, id uint, req domain.UpdateWebhookRequest) (*domain.WebhookSubscription, error) {
+	subscription, err := s.subscriptionRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrWebhookNotFound
+	}
+	if err := s.validateWebhook(ctx, req.URL, req.EventTypes); err != nil {
+		return nil, err
+	}
+
+	subscription.URL = req.URL
+	subscription.EventTypes = req.EventTypes
+	if subscription.Disabled && !req.Disabled {
+		subscription.ConsecutiveFailures = 0
+		subscription.DisabledAt = nil
+	} else if !subscription.Disabled && req.Disabled {
+		now := s.now()
+		subscription.DisabledAt = &now
+	}
+	subscription.Disabled = req.Disabled
+
+	if err := s.subscriptionRepo.Update(ctx, subscription); err != nil {
+		return nil, err
+	}
+	subscription.Secret = ""
+	return subscription, nil
+}
+
+func (s *WebhookService) DeleteSubscription(ctx context.Context, id uint) error {
+	if _, err := s.subscriptionRepo.GetByID(ctx, id); err != nil {
+		return ErrWebhookNotFound
+	}
+	return s.subscriptionRepo.Delete(ctx, id)
+}
+
+// GetDeliveries devuelve el registro de entregas de una suscripción, las más recientes primero
+func (s *WebhookService) GetDeliveries(ctx context.Context, subscriptionID uint) ([]domain.WebhookDelivery, error) {
+	if _, err := s.subscriptionRepo.GetByID(ctx, subscriptionID); err != nil {
+		return nil, ErrWebhookNotFound
+	}
+	return s.deliveryRepo.GetBySubscription(ctx, subscriptionID, webhookDeliveryLimit)
+}
+
+// HandleEvent es el suscriptor del outbox: crea una entrega por cada suscripción habilitada
+// interesada en el evento. Como el outbox puede repetir eventos, no duplica entregas.
+func (s *WebhookService) HandleEvent(ctx context.Context, event domain.OutboxEvent) error {
+	subscriptions, err := s.subscriptionRepo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, subscription := range subscriptions {
+		if subscription.Disabled || !subscription.EventTypes.Includes(event.EventType) {
+			continue
+		}
+		exists, err := s.deliveryRepo.ExistsForEvent(ctx, subscription.ID, event.ID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+
+		delivery := &domain.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.EventType,
+			Payload:        webhookBody(event),
+			Status:         domain.WebhookDeliveryPending,
+			NextAttemptAt:  s.now(),
+		}
+		if err := s.deliveryRepo.Create(ctx, delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Redeliver reenvía en el momento el evento de una entrega anterior como una entrega nueva
+func (s *WebhookService) Redeliver(ctx context.Context, subscriptionID, deliveryID uint) (*domain.WebhookDelivery, error) {
+	subscription, err := s.subscriptionRepo.GetByID(ctx, subscriptionID)
+	if err != nil {
+		return nil, ErrWebhookNotFound
+	}
+	original, err := s.deliveryRepo.GetByID(ctx, deliveryID)
+	if err != nil || original.SubscriptionID != subscription.ID {
+		return nil, ErrDeliveryNotFound
+	}
+
+	delivery := &domain.WebhookDelivery{
+		SubscriptionID: subscription.ID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		Status:         domain.WebhookDeliveryPending,
+		NextAttemptAt:  s.now(),
+	}
+	if err := s.deliveryRepo.Create(ctx, delivery); err != nil {
+		return nil, err
+	}
+	if err := s.attempt(ctx, subscription, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// DeliverDue envía las entregas pendientes cuyo próximo intento ya venció
+func (s *WebhookService) DeliverDue(ctx context.Context) error {
+	s.delivering.Lock()
+	defer s.delivering.Unlock()
+
+	deliveries, err := s.deliveryRepo.GetDue(ctx, s.now(), webhookBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		subscription, err := s.subscriptionRepo.GetByID(ctx, delivery.SubscriptionID)
+		if err != nil || subscription.Disabled {
+			delivery.Status = domain.WebhookDeliveryFailed
+			delivery.LastError = "subscription disabled"
+			if err := s.deliveryRepo.Update(ctx, delivery); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := s.attempt(ctx, subscription, delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attempt envía la entrega, registra el resultado y actualiza el contador de fallas de la suscripción
+func (s *WebhookService) attempt(ctx context.Context, subscription *domain.WebhookSubscription, delivery *domain.WebhookDelivery) error {
+	now := s.now()
+	result := s.sender.Send(subscription.URL, subscription.Secret, webhooks.Message{
+		ID:        strconv.FormatUint(uint64(delivery.ID), 10),
+		Event:     string(delivery.EventType),
+		Body:      []byte(delivery.Payload),
+		Timestamp: now,
+	})
+
+	delivery.Attempts++
+	delivery.ResponseStatus = result.StatusCode
+	delivery.ResponseBody = result.Body
+
+	if result.Err == nil {
+		delivery.Status = domain.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		subscription.ConsecutiveFailures = 0
+	} else {
+		delivery.LastError = result.Err.Error()
+		if delivery.Attempts >= s.maxAttempts {
+			delivery.Status = domain.WebhookDeliveryFailed
+		} else {
+			delivery.NextAttemptAt = now.Add(backoff.Exponential(s.baseBackoff, s.maxBackoff, delivery.Attempts))
+		}
+
+		subscription.ConsecutiveFailures++
+		if !subscription.Disabled && s.disableThreshold > 0 && subscription.ConsecutiveFailures >= s.disableThreshold {
+			subscription.Disabled = true
+			subscription.DisabledAt = &now
+			logging.For(ctx, "webhooks").Warn("subscription disabled", "subscription_id", subscription.ID, "consecutive_failures", subscription.ConsecutiveFailures)
+		}
+	}
+
+	if err := s.deliveryRepo.Update(ctx, delivery); err != nil {
+		return err
+	}
+	return s.subscriptionRepo.Update(ctx, subscription)
+}
+
+// webhookBody es el cuerpo JSON que reciben los partners
+func webhookBody(event domain.OutboxEvent) string {
+	data, err := event.PublicPayload()
+	if err != nil {
+		data = json.RawMessage(event.Payload)
+	}
+	body, _ := json.Marshal(struct {
+		ID        uint             `json:"id"`
+		Type      domain.EventType `json:"type"`
+		CreatedAt time.Time        `json:"created_at"`
+		Data      json.RawMessage  `json:"data"`
+	}{event.ID, event.EventType, event.CreatedAt, data})
+	return string(body)
+}
+
+// validateWebhook exige una URL http(s) absoluta a una dirección pública y eventos conocidos
+func (s *WebhookService) validateWebhook(ctx context.Context, rawURL string, eventTypes []domain.EventType) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidWebhook)
+	}
+	// El HTTPSender vuelve a validar la IP al conectar, por si el DNS cambia después del alta
+	if !s.allowPrivate {
+		if err := webhooks.CheckHost(ctx, s.resolver, parsed.Hostname()); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+		}
+	}
+	for _, eventType := range eventTypes {
+		if !webhookEventTypes[eventType] {
+			return fmt.Errorf("%w: unknown event type %q", ErrInvalidWebhook, eventType)
+		}
+	}
+	return nil
+}
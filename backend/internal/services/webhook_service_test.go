@@ -0,0 +1,334 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"order-management-system/internal/clock"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/webhooks"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mockWebhookRepository struct {
+	subscriptions map[uint]*domain.WebhookSubscription
+	nextID        uint
+}
+
+func (m *mockWebhookRepository) Create(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	m.nextID++
+	subscription.ID = m.nextID
+	stored := *subscription
+	m.subscriptions[subscription.ID] = &stored
+	return nil
+}
+
+func (m *mockWebhookRepository) GetByID(ctx context.Context, id uint) (*domain.WebhookSubscription, error) {
+	if subscription, ok := m.subscriptions[id]; ok {
+		found := *subscription
+		return &found, nil
+	}
+	return nil, errors.New("subscription not found")
+}
+
+func (m *mockWebhookRepository) GetAll(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	var subscriptions []domain.WebhookSubscription
+	for id := uint(1); id <= m.nextID; id++ {
+		if subscription, ok := m.subscriptions[id]; ok {
+			subscriptions = append(subscriptions, *subscription)
+		}
+	}
+	return subscriptions, nil
+}
+
+func (m *mockWebhookRepository) Update(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	stored := *subscription
+	m.subscriptions[subscription.ID] = &stored
+	return nil
+}
+
+func (m *mockWebhookRepository) Delete(ctx context.Context, id uint) error {
+	delete(m.subscriptions, id)
+	return nil
+}
+
+type mockWebhookDeliveryRepository struct {
+	deliveries []domain.WebhookDelivery
+}
+
+func (m *mockWebhookDeliveryRepository) Create(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	delivery.ID = uint(len(m.deliveries) + 1)
+	m.deliveries = append(m.deliveries, *delivery)
+	return nil
+}
+
+func (m *mockWebhookDeliveryRepository) GetByID(ctx context.Context, id uint) (*domain.WebhookDelivery, error) {
+	if id == 0 || int(id) > len(m.deliveries) {
+		return nil, errors.New("delivery not found")
+	}
+	found := m.deliveries[id-1]
+	return &found, nil
+}
+
+func (m *mockWebhookDeliveryRepository) GetBySubscription(ctx context.Context, subscriptionID uint, limit int) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+	for i := len(m.deliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		if m.deliveries[i].SubscriptionID == subscriptionID {
+			deliveries = append(deliveries, m.deliveries[i])
+		}
+	}
+	return deliveries, nil
+}
+
+func (m *mockWebhookDeliveryRepository) ExistsForEvent(ctx context.Context, subscriptionID, eventID uint) (bool, error) {
+	for _, d := range m.deliveries {
+		if d.SubscriptionID == subscriptionID && d.EventID == eventID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockWebhookDeliveryRepository) GetDue(ctx context.Context, now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	var due []domain.WebhookDelivery
+	for _, d := range m.deliveries {
+		if d.Status == domain.WebhookDeliveryPending && !d.NextAttemptAt.After(now) && len(due) < limit {
+			due = append(due, d)
+		}
+	}
+	return due, nil
+}
+
+func (m *mockWebhookDeliveryRepository) Update(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	m.deliveries[delivery.ID-1] = *delivery
+	return nil
+}
+
+// partnerServer simula el endpoint de un partner que valida la firma y puede fallar a pedido
+type partnerServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	secret   string
+	failing  bool
+	received []map[string]interface{}
+}
+
+func newPartnerServer(t *testing.T, secret string) *partnerServer {
+	p := &partnerServer{secret: secret}
+	p.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		body, _ := io.ReadAll(r.Body)
+		if !webhooks.Verify(p.secret, r.Header.Get(webhooks.HeaderTimestamp), r.Header.Get(webhooks.HeaderSignature), body) {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		if p.failing {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		var payload map[string]interface{}
+		json.Unmarshal(body, &payload)
+		p.received = append(p.received, payload)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(p.Close)
+	return p
+}
+
+func (p *partnerServer) setFailing(failing bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.failing = failing
+}
+
+func setupWebhookService(t *testing.T, opts ...WebhookServiceOption) (*WebhookService, *mockWebhookDeliveryRepository, *clock.Fake) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	deliveryRepo := &mockWebhookDeliveryRepository{}
+	opts = append([]WebhookServiceOption{
+		WithWebhookClock(clk),
+		WithWebhookRetry(3, time.Minute, time.Hour),
+		WithWebhookResolver(staticResolver{
+			"example.com":       {{IP: net.ParseIP("93.184.216.34")}},
+			"metadata.internal": {{IP: net.ParseIP("169.254.169.254")}},
+		}),
+	}, opts...)
+	service := NewWebhookService(
+		&mockWebhookRepository{subscriptions: make(map[uint]*domain.WebhookSubscription)},
+		deliveryRepo,
+		webhooks.NewHTTPSender(nil, webhooks.WithPrivateNetworks()),
+		opts...,
+	)
+	return service, deliveryRepo, clk
+}
+
+func shippedEvent(t *testing.T, id uint) domain.OutboxEvent {
+	t.Helper()
+	event, err := domain.NewOutboxEvent(domain.EventOrderShipped, "order", 7, domain.OrderEvent{OrderID: 7, PublicID: "ord_7", Number: "ORD-000007", Status: domain.StatusShipped})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	event.ID = id
+	return *event
+}
+
+func TestWebhooks_DeliversSignedEvent(t *testing.T) {
+	ctx := context.Background()
+	service, deliveryRepo, _ := setupWebhookService(t, WithPrivateWebhookTargets())
+	partner := newPartnerServer(t, "s3cret")
+
+	subscription, err := service.CreateSubscription(ctx, domain.CreateWebhookRequest{
+		URL:        partner.URL,
+		EventTypes: []domain.EventType{domain.EventOrderShipped},
+		Secret:     "s3cret",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Un evento repetido por el outbox no duplica la entrega, y los eventos no suscriptos se ignoran
+	event := shippedEvent(t, 1)
+	service.HandleEvent(ctx, event)
+	service.HandleEvent(ctx, event)
+	created, _ := domain.NewOutboxEvent(domain.EventOrderCreated, "order", 7, domain.OrderEvent{OrderID: 7})
+	created.ID = 2
+	service.HandleEvent(ctx, *created)
+	if len(deliveryRepo.deliveries) != 1 {
+		t.Fatalf("Expected 1 delivery, got %d", len(deliveryRepo.deliveries))
+	}
+
+	if err := service.DeliverDue(ctx); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	deliveries, _ := service.GetDeliveries(ctx, subscription.ID)
+	if deliveries[0].Status != domain.WebhookDeliverySucceeded || deliveries[0].ResponseStatus != http.StatusNoContent {
+		t.Errorf("Expected succeeded delivery, got %+v", deliveries[0])
+	}
+	if len(partner.received) != 1 || partner.received[0]["type"] != "order.shipped" {
+		t.Fatalf("Expected partner to receive order.shipped, got %v", partner.received)
+	}
+	data := partner.received[0]["data"].(map[string]interface{})
+	if data["public_id"] != "ord_7" || data["number"] != "ORD-000007" {
+		t.Errorf("Expected order ORD-000007 in payload, got %v", data)
+	}
+	if _, ok := data["order_id"]; ok {
+		t.Errorf("Expected no numeric order id in payload, got %v", data)
+	}
+}
+
+func TestWebhookBody_OmitsNumericOrderID(t *testing.T) {
+	stock, _ := domain.NewOutboxEvent(domain.EventStockChanged, "product", 3, domain.StockChangedEvent{ProductID: 3, OrderID: 7, OrderNumber: "ORD-000007", PreviousStock: 5, Stock: 4})
+	for _, event := range []domain.OutboxEvent{shippedEvent(t, 1), *stock} {
+		var body struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(webhookBody(event)), &body); err != nil {
+			t.Fatalf("Expected JSON body, got %v", err)
+		}
+		if _, ok := body.Data["order_id"]; ok {
+			t.Errorf("Expected no order_id in %s, got %v", event.EventType, body.Data)
+		}
+		if body.Data["number"] != "ORD-000007" && body.Data["order_number"] != "ORD-000007" {
+			t.Errorf("Expected the order number in %s, got %v", event.EventType, body.Data)
+		}
+	}
+}
+
+func TestWebhooks_RetriesWithBackoffAndDisables(t *testing.T) {
+	ctx := context.Background()
+	service, deliveryRepo, clk := setupWebhookService(t, WithDisableThreshold(3), WithPrivateWebhookTargets())
+	partner := newPartnerServer(t, "s3cret")
+	partner.setFailing(true)
+
+	subscription, _ := service.CreateSubscription(ctx, domain.CreateWebhookRequest{URL: partner.URL, Secret: "s3cret"})
+	service.HandleEvent(ctx, shippedEvent(t, 1))
+
+	service.DeliverDue(ctx)
+	delivery := deliveryRepo.deliveries[0]
+	if delivery.Status != domain.WebhookDeliveryPending || delivery.Attempts != 1 || delivery.ResponseStatus != http.StatusServiceUnavailable {
+		t.Fatalf("Expected pending retry after 503, got %+v", delivery)
+	}
+	if !delivery.NextAttemptAt.Equal(clk.Now().Add(time.Minute)) {
+		t.Errorf("Expected retry in 1m, got %v", delivery.NextAttemptAt.Sub(clk.Now()))
+	}
+
+	clk.Advance(time.Minute)
+	service.DeliverDue(ctx)
+	if delivery = deliveryRepo.deliveries[0]; !delivery.NextAttemptAt.Equal(clk.Now().Add(2 * time.Minute)) {
+		t.Errorf("Expected retry in 2m, got %v", delivery.NextAttemptAt.Sub(clk.Now()))
+	}
+
+	clk.Advance(2 * time.Minute)
+	service.DeliverDue(ctx)
+	if delivery = deliveryRepo.deliveries[0]; delivery.Status != domain.WebhookDeliveryFailed || delivery.Attempts != 3 {
+		t.Errorf("Expected FAILED after 3 attempts, got %+v", delivery)
+	}
+
+	stored, _ := service.GetSubscription(ctx, subscription.ID)
+	if !stored.Disabled || stored.ConsecutiveFailures != 3 || stored.Secret != "" {
+		t.Errorf("Expected subscription disabled with secret hidden, got %+v", stored)
+	}
+
+	// Deshabilitada no recibe eventos nuevos
+	service.HandleEvent(ctx, shippedEvent(t, 2))
+	if len(deliveryRepo.deliveries) != 1 {
+		t.Errorf("Expected no delivery for disabled subscription, got %d", len(deliveryRepo.deliveries))
+	}
+
+	// El reenvío manual funciona aunque esté deshabilitada y reinicia el contador
+	partner.setFailing(false)
+	redelivered, err := service.Redeliver(ctx, subscription.ID, delivery.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if redelivered.Status != domain.WebhookDeliverySucceeded || redelivered.ID == delivery.ID {
+		t.Errorf("Expected new succeeded delivery, got %+v", redelivered)
+	}
+
+	enabled, err := service.UpdateSubscription(ctx, subscription.ID, domain.UpdateWebhookRequest{URL: partner.URL})
+	if err != nil || enabled.Disabled || enabled.ConsecutiveFailures != 0 {
+		t.Errorf("Expected subscription re-enabled, got %+v (%v)", enabled, err)
+	}
+}
+
+func TestCreateSubscription_Validation(t *testing.T) {
+	ctx := context.Background()
+	service, _, _ := setupWebhookService(t)
+
+	if _, err := service.CreateSubscription(ctx, domain.CreateWebhookRequest{URL: "ftp://example.com"}); !errors.Is(err, ErrInvalidWebhook) {
+		t.Errorf("Expected ErrInvalidWebhook for ftp URL, got %v", err)
+	}
+	if _, err := service.CreateSubscription(ctx, domain.CreateWebhookRequest{
+		URL:        "https://example.com/hook",
+		EventTypes: []domain.EventType{"order.exploded"},
+	}); !errors.Is(err, ErrInvalidWebhook) {
+		t.Errorf("Expected ErrInvalidWebhook for unknown event, got %v", err)
+	}
+
+	for _, target := range []string{"http://127.0.0.1:8080/hook", "http://169.254.169.254/latest/meta-data", "http://[::1]/hook", "http://10.0.0.5/hook", "http://metadata.internal/hook"} {
+		if _, err := service.CreateSubscription(ctx, domain.CreateWebhookRequest{URL: target}); !errors.Is(err, ErrInvalidWebhook) {
+			t.Errorf("Expected ErrInvalidWebhook for internal target %s, got %v", target, err)
+		}
+	}
+
+	subscription, err := service.CreateSubscription(ctx, domain.CreateWebhookRequest{URL: "https://example.com/hook"})
+	if err != nil || len(subscription.Secret) < 20 {
+		t.Errorf("Expected generated secret, got %+v (%v)", subscription, err)
+	}
+}
+
+type staticResolver map[string][]net.IPAddr
+
+func (r staticResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	if addrs, ok := r[host]; ok {
+		return addrs, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
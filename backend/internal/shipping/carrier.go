@@ -0,0 +1,102 @@
+// Package shipping define la integración con transportistas: cotización de
+// tarifas, generación de etiquetas y seguimiento de envíos.
+package shipping
+
+import (
+	"context"
+	"errors"
+	"order-management-system/internal/domain"
+	"time"
+)
+
+var (
+	ErrNoRates            = errors.New("no shipping rates available for destination")
+	ErrMethodUnavailable  = errors.New("shipping method not available")
+	ErrTrackingNotFound   = errors.New("tracking number not found")
+	ErrCarrierUnavailable = errors.New("carrier unavailable")
+)
+
+const (
+	MethodStandard = "STANDARD"
+	MethodExpress  = "EXPRESS"
+)
+
+const (
+	TrackingLabelCreated = "LABEL_CREATED"
+	TrackingInTransit    = "IN_TRANSIT"
+	TrackingDelivered    = "DELIVERED"
+	TrackingVoided       = "VOIDED"
+)
+
+// Parcel es un bulto a enviar.
+type Parcel struct {
+	WeightGrams int     `json:"weight_grams"`
+	LengthCm    float64 `json:"length_cm"`
+	WidthCm     float64 `json:"width_cm"`
+	HeightCm    float64 `json:"height_cm"`
+}
+
+// Rate es una tarifa cotizada para un método de envío.
+type Rate struct {
+	Carrier       string  `json:"carrier"`
+	Method        string  `json:"method"`
+	Description   string  `json:"description"`
+	Amount        float64 `json:"amount"`
+	EstimatedDays int     `json:"estimated_days"`
+}
+
+// LabelRequest contiene los datos para generar la etiqueta de un envío.
+type LabelRequest struct {
+	Reference   string                 `json:"reference"`
+	Method      string                 `json:"method"`
+	Destination domain.AddressSnapshot `json:"destination"`
+	Parcels     []Parcel               `json:"parcels"`
+}
+
+// Label es la etiqueta generada por el transportista.
+type Label struct {
+	TrackingNumber string  `json:"tracking_number"`
+	LabelURL       string  `json:"label_url"`
+	Amount         float64 `json:"amount"`
+}
+
+// TrackingStatus es el último estado conocido de un envío.
+type TrackingStatus struct {
+	TrackingNumber string    `json:"tracking_number"`
+	Status         string    `json:"status"`
+	Description    string    `json:"description"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Carrier es la interfaz que implementa cada transportista.
+type Carrier interface {
+	Name() string
+	QuoteRates(ctx context.Context, destination domain.AddressSnapshot, parcels []Parcel) ([]Rate, error)
+	CreateLabel(ctx context.Context, req LabelRequest) (*Label, error)
+	// VoidLabel anula una etiqueta que no llegó a usarse
+	VoidLabel(ctx context.Context, trackingNumber string) error
+	GetTrackingStatus(ctx context.Context, trackingNumber string) (*TrackingStatus, error)
+}
+
+// ChargeableWeight devuelve el mayor entre el peso real y el volumétrico (divisor 5000 cm³/kg)
+func ChargeableWeight(parcels []Parcel) int {
+	var actual, volumetric float64
+	for _, p := range parcels {
+		actual += float64(p.WeightGrams)
+		volumetric += p.LengthCm * p.WidthCm * p.HeightCm / 5
+	}
+	if volumetric > actual {
+		return int(volumetric + 0.5)
+	}
+	return int(actual)
+}
+
+// FindRate busca la tarifa del método indicado
+func FindRate(rates []Rate, method string) (*Rate, error) {
+	for i := range rates {
+		if rates[i].Method == method {
+			return &rates[i], nil
+		}
+	}
+	return nil, ErrMethodUnavailable
+}
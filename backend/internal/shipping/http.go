@@ -0,0 +1,132 @@
+package shipping
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"order-management-system/internal/domain"
+	"strings"
+	"time"
+)
+
+// HTTPCarrier integra un transportista externo con una API JSON:
+//
+//	POST /rates              {destination, parcels}  -> {rates: [...]}
+//	POST /labels             LabelRequest            -> Label
+//	POST /labels/{number}/void                       -> 204
+//	GET  /tracking/{number}                          -> TrackingStatus
+type HTTPCarrier struct {
+	name    string
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+func NewHTTPCarrier(name, baseURL, apiKey string, client *http.Client) *HTTPCarrier {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &HTTPCarrier{
+		name:    name,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		client:  client,
+	}
+}
+
+func (c *HTTPCarrier) Name() string {
+	return c.name
+}
+
+func (c *HTTPCarrier) QuoteRates(ctx context.Context, destination domain.AddressSnapshot, parcels []Parcel) ([]Rate, error) {
+	body := struct {
+		Destination domain.AddressSnapshot `json:"destination"`
+		Parcels     []Parcel               `json:"parcels"`
+	}{destination, parcels}
+
+	var response struct {
+		Rates []Rate `json:"rates"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/rates", body, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Rates) == 0 {
+		return nil, ErrNoRates
+	}
+	for i := range response.Rates {
+		response.Rates[i].Carrier = c.name
+	}
+	return response.Rates, nil
+}
+
+func (c *HTTPCarrier) CreateLabel(ctx context.Context, req LabelRequest) (*Label, error) {
+	var label Label
+	if err := c.do(ctx, http.MethodPost, "/labels", req, &label); err != nil {
+		return nil, err
+	}
+	return &label, nil
+}
+
+func (c *HTTPCarrier) VoidLabel(ctx context.Context, trackingNumber string) error {
+	return c.do(ctx, http.MethodPost, "/labels/"+url.PathEscape(trackingNumber)+"/void", nil, nil)
+}
+
+func (c *HTTPCarrier) GetTrackingStatus(ctx context.Context, trackingNumber string) (*TrackingStatus, error) {
+	var status TrackingStatus
+	if err := c.do(ctx, http.MethodGet, "/tracking/"+url.PathEscape(trackingNumber), nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (c *HTTPCarrier) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader *bytes.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCarrierUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound && (strings.HasPrefix(path, "/tracking/") || strings.HasSuffix(path, "/void")):
+		return ErrTrackingNotFound
+	case resp.StatusCode == http.StatusUnprocessableEntity:
+		return ErrMethodUnavailable
+	case resp.StatusCode >= 500:
+		return fmt.Errorf("%w: status %d", ErrCarrierUnavailable, resp.StatusCode)
+	case resp.StatusCode >= 300:
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("carrier %s returned %d: %s", c.name, resp.StatusCode, apiErr.Error)
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,67 @@
+package shipping_test
+
+import (
+	"context"
+	"errors"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/shipping"
+	"order-management-system/internal/shipping/shippingtest"
+	"testing"
+)
+
+func TestHTTPCarrier_AgainstFakeServer(t *testing.T) {
+	ctx := context.Background()
+	server := shippingtest.NewServer("secret")
+	defer server.Close()
+
+	carrier := shipping.NewHTTPCarrier("fake", server.URL, "secret", server.Client())
+	destination := domain.AddressSnapshot{Country: "AR", Region: "CABA", City: "Buenos Aires", Line1: "Av. Santa Fe 2500"}
+	parcels := []shipping.Parcel{{WeightGrams: 700, LengthCm: 25, WidthCm: 20, HeightCm: 10}}
+
+	rates, err := carrier.QuoteRates(ctx, destination, parcels)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(rates) != 2 || rates[0].Carrier != "fake" {
+		t.Fatalf("Expected 2 rates from fake carrier, got %+v", rates)
+	}
+
+	label, err := carrier.CreateLabel(ctx, shipping.LabelRequest{Reference: "order-7", Method: shipping.MethodStandard, Destination: destination, Parcels: parcels})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if label.TrackingNumber == "" || len(server.Labels()) != 1 {
+		t.Errorf("Expected label to be registered, got %+v", label)
+	}
+
+	status, err := carrier.GetTrackingStatus(ctx, label.TrackingNumber)
+	if err != nil || status.Status != shipping.TrackingLabelCreated {
+		t.Errorf("Expected LABEL_CREATED, got %+v (%v)", status, err)
+	}
+
+	if _, err := carrier.GetTrackingStatus(ctx, "missing"); err != shipping.ErrTrackingNotFound {
+		t.Errorf("Expected ErrTrackingNotFound, got %v", err)
+	}
+
+	if _, err := carrier.CreateLabel(ctx, shipping.LabelRequest{Method: "DRONE", Destination: destination, Parcels: parcels}); err != shipping.ErrMethodUnavailable {
+		t.Errorf("Expected ErrMethodUnavailable, got %v", err)
+	}
+}
+
+func TestHTTPCarrier_Failures(t *testing.T) {
+	ctx := context.Background()
+	server := shippingtest.NewServer("secret")
+	defer server.Close()
+	destination := domain.AddressSnapshot{Country: "AR"}
+
+	unauthorized := shipping.NewHTTPCarrier("fake", server.URL, "wrong", server.Client())
+	if _, err := unauthorized.QuoteRates(ctx, destination, nil); err == nil {
+		t.Errorf("Expected error with invalid API key")
+	}
+
+	server.SetFailing(true)
+	carrier := shipping.NewHTTPCarrier("fake", server.URL, "secret", server.Client())
+	if _, err := carrier.QuoteRates(ctx, destination, nil); !errors.Is(err, shipping.ErrCarrierUnavailable) {
+		t.Errorf("Expected ErrCarrierUnavailable, got %v", err)
+	}
+}
@@ -0,0 +1,255 @@
+package shipping
+
+import (
+	"context"
+	"fmt"
+	"order-management-system/internal/clock"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/ids"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ZoneRule asigna una zona a un destino. Region vacío aplica a todo el país
+// y Country vacío a cualquier destino.
+type ZoneRule struct {
+	Country string
+	Region  string
+	Zone    int
+}
+
+// RateRow es una fila de la tabla de tarifas: precio para un método y zona hasta un peso máximo.
+type RateRow struct {
+	Method         string
+	Zone           int
+	MaxWeightGrams int
+	Amount         float64
+	EstimatedDays  int
+}
+
+// LabelStore guarda las etiquetas que emite el LocalCarrier.
+// repositories.ShippingLabelRepository la implementa sobre la base.
+type LabelStore interface {
+	Create(ctx context.Context, label *domain.ShippingLabel) error
+	// GetByTrackingNumber devuelve nil sin error si la etiqueta no existe
+	GetByTrackingNumber(ctx context.Context, trackingNumber string) (*domain.ShippingLabel, error)
+	Update(ctx context.Context, label *domain.ShippingLabel) error
+}
+
+// LocalCarrier cotiza con tablas de zonas y pesos, sin llamadas externas.
+type LocalCarrier struct {
+	zones    []ZoneRule
+	rates    []RateRow
+	labels   LabelStore
+	trackIDs ids.Generator
+}
+
+// LocalOption configura parámetros opcionales del LocalCarrier
+type LocalOption func(*LocalCarrier)
+
+// WithLabelStore persiste las etiquetas; sin ella se guardan en memoria y el
+// seguimiento se pierde al reiniciar
+func WithLabelStore(store LabelStore) LocalOption {
+	return func(c *LocalCarrier) {
+		c.labels = store
+	}
+}
+
+func NewLocalCarrier(zones []ZoneRule, rates []RateRow, opts ...LocalOption) *LocalCarrier {
+	c := &LocalCarrier{
+		zones:    zones,
+		rates:    rates,
+		labels:   newMemoryLabelStore(),
+		trackIDs: ids.NewULIDGenerator(clock.System()),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// DefaultLocalCarrier usa zonas para Argentina (AMBA, resto del país) y una zona internacional
+func DefaultLocalCarrier(opts ...LocalOption) *LocalCarrier {
+	zones := []ZoneRule{
+		{Country: "AR", Region: "CABA", Zone: 1},
+		{Country: "AR", Region: "Buenos Aires", Zone: 1},
+		{Country: "AR", Zone: 2},
+		{Zone: 3},
+	}
+	var rates []RateRow
+	brackets := []struct {
+		maxWeight int
+		base      float64
+	}{{1000, 3500}, {5000, 6000}, {20000, 12000}, {50000, 25000}}
+	for zone := 1; zone <= 3; zone++ {
+		for _, b := range brackets {
+			factor := float64(zone)
+			rates = append(rates,
+				RateRow{Method: MethodStandard, Zone: zone, MaxWeightGrams: b.maxWeight, Amount: b.base * factor, EstimatedDays: 2 + 3*zone},
+				RateRow{Method: MethodExpress, Zone: zone, MaxWeightGrams: b.maxWeight, Amount: b.base * factor * 1.8, EstimatedDays: zone},
+			)
+		}
+	}
+	return NewLocalCarrier(zones, rates, opts...)
+}
+
+func (c *LocalCarrier) Name() string {
+	return "local"
+}
+
+func (c *LocalCarrier) QuoteRates(ctx context.Context, destination domain.AddressSnapshot, parcels []Parcel) ([]Rate, error) {
+	zone, ok := c.zoneFor(destination)
+	if !ok {
+		return nil, ErrNoRates
+	}
+	weight := ChargeableWeight(parcels)
+
+	best := make(map[string]RateRow)
+	for _, row := range c.rates {
+		if row.Zone != zone || weight > row.MaxWeightGrams {
+			continue
+		}
+		current, found := best[row.Method]
+		if !found || row.MaxWeightGrams < current.MaxWeightGrams {
+			best[row.Method] = row
+		}
+	}
+	if len(best) == 0 {
+		return nil, ErrNoRates
+	}
+
+	var rates []Rate
+	for method, row := range best {
+		rates = append(rates, Rate{
+			Carrier:       c.Name(),
+			Method:        method,
+			Description:   fmt.Sprintf("%s zona %d", strings.ToLower(method), zone),
+			Amount:        row.Amount,
+			EstimatedDays: row.EstimatedDays,
+		})
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].Amount < rates[j].Amount })
+	return rates, nil
+}
+
+func (c *LocalCarrier) CreateLabel(ctx context.Context, req LabelRequest) (*Label, error) {
+	rates, err := c.QuoteRates(ctx, req.Destination, req.Parcels)
+	if err != nil {
+		return nil, err
+	}
+	rate, err := FindRate(rates, req.Method)
+	if err != nil {
+		return nil, err
+	}
+
+	// El número sale de un ULID para no repetirse entre reinicios
+	label := &domain.ShippingLabel{
+		TrackingNumber: "LOC" + c.trackIDs.NewID(),
+		Reference:      req.Reference,
+		Method:         rate.Method,
+		Status:         TrackingLabelCreated,
+		Description:    "Etiqueta generada para " + req.Reference,
+	}
+	if err := c.labels.Create(ctx, label); err != nil {
+		return nil, err
+	}
+
+	return &Label{
+		TrackingNumber: label.TrackingNumber,
+		LabelURL:       "local://labels/" + label.TrackingNumber,
+		Amount:         rate.Amount,
+	}, nil
+}
+
+func (c *LocalCarrier) VoidLabel(ctx context.Context, trackingNumber string) error {
+	label, err := c.labels.GetByTrackingNumber(ctx, trackingNumber)
+	if err != nil {
+		return err
+	}
+	if label == nil {
+		return ErrTrackingNotFound
+	}
+	label.Status = TrackingVoided
+	label.Description = "Etiqueta anulada"
+	return c.labels.Update(ctx, label)
+}
+
+func (c *LocalCarrier) GetTrackingStatus(ctx context.Context, trackingNumber string) (*TrackingStatus, error) {
+	label, err := c.labels.GetByTrackingNumber(ctx, trackingNumber)
+	if err != nil {
+		return nil, err
+	}
+	if label == nil {
+		return nil, ErrTrackingNotFound
+	}
+	return &TrackingStatus{
+		TrackingNumber: label.TrackingNumber,
+		Status:         label.Status,
+		Description:    label.Description,
+		UpdatedAt:      label.UpdatedAt,
+	}, nil
+}
+
+func (c *LocalCarrier) zoneFor(destination domain.AddressSnapshot) (int, bool) {
+	// Gana la regla más específica: país y región, luego país, luego comodín
+	best, bestScore := 0, -1
+	for _, rule := range c.zones {
+		score := 0
+		if rule.Country != "" {
+			if !strings.EqualFold(rule.Country, destination.Country) {
+				continue
+			}
+			score++
+		}
+		if rule.Region != "" {
+			if !strings.EqualFold(rule.Region, destination.Region) {
+				continue
+			}
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = rule.Zone, score
+		}
+	}
+	return best, bestScore >= 0
+}
+
+// memoryLabelStore guarda las etiquetas en memoria, para tests y el transportista falso
+type memoryLabelStore struct {
+	mu     sync.Mutex
+	labels map[string]domain.ShippingLabel
+}
+
+func newMemoryLabelStore() *memoryLabelStore {
+	return &memoryLabelStore{labels: make(map[string]domain.ShippingLabel)}
+}
+
+func (m *memoryLabelStore) Create(ctx context.Context, label *domain.ShippingLabel) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	label.ID = uint(len(m.labels) + 1)
+	label.CreatedAt = time.Now()
+	label.UpdatedAt = label.CreatedAt
+	m.labels[label.TrackingNumber] = *label
+	return nil
+}
+
+func (m *memoryLabelStore) GetByTrackingNumber(ctx context.Context, trackingNumber string) (*domain.ShippingLabel, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	label, ok := m.labels[trackingNumber]
+	if !ok {
+		return nil, nil
+	}
+	return &label, nil
+}
+
+func (m *memoryLabelStore) Update(ctx context.Context, label *domain.ShippingLabel) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	label.UpdatedAt = time.Now()
+	m.labels[label.TrackingNumber] = *label
+	return nil
+}
@@ -0,0 +1,94 @@
+package shipping
+
+import (
+	"context"
+	"order-management-system/internal/domain"
+	"testing"
+)
+
+func TestChargeableWeight_UsesVolumetric(t *testing.T) {
+	// 50x40x30 cm = 60000 cm³ -> 12 kg volumétricos
+	parcels := []Parcel{{WeightGrams: 2000, LengthCm: 50, WidthCm: 40, HeightCm: 30}}
+	if got := ChargeableWeight(parcels); got != 12000 {
+		t.Errorf("Expected 12000 grams, got %d", got)
+	}
+
+	parcels = []Parcel{{WeightGrams: 3000, LengthCm: 10, WidthCm: 10, HeightCm: 10}}
+	if got := ChargeableWeight(parcels); got != 3000 {
+		t.Errorf("Expected actual weight 3000 grams, got %d", got)
+	}
+}
+
+func TestLocalCarrier_QuoteRatesByZoneAndWeight(t *testing.T) {
+	ctx := context.Background()
+	carrier := NewLocalCarrier(
+		[]ZoneRule{{Country: "AR", Region: "CABA", Zone: 1}, {Country: "AR", Zone: 2}},
+		[]RateRow{
+			{Method: MethodStandard, Zone: 1, MaxWeightGrams: 1000, Amount: 100},
+			{Method: MethodStandard, Zone: 1, MaxWeightGrams: 5000, Amount: 200},
+			{Method: MethodExpress, Zone: 1, MaxWeightGrams: 1000, Amount: 300},
+			{Method: MethodStandard, Zone: 2, MaxWeightGrams: 5000, Amount: 400},
+		},
+	)
+	small := []Parcel{{WeightGrams: 800, LengthCm: 10, WidthCm: 10, HeightCm: 10}}
+	medium := []Parcel{{WeightGrams: 3000, LengthCm: 10, WidthCm: 10, HeightCm: 10}}
+
+	tests := []struct {
+		name        string
+		destination domain.AddressSnapshot
+		parcels     []Parcel
+		expected    map[string]float64
+		err         error
+	}{
+		{"zone 1 small", domain.AddressSnapshot{Country: "AR", Region: "caba"}, small, map[string]float64{MethodStandard: 100, MethodExpress: 300}, nil},
+		{"zone 1 medium drops express", domain.AddressSnapshot{Country: "AR", Region: "CABA"}, medium, map[string]float64{MethodStandard: 200}, nil},
+		{"zone 2 by country", domain.AddressSnapshot{Country: "AR", Region: "Córdoba"}, small, map[string]float64{MethodStandard: 400}, nil},
+		{"no zone", domain.AddressSnapshot{Country: "UY"}, small, nil, ErrNoRates},
+		{"too heavy", domain.AddressSnapshot{Country: "AR"}, []Parcel{{WeightGrams: 9000}}, nil, ErrNoRates},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rates, err := carrier.QuoteRates(ctx, tt.destination, tt.parcels)
+			if err != tt.err {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			if len(rates) != len(tt.expected) {
+				t.Fatalf("Expected %d rates, got %+v", len(tt.expected), rates)
+			}
+			for _, rate := range rates {
+				if tt.expected[rate.Method] != rate.Amount {
+					t.Errorf("Expected %s at %f, got %f", rate.Method, tt.expected[rate.Method], rate.Amount)
+				}
+			}
+		})
+	}
+}
+
+func TestLocalCarrier_LabelAndTracking(t *testing.T) {
+	ctx := context.Background()
+	carrier := DefaultLocalCarrier()
+	destination := domain.AddressSnapshot{Country: "AR", Region: "Córdoba"}
+
+	label, err := carrier.CreateLabel(ctx, LabelRequest{
+		Reference:   "order-1",
+		Method:      MethodExpress,
+		Destination: destination,
+		Parcels:     []Parcel{{WeightGrams: 500}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	status, err := carrier.GetTrackingStatus(ctx, label.TrackingNumber)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if status.Status != TrackingLabelCreated {
+		t.Errorf("Expected LABEL_CREATED, got %s", status.Status)
+	}
+
+	if _, err := carrier.GetTrackingStatus(ctx, "unknown"); err != ErrTrackingNotFound {
+		t.Errorf("Expected ErrTrackingNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+// Package shippingtest provee un transportista HTTP falso para tests.
+package shippingtest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"order-management-system/internal/domain"
+	"order-management-system/internal/shipping"
+	"strings"
+	"sync"
+)
+
+// Server es una API de transportista falsa respaldada por un LocalCarrier.
+// Registra las etiquetas creadas y permite forzar fallas.
+type Server struct {
+	*httptest.Server
+	APIKey string
+
+	mu      sync.Mutex
+	carrier *shipping.LocalCarrier
+	labels  []shipping.LabelRequest
+	voided  []string
+	fail    bool
+}
+
+func NewServer(apiKey string) *Server {
+	s := &Server{
+		APIKey:  apiKey,
+		carrier: shipping.DefaultLocalCarrier(),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rates", s.rates)
+	mux.HandleFunc("/labels", s.createLabel)
+	mux.HandleFunc("/labels/", s.voidLabel)
+	mux.HandleFunc("/tracking/", s.tracking)
+	s.Server = httptest.NewServer(s.authorize(mux))
+	return s
+}
+
+// SetFailing hace que todas las respuestas sean 503
+func (s *Server) SetFailing(fail bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fail = fail
+}
+
+// Labels devuelve las solicitudes de etiqueta recibidas
+func (s *Server) Labels() []shipping.LabelRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]shipping.LabelRequest(nil), s.labels...)
+}
+
+// Voided devuelve los números de seguimiento de las etiquetas anuladas
+func (s *Server) Voided() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.voided...)
+}
+
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		fail := s.fail
+		s.mu.Unlock()
+		if fail {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "unavailable"})
+			return
+		}
+		if s.APIKey != "" && r.Header.Get("Authorization") != "Bearer "+s.APIKey {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) rates(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Destination domain.AddressSnapshot `json:"destination"`
+		Parcels     []shipping.Parcel      `json:"parcels"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	rates, err := s.carrier.QuoteRates(r.Context(), body.Destination, body.Parcels)
+	if err != nil {
+		rates = nil
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"rates": rates})
+}
+
+func (s *Server) createLabel(w http.ResponseWriter, r *http.Request) {
+	var req shipping.LabelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	label, err := s.carrier.CreateLabel(r.Context(), req)
+	if err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		return
+	}
+	s.mu.Lock()
+	s.labels = append(s.labels, req)
+	s.mu.Unlock()
+	label.LabelURL = s.URL + "/labels/" + label.TrackingNumber + ".pdf"
+	writeJSON(w, http.StatusCreated, label)
+}
+
+func (s *Server) voidLabel(w http.ResponseWriter, r *http.Request) {
+	number := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/labels/"), "/void")
+	if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, "/void") {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+		return
+	}
+	if err := s.carrier.VoidLabel(r.Context(), number); err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+	s.mu.Lock()
+	s.voided = append(s.voided, number)
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) tracking(w http.ResponseWriter, r *http.Request) {
+	number := strings.TrimPrefix(r.URL.Path, "/tracking/")
+	status, err := s.carrier.GetTrackingStatus(r.Context(), number)
+	if errors.Is(err, shipping.ErrTrackingNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,61 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// ErrForbiddenAddress indica un destino en una red interna: loopback, privada, link-local
+// (incluida la metadata de la nube en 169.254.169.254) o reservada
+var ErrForbiddenAddress = errors.New("webhook target is a private or reserved address")
+
+// sharedAddressSpace es el rango 100.64.0.0/10 de CGNAT, que los proveedores usan para redes internas
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// Resolver resuelve nombres de host; net.DefaultResolver lo implementa
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// CheckHost devuelve ErrForbiddenAddress si host es, o resuelve a, una dirección interna
+func CheckHost(ctx context.Context, resolver Resolver, host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		return checkIP(host, ip)
+	}
+	addrs, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if err := checkIP(host, addr.IP); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkIP(host string, ip net.IP) error {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() || sharedAddressSpace.Contains(ip) {
+		return fmt.Errorf("%w: %s resolves to %s", ErrForbiddenAddress, host, ip)
+	}
+	return nil
+}
+
+// controlDial valida la IP a la que se va a conectar, ya resuelta, para que un DNS que cambia
+// entre el alta y el envío no pueda apuntar a la red interna
+func controlDial(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("%w: %s is not an IP address", ErrForbiddenAddress, host)
+	}
+	return checkIP(host, ip)
+}
@@ -0,0 +1,140 @@
+// Package webhooks envía eventos firmados a los endpoints de los partners.
+//
+// Cada request lleva los headers:
+//
+//	X-Webhook-Id         ID de la entrega (para deduplicar reintentos)
+//	X-Webhook-Event      tipo de evento, por ejemplo order.shipped
+//	X-Webhook-Timestamp  segundos Unix del envío
+//	X-Webhook-Signature  sha256=<hex>, HMAC-SHA256 de "<timestamp>.<body>" con el secreto
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderID        = "X-Webhook-Id"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+
+	// maxResponseBody es lo que se guarda de la respuesta en el registro de entregas
+	maxResponseBody = 1024
+)
+
+// Message es un evento listo para enviar a un endpoint
+type Message struct {
+	ID        string
+	Event     string
+	Body      []byte
+	Timestamp time.Time
+}
+
+// Result es la respuesta del endpoint. Err es distinto de nil si no hubo respuesta
+// o si el status no fue 2xx.
+type Result struct {
+	StatusCode int
+	Body       string
+	Err        error
+}
+
+// Sender envía un mensaje firmado con el secreto de la suscripción
+type Sender interface {
+	Send(url, secret string, msg Message) Result
+}
+
+// HTTPSender envía los webhooks por HTTP POST
+type HTTPSender struct {
+	client       *http.Client
+	allowPrivate bool
+}
+
+// SenderOption configura parámetros opcionales del HTTPSender
+type SenderOption func(*HTTPSender)
+
+// WithPrivateNetworks permite enviar a direcciones internas; sólo para desarrollo local y tests
+func WithPrivateNetworks() SenderOption {
+	return func(s *HTTPSender) {
+		s.allowPrivate = true
+	}
+}
+
+// NewHTTPSender usa client tal cual si no es nil. El cliente por defecto rechaza al conectar
+// las direcciones internas, salvo con WithPrivateNetworks.
+func NewHTTPSender(client *http.Client, opts ...SenderOption) *HTTPSender {
+	s := &HTTPSender{client: client}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.client == nil {
+		dialer := &net.Dialer{Timeout: 5 * time.Second, KeepAlive: 30 * time.Second}
+		if !s.allowPrivate {
+			dialer.Control = controlDial
+		}
+		// Sin proxy: el chequeo tiene que ver la IP real del endpoint
+		s.client = &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: 5 * time.Second,
+				MaxIdleConns:        10,
+				IdleConnTimeout:     90 * time.Second,
+			},
+		}
+	}
+	return s
+}
+
+func (s *HTTPSender) Send(url, secret string, msg Message) Result {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(msg.Body))
+	if err != nil {
+		return Result{Err: err}
+	}
+	timestamp := msg.Timestamp.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "order-management-system-webhooks")
+	req.Header.Set(HeaderID, msg.ID)
+	req.Header.Set(HeaderEvent, msg.Event)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(secret, timestamp, msg.Body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return Result{Err: err}
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	result := Result{StatusCode: resp.StatusCode, Body: string(body)}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		result.Err = fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+	return result
+}
+
+// Sign calcula la firma del header X-Webhook-Signature
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify valida la firma recibida por un endpoint; pensado para partners y tests
+func Verify(secret, timestamp, signature string, body []byte) bool {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || !strings.HasPrefix(signature, "sha256=") {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, ts, body)), []byte(signature))
+}
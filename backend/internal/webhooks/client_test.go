@@ -0,0 +1,104 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPSender_SignsRequest(t *testing.T) {
+	var verified bool
+	var event string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		verified = Verify("s3cret", r.Header.Get(HeaderTimestamp), r.Header.Get(HeaderSignature), body)
+		event = r.Header.Get(HeaderEvent)
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	result := NewHTTPSender(nil, WithPrivateNetworks()).Send(server.URL, "s3cret", Message{
+		ID:        "1",
+		Event:     "order.shipped",
+		Body:      []byte(`{"order_id":1}`),
+		Timestamp: time.Now(),
+	})
+
+	if result.Err != nil || result.StatusCode != http.StatusAccepted || result.Body != "ok" {
+		t.Fatalf("Unexpected result %+v", result)
+	}
+	if !verified || event != "order.shipped" {
+		t.Errorf("Expected valid signature for order.shipped, got verified=%v event=%s", verified, event)
+	}
+}
+
+func TestHTTPSender_NonSuccessStatusIsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	result := NewHTTPSender(nil, WithPrivateNetworks()).Send(server.URL, "s", Message{ID: "1", Body: []byte("{}"), Timestamp: time.Now()})
+	if result.Err == nil || result.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected error for 500, got %+v", result)
+	}
+}
+
+func TestHTTPSender_RejectsPrivateAddresses(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	result := NewHTTPSender(nil).Send(server.URL, "s", Message{ID: "1", Body: []byte("{}"), Timestamp: time.Now()})
+	if !errors.Is(result.Err, ErrForbiddenAddress) || called {
+		t.Errorf("Expected ErrForbiddenAddress without reaching the server, got %+v", result)
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	ctx := context.Background()
+	resolver := staticResolver{
+		"partner.example":   {{IP: net.ParseIP("93.184.216.34")}},
+		"metadata.internal": {{IP: net.ParseIP("169.254.169.254")}},
+	}
+	for _, host := range []string{"127.0.0.1", "10.1.2.3", "192.168.0.10", "169.254.169.254", "::1", "fd00::1", "0.0.0.0", "100.64.1.1", "metadata.internal"} {
+		if err := CheckHost(ctx, resolver, host); !errors.Is(err, ErrForbiddenAddress) {
+			t.Errorf("Expected %s to be rejected, got %v", host, err)
+		}
+	}
+	for _, host := range []string{"partner.example", "8.8.8.8"} {
+		if err := CheckHost(ctx, resolver, host); err != nil {
+			t.Errorf("Expected %s to be allowed, got %v", host, err)
+		}
+	}
+}
+
+type staticResolver map[string][]net.IPAddr
+
+func (r staticResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	if addrs, ok := r[host]; ok {
+		return addrs, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func TestVerify_RejectsTamperedBody(t *testing.T) {
+	signature := Sign("secret", 1700000000, []byte(`{"a":1}`))
+	if !Verify("secret", "1700000000", signature, []byte(`{"a":1}`)) {
+		t.Error("Expected signature to verify")
+	}
+	if Verify("secret", "1700000000", signature, []byte(`{"a":2}`)) {
+		t.Error("Expected tampered body to fail verification")
+	}
+	if Verify("other", "1700000000", signature, []byte(`{"a":1}`)) {
+		t.Error("Expected wrong secret to fail verification")
+	}
+}
@@ -1,6 +1,8 @@
 package integration
 
 import (
+	"context"
+	"order-management-system/internal/clock"
 	"order-management-system/internal/config"
 	"order-management-system/internal/domain"
 	"order-management-system/internal/repositories"
@@ -9,27 +11,50 @@ import (
 	"testing"
 )
 
-// This integration test requires a real MySQL database
-// Set environment variables before running:
-// DB_HOST, DB_PORT, DB_USER, DB_PASSWORD, DB_NAME
+// By default this integration test runs against an in-memory SQLite database.
+// To run it against a real server set DB_DRIVER=mysql (DB_HOST, DB_PORT, DB_USER,
+// DB_PASSWORD, DB_NAME) or DB_DRIVER=postgres (DATABASE_URL).
 
 func TestOrderIntegration(t *testing.T) {
-	// Skip if not in integration test mode
-	if os.Getenv("INTEGRATION_TEST") != "true" {
-		t.Skip("Skipping integration test. Set INTEGRATION_TEST=true to run")
+	ctx := context.Background()
+	if os.Getenv("DB_DRIVER") == "" {
+		t.Setenv("DB_DRIVER", "sqlite")
+		t.Setenv("SQLITE_PATH", ":memory:")
 	}
+	t.Setenv("MIGRATE_ON_START", "true")
 
 	// Initialize database
-	db, err := config.InitDB()
+	cfg, _, err := config.Load(nil, os.LookupEnv)
+	if err != nil {
+		t.Fatalf("Invalid configuration: %v", err)
+	}
+	db, err := config.InitDB(ctx, cfg.Database, clock.System())
 	if err != nil {
 		t.Fatalf("Failed to connect to database: %v", err)
 	}
 
 	// Clean up after test
 	defer func() {
+		db.Exec("DELETE FROM outbox_events")
+		db.Exec("DELETE FROM webhook_deliveries")
+		db.Exec("DELETE FROM webhook_subscriptions")
+		db.Exec("DELETE FROM notifications")
+		db.Exec("DELETE FROM notification_preferences")
+		db.Exec("DELETE FROM jobs")
+		db.Exec("DELETE FROM order_changes")
+		db.Exec("DELETE FROM return_transitions")
+		db.Exec("DELETE FROM return_items")
+		db.Exec("DELETE FROM return_authorizations")
+		db.Exec("DELETE FROM refund_lines")
+		db.Exec("DELETE FROM refunds")
+		db.Exec("DELETE FROM payments")
+		db.Exec("DELETE FROM order_tax_lines")
+		db.Exec("DELETE FROM order_item_discounts")
+		db.Exec("DELETE FROM promotion_redemptions")
 		db.Exec("DELETE FROM order_items")
 		db.Exec("DELETE FROM orders")
 		db.Exec("DELETE FROM products")
+		db.Exec("DELETE FROM addresses")
 		db.Exec("DELETE FROM users")
 	}()
 
@@ -37,26 +62,45 @@ func TestOrderIntegration(t *testing.T) {
 	userRepo := repositories.NewUserRepository(db)
 	productRepo := repositories.NewProductRepository(db)
 	orderRepo := repositories.NewOrderRepository(db)
+	addressRepo := repositories.NewAddressRepository(db)
 
 	// Setup service
-	orderService := services.NewOrderService(orderRepo, productRepo, userRepo)
+	addressService := services.NewAddressService(addressRepo, userRepo)
+	orderService := services.NewOrderService(orderRepo, productRepo, userRepo,
+		services.WithAddresses(addressService),
+	)
 
 	// Create test user
 	user := &domain.User{
 		Name:  "Integration Test User",
 		Email: "integration@test.com",
 	}
-	if err := userRepo.Create(user); err != nil {
+	if err := userRepo.Create(ctx, user); err != nil {
 		t.Fatalf("Failed to create user: %v", err)
 	}
 
+	// Create default shipping address
+	address := &domain.Address{
+		AddressSnapshot: domain.AddressSnapshot{
+			RecipientName: user.Name,
+			Line1:         "Av. Colón 1234",
+			City:          "Córdoba",
+			Region:        "Córdoba",
+			PostalCode:    "5000",
+			Country:       "AR",
+		},
+	}
+	if err := addressService.CreateAddress(ctx, user.ID, address); err != nil {
+		t.Fatalf("Failed to create address: %v", err)
+	}
+
 	// Create test product
 	product := &domain.Product{
 		Name:  "Test Product",
 		Price: 100.0,
 		Stock: 10,
 	}
-	if err := productRepo.Create(product); err != nil {
+	if err := productRepo.Create(ctx, product); err != nil {
 		t.Fatalf("Failed to create product: %v", err)
 	}
 
@@ -69,7 +113,7 @@ func TestOrderIntegration(t *testing.T) {
 			},
 		}
 
-		order, err := orderService.CreateOrder(req)
+		order, err := orderService.CreateOrder(ctx, req)
 		if err != nil {
 			t.Fatalf("Failed to create order: %v", err)
 		}
@@ -85,7 +129,7 @@ func TestOrderIntegration(t *testing.T) {
 
 		// Test: Confirm Order
 		t.Run("Confirm Order", func(t *testing.T) {
-			confirmedOrder, err := orderService.ConfirmOrder(order.ID)
+			confirmedOrder, err := orderService.ConfirmOrder(ctx, order.ID)
 			if err != nil {
 				t.Fatalf("Failed to confirm order: %v", err)
 			}
@@ -95,7 +139,7 @@ func TestOrderIntegration(t *testing.T) {
 			}
 
 			// Verify stock reduction
-			updatedProduct, err := productRepo.GetByID(product.ID)
+			updatedProduct, err := productRepo.GetByID(ctx, product.ID)
 			if err != nil {
 				t.Fatalf("Failed to get product: %v", err)
 			}
@@ -107,7 +151,7 @@ func TestOrderIntegration(t *testing.T) {
 
 			// Test: Ship Order
 			t.Run("Ship Order", func(t *testing.T) {
-				shippedOrder, err := orderService.ShipOrder(order.ID)
+				shippedOrder, err := orderService.ShipOrder(ctx, order.ID)
 				if err != nil {
 					t.Fatalf("Failed to ship order: %v", err)
 				}
@@ -129,21 +173,21 @@ func TestOrderIntegration(t *testing.T) {
 			},
 		}
 
-		order, err := orderService.CreateOrder(req)
+		order, err := orderService.CreateOrder(ctx, req)
 		if err != nil {
 			t.Fatalf("Failed to create order: %v", err)
 		}
 
 		// Confirm order (reduces stock)
-		_, err = orderService.ConfirmOrder(order.ID)
+		_, err = orderService.ConfirmOrder(ctx, order.ID)
 		if err != nil {
 			t.Fatalf("Failed to confirm order: %v", err)
 		}
 
-		stockBeforeCancel, _ := productRepo.GetByID(product.ID)
+		stockBeforeCancel, _ := productRepo.GetByID(ctx, product.ID)
 
 		// Cancel order
-		cancelledOrder, err := orderService.CancelOrder(order.ID)
+		cancelledOrder, err := orderService.CancelOrder(ctx, order.ID)
 		if err != nil {
 			t.Fatalf("Failed to cancel order: %v", err)
 		}
@@ -153,7 +197,7 @@ func TestOrderIntegration(t *testing.T) {
 		}
 
 		// Verify stock returned
-		stockAfterCancel, _ := productRepo.GetByID(product.ID)
+		stockAfterCancel, _ := productRepo.GetByID(ctx, product.ID)
 		expectedStock := stockBeforeCancel.Stock + 3
 
 		if stockAfterCancel.Stock != expectedStock {
@@ -1,18 +1,26 @@
 import { useState, useEffect } from 'react';
-import { orderService } from '../services/api';
+import { orderService, paymentService } from '../services/api';
 
 const statusColors = {
   PENDING: 'bg-yellow-100 text-yellow-800',
   CONFIRMED: 'bg-blue-100 text-blue-800',
   SHIPPED: 'bg-green-100 text-green-800',
   CANCELLED: 'bg-red-100 text-red-800',
+  MERGED: 'bg-gray-100 text-gray-800',
 };
 
+const orderEvents = ['order.created', 'order.confirmed', 'order.shipped', 'order.cancelled', 'order.merged'];
+
+// La API identifica los pedidos por número o identificador público; el ID numérico
+// no viaja en las respuestas
+const orderRef = (order) => order.number || order.public_id;
+
 const statusLabels = {
   PENDING: 'Pendiente',
   CONFIRMED: 'Confirmado',
   SHIPPED: 'Enviado',
   CANCELLED: 'Cancelado',
+  MERGED: 'Fusionado',
 };
 
 export default function OrderHistory({ refreshTrigger }) {
@@ -25,11 +33,34 @@ export default function OrderHistory({ refreshTrigger }) {
     loadOrders();
   }, [refreshTrigger]);
 
+  // Actualizaciones en tiempo real; EventSource se reconecta solo enviando Last-Event-ID
+  useEffect(() => {
+    const source = new EventSource(orderService.streamUrl());
+    const handleUpdate = (event) => {
+      const update = JSON.parse(event.data);
+      if (event.type === 'order.created') {
+        loadOrders();
+        return;
+      }
+      setOrders((current) =>
+        current.map((order) =>
+          orderRef(order) === (update.number || update.public_id)
+            ? { ...order, status: update.status, total: update.total, tracking_number: update.tracking_number || order.tracking_number }
+            : order
+        )
+      );
+    };
+    orderEvents.forEach((type) => source.addEventListener(type, handleUpdate));
+    // El servidor perdió eventos intermedios: recargar la lista completa
+    source.addEventListener('reset', () => loadOrders());
+    return () => source.close();
+  }, []);
+
   const loadOrders = async () => {
     try {
       setLoading(true);
       const response = await orderService.getAll();
-      setOrders(response.data.sort((a, b) => b.id - a.id));
+      setOrders(response.data.sort((a, b) => new Date(b.created_at) - new Date(a.created_at)));
     } catch (err) {
       setError('Error al cargar pedidos');
       console.error(err);
@@ -38,11 +69,23 @@ export default function OrderHistory({ refreshTrigger }) {
     }
   };
 
-  const handleConfirm = async (orderId) => {
-    if (!confirm('¿Confirmar este pedido? Se reducirá el stock.')) return;
+  const handleConfirm = async (order) => {
+    if (!confirm('¿Confirmar este pedido? Se cobrará el pago y se reducirá el stock.')) return;
     
     try {
-      await orderService.confirm(orderId);
+      const authorized = order.payments?.some((p) => p.status === 'AUTHORIZED');
+      if (!authorized) {
+        // Tarjeta de prueba del simulador de pagos
+        const number = prompt('Número de tarjeta', '4242424242424242');
+        if (!number) return;
+        await paymentService.authorize(orderRef(order), {
+          number,
+          exp_month: 12,
+          exp_year: new Date().getFullYear() + 1,
+          cvc: '123',
+        });
+      }
+      await orderService.confirm(orderRef(order));
       loadOrders();
     } catch (err) {
       alert(err.response?.data?.error || 'Error al confirmar pedido');
@@ -98,15 +141,15 @@ export default function OrderHistory({ refreshTrigger }) {
   return (
     <div className="space-y-4">
       {orders.map((order) => (
-        <div key={order.id} className="bg-white rounded-lg shadow-md overflow-hidden">
+        <div key={orderRef(order)} className="bg-white rounded-lg shadow-md overflow-hidden">
           <div 
             className="p-4 cursor-pointer hover:bg-gray-50 transition-colors"
-            onClick={() => setExpandedOrder(expandedOrder === order.id ? null : order.id)}
+            onClick={() => setExpandedOrder(expandedOrder === orderRef(order) ? null : orderRef(order))}
           >
             <div className="flex justify-between items-start">
               <div className="flex-1">
                 <div className="flex items-center gap-3 mb-2">
-                  <h3 className="font-semibold text-lg">Pedido #{order.id}</h3>
+                  <h3 className="font-semibold text-lg">Pedido {orderRef(order)}</h3>
                   <span className={`px-3 py-1 rounded-full text-xs font-semibold ${statusColors[order.status]}`}>
                     {statusLabels[order.status]}
                   </span>
@@ -129,7 +172,7 @@ export default function OrderHistory({ refreshTrigger }) {
             </div>
           </div>
 
-          {expandedOrder === order.id && (
+          {expandedOrder === orderRef(order) && (
             <div className="border-t border-gray-200 p-4 bg-gray-50">
               <h4 className="font-semibold mb-3">Productos:</h4>
               <div className="space-y-2 mb-4">
@@ -152,13 +195,13 @@ export default function OrderHistory({ refreshTrigger }) {
                 {order.status === 'PENDING' && (
                   <>
                     <button
-                      onClick={() => handleConfirm(order.id)}
+                      onClick={() => handleConfirm(order)}
                       className="flex-1 bg-blue-500 text-white py-2 px-4 rounded hover:bg-blue-600 transition-colors"
                     >
                       ✓ Confirmar
                     </button>
                     <button
-                      onClick={() => handleCancel(order.id)}
+                      onClick={() => handleCancel(orderRef(order))}
                       className="flex-1 bg-red-500 text-white py-2 px-4 rounded hover:bg-red-600 transition-colors"
                     >
                       ✕ Cancelar
@@ -168,13 +211,13 @@ export default function OrderHistory({ refreshTrigger }) {
                 {order.status === 'CONFIRMED' && (
                   <>
                     <button
-                      onClick={() => handleShip(order.id)}
+                      onClick={() => handleShip(orderRef(order))}
                       className="flex-1 bg-green-500 text-white py-2 px-4 rounded hover:bg-green-600 transition-colors"
                     >
                       🚚 Enviar
                     </button>
                     <button
-                      onClick={() => handleCancel(order.id)}
+                      onClick={() => handleCancel(orderRef(order))}
                       className="flex-1 bg-red-500 text-white py-2 px-4 rounded hover:bg-red-600 transition-colors"
                     >
                       ✕ Cancelar
@@ -29,6 +29,16 @@ export const orderService = {
   confirm: (id) => api.patch(`/orders/${id}/confirm`),
   ship: (id) => api.patch(`/orders/${id}/ship`),
   cancel: (id) => api.patch(`/orders/${id}/cancel`),
+  // Stream SSE de cambios de estado; se consume con EventSource
+  streamUrl: (params = {}) => {
+    const query = new URLSearchParams(params).toString();
+    return `${API_URL}/orders/stream${query ? `?${query}` : ''}`;
+  },
+};
+
+export const paymentService = {
+  getByOrder: (orderId) => api.get(`/orders/${orderId}/payments`),
+  authorize: (orderId, card) => api.post(`/orders/${orderId}/payments`, card),
 };
 
 export default api;